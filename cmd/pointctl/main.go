@@ -0,0 +1,376 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/di"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints"
+	"github.com/sirawong/point-accumulate-interview/internal/services/audit"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+	"github.com/sirawong/point-accumulate-interview/pkg/storage"
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
+	"github.com/sirawong/point-accumulate-interview/pkg/utils"
+)
+
+const (
+	exitOK         = 0
+	exitFailure    = 1
+	exitUsageError = 2
+
+	// formatZip packs the CSV reports into a single archive.
+	formatZip = "zip"
+
+	summaryFileName = "point-summary_%s.csv"
+	zipFileName     = "point-summary.zip"
+)
+
+type options struct {
+	outputDir string
+	dryRun    bool
+	date      string
+	format    report.Format
+	zip       bool
+	tenant    string
+	paths     []string
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		log.Println(err)
+		return exitUsageError
+	}
+
+	inputs, err := collectInputs(opts.paths)
+	if err != nil {
+		log.Println(err)
+		return exitUsageError
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Println("Failed to load config:", err)
+		return exitFailure
+	}
+	if opts.tenant != tenant.Default && !slices.Contains(cfg.Tenants, opts.tenant) {
+		log.Printf("Unknown tenant %s: it must be listed in TENANTS", opts.tenant)
+		return exitUsageError
+	}
+
+	files, closeFiles, err := openInputs(inputs, opts.date)
+	if err != nil {
+		log.Println(err)
+		return exitUsageError
+	}
+	defer closeFiles()
+
+	commands, cleanup, err := di.NewCommands(cfg)
+	if err != nil {
+		log.Println("Failed to initialize di:", err)
+		return exitFailure
+	}
+	defer cleanup()
+
+	ctx := tenant.WithTenant(context.Background(), opts.tenant)
+	target, err := reportTarget(ctx, cfg, opts)
+	if err != nil {
+		log.Println("Failed to initialize storage:", err)
+		return exitFailure
+	}
+
+	if opts.dryRun {
+		_, err = commands.AccumulatePointSvc.DryRunMultipleFiles(ctx, files, target)
+	} else {
+		err = execute(ctx, commands, files)
+	}
+	if err != nil {
+		log.Println("Failed to process files:", err)
+		return exitFailure
+	}
+
+	// A run writes the live CSV reports under FILE_PATH; copies are only needed
+	// elsewhere or in another format. A dry run already wrote to the target.
+	copies := !opts.dryRun && (opts.outputDir != "" || opts.format != report.FormatCSV)
+	written, err := writeOutput(ctx, commands.AccumulatePointSvc, target, files, copies, opts.zip)
+	if err != nil {
+		log.Println("Failed to write output:", err)
+		return exitFailure
+	}
+
+	for _, path := range written {
+		fmt.Println(path)
+	}
+	if opts.dryRun {
+		log.Println("Dry run: no points were stored")
+	}
+
+	return exitOK
+}
+
+// execute stores the points of files and records the run in the audit log.
+func execute(ctx context.Context, commands *di.Commands, files []accumulatepoints.FileInput) error {
+	entry := entity.AuditEntry{
+		Actor:  audit.LocalActor(),
+		Source: audit.SourcePointctl,
+		Action: audit.ActionUpload,
+	}
+	for _, file := range files {
+		digest, err := audit.HashFile(file.Name, file.Reader)
+		if err != nil {
+			return err
+		}
+		entry.Files = append(entry.Files, digest)
+	}
+
+	result, err := commands.AccumulatePointSvc.ExecuteMultipleFiles(ctx, files)
+	if err != nil {
+		entry.Details = map[string]string{"error": err.Error()}
+	} else {
+		entry.Details = audit.ProcessDetails(result)
+	}
+
+	if _, recordErr := commands.AuditSvc.Record(ctx, entry); recordErr != nil {
+		log.Println("Failed to record the run in the audit log:", recordErr)
+		if err == nil {
+			err = recordErr
+		}
+	}
+	return err
+}
+
+func parseOptions(args []string) (options, error) {
+	var opts options
+
+	fs := flag.NewFlagSet("pointctl", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pointctl [flags] <file.csv|directory>...")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.outputDir, "out", "", "directory for the point-summary files (default: directory of FILE_PATH)")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "calculate and write reports to -out without storing any points")
+	fs.StringVar(&opts.date, "date", "", "purchase date (YYYY-MM-DD) for every input, instead of the date in each filename")
+	format := fs.String("format", string(report.FormatCSV), "output format: csv, json, xlsx, parquet, or zip for a single archive of the CSV files")
+	fs.StringVar(&opts.tenant, "tenant", tenant.Default, "loyalty program to credit, one of TENANTS (default: the default program)")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+
+	opts.paths = fs.Args()
+	if len(opts.paths) == 0 {
+		fs.Usage()
+		return opts, errors.New("no input files or directories given")
+	}
+
+	if strings.EqualFold(strings.TrimSpace(*format), formatZip) {
+		opts.format, opts.zip = report.FormatCSV, true
+	} else {
+		parsed, err := report.ParseFormat(*format)
+		if err != nil {
+			return opts, err
+		}
+		opts.format = parsed
+	}
+
+	// A dry run's reports would otherwise replace the live ones under FILE_PATH,
+	// which the API serves.
+	if opts.dryRun && opts.outputDir == "" {
+		return opts, errors.New("-dry-run needs -out")
+	}
+
+	if err := tenant.Validate(opts.tenant); err != nil {
+		return opts, err
+	}
+
+	if opts.date != "" {
+		if _, err := time.Parse(time.DateOnly, opts.date); err != nil {
+			return opts, fmt.Errorf("invalid -date %q: must be YYYY-MM-DD", opts.date)
+		}
+	}
+
+	return opts, nil
+}
+
+// collectInputs expands directories to the CSV files directly inside them.
+func collectInputs(paths []string) ([]string, error) {
+	var inputs []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			inputs = append(inputs, path)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(path, "*.csv"))
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, matches...)
+	}
+
+	if len(inputs) == 0 {
+		return nil, errors.New("no CSV files found")
+	}
+
+	sort.Strings(inputs)
+	return inputs, nil
+}
+
+func openInputs(inputs []string, dateOverride string) ([]accumulatepoints.FileInput, func(), error) {
+	var opened []*os.File
+	closeAll := func() {
+		for _, file := range opened {
+			file.Close()
+		}
+	}
+
+	files := make([]accumulatepoints.FileInput, 0, len(inputs))
+	for _, input := range inputs {
+		if filepath.Ext(input) != ".csv" {
+			closeAll()
+			return nil, nil, fmt.Errorf("%s: not a .csv file", input)
+		}
+
+		var (
+			purchasedDate time.Time
+			err           error
+		)
+		if dateOverride != "" {
+			purchasedDate, err = time.Parse(time.DateOnly, dateOverride)
+		} else {
+			purchasedDate, err = accumulatepoints.PurchasedDateFromFilename(filepath.Base(input))
+		}
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("%s: %w", input, err)
+		}
+
+		file, err := os.Open(input)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		opened = append(opened, file)
+
+		files = append(files, accumulatepoints.FileInput{
+			Name:          filepath.Base(input),
+			PurchasedDate: purchasedDate,
+			Reader:        file,
+		})
+	}
+
+	return files, closeAll, nil
+}
+
+// reportTarget is where the reports of this run are written: the configured
+// storage under FILE_PATH, or the local -out directory. The configured storage is
+// never redirected, so inputs are still archived where the service keeps them.
+func reportTarget(ctx context.Context, cfg *config.Config, opts options) (accumulatepoints.ReportTarget, error) {
+	target := accumulatepoints.ReportTarget{FilePath: cfg.FilePath, Format: opts.format}
+	if opts.outputDir != "" {
+		target.Store = storage.NewLocal("")
+		target.FilePath = filepath.Join(opts.outputDir, summaryFileName)
+	} else {
+		store, err := storage.New(cfg)
+		if err != nil {
+			return target, err
+		}
+		target.Store = store
+	}
+
+	target.FilePath = report.ReplaceExtension(tenant.Path(ctx, target.FilePath), opts.format)
+	return target, nil
+}
+
+// writeOutput returns the keys of the reports for the processed dates, writing
+// them to target first when copies is set, and packing them into a single
+// archive beside them when archive is set.
+func writeOutput(ctx context.Context, service accumulatepoints.AccumulatePointService, target accumulatepoints.ReportTarget, files []accumulatepoints.FileInput, copies, archive bool) ([]string, error) {
+	seen := make(map[string]struct{})
+	var reports []string
+	for _, file := range files {
+		path := fmt.Sprintf(target.FilePath, file.PurchasedDate.Format(time.DateOnly))
+		if _, ok := seen[path]; ok {
+			continue
+		}
+		seen[path] = struct{}{}
+		reports = append(reports, path)
+
+		if !copies {
+			continue
+		}
+		err := put(ctx, target.Store, path, func(w io.Writer) error {
+			return service.WriteSummary(ctx, file.PurchasedDate, target.Format, w)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if !archive {
+		return reports, nil
+	}
+
+	zipPath := filepath.Join(filepath.Dir(target.FilePath), zipFileName)
+	err := put(ctx, target.Store, zipPath, func(w io.Writer) error {
+		return zipReports(ctx, target.Store, reports, w)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{zipPath}, nil
+}
+
+func zipReports(ctx context.Context, store storage.Storage, reports []string, w io.Writer) error {
+	zipWriter := zip.NewWriter(w)
+	for _, report := range reports {
+		file, err := store.Open(ctx, report)
+		if err != nil {
+			return fmt.Errorf("%s: %w", report, err)
+		}
+
+		err = utils.AddReaderToZip(zipWriter, file, filepath.Base(report))
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
+
+// put stores what write produces under key, piping it to the store as it is
+// written.
+func put(ctx context.Context, store storage.Storage, key string, write func(io.Writer) error) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(write(pw))
+	}()
+
+	err := store.Put(ctx, key, pr, -1)
+	// Unblocks write if the store gave up before reading everything.
+	pr.CloseWithError(err)
+	return err
+}
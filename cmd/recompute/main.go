@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/di"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/services/audit"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
+)
+
+func main() {
+	swap := flag.Bool("swap", false, "replace live balances with the recomputed ones after printing the diff")
+	tenantID := flag.String("tenant", tenant.Default, "loyalty program to recompute, one of TENANTS (default: the default program)")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+	if *tenantID != tenant.Default && !slices.Contains(cfg.Tenants, *tenantID) {
+		log.Fatalf("Unknown tenant %s: it must be listed in TENANTS", *tenantID)
+	}
+
+	commands, cleanup, err := di.NewCommands(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize di:", err)
+	}
+	defer cleanup()
+
+	ctx := tenant.WithTenant(context.Background(), *tenantID)
+
+	svc := commands.AccumulatePointSvc
+
+	result, err := svc.Recompute(ctx)
+	if err != nil {
+		log.Fatal("Failed to recompute balances:", err)
+	}
+	record(ctx, commands, audit.ActionRecompute, map[string]string{
+		"customers":        strconv.Itoa(result.Customers),
+		"changed":          strconv.Itoa(result.Changed),
+		"legacy_purchases": strconv.Itoa(result.LegacyPurchases),
+		"snapshot_at":      result.SnapshotAt.Format(time.RFC3339Nano),
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "customer_id\tcurrent_points\trecomputed_points\tdelta")
+	for _, diff := range result.Diffs {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%+d\n", diff.CustomerID, diff.CurrentPoints, diff.RecomputedPoints, diff.Delta)
+	}
+	w.Flush()
+
+	log.Printf("Recomputed %d customers, %d changed (snapshot %s)", result.Customers, result.Changed, result.SnapshotAt.Format("2006-01-02T15:04:05.000Z07:00"))
+	if result.LegacyPurchases > 0 {
+		log.Printf("Kept %d purchases stored without a category as they are", result.LegacyPurchases)
+	}
+
+	if !*swap {
+		log.Println("Shadow collection left in place; rerun with -swap to apply")
+		return
+	}
+
+	swapped, err := svc.SwapRecompute(ctx, result.SnapshotAt)
+	if err != nil {
+		log.Fatal("Failed to swap recomputed balances:", err)
+	}
+	record(ctx, commands, audit.ActionSwap, map[string]string{
+		"snapshot_at": result.SnapshotAt.Format(time.RFC3339Nano),
+		"swapped":     strconv.Itoa(swapped.Swapped),
+		"skipped":     strconv.Itoa(len(swapped.Skipped)),
+	})
+
+	log.Printf("Recomputed balances of %d customers are now live", swapped.Swapped)
+	if len(swapped.Skipped) > 0 {
+		log.Printf("Kept the live balances of %d customers written to during the swap: %s", len(swapped.Skipped), strings.Join(swapped.Skipped, ", "))
+	}
+}
+
+func record(ctx context.Context, commands *di.Commands, action string, details map[string]string) {
+	_, err := commands.AuditSvc.Record(ctx, entity.AuditEntry{
+		Actor:   audit.LocalActor(),
+		Source:  audit.SourceRecompute,
+		Action:  action,
+		Details: details,
+	})
+	if err != nil {
+		log.Fatal("Failed to record the run in the audit log:", err)
+	}
+}
@@ -3,10 +3,14 @@ package di
 import (
 	"net/http"
 
+	"github.com/sirawong/point-accumulate-interview/internal/handler/inbox"
 	"github.com/sirawong/point-accumulate-interview/pkg/config"
 )
 
 type Application struct {
-	httpServer *http.Server
-	Cfg        *config.Config
+	httpServer   *http.Server
+	inboxWatcher *inbox.Watcher
+	stopInbox    func()
+	inboxDone    chan struct{}
+	Cfg          *config.Config
 }
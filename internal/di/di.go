@@ -2,11 +2,27 @@ package di
 
 import (
 	"github.com/sirawong/point-accumulate-interview/internal/handler/http"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/inbox"
+	adjustmentsdb "github.com/sirawong/point-accumulate-interview/internal/repository/mongodb/adjustments"
+	auditdb "github.com/sirawong/point-accumulate-interview/internal/repository/mongodb/audit"
 	customerdb "github.com/sirawong/point-accumulate-interview/internal/repository/mongodb/customers"
+	householdsdb "github.com/sirawong/point-accumulate-interview/internal/repository/mongodb/households"
+	mergesdb "github.com/sirawong/point-accumulate-interview/internal/repository/mongodb/merges"
 	rulesdb "github.com/sirawong/point-accumulate-interview/internal/repository/mongodb/rules"
+	tiersdb "github.com/sirawong/point-accumulate-interview/internal/repository/mongodb/tiers"
 	"github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints"
+	"github.com/sirawong/point-accumulate-interview/internal/services/adjustments"
+	"github.com/sirawong/point-accumulate-interview/internal/services/audit"
+	"github.com/sirawong/point-accumulate-interview/internal/services/customers"
+	"github.com/sirawong/point-accumulate-interview/internal/services/households"
+	"github.com/sirawong/point-accumulate-interview/internal/services/merges"
+	"github.com/sirawong/point-accumulate-interview/internal/services/rules"
+	"github.com/sirawong/point-accumulate-interview/internal/services/transfers"
 	"github.com/sirawong/point-accumulate-interview/pkg/config"
 	"github.com/sirawong/point-accumulate-interview/pkg/database/mongodb"
+	"github.com/sirawong/point-accumulate-interview/pkg/storage"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func NewNewApplication() (*Application, func(), error) {
@@ -15,24 +31,83 @@ func NewNewApplication() (*Application, func(), error) {
 		return nil, nil, err
 	}
 
-	db, cleanup, err := mongodb.NewMongoConn(cfg)
+	store, err := storage.New(cfg)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	customerRepo := customerdb.NewCustomerRepository(db)
-	rulesRepo := rulesdb.NewRuleRepository(db)
+	authenticator, err := auth.New(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, cleanup, err := mongodb.NewMongoConn(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	accumulatePointsSrv := accumulatepoints.NewAccumulatePointService(rulesRepo, customerRepo, cfg)
+	accumulatePointsSrv := newAccumulatePointService(db, store, cfg)
+	ruleSrv := rules.NewRuleService(rulesdb.NewRuleRepository(db))
+	customerSrv := customers.NewCustomerService(customerdb.NewCustomerRepository(db), tiersdb.NewTierRepository(db))
+	adjustmentSrv := adjustments.NewAdjustmentService(adjustmentsdb.NewAdjustmentRepository(db), customerdb.NewCustomerRepository(db), cfg)
+	transferSrv := transfers.NewTransferService(customerdb.NewCustomerRepository(db), cfg)
+	householdSrv := households.NewHouseholdService(householdsdb.NewHouseholdRepository(db), customerdb.NewCustomerRepository(db), cfg)
+	mergeSrv := merges.NewMergeService(mergesdb.NewMergeRepository(db), customerdb.NewCustomerRepository(db))
+	auditSrv := audit.NewAuditService(auditdb.NewAuditRepository(db))
 
-	apHandler := http.NewAccumulatePointHandler(accumulatePointsSrv, cfg)
-	httpRouter := http.NewRouter(apHandler)
+	apHandler := http.NewAccumulatePointHandler(accumulatePointsSrv, store, cfg)
+	ruleHandler := http.NewRuleHandler(ruleSrv)
+	reportHandler := http.NewReportHandler(accumulatePointsSrv)
+	customerHandler := http.NewCustomerHandler(customerSrv)
+	adjustmentHandler := http.NewAdjustmentHandler(adjustmentSrv)
+	transferHandler := http.NewTransferHandler(transferSrv)
+	householdHandler := http.NewHouseholdHandler(householdSrv)
+	mergeHandler := http.NewMergeHandler(mergeSrv)
+	auditHandler := http.NewAuditHandler(auditSrv)
+	httpRouter := http.NewRouter(authenticator, apHandler, ruleHandler, reportHandler, customerHandler, adjustmentHandler, transferHandler, householdHandler, mergeHandler, auditHandler)
 	httpServer := httpRouter.NewServer(cfg)
 
+	var inboxWatcher *inbox.Watcher
+	if cfg.InboxDir != "" {
+		inboxWatcher = inbox.NewWatcher(accumulatePointsSrv, auditSrv, cfg)
+	}
+
 	return &Application{
-			httpServer: httpServer,
-			Cfg:        cfg,
+			httpServer:   httpServer,
+			inboxWatcher: inboxWatcher,
+			Cfg:          cfg,
 		}, func() {
 			cleanup()
 		}, nil
 }
+
+// Commands are the services the command-line tools use, without the HTTP server.
+type Commands struct {
+	AccumulatePointSvc accumulatepoints.AccumulatePointService
+	AuditSvc           audit.AuditService
+}
+
+// NewCommands wires the services for command-line tools.
+func NewCommands(cfg *config.Config) (*Commands, func(), error) {
+	store, err := storage.New(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, cleanup, err := mongodb.NewMongoConn(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Commands{
+		AccumulatePointSvc: newAccumulatePointService(db, store, cfg),
+		AuditSvc:           audit.NewAuditService(auditdb.NewAuditRepository(db)),
+	}, cleanup, nil
+}
+
+func newAccumulatePointService(db *mongo.Database, store storage.Storage, cfg *config.Config) accumulatepoints.AccumulatePointService {
+	customerRepo := customerdb.NewCustomerRepository(db)
+	rulesRepo := rulesdb.NewRuleRepository(db)
+
+	return accumulatepoints.NewAccumulatePointService(rulesRepo, customerRepo, tiersdb.NewTierRepository(db), store, cfg)
+}
@@ -16,6 +16,20 @@ func (a *Application) Start() error {
 		}
 	}()
 
+	if a.inboxWatcher != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		a.stopInbox = cancel
+		a.inboxDone = make(chan struct{})
+
+		go func() {
+			defer close(a.inboxDone)
+			log.Printf("Watching inbox %s", a.Cfg.InboxDir)
+			if err := a.inboxWatcher.Run(ctx); err != nil {
+				log.Fatalf("inbox watcher stopped with error: %v", err)
+			}
+		}()
+	}
+
 	return nil
 }
 
@@ -25,5 +39,14 @@ func (a *Application) Shutdown(ctx context.Context) error {
 		return err
 	}
 
+	if a.stopInbox != nil {
+		a.stopInbox()
+		select {
+		case <-a.inboxDone:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
 	return nil
 }
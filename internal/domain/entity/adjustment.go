@@ -0,0 +1,62 @@
+package entity
+
+import "time"
+
+type AdjustmentReason string
+
+const (
+	AdjustmentReasonGoodwill        AdjustmentReason = "GOODWILL"
+	AdjustmentReasonServiceRecovery AdjustmentReason = "SERVICE_RECOVERY"
+	AdjustmentReasonCorrection      AdjustmentReason = "CORRECTION"
+	AdjustmentReasonMigration       AdjustmentReason = "MIGRATION"
+	AdjustmentReasonFraud           AdjustmentReason = "FRAUD"
+)
+
+var AdjustmentReasons = []AdjustmentReason{
+	AdjustmentReasonGoodwill,
+	AdjustmentReasonServiceRecovery,
+	AdjustmentReasonCorrection,
+	AdjustmentReasonMigration,
+	AdjustmentReasonFraud,
+}
+
+type AdjustmentStatus string
+
+const (
+	AdjustmentPending  AdjustmentStatus = "PENDING"
+	AdjustmentApproved AdjustmentStatus = "APPROVED"
+	AdjustmentRejected AdjustmentStatus = "REJECTED"
+)
+
+// Adjustment is a manual credit, or a debit when Points is negative. It only
+// changes the customer's balance once it is approved, on the day it is approved.
+type Adjustment struct {
+	ID          string
+	CustomerID  string
+	Points      int64
+	ReasonCode  AdjustmentReason
+	Reference   string
+	Status      AdjustmentStatus
+	RequestedBy string
+	RequestedAt time.Time
+	DecidedBy   string
+	DecidedAt   *time.Time
+}
+
+// AdjustmentFilter selects adjustments; an empty field matches any.
+type AdjustmentFilter struct {
+	CustomerID string
+	Status     AdjustmentStatus
+}
+
+// Movement returns the point movement that records the approved adjustment in the
+// customer's history.
+func (a Adjustment) Movement(date time.Time) Record {
+	return Record{
+		PurchaseDate: date,
+		Points:       a.Points,
+		Type:         AdjustmentTransaction,
+		AdjustmentID: a.ID,
+		ReasonCode:   a.ReasonCode,
+	}
+}
@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// AuditEntry records one state-changing operation: who did it, through which
+// source, and what it touched. Entries form a chain: Hash covers the entry and
+// PrevHash, the Hash of the entry before it, so changing or removing any entry
+// breaks every hash after it.
+type AuditEntry struct {
+	Sequence   int64
+	OccurredAt time.Time
+	Actor      string
+	// Source is api, inbox, pointctl or recompute; ClientIP and Status are set
+	// for API requests only.
+	Source   string
+	ClientIP string
+	Action   string
+	Target   string
+	Status   int
+	Files    []AuditFile
+	Details  map[string]string
+	PrevHash string
+	Hash     string
+}
+
+// AuditFile identifies an uploaded file by its content.
+type AuditFile struct {
+	Name   string
+	SHA256 string
+	Size   int64
+}
+
+// AuditFilter selects audit entries. Zero fields match everything, and a Limit of
+// zero returns every match.
+type AuditFilter struct {
+	Actor         string
+	Action        string
+	From          time.Time
+	To            time.Time
+	AfterSequence int64
+	Limit         int64
+}
+
+// AuditVerification is the result of checking the audit chain. FirstBroken is the
+// sequence of the first entry whose hash or link does not match, when Valid is
+// false.
+type AuditVerification struct {
+	Entries     int64
+	Valid       bool
+	FirstBroken int64
+	Reason      string
+	LastHash    string
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "slices"
+
+// BranchGroup is a named set of branches, such as a region, that rules can
+// target as a whole.
+type BranchGroup struct {
+	Name      string
+	BranchIDs []string
+}
+
+type BranchGroups []BranchGroup
+
+// GroupsOf returns the names of the groups that include branchID.
+func (groups BranchGroups) GroupsOf(branchID string) []string {
+	result := make([]string, 0)
+	for _, group := range groups {
+		if slices.Contains(group.BranchIDs, branchID) {
+			result = append(result, group.Name)
+		}
+	}
+	return result
+}
+
+// Resolve returns rules with their branch groups expanded to the branches in
+// them, which MatchesBranch needs. A group that does not exist matches nothing.
+func (groups BranchGroups) Resolve(rules []Rule) []Rule {
+	result := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		rule.Conditions.groupBranchIDs = nil
+		for _, name := range rule.Conditions.BranchGroups {
+			for _, group := range groups {
+				if group.Name == name {
+					rule.Conditions.groupBranchIDs = append(rule.Conditions.groupBranchIDs, group.BranchIDs...)
+				}
+			}
+		}
+		result = append(result, rule)
+	}
+	return result
+}
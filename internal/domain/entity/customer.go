@@ -14,14 +14,101 @@ type Customer struct {
 	UpdatedAt        time.Time
 	Records          []Record
 	PointsByDate     map[string]int64
+	Attributes       CustomerAttributes
+
+	// HouseholdID is the household the customer pools their points into, if any.
+	HouseholdID string
+
+	// MergedInto is the customer this account was merged into. A merged account
+	// keeps no points or history and only remains so its ID still resolves.
+	MergedInto string
+	// Aliases are the IDs of the accounts merged into this one.
+	Aliases []string
+}
+
+// CustomerAttributes are imported customer details that rules can match on.
+// BirthMonth is 1 to 12, or 0 when unknown.
+type CustomerAttributes struct {
+	SignupDate *time.Time
+	BirthMonth int
+	Segment    string
+}
+
+// UpdateCustomerAttributes replaces a customer's attributes, creating the
+// customer when they have not purchased yet.
+type UpdateCustomerAttributes struct {
+	CustomerID string
+	Attributes CustomerAttributes
 }
 
+type TransactionType string
+
+// A purchase record has an empty TransactionType. A return takes back part of an
+// earlier purchase and a void all of what is left of it. An adjustment is a
+// manual credit or debit. A transfer moves points between two customers, and a
+// pool movement moves a household member's points into the household's balance.
+const (
+	PurchaseTransaction   TransactionType = ""
+	ReturnTransaction     TransactionType = "RETURN"
+	VoidTransaction       TransactionType = "VOID"
+	AdjustmentTransaction TransactionType = "ADJUSTMENT"
+	TransferTransaction   TransactionType = "TRANSFER"
+	PoolTransaction       TransactionType = "POOL"
+)
+
+// Record is a point movement: one purchase and the points a single rule version awarded for it.
+// Purchases that earned nothing during a recompute are kept with an empty RuleID.
+// Tier is the customer's tier when the points were earned; Points include its multiplier.
+// A basket rule's movement is stored on the first line of the basket that matched it.
+// A return movement has a negative Amount, the amount returned, and negative Points,
+// taken back from the movement of the same rule on the purchase made on
+// OriginalPurchaseDate. An adjustment movement only has the date, points and the
+// adjustment it records. Transfer and pool movements are stored on both sides,
+// negative on the one giving the points, each naming the other in CounterpartyID.
 type Record struct {
 	ProductID    string
+	CategoryID   string
 	BranchID     string
+	ReceiptID    string
 	Amount       decimal.Decimal
 	PurchaseDate time.Time
+	RuleID       string
+	RuleVersion  int64
+	Tier         string
+	Points       int64
+
+	Type                 TransactionType
+	OriginalPurchaseDate *time.Time
+
+	AdjustmentID string
+	ReasonCode   AdjustmentReason
+
+	TransferID     string
+	CounterpartyID string
 }
+
+// IsPurchase reports whether the movement is for a purchase.
+func (r Record) IsPurchase() bool {
+	return r.Type == PurchaseTransaction
+}
+
+// IsReturn reports whether the movement takes back points of an earlier purchase.
+func (r Record) IsReturn() bool {
+	return r.Type == ReturnTransaction || r.Type == VoidTransaction
+}
+
+// IsAdjustment reports whether the movement is a manual adjustment.
+func (r Record) IsAdjustment() bool {
+	return r.Type == AdjustmentTransaction
+}
+
+// IsTransfer reports whether the movement moves points between customers or into
+// a household. These points were earned by someone else, so they do not count
+// towards tiers.
+func (r Record) IsTransfer() bool {
+	return r.Type == TransferTransaction || r.Type == PoolTransaction
+}
+
 type UpdateCustomer struct {
 	CustomerID       string
 	PointsToAdd      int64
@@ -40,3 +127,9 @@ func (customers Customers) GetCustomerByID(id string) (Customer, bool) {
 	}
 	return Customer{}, false
 }
+
+// CustomerProfile is a customer with their tier standing.
+type CustomerProfile struct {
+	Customer Customer
+	Tier     CustomerTier
+}
@@ -0,0 +1,121 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/shopspring/decimal"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/expr"
+)
+
+// ExpressionVars are the variables rule expressions can use. They describe one
+// purchase line and the customer behind it; weekday is 0 for Sunday to 6 for
+// Saturday.
+var ExpressionVars = map[string]expr.Type{
+	"amount":         expr.Number,
+	"product":        expr.String,
+	"category":       expr.String,
+	"branch":         expr.String,
+	"receipt":        expr.String,
+	"month":          expr.Number,
+	"day":            expr.Number,
+	"weekday":        expr.Number,
+	"tier":           expr.String,
+	"segment":        expr.String,
+	"birthday_month": expr.Bool,
+	"first_purchase": expr.Bool,
+}
+
+// Compile compiles the rule's expressions so they can be evaluated. A rule whose
+// expressions are already compiled is returned as it is.
+func (r Rule) Compile() (Rule, error) {
+	if r.Conditions.Expression != "" && r.Conditions.expression == nil {
+		program, err := compileExpression(r.Conditions.Expression, expr.Bool)
+		if err != nil {
+			return r, fmt.Errorf("conditions expression: %w", err)
+		}
+		r.Conditions.expression = program
+	}
+
+	if r.Reward.Expression != "" && r.Reward.expression == nil {
+		program, err := compileExpression(r.Reward.Expression, expr.Number)
+		if err != nil {
+			return r, fmt.Errorf("reward expression: %w", err)
+		}
+		r.Reward.expression = program
+	}
+
+	return r, nil
+}
+
+// MatchesExpression reports whether the purchase meets the conditions
+// expression. It never matches when the expression was not compiled or fails.
+func (c Conditions) MatchesExpression(purchase Record, purchaser Purchaser) bool {
+	if c.Expression == "" {
+		return true
+	}
+	if c.expression == nil {
+		return false
+	}
+
+	matched, err := c.expression.EvalBool(expressionEnv(purchase, purchaser))
+	return err == nil && matched
+}
+
+// maxRewardPoints is the most points a reward expression may give one purchase.
+var maxRewardPoints = decimal.NewFromInt(math.MaxInt64)
+
+// Evaluate returns the whole points the reward expression gives the purchase.
+// Nothing is awarded when the result is not positive or the expression was not
+// compiled or fails. A result too large to store is an ErrInvalidArgument
+// rather than a wrapped-around balance.
+func (r Reward) Evaluate(purchase Record, purchaser Purchaser) (points int64, applied bool, err error) {
+	if r.expression == nil {
+		return 0, false, nil
+	}
+
+	value, err := r.expression.EvalNumber(expressionEnv(purchase, purchaser))
+	if err != nil || !value.IsPositive() {
+		return 0, false, nil
+	}
+
+	value = value.Floor()
+	if value.GreaterThan(maxRewardPoints) {
+		return 0, false, apperr.ErrInvalidArgument.WithMessage(fmt.Sprintf("reward %s is more than %s points", value, maxRewardPoints))
+	}
+
+	return value.IntPart(), true, nil
+}
+
+func compileExpression(source string, typ expr.Type) (*expr.Program, error) {
+	program, err := expr.Compile(source, ExpressionVars)
+	if err != nil {
+		return nil, err
+	}
+
+	if program.Type() != typ {
+		return nil, fmt.Errorf("must be a %s, got %s", typ, program.Type())
+	}
+
+	return program, nil
+}
+
+func expressionEnv(purchase Record, purchaser Purchaser) expr.Env {
+	date := purchase.PurchaseDate
+
+	return expr.Env{
+		"amount":         purchase.Amount,
+		"product":        purchase.ProductID,
+		"category":       purchase.CategoryID,
+		"branch":         purchase.BranchID,
+		"receipt":        purchase.ReceiptID,
+		"month":          decimal.NewFromInt(int64(date.Month())),
+		"day":            decimal.NewFromInt(int64(date.Day())),
+		"weekday":        decimal.NewFromInt(int64(date.Weekday())),
+		"tier":           purchaser.Tier,
+		"segment":        purchaser.Attributes.Segment,
+		"birthday_month": purchaser.Attributes.BirthMonth == int(date.Month()),
+		"first_purchase": purchaser.FirstPurchase,
+	}
+}
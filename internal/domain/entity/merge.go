@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// Merge records that the source customer's account was merged into the target's.
+// Points and Records are what moved to the target; Duplicates are the source's
+// movements dropped because the target already held the same purchase or return,
+// and DuplicatePoints what they were worth.
+type Merge struct {
+	ID               string
+	SourceCustomerID string
+	TargetCustomerID string
+	Reason           string
+	RequestedBy      string
+	MergedAt         time.Time
+
+	Points          int64
+	Records         int
+	Duplicates      int
+	DuplicatePoints int64
+}
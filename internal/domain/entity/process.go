@@ -0,0 +1,10 @@
+package entity
+
+// ProcessResult summarizes one run over uploaded purchase files. Records counts
+// the unique purchases read; Customers and PointsAwarded cover those that earned.
+type ProcessResult struct {
+	Records       int
+	Customers     int
+	PointsAwarded int64
+	DryRun        bool
+}
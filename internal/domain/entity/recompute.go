@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+type BalanceDiff struct {
+	CustomerID       string
+	CurrentPoints    int64
+	RecomputedPoints int64
+	Delta            int64
+}
+
+// RecomputeResult describes a recompute. LegacyPurchases counts the stored
+// purchases without a category, which are kept as they are rather than replayed.
+type RecomputeResult struct {
+	SnapshotAt      time.Time
+	Customers       int
+	Changed         int
+	LegacyPurchases int
+	Diffs           []BalanceDiff
+}
+
+// SwapResult describes a swap. Skipped lists the customers written to after the
+// snapshot, who keep their live balances until the next recompute.
+type SwapResult struct {
+	Swapped int
+	Skipped []string
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Report is a point summary kept in storage.
+type Report struct {
+	Date time.Time
+	Key  string
+}
+
+// PointSummary is a customer's balance as of a date. LastPurchaseDate is the
+// latest date, in YYYY-MM-DD form, on which the customer earned points. The
+// qualifying totals cover the tier window ending on the date.
+type PointSummary struct {
+	CustomerID       string
+	Points           int64
+	LastPurchaseDate string
+	QualifyingPoints int64
+	QualifyingSpend  decimal.Decimal
+}
+
+type Breakdown string
+
+const (
+	BreakdownBranch   Breakdown = "branch"
+	BreakdownCategory Breakdown = "category"
+	BreakdownRule     Breakdown = "rule"
+	BreakdownSource   Breakdown = "source"
+)
+
+// BreakdownTotal is one group of a breakdown report. A purchase that earned points
+// from several rules is one transaction and its amount is counted once.
+type BreakdownTotal struct {
+	Key             string
+	Points          int64
+	Transactions    int64
+	PurchasedAmount decimal.Decimal
+}
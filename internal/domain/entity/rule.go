@@ -1,7 +1,11 @@
 package entity
 
 import (
+	"slices"
+	"time"
+
 	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/pkg/expr"
 )
 
 type RuleType string
@@ -10,24 +14,179 @@ const (
 	PercentageRule RuleType = "PERCENTAGE"
 	FixedPointRule RuleType = "FIXED_POINT"
 	RatioRule      RuleType = "RATIO"
+
+	// Basket rules award Reward.Value once per basket, the lines of one visit,
+	// instead of per line.
+	BasketSpendRule      RuleType = "BASKET_SPEND"
+	BasketCategoriesRule RuleType = "BASKET_CATEGORIES"
+	BasketBundleRule     RuleType = "BASKET_BUNDLE"
+
+	// Engagement rules award Reward.Value for a pattern of visits, a visit being
+	// a day with a matching purchase. They look at the customer's whole history.
+	VisitCountRule RuleType = "VISIT_COUNT"
+	StreakRule     RuleType = "STREAK"
+	NewBranchRule  RuleType = "NEW_BRANCH"
+
+	// CustomRule awards what Reward.Expression works out for each line.
+	CustomRule RuleType = "CUSTOM"
+)
+
+// IsBasket reports whether rules of this type are evaluated per basket.
+func (t RuleType) IsBasket() bool {
+	switch t {
+	case BasketSpendRule, BasketCategoriesRule, BasketBundleRule:
+		return true
+	}
+	return false
+}
+
+// IsEngagement reports whether rules of this type are evaluated on visit history.
+func (t RuleType) IsEngagement() bool {
+	switch t {
+	case VisitCountRule, StreakRule, NewBranchRule:
+		return true
+	}
+	return false
+}
+
+const (
+	RuleStatusActive   = "ACTIVE"
+	RuleStatusInactive = "INACTIVE"
 )
 
 type Rule struct {
-	ID         string
-	Name       string
-	RuleType   RuleType
-	Conditions Conditions
-	Reward     Reward
-	Status     string
+	ID            string
+	Name          string
+	RuleType      RuleType
+	Conditions    Conditions
+	Reward        Reward
+	Status        string
+	EffectiveFrom *time.Time
+	EffectiveTo   *time.Time
+	Version       int64
+	CreatedBy     string
+	CreatedAt     time.Time
 }
 
+// Reward.Expression is the number expression a CUSTOM rule awards; see
+// ExpressionVars.
 type Reward struct {
-	Value     int64
-	RatioUnit *float64
+	Value      int64
+	RatioUnit  *float64
+	Expression string
+	expression *expr.Program
 }
 
+// Conditions a purchase must meet to earn from a rule. The customer conditions
+// from Tiers on are checked against the Purchaser; an empty one matches anyone.
 type Conditions struct {
 	MinAmount  decimal.Decimal
 	BranchID   string
 	CategoryID []string
+
+	// BranchIDs and BranchGroups add to BranchID. A rule without any of the three
+	// applies at every branch.
+	BranchIDs      []string
+	BranchGroups   []string
+	groupBranchIDs []string
+
+	// ProductIDs limits the rule to those products when set. The exclusions
+	// win over every inclusion.
+	ProductIDs         []string
+	ExcludeProductIDs  []string
+	ExcludeCategoryIDs []string
+
+	// For basket rules MinAmount is the least the matching lines of a basket must
+	// add up to. MinCategories is how many distinct categories a
+	// BASKET_CATEGORIES basket needs and RequiredProductIDs the products a
+	// BASKET_BUNDLE basket must all contain.
+	MinCategories      int
+	RequiredProductIDs []string
+
+	// VisitCount is the visit in a calendar month that earns a VISIT_COUNT rule
+	// and StreakDays the run of consecutive visit days that earns a STREAK rule.
+	VisitCount int
+	StreakDays int
+
+	Tiers         []string
+	SignupFrom    *time.Time
+	SignupTo      *time.Time
+	BirthdayMonth bool
+	Segments      []string
+	FirstPurchase bool
+
+	// Expression is a bool expression every line must also meet; see ExpressionVars.
+	Expression string
+	expression *expr.Program
+}
+
+// Purchaser is what rules know about the customer behind a purchase. Tier is the
+// tier the purchase earns at and FirstPurchase is set on the customer's first
+// purchase only.
+type Purchaser struct {
+	Attributes    CustomerAttributes
+	Tier          string
+	FirstPurchase bool
+}
+
+// InForce reports whether the rule applies to purchases made on date.
+// EffectiveFrom is inclusive and EffectiveTo is exclusive; a nil bound is open.
+func (r Rule) InForce(date time.Time) bool {
+	if r.EffectiveFrom != nil && date.Before(*r.EffectiveFrom) {
+		return false
+	}
+
+	if r.EffectiveTo != nil && !date.Before(*r.EffectiveTo) {
+		return false
+	}
+
+	return true
+}
+
+// IsGlobal reports whether the rule applies at every branch.
+func (c Conditions) IsGlobal() bool {
+	return c.BranchID == "" && len(c.BranchIDs) == 0 && len(c.BranchGroups) == 0
+}
+
+// MatchesBranch reports whether the rule applies at branchID. Branch groups only
+// match once the rule has been through BranchGroups.Resolve.
+func (c Conditions) MatchesBranch(branchID string) bool {
+	if c.IsGlobal() {
+		return true
+	}
+
+	return c.BranchID == branchID ||
+		slices.Contains(c.BranchIDs, branchID) ||
+		slices.Contains(c.groupBranchIDs, branchID)
+}
+
+// MatchesPurchaser reports whether the customer conditions hold for a purchase
+// made by purchaser on date. SignupFrom is inclusive and SignupTo is exclusive;
+// a customer without a signup date never matches a signup bound.
+func (c Conditions) MatchesPurchaser(purchaser Purchaser, date time.Time) bool {
+	if len(c.Tiers) > 0 && !slices.Contains(c.Tiers, purchaser.Tier) {
+		return false
+	}
+
+	signup := purchaser.Attributes.SignupDate
+	if c.SignupFrom != nil && (signup == nil || signup.Before(*c.SignupFrom)) {
+		return false
+	}
+	if c.SignupTo != nil && (signup == nil || !signup.Before(*c.SignupTo)) {
+		return false
+	}
+
+	if c.BirthdayMonth && purchaser.Attributes.BirthMonth != int(date.Month()) {
+		return false
+	}
+
+	if len(c.Segments) > 0 && !slices.Contains(c.Segments, purchaser.Attributes.Segment) {
+		return false
+	}
+
+	if c.FirstPurchase && !purchaser.FirstPurchase {
+		return false
+	}
+
+	return true
 }
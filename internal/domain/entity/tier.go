@@ -0,0 +1,208 @@
+package entity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type TierBasis string
+
+const (
+	TierBasisPoints TierBasis = "POINTS"
+	TierBasisSpend  TierBasis = "SPEND"
+)
+
+// Tier is a customer status reached by earning Threshold points, or spending
+// Threshold, over the qualifying window. Points earned while in the tier are
+// multiplied by Multiplier.
+type Tier struct {
+	Name       string
+	Rank       int
+	Basis      TierBasis
+	Threshold  decimal.Decimal
+	Multiplier decimal.Decimal
+}
+
+// Tiers are ordered from the lowest rank to the highest.
+type Tiers []Tier
+
+// TierChange is a move between tiers that takes effect on Date. From is empty
+// for a customer's first tier and To is empty when a customer loses every tier.
+type TierChange struct {
+	Date      time.Time
+	From      string
+	To        string
+	Promotion bool
+}
+
+// CustomerTier is a customer's standing as of a date.
+type CustomerTier struct {
+	Tier             string
+	Multiplier       decimal.Decimal
+	Since            *time.Time
+	QualifyingPoints int64
+	QualifyingSpend  decimal.Decimal
+	History          []TierChange
+}
+
+// TierWindowStart returns the first day of the rolling twelve-month window that
+// ends on date, both days inclusive.
+func TierWindowStart(date time.Time) time.Time {
+	return date.AddDate(-1, 0, 1)
+}
+
+// SortTiers orders tiers by rank.
+func SortTiers(tiers []Tier) Tiers {
+	sorted := make(Tiers, len(tiers))
+	copy(sorted, tiers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Rank < sorted[j].Rank
+	})
+	return sorted
+}
+
+// Qualify returns the highest tier whose threshold is met. It returns false when
+// no tier is met.
+func (tiers Tiers) Qualify(points int64, spend decimal.Decimal) (Tier, bool) {
+	for i := len(tiers) - 1; i >= 0; i-- {
+		tier := tiers[i]
+
+		qualifying := decimal.NewFromInt(points)
+		if tier.Basis == TierBasisSpend {
+			qualifying = spend
+		}
+
+		if qualifying.GreaterThanOrEqual(tier.Threshold) {
+			return tier, true
+		}
+	}
+	return Tier{}, false
+}
+
+// Rank returns the rank of the named tier, or -1 when it is not one of tiers.
+func (tiers Tiers) Rank(name string) int {
+	for _, tier := range tiers {
+		if tier.Name == name {
+			return tier.Rank
+		}
+	}
+	return -1
+}
+
+// Apply multiplies points by the tier's multiplier, rounding down. A tier
+// without a multiplier leaves points unchanged.
+func (t Tier) Apply(points int64) int64 {
+	if t.Multiplier.IsZero() {
+		return points
+	}
+	return decimal.NewFromInt(points).Mul(t.Multiplier).Floor().IntPart()
+}
+
+// QualifyingTotals returns the points the customer earned and the amount spent
+// between from and to, both inclusive. A purchase that earned from several rules
+// is spent once. Points transferred or pooled in or out leave the totals as they
+// were.
+func (c Customer) QualifyingTotals(from, to time.Time) (int64, decimal.Decimal) {
+	fromDate, toDate := from.Format(time.DateOnly), to.Format(time.DateOnly)
+
+	var points int64
+	for date, value := range c.PointsByDate {
+		if date >= fromDate && date <= toDate {
+			points += value
+		}
+	}
+
+	type purchase struct {
+		productID, categoryID, branchID, amount string
+		date                                    time.Time
+	}
+
+	spend := decimal.Zero
+	seen := make(map[purchase]struct{})
+	for _, record := range c.Records {
+		if record.PurchaseDate.Before(from) || record.PurchaseDate.After(to) {
+			continue
+		}
+		if record.IsTransfer() {
+			points -= record.Points
+			continue
+		}
+
+		key := purchase{record.ProductID, record.CategoryID, record.BranchID, record.Amount.String(), record.PurchaseDate}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		spend = spend.Add(record.Amount)
+	}
+
+	return points, spend
+}
+
+// TierAsOf returns the tier the customer holds at the end of date, from the
+// twelve months ending on date. Purchases on the following day earn at this tier.
+func (tiers Tiers) TierAsOf(customer Customer, date time.Time) (Tier, bool) {
+	if len(tiers) == 0 {
+		return Tier{}, false
+	}
+	return tiers.Qualify(customer.QualifyingTotals(TierWindowStart(date), date))
+}
+
+// Standing returns the customer's tier as of asOf with every change before it.
+// Totals only change on a day points were earned or a year after it, when they
+// leave the window, so those are the only days examined.
+func (tiers Tiers) Standing(customer Customer, asOf time.Time) CustomerTier {
+	days := make(map[time.Time]struct{})
+	addDay := func(day time.Time) {
+		for _, d := range []time.Time{day, day.AddDate(1, 0, 0)} {
+			if !d.After(asOf) {
+				days[d] = struct{}{}
+			}
+		}
+	}
+	for date := range customer.PointsByDate {
+		if day, err := time.Parse(time.DateOnly, date); err == nil {
+			addDay(day)
+		}
+	}
+	for _, record := range customer.Records {
+		addDay(record.PurchaseDate)
+	}
+
+	ordered := make([]time.Time, 0, len(days))
+	for day := range days {
+		ordered = append(ordered, day)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Before(ordered[j]) })
+
+	standing := CustomerTier{History: make([]TierChange, 0)}
+	current := ""
+	for _, day := range ordered {
+		tier, _ := tiers.TierAsOf(customer, day)
+		if tier.Name == current {
+			continue
+		}
+
+		standing.History = append(standing.History, TierChange{
+			Date:      day,
+			From:      current,
+			To:        tier.Name,
+			Promotion: tiers.Rank(tier.Name) > tiers.Rank(current),
+		})
+		current = tier.Name
+		since := day
+		standing.Since = &since
+	}
+
+	tier, _ := tiers.TierAsOf(customer, asOf)
+	standing.Tier = tier.Name
+	standing.Multiplier = tier.Multiplier
+	standing.QualifyingPoints, standing.QualifyingSpend = customer.QualifyingTotals(TierWindowStart(asOf), asOf)
+	if standing.Tier == "" {
+		standing.Since = nil
+	}
+
+	return standing
+}
@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+// Transfer moves points from one customer to another on Date.
+type Transfer struct {
+	ID             string
+	FromCustomerID string
+	ToCustomerID   string
+	Points         int64
+	Reference      string
+	RequestedBy    string
+	Date           time.Time
+}
+
+// Movements returns the movements that record the transfer in the history of the
+// customer giving the points and of the one receiving them.
+func (t Transfer) Movements() (from, to Record) {
+	from = Record{
+		PurchaseDate:   t.Date,
+		Points:         -t.Points,
+		Type:           TransferTransaction,
+		TransferID:     t.ID,
+		CounterpartyID: t.ToCustomerID,
+	}
+	to = from
+	to.Points = t.Points
+	to.CounterpartyID = t.FromCustomerID
+
+	return from, to
+}
+
+// Household is a group of customers who earn into one shared balance. The balance
+// is kept on a customer account with the household's ID; Points is that balance
+// when it was looked up.
+type Household struct {
+	ID        string
+	Name      string
+	Members   []string
+	CreatedAt time.Time
+	Points    int64
+}
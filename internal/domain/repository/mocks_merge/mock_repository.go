@@ -0,0 +1,715 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=repository.go -destination=mocks_merge/mock_repository.go -package=mocks_merge
+//
+
+// Package mocks_merge is a generated GoMock package.
+package mocks_merge
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	entity "github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRuleRepository is a mock of RuleRepository interface.
+type MockRuleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRuleRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRuleRepositoryMockRecorder is the mock recorder for MockRuleRepository.
+type MockRuleRepositoryMockRecorder struct {
+	mock *MockRuleRepository
+}
+
+// NewMockRuleRepository creates a new mock instance.
+func NewMockRuleRepository(ctrl *gomock.Controller) *MockRuleRepository {
+	mock := &MockRuleRepository{ctrl: ctrl}
+	mock.recorder = &MockRuleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRuleRepository) EXPECT() *MockRuleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateRule mocks base method.
+func (m *MockRuleRepository) CreateRule(ctx context.Context, rule entity.Rule) (*entity.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRule", ctx, rule)
+	ret0, _ := ret[0].(*entity.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRule indicates an expected call of CreateRule.
+func (mr *MockRuleRepositoryMockRecorder) CreateRule(ctx, rule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRule", reflect.TypeOf((*MockRuleRepository)(nil).CreateRule), ctx, rule)
+}
+
+// GetActiveRules mocks base method.
+func (m *MockRuleRepository) GetActiveRules(ctx context.Context, branchIDWithCategoryIDs, branchIDWithProductIDs, branchIDWithGroups map[string][]string) ([]entity.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveRules", ctx, branchIDWithCategoryIDs, branchIDWithProductIDs, branchIDWithGroups)
+	ret0, _ := ret[0].([]entity.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveRules indicates an expected call of GetActiveRules.
+func (mr *MockRuleRepositoryMockRecorder) GetActiveRules(ctx, branchIDWithCategoryIDs, branchIDWithProductIDs, branchIDWithGroups any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveRules", reflect.TypeOf((*MockRuleRepository)(nil).GetActiveRules), ctx, branchIDWithCategoryIDs, branchIDWithProductIDs, branchIDWithGroups)
+}
+
+// GetAllActiveRules mocks base method.
+func (m *MockRuleRepository) GetAllActiveRules(ctx context.Context) ([]entity.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllActiveRules", ctx)
+	ret0, _ := ret[0].([]entity.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllActiveRules indicates an expected call of GetAllActiveRules.
+func (mr *MockRuleRepositoryMockRecorder) GetAllActiveRules(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllActiveRules", reflect.TypeOf((*MockRuleRepository)(nil).GetAllActiveRules), ctx)
+}
+
+// GetBranchGroups mocks base method.
+func (m *MockRuleRepository) GetBranchGroups(ctx context.Context) (entity.BranchGroups, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBranchGroups", ctx)
+	ret0, _ := ret[0].(entity.BranchGroups)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBranchGroups indicates an expected call of GetBranchGroups.
+func (mr *MockRuleRepositoryMockRecorder) GetBranchGroups(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBranchGroups", reflect.TypeOf((*MockRuleRepository)(nil).GetBranchGroups), ctx)
+}
+
+// GetRule mocks base method.
+func (m *MockRuleRepository) GetRule(ctx context.Context, id string) (*entity.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRule", ctx, id)
+	ret0, _ := ret[0].(*entity.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRule indicates an expected call of GetRule.
+func (mr *MockRuleRepositoryMockRecorder) GetRule(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRule", reflect.TypeOf((*MockRuleRepository)(nil).GetRule), ctx, id)
+}
+
+// GetRuleVersions mocks base method.
+func (m *MockRuleRepository) GetRuleVersions(ctx context.Context, id string) ([]entity.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuleVersions", ctx, id)
+	ret0, _ := ret[0].([]entity.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuleVersions indicates an expected call of GetRuleVersions.
+func (mr *MockRuleRepositoryMockRecorder) GetRuleVersions(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuleVersions", reflect.TypeOf((*MockRuleRepository)(nil).GetRuleVersions), ctx, id)
+}
+
+// GetRules mocks base method.
+func (m *MockRuleRepository) GetRules(ctx context.Context) ([]entity.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRules", ctx)
+	ret0, _ := ret[0].([]entity.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRules indicates an expected call of GetRules.
+func (mr *MockRuleRepositoryMockRecorder) GetRules(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRules", reflect.TypeOf((*MockRuleRepository)(nil).GetRules), ctx)
+}
+
+// GetRulesAsOf mocks base method.
+func (m *MockRuleRepository) GetRulesAsOf(ctx context.Context, asOf time.Time) ([]entity.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRulesAsOf", ctx, asOf)
+	ret0, _ := ret[0].([]entity.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRulesAsOf indicates an expected call of GetRulesAsOf.
+func (mr *MockRuleRepositoryMockRecorder) GetRulesAsOf(ctx, asOf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRulesAsOf", reflect.TypeOf((*MockRuleRepository)(nil).GetRulesAsOf), ctx, asOf)
+}
+
+// PutBranchGroup mocks base method.
+func (m *MockRuleRepository) PutBranchGroup(ctx context.Context, group entity.BranchGroup) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutBranchGroup", ctx, group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutBranchGroup indicates an expected call of PutBranchGroup.
+func (mr *MockRuleRepositoryMockRecorder) PutBranchGroup(ctx, group any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutBranchGroup", reflect.TypeOf((*MockRuleRepository)(nil).PutBranchGroup), ctx, group)
+}
+
+// UpdateRule mocks base method.
+func (m *MockRuleRepository) UpdateRule(ctx context.Context, rule entity.Rule, previousVersion int64) (*entity.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRule", ctx, rule, previousVersion)
+	ret0, _ := ret[0].(*entity.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRule indicates an expected call of UpdateRule.
+func (mr *MockRuleRepositoryMockRecorder) UpdateRule(ctx, rule, previousVersion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRule", reflect.TypeOf((*MockRuleRepository)(nil).UpdateRule), ctx, rule, previousVersion)
+}
+
+// MockCustomerRepository is a mock of CustomerRepository interface.
+type MockCustomerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCustomerRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCustomerRepositoryMockRecorder is the mock recorder for MockCustomerRepository.
+type MockCustomerRepositoryMockRecorder struct {
+	mock *MockCustomerRepository
+}
+
+// NewMockCustomerRepository creates a new mock instance.
+func NewMockCustomerRepository(ctrl *gomock.Controller) *MockCustomerRepository {
+	mock := &MockCustomerRepository{ctrl: ctrl}
+	mock.recorder = &MockCustomerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCustomerRepository) EXPECT() *MockCustomerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetBreakdown mocks base method.
+func (m *MockCustomerRepository) GetBreakdown(ctx context.Context, breakdown entity.Breakdown, from, to time.Time) ([]entity.BreakdownTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBreakdown", ctx, breakdown, from, to)
+	ret0, _ := ret[0].([]entity.BreakdownTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBreakdown indicates an expected call of GetBreakdown.
+func (mr *MockCustomerRepositoryMockRecorder) GetBreakdown(ctx, breakdown, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBreakdown", reflect.TypeOf((*MockCustomerRepository)(nil).GetBreakdown), ctx, breakdown, from, to)
+}
+
+// GetCustomers mocks base method.
+func (m *MockCustomerRepository) GetCustomers(ctx context.Context, customerIDs []string) ([]entity.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomers", ctx, customerIDs)
+	ret0, _ := ret[0].([]entity.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomers indicates an expected call of GetCustomers.
+func (mr *MockCustomerRepositoryMockRecorder) GetCustomers(ctx, customerIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomers", reflect.TypeOf((*MockCustomerRepository)(nil).GetCustomers), ctx, customerIDs)
+}
+
+// GetCustomersActiveOn mocks base method.
+func (m *MockCustomerRepository) GetCustomersActiveOn(ctx context.Context, date time.Time) ([]entity.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomersActiveOn", ctx, date)
+	ret0, _ := ret[0].([]entity.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomersActiveOn indicates an expected call of GetCustomersActiveOn.
+func (mr *MockCustomerRepositoryMockRecorder) GetCustomersActiveOn(ctx, date any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomersActiveOn", reflect.TypeOf((*MockCustomerRepository)(nil).GetCustomersActiveOn), ctx, date)
+}
+
+// MergeCustomers mocks base method.
+func (m *MockCustomerRepository) MergeCustomers(ctx context.Context, source, target entity.Customer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeCustomers", ctx, source, target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeCustomers indicates an expected call of MergeCustomers.
+func (mr *MockCustomerRepositoryMockRecorder) MergeCustomers(ctx, source, target any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeCustomers", reflect.TypeOf((*MockCustomerRepository)(nil).MergeCustomers), ctx, source, target)
+}
+
+// ReplaceShadowCustomers mocks base method.
+func (m *MockCustomerRepository) ReplaceShadowCustomers(ctx context.Context, snapshotAt time.Time, fill func(func(entity.Customer) error) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceShadowCustomers", ctx, snapshotAt, fill)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceShadowCustomers indicates an expected call of ReplaceShadowCustomers.
+func (mr *MockCustomerRepositoryMockRecorder) ReplaceShadowCustomers(ctx, snapshotAt, fill any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceShadowCustomers", reflect.TypeOf((*MockCustomerRepository)(nil).ReplaceShadowCustomers), ctx, snapshotAt, fill)
+}
+
+// SetHousehold mocks base method.
+func (m *MockCustomerRepository) SetHousehold(ctx context.Context, customerID, from, to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHousehold", ctx, customerID, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHousehold indicates an expected call of SetHousehold.
+func (mr *MockCustomerRepositoryMockRecorder) SetHousehold(ctx, customerID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHousehold", reflect.TypeOf((*MockCustomerRepository)(nil).SetHousehold), ctx, customerID, from, to)
+}
+
+// StreamCustomers mocks base method.
+func (m *MockCustomerRepository) StreamCustomers(ctx context.Context, fn func(entity.Customer) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamCustomers", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamCustomers indicates an expected call of StreamCustomers.
+func (mr *MockCustomerRepositoryMockRecorder) StreamCustomers(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamCustomers", reflect.TypeOf((*MockCustomerRepository)(nil).StreamCustomers), ctx, fn)
+}
+
+// StreamPointSummaries mocks base method.
+func (m *MockCustomerRepository) StreamPointSummaries(ctx context.Context, asOf string, excludeIDs []string, fn func(entity.PointSummary) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamPointSummaries", ctx, asOf, excludeIDs, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamPointSummaries indicates an expected call of StreamPointSummaries.
+func (mr *MockCustomerRepositoryMockRecorder) StreamPointSummaries(ctx, asOf, excludeIDs, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamPointSummaries", reflect.TypeOf((*MockCustomerRepository)(nil).StreamPointSummaries), ctx, asOf, excludeIDs, fn)
+}
+
+// SwapShadowCustomers mocks base method.
+func (m *MockCustomerRepository) SwapShadowCustomers(ctx context.Context, snapshotAt time.Time) (*entity.SwapResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwapShadowCustomers", ctx, snapshotAt)
+	ret0, _ := ret[0].(*entity.SwapResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SwapShadowCustomers indicates an expected call of SwapShadowCustomers.
+func (mr *MockCustomerRepositoryMockRecorder) SwapShadowCustomers(ctx, snapshotAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwapShadowCustomers", reflect.TypeOf((*MockCustomerRepository)(nil).SwapShadowCustomers), ctx, snapshotAt)
+}
+
+// TransferPoints mocks base method.
+func (m *MockCustomerRepository) TransferPoints(ctx context.Context, transfer entity.Transfer, dailyLimit int64) (*entity.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferPoints", ctx, transfer, dailyLimit)
+	ret0, _ := ret[0].(*entity.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferPoints indicates an expected call of TransferPoints.
+func (mr *MockCustomerRepositoryMockRecorder) TransferPoints(ctx, transfer, dailyLimit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferPoints", reflect.TypeOf((*MockCustomerRepository)(nil).TransferPoints), ctx, transfer, dailyLimit)
+}
+
+// UpdateBulkCustomers mocks base method.
+func (m *MockCustomerRepository) UpdateBulkCustomers(ctx context.Context, updateCustomer []entity.UpdateCustomer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBulkCustomers", ctx, updateCustomer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBulkCustomers indicates an expected call of UpdateBulkCustomers.
+func (mr *MockCustomerRepositoryMockRecorder) UpdateBulkCustomers(ctx, updateCustomer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBulkCustomers", reflect.TypeOf((*MockCustomerRepository)(nil).UpdateBulkCustomers), ctx, updateCustomer)
+}
+
+// UpdateCustomerAttributes mocks base method.
+func (m *MockCustomerRepository) UpdateCustomerAttributes(ctx context.Context, updates []entity.UpdateCustomerAttributes) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomerAttributes", ctx, updates)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCustomerAttributes indicates an expected call of UpdateCustomerAttributes.
+func (mr *MockCustomerRepositoryMockRecorder) UpdateCustomerAttributes(ctx, updates any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomerAttributes", reflect.TypeOf((*MockCustomerRepository)(nil).UpdateCustomerAttributes), ctx, updates)
+}
+
+// MockTierRepository is a mock of TierRepository interface.
+type MockTierRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTierRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockTierRepositoryMockRecorder is the mock recorder for MockTierRepository.
+type MockTierRepositoryMockRecorder struct {
+	mock *MockTierRepository
+}
+
+// NewMockTierRepository creates a new mock instance.
+func NewMockTierRepository(ctrl *gomock.Controller) *MockTierRepository {
+	mock := &MockTierRepository{ctrl: ctrl}
+	mock.recorder = &MockTierRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTierRepository) EXPECT() *MockTierRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetTiers mocks base method.
+func (m *MockTierRepository) GetTiers(ctx context.Context) (entity.Tiers, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTiers", ctx)
+	ret0, _ := ret[0].(entity.Tiers)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTiers indicates an expected call of GetTiers.
+func (mr *MockTierRepositoryMockRecorder) GetTiers(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTiers", reflect.TypeOf((*MockTierRepository)(nil).GetTiers), ctx)
+}
+
+// MockAdjustmentRepository is a mock of AdjustmentRepository interface.
+type MockAdjustmentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdjustmentRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAdjustmentRepositoryMockRecorder is the mock recorder for MockAdjustmentRepository.
+type MockAdjustmentRepositoryMockRecorder struct {
+	mock *MockAdjustmentRepository
+}
+
+// NewMockAdjustmentRepository creates a new mock instance.
+func NewMockAdjustmentRepository(ctrl *gomock.Controller) *MockAdjustmentRepository {
+	mock := &MockAdjustmentRepository{ctrl: ctrl}
+	mock.recorder = &MockAdjustmentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdjustmentRepository) EXPECT() *MockAdjustmentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAdjustment mocks base method.
+func (m *MockAdjustmentRepository) CreateAdjustment(ctx context.Context, adjustment entity.Adjustment) (*entity.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdjustment", ctx, adjustment)
+	ret0, _ := ret[0].(*entity.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAdjustment indicates an expected call of CreateAdjustment.
+func (mr *MockAdjustmentRepositoryMockRecorder) CreateAdjustment(ctx, adjustment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdjustment", reflect.TypeOf((*MockAdjustmentRepository)(nil).CreateAdjustment), ctx, adjustment)
+}
+
+// GetAdjustment mocks base method.
+func (m *MockAdjustmentRepository) GetAdjustment(ctx context.Context, id string) (*entity.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdjustment", ctx, id)
+	ret0, _ := ret[0].(*entity.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdjustment indicates an expected call of GetAdjustment.
+func (mr *MockAdjustmentRepositoryMockRecorder) GetAdjustment(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdjustment", reflect.TypeOf((*MockAdjustmentRepository)(nil).GetAdjustment), ctx, id)
+}
+
+// ListAdjustments mocks base method.
+func (m *MockAdjustmentRepository) ListAdjustments(ctx context.Context, filter entity.AdjustmentFilter) ([]entity.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAdjustments", ctx, filter)
+	ret0, _ := ret[0].([]entity.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAdjustments indicates an expected call of ListAdjustments.
+func (mr *MockAdjustmentRepositoryMockRecorder) ListAdjustments(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAdjustments", reflect.TypeOf((*MockAdjustmentRepository)(nil).ListAdjustments), ctx, filter)
+}
+
+// UpdateAdjustmentStatus mocks base method.
+func (m *MockAdjustmentRepository) UpdateAdjustmentStatus(ctx context.Context, id string, from, to entity.AdjustmentStatus, decidedBy string, decidedAt *time.Time) (*entity.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAdjustmentStatus", ctx, id, from, to, decidedBy, decidedAt)
+	ret0, _ := ret[0].(*entity.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAdjustmentStatus indicates an expected call of UpdateAdjustmentStatus.
+func (mr *MockAdjustmentRepositoryMockRecorder) UpdateAdjustmentStatus(ctx, id, from, to, decidedBy, decidedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdjustmentStatus", reflect.TypeOf((*MockAdjustmentRepository)(nil).UpdateAdjustmentStatus), ctx, id, from, to, decidedBy, decidedAt)
+}
+
+// MockHouseholdRepository is a mock of HouseholdRepository interface.
+type MockHouseholdRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHouseholdRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockHouseholdRepositoryMockRecorder is the mock recorder for MockHouseholdRepository.
+type MockHouseholdRepositoryMockRecorder struct {
+	mock *MockHouseholdRepository
+}
+
+// NewMockHouseholdRepository creates a new mock instance.
+func NewMockHouseholdRepository(ctrl *gomock.Controller) *MockHouseholdRepository {
+	mock := &MockHouseholdRepository{ctrl: ctrl}
+	mock.recorder = &MockHouseholdRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHouseholdRepository) EXPECT() *MockHouseholdRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddMember mocks base method.
+func (m *MockHouseholdRepository) AddMember(ctx context.Context, householdID, customerID string, maxMembers int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", ctx, householdID, customerID, maxMembers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockHouseholdRepositoryMockRecorder) AddMember(ctx, householdID, customerID, maxMembers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockHouseholdRepository)(nil).AddMember), ctx, householdID, customerID, maxMembers)
+}
+
+// CreateHousehold mocks base method.
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household entity.Household) (*entity.Household, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHousehold", ctx, household)
+	ret0, _ := ret[0].(*entity.Household)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHousehold indicates an expected call of CreateHousehold.
+func (mr *MockHouseholdRepositoryMockRecorder) CreateHousehold(ctx, household any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHousehold", reflect.TypeOf((*MockHouseholdRepository)(nil).CreateHousehold), ctx, household)
+}
+
+// GetHousehold mocks base method.
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, id string) (*entity.Household, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHousehold", ctx, id)
+	ret0, _ := ret[0].(*entity.Household)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHousehold indicates an expected call of GetHousehold.
+func (mr *MockHouseholdRepositoryMockRecorder) GetHousehold(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHousehold", reflect.TypeOf((*MockHouseholdRepository)(nil).GetHousehold), ctx, id)
+}
+
+// RemoveMember mocks base method.
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID, customerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, householdID, customerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockHouseholdRepositoryMockRecorder) RemoveMember(ctx, householdID, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockHouseholdRepository)(nil).RemoveMember), ctx, householdID, customerID)
+}
+
+// MockMergeRepository is a mock of MergeRepository interface.
+type MockMergeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMergeRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMergeRepositoryMockRecorder is the mock recorder for MockMergeRepository.
+type MockMergeRepositoryMockRecorder struct {
+	mock *MockMergeRepository
+}
+
+// NewMockMergeRepository creates a new mock instance.
+func NewMockMergeRepository(ctrl *gomock.Controller) *MockMergeRepository {
+	mock := &MockMergeRepository{ctrl: ctrl}
+	mock.recorder = &MockMergeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMergeRepository) EXPECT() *MockMergeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateMerge mocks base method.
+func (m *MockMergeRepository) CreateMerge(ctx context.Context, merge entity.Merge) (*entity.Merge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMerge", ctx, merge)
+	ret0, _ := ret[0].(*entity.Merge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMerge indicates an expected call of CreateMerge.
+func (mr *MockMergeRepositoryMockRecorder) CreateMerge(ctx, merge any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMerge", reflect.TypeOf((*MockMergeRepository)(nil).CreateMerge), ctx, merge)
+}
+
+// ListMerges mocks base method.
+func (m *MockMergeRepository) ListMerges(ctx context.Context, customerID string) ([]entity.Merge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerges", ctx, customerID)
+	ret0, _ := ret[0].([]entity.Merge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerges indicates an expected call of ListMerges.
+func (mr *MockMergeRepositoryMockRecorder) ListMerges(ctx, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerges", reflect.TypeOf((*MockMergeRepository)(nil).ListMerges), ctx, customerID)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAuditEntry mocks base method.
+func (m *MockAuditRepository) CreateAuditEntry(ctx context.Context, entry entity.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEntry", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditEntry indicates an expected call of CreateAuditEntry.
+func (mr *MockAuditRepositoryMockRecorder) CreateAuditEntry(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEntry", reflect.TypeOf((*MockAuditRepository)(nil).CreateAuditEntry), ctx, entry)
+}
+
+// LastAuditEntry mocks base method.
+func (m *MockAuditRepository) LastAuditEntry(ctx context.Context) (*entity.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastAuditEntry", ctx)
+	ret0, _ := ret[0].(*entity.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastAuditEntry indicates an expected call of LastAuditEntry.
+func (mr *MockAuditRepositoryMockRecorder) LastAuditEntry(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastAuditEntry", reflect.TypeOf((*MockAuditRepository)(nil).LastAuditEntry), ctx)
+}
+
+// StreamAuditEntries mocks base method.
+func (m *MockAuditRepository) StreamAuditEntries(ctx context.Context, filter entity.AuditFilter, fn func(entity.AuditEntry) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamAuditEntries", ctx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamAuditEntries indicates an expected call of StreamAuditEntries.
+func (mr *MockAuditRepositoryMockRecorder) StreamAuditEntries(ctx, filter, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamAuditEntries", reflect.TypeOf((*MockAuditRepository)(nil).StreamAuditEntries), ctx, filter, fn)
+}
@@ -2,17 +2,116 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
 )
 
 //go:generate mockgen -source=repository.go -destination=mocks_rule/mock_repository.go -package=mocks_rule
 type RuleRepository interface {
-	GetActiveRules(ctx context.Context, branchIDWithCategoryIDs map[string][]string) ([]entity.Rule, error)
+	// GetActiveRules returns the active rules that could apply to purchases of the
+	// given categories and products at each branch, including the rules of the
+	// branch's groups and global rules.
+	GetActiveRules(ctx context.Context, branchIDWithCategoryIDs, branchIDWithProductIDs, branchIDWithGroups map[string][]string) ([]entity.Rule, error)
+	GetAllActiveRules(ctx context.Context) ([]entity.Rule, error)
+	GetRules(ctx context.Context) ([]entity.Rule, error)
+	GetRule(ctx context.Context, id string) (*entity.Rule, error)
+	CreateRule(ctx context.Context, rule entity.Rule) (*entity.Rule, error)
+	UpdateRule(ctx context.Context, rule entity.Rule, previousVersion int64) (*entity.Rule, error)
+	GetRuleVersions(ctx context.Context, id string) ([]entity.Rule, error)
+	GetRulesAsOf(ctx context.Context, asOf time.Time) ([]entity.Rule, error)
+	GetBranchGroups(ctx context.Context) (entity.BranchGroups, error)
+	// PutBranchGroup creates the group or replaces its branches.
+	PutBranchGroup(ctx context.Context, group entity.BranchGroup) error
 }
 
 //go:generate mockgen -source=repository.go -destination=mocks_customer/mock_repository.go -package=mocks_customer
 type CustomerRepository interface {
 	GetCustomers(ctx context.Context, customerIDs []string) ([]entity.Customer, error)
 	UpdateBulkCustomers(ctx context.Context, updateCustomer []entity.UpdateCustomer) error
+	// UpdateCustomerAttributes replaces the attributes of each customer, creating
+	// customers that do not exist yet.
+	UpdateCustomerAttributes(ctx context.Context, updates []entity.UpdateCustomerAttributes) error
+	// StreamCustomers calls fn for every customer, reading them in batches so they
+	// are never all held in memory. Returning an error from fn stops the stream.
+	StreamCustomers(ctx context.Context, fn func(entity.Customer) error) error
+	// ReplaceShadowCustomers empties the shadow collection and calls fill, which
+	// passes add the customers recomputed from the live ones as they were at
+	// snapshotAt. snapshotAt is recorded once fill returns without an error.
+	ReplaceShadowCustomers(ctx context.Context, snapshotAt time.Time, fill func(add func(entity.Customer) error) error) error
+	// SwapShadowCustomers replaces the live customers with the shadow collection
+	// whose recorded snapshot is snapshotAt. Customers written after snapshotAt
+	// keep their live documents and are reported as skipped.
+	SwapShadowCustomers(ctx context.Context, snapshotAt time.Time) (*entity.SwapResult, error)
+	// StreamPointSummaries calls fn for every customer with points on or before
+	// asOf (YYYY-MM-DD), most points first, then latest purchase, then customer ID.
+	// Customers in excludeIDs are skipped. Returning an error from fn stops the stream.
+	StreamPointSummaries(ctx context.Context, asOf string, excludeIDs []string, fn func(entity.PointSummary) error) error
+	// GetCustomersActiveOn returns the customers who earned points on date, with
+	// only that date's points and movements.
+	GetCustomersActiveOn(ctx context.Context, date time.Time) ([]entity.Customer, error)
+	GetBreakdown(ctx context.Context, breakdown entity.Breakdown, from, to time.Time) ([]entity.BreakdownTotal, error)
+	// TransferPoints debits the sender and credits the receiver, recording the
+	// transfer on both. The debit only happens while the sender has the points and
+	// stays within dailyLimit points transferred out on the transfer's date, and
+	// fails with ErrConflict otherwise. The debit is undone when the receiver cannot
+	// be credited.
+	TransferPoints(ctx context.Context, transfer entity.Transfer, dailyLimit int64) (*entity.Transfer, error)
+	// SetHousehold moves an existing customer from one household to another, where
+	// an empty ID is no household. It fails with ErrConflict when the customer is no
+	// longer in from.
+	SetHousehold(ctx context.Context, customerID, from, to string) error
+	// MergeCustomers empties source and marks it as merged into target, then writes
+	// target, which holds the merged balance, history and aliases. Each is
+	// only written while unchanged since it was read, going by UpdatedAt, and fails
+	// with ErrConflict otherwise. The source is restored when target cannot be written.
+	MergeCustomers(ctx context.Context, source, target entity.Customer) error
+}
+
+//go:generate mockgen -source=repository.go -destination=mocks_tier/mock_repository.go -package=mocks_tier
+type TierRepository interface {
+	// GetTiers returns the tier definitions, lowest rank first.
+	GetTiers(ctx context.Context) (entity.Tiers, error)
+}
+
+//go:generate mockgen -source=repository.go -destination=mocks_adjustment/mock_repository.go -package=mocks_adjustment
+type AdjustmentRepository interface {
+	CreateAdjustment(ctx context.Context, adjustment entity.Adjustment) (*entity.Adjustment, error)
+	GetAdjustment(ctx context.Context, id string) (*entity.Adjustment, error)
+	// ListAdjustments returns the matching adjustments, newest first.
+	ListAdjustments(ctx context.Context, filter entity.AdjustmentFilter) ([]entity.Adjustment, error)
+	// UpdateAdjustmentStatus moves the adjustment from one status to another and
+	// records who decided and when. It fails with ErrConflict when the adjustment is
+	// no longer in from, so only one caller can make each decision.
+	UpdateAdjustmentStatus(ctx context.Context, id string, from, to entity.AdjustmentStatus, decidedBy string, decidedAt *time.Time) (*entity.Adjustment, error)
+}
+
+//go:generate mockgen -source=repository.go -destination=mocks_household/mock_repository.go -package=mocks_household
+type HouseholdRepository interface {
+	CreateHousehold(ctx context.Context, household entity.Household) (*entity.Household, error)
+	GetHousehold(ctx context.Context, id string) (*entity.Household, error)
+	// AddMember adds the customer to the household's members unless it already has
+	// maxMembers, failing with ErrConflict when it is full or they are a member.
+	AddMember(ctx context.Context, householdID, customerID string, maxMembers int) error
+	RemoveMember(ctx context.Context, householdID, customerID string) error
+}
+
+//go:generate mockgen -source=repository.go -destination=mocks_merge/mock_repository.go -package=mocks_merge
+type MergeRepository interface {
+	CreateMerge(ctx context.Context, merge entity.Merge) (*entity.Merge, error)
+	// ListMerges returns the merges the customer took part in on either side, or
+	// every merge when customerID is empty, newest first.
+	ListMerges(ctx context.Context, customerID string) ([]entity.Merge, error)
+}
+
+//go:generate mockgen -source=repository.go -destination=mocks_audit/mock_repository.go -package=mocks_audit
+type AuditRepository interface {
+	// LastAuditEntry returns the entry with the highest sequence, or nil when the
+	// log is empty.
+	LastAuditEntry(ctx context.Context) (*entity.AuditEntry, error)
+	// CreateAuditEntry appends the entry. It fails with ErrConflict when an entry
+	// with the same sequence exists, so concurrent writers cannot fork the chain.
+	CreateAuditEntry(ctx context.Context, entry entity.AuditEntry) error
+	// StreamAuditEntries calls fn for each matching entry in sequence order.
+	StreamAuditEntries(ctx context.Context, filter entity.AuditFilter, fn func(entity.AuditEntry) error) error
 }
@@ -3,10 +3,12 @@ package errors
 import "errors"
 
 var (
-	ErrNotFound        = New("NOT_FOUND", "data not found")
-	ErrInvalidArgument = New("INVALID_ARGUMENT", "invalid argument provided")
-	ErrUnauthenticated = New("UNAUTHENTICATED", "authentication failed")
-	ErrInternal        = New("INTERNAL_ERROR", "an internal errors occurred")
+	ErrNotFound         = New("NOT_FOUND", "data not found")
+	ErrInvalidArgument  = New("INVALID_ARGUMENT", "invalid argument provided")
+	ErrUnauthenticated  = New("UNAUTHENTICATED", "authentication failed")
+	ErrPermissionDenied = New("PERMISSION_DENIED", "permission denied")
+	ErrConflict         = New("CONFLICT", "resource state has changed")
+	ErrInternal         = New("INTERNAL_ERROR", "an internal errors occurred")
 )
 
 func New(code, message string) *AppError {
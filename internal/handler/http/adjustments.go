@@ -0,0 +1,140 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/adjustments"
+)
+
+type AdjustmentHandler struct {
+	AdjustmentSvc adjustments.AdjustmentService
+}
+
+func NewAdjustmentHandler(AdjustmentSvc adjustments.AdjustmentService) *AdjustmentHandler {
+	return &AdjustmentHandler{AdjustmentSvc: AdjustmentSvc}
+}
+
+type adjustmentRequest struct {
+	Points     int64                   `json:"points"`
+	ReasonCode entity.AdjustmentReason `json:"reason_code"`
+	Reference  string                  `json:"reference"`
+}
+
+type adjustmentResponse struct {
+	ID          string                  `json:"id"`
+	CustomerID  string                  `json:"customer_id"`
+	Points      int64                   `json:"points"`
+	ReasonCode  entity.AdjustmentReason `json:"reason_code"`
+	Reference   string                  `json:"reference"`
+	Status      entity.AdjustmentStatus `json:"status"`
+	RequestedBy string                  `json:"requested_by"`
+	RequestedAt time.Time               `json:"requested_at"`
+	DecidedBy   string                  `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time              `json:"decided_at,omitempty"`
+}
+
+// RequestAdjustment credits or debits the customer's points on behalf of the
+// caller. It answers 201 when the adjustment is applied and 202 when it waits for
+// a second approver.
+func (h AdjustmentHandler) RequestAdjustment(c *gin.Context) {
+	var req adjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.Wrap(err))
+		return
+	}
+
+	identity, _ := auth.IdentityFrom(c)
+	result, err := h.AdjustmentSvc.RequestAdjustment(c.Request.Context(), entity.Adjustment{
+		CustomerID:  c.Param("id"),
+		Points:      req.Points,
+		ReasonCode:  req.ReasonCode,
+		Reference:   req.Reference,
+		RequestedBy: identity.Subject,
+	})
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	addAdjustmentAuditDetails(c, *result)
+
+	status := http.StatusCreated
+	if result.Status == entity.AdjustmentPending {
+		status = http.StatusAccepted
+	}
+
+	c.JSON(status, toAdjustmentResponse(*result))
+}
+
+// ApproveAdjustment applies a pending adjustment. The caller is the approver, so
+// a requester cannot approve their own adjustment under another name.
+func (h AdjustmentHandler) ApproveAdjustment(c *gin.Context) {
+	identity, _ := auth.IdentityFrom(c)
+	result, err := h.AdjustmentSvc.ApproveAdjustment(c.Request.Context(), c.Param("id"), identity.Subject)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+	addAdjustmentAuditDetails(c, *result)
+
+	c.JSON(http.StatusOK, toAdjustmentResponse(*result))
+}
+
+func (h AdjustmentHandler) RejectAdjustment(c *gin.Context) {
+	identity, _ := auth.IdentityFrom(c)
+	result, err := h.AdjustmentSvc.RejectAdjustment(c.Request.Context(), c.Param("id"), identity.Subject)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+	addAdjustmentAuditDetails(c, *result)
+
+	c.JSON(http.StatusOK, toAdjustmentResponse(*result))
+}
+
+func (h AdjustmentHandler) ListAdjustments(c *gin.Context) {
+	result, err := h.AdjustmentSvc.ListAdjustments(c.Request.Context(), entity.AdjustmentFilter{
+		CustomerID: c.Query("customer_id"),
+		Status:     entity.AdjustmentStatus(c.Query("status")),
+	})
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	response := make([]adjustmentResponse, 0, len(result))
+	for _, adjustment := range result {
+		response = append(response, toAdjustmentResponse(adjustment))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func addAdjustmentAuditDetails(c *gin.Context, adjustment entity.Adjustment) {
+	addAuditDetail(c, "adjustment_id", adjustment.ID)
+	addAuditDetail(c, "customer_id", adjustment.CustomerID)
+	addAuditDetail(c, "points", strconv.FormatInt(adjustment.Points, 10))
+	addAuditDetail(c, "status", string(adjustment.Status))
+}
+
+func toAdjustmentResponse(adjustment entity.Adjustment) adjustmentResponse {
+	return adjustmentResponse{
+		ID:          adjustment.ID,
+		CustomerID:  adjustment.CustomerID,
+		Points:      adjustment.Points,
+		ReasonCode:  adjustment.ReasonCode,
+		Reference:   adjustment.Reference,
+		Status:      adjustment.Status,
+		RequestedBy: adjustment.RequestedBy,
+		RequestedAt: adjustment.RequestedAt,
+		DecidedBy:   adjustment.DecidedBy,
+		DecidedAt:   adjustment.DecidedAt,
+	}
+}
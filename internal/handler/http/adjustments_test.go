@@ -0,0 +1,182 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	adjustmentrepomocks "github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_adjustment"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	"github.com/sirawong/point-accumulate-interview/internal/services/adjustments"
+	adjustmentmocks "github.com/sirawong/point-accumulate-interview/internal/services/adjustments/mocks"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type AdjustmentHandlerTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockService *adjustmentmocks.MockAdjustmentService
+	router      *gin.Engine
+}
+
+// newAdjustmentRouter mounts handler behind an authenticator that knows alice and
+// bob as customer admins.
+func newAdjustmentRouter(t *testing.T, handler *AdjustmentHandler) *gin.Engine {
+	authenticator := newTestAuthenticator(t,
+		auth.Identity{Subject: "alice", Roles: []auth.Role{auth.RoleCustomerAdmin}},
+		auth.Identity{Subject: "bob", Roles: []auth.Role{auth.RoleCustomerAdmin}},
+	)
+
+	router := gin.New()
+	router.Use(authenticator.Middleware())
+	router.POST("/customers/:id/adjustments", handler.RequestAdjustment)
+	router.GET("/adjustments", handler.ListAdjustments)
+	router.POST("/adjustments/:id/approve", handler.ApproveAdjustment)
+	router.POST("/adjustments/:id/reject", handler.RejectAdjustment)
+	return router
+}
+
+func TestAdjustmentHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(AdjustmentHandlerTestSuite))
+}
+
+func (suite *AdjustmentHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockService = adjustmentmocks.NewMockAdjustmentService(suite.mockCtrl)
+	suite.router = newAdjustmentRouter(suite.T(), NewAdjustmentHandler(suite.mockService))
+}
+
+func (suite *AdjustmentHandlerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *AdjustmentHandlerTestSuite) post(caller, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auth.APIKeyHeader, caller)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *AdjustmentHandlerTestSuite) TestRequestAdjustment_Pending() {
+	requestedAt := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	adjustment := entity.Adjustment{
+		CustomerID:  "U000001",
+		Points:      5000,
+		ReasonCode:  entity.AdjustmentReasonServiceRecovery,
+		Reference:   "TICKET-42",
+		RequestedBy: "alice",
+	}
+	suite.mockService.EXPECT().
+		RequestAdjustment(gomock.Any(), adjustment).
+		DoAndReturn(func(_ context.Context, adjustment entity.Adjustment) (*entity.Adjustment, error) {
+			adjustment.ID = "A1"
+			adjustment.Status = entity.AdjustmentPending
+			adjustment.RequestedAt = requestedAt
+			return &adjustment, nil
+		})
+
+	w := suite.post("alice", "/customers/U000001/adjustments", `{"points":5000,"reason_code":"SERVICE_RECOVERY","reference":"TICKET-42"}`)
+
+	suite.Equal(http.StatusAccepted, w.Code)
+	suite.JSONEq(`{
+		"id": "A1",
+		"customer_id": "U000001",
+		"points": 5000,
+		"reason_code": "SERVICE_RECOVERY",
+		"reference": "TICKET-42",
+		"status": "PENDING",
+		"requested_by": "alice",
+		"requested_at": "2025-03-01T09:00:00Z"
+	}`, w.Body.String())
+}
+
+func (suite *AdjustmentHandlerTestSuite) TestRequestAdjustment_Applied() {
+	suite.mockService.EXPECT().
+		RequestAdjustment(gomock.Any(), gomock.Any()).
+		Return(&entity.Adjustment{ID: "A1", Status: entity.AdjustmentApproved}, nil)
+
+	w := suite.post("alice", "/customers/U000001/adjustments", `{"points":-50,"reason_code":"CORRECTION","reference":"R1"}`)
+
+	suite.Equal(http.StatusCreated, w.Code)
+}
+
+func (suite *AdjustmentHandlerTestSuite) TestApproveAdjustment_Conflict() {
+	suite.mockService.EXPECT().
+		ApproveAdjustment(gomock.Any(), "A1", "bob").
+		Return(nil, apperr.ErrConflict.WithMessage("adjustment is already REJECTED"))
+
+	w := suite.post("bob", "/adjustments/A1/approve", "")
+
+	suite.Equal(http.StatusConflict, w.Code)
+}
+
+func (suite *AdjustmentHandlerTestSuite) TestRequestAdjustment_IgnoresRequesterInBody() {
+	suite.mockService.EXPECT().
+		RequestAdjustment(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, adjustment entity.Adjustment) (*entity.Adjustment, error) {
+			suite.Equal("alice", adjustment.RequestedBy)
+			adjustment.Status = entity.AdjustmentPending
+			return &adjustment, nil
+		})
+
+	w := suite.post("alice", "/customers/U000001/adjustments", `{"points":5000,"reason_code":"GOODWILL","reference":"R1","requested_by":"carol"}`)
+
+	suite.Equal(http.StatusAccepted, w.Code)
+}
+
+func (suite *AdjustmentHandlerTestSuite) TestApproveAdjustment_OwnRequest() {
+	adjustmentRepo := adjustmentrepomocks.NewMockAdjustmentRepository(suite.mockCtrl)
+	service := adjustments.NewAdjustmentService(adjustmentRepo, nil, &config.Config{AdjustmentApprovalThreshold: 1000})
+	router := newAdjustmentRouter(suite.T(), NewAdjustmentHandler(service))
+
+	adjustmentRepo.EXPECT().
+		GetAdjustment(gomock.Any(), "A1").
+		Return(&entity.Adjustment{ID: "A1", CustomerID: "U000001", Points: 5000, Status: entity.AdjustmentPending, RequestedBy: "alice"}, nil)
+
+	// A decided_by in the body used to stand in for the approver.
+	req := httptest.NewRequest("POST", "/adjustments/A1/approve", strings.NewReader(`{"decided_by":"bob"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auth.APIKeyHeader, "alice")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.Contains(w.Body.String(), "someone other than its requester")
+}
+
+func (suite *AdjustmentHandlerTestSuite) TestRejectAdjustment() {
+	suite.mockService.EXPECT().
+		RejectAdjustment(gomock.Any(), "A1", "bob").
+		Return(&entity.Adjustment{ID: "A1", Status: entity.AdjustmentRejected, DecidedBy: "bob"}, nil)
+
+	w := suite.post("bob", "/adjustments/A1/reject", "")
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"status":"REJECTED"`)
+}
+
+func (suite *AdjustmentHandlerTestSuite) TestListAdjustments() {
+	suite.mockService.EXPECT().
+		ListAdjustments(gomock.Any(), entity.AdjustmentFilter{CustomerID: "U000001", Status: entity.AdjustmentPending}).
+		Return(nil, nil)
+
+	req := httptest.NewRequest("GET", "/adjustments?status=PENDING&customer_id=U000001", nil)
+	req.Header.Set(auth.APIKeyHeader, "alice")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`[]`, w.Body.String())
+}
@@ -0,0 +1,385 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/audit"
+)
+
+const (
+	auditDetailsKey = "audit.details"
+	auditFilesKey   = "audit.files"
+	// responseAbortedKey holds why a response was cut off after its status was sent.
+	responseAbortedKey = "audit.aborted"
+
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+
+	// unauthenticatedActor is the actor of requests refused before a caller was
+	// identified.
+	unauthenticatedActor = "unauthenticated"
+)
+
+type AuditHandler struct {
+	AuditSvc audit.AuditService
+}
+
+func NewAuditHandler(AuditSvc audit.AuditService) *AuditHandler {
+	return &AuditHandler{AuditSvc: AuditSvc}
+}
+
+type auditFileResponse struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+type auditEntryResponse struct {
+	Sequence   int64               `json:"sequence"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	Actor      string              `json:"actor"`
+	Source     string              `json:"source"`
+	ClientIP   string              `json:"client_ip,omitempty"`
+	Action     string              `json:"action"`
+	Target     string              `json:"target,omitempty"`
+	Status     int                 `json:"status,omitempty"`
+	Files      []auditFileResponse `json:"files,omitempty"`
+	Details    map[string]string   `json:"details,omitempty"`
+	PrevHash   string              `json:"prev_hash"`
+	Hash       string              `json:"hash"`
+}
+
+type auditVerificationResponse struct {
+	Entries     int64  `json:"entries"`
+	Valid       bool   `json:"valid"`
+	FirstBroken int64  `json:"first_broken,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	LastHash    string `json:"last_hash"`
+}
+
+// Middleware records every request that may change state, refused or not, with
+// the details and files its handler added. It runs before authentication so
+// refused callers are recorded too.
+//
+// The entry is written when the response status is about to be sent, so the
+// body still streams. When it cannot be written, the caller gets a 500 instead
+// of the handler's response, so no change is answered as done without a record.
+// A response that is aborted after its status was sent is recorded again as a
+// failure.
+func (h AuditHandler) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		writer.commit = func() bool {
+			if err := h.record(c, writer.Status(), nil); err != nil {
+				writer.failed = true
+				current := c.Writer
+				c.Writer = writer.ResponseWriter
+				clear(c.Writer.Header())
+				errors.RespondWithError(c, errAuditFailed)
+				c.Writer = current
+				return false
+			}
+			return true
+		}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.failed {
+			return
+		}
+
+		cause, aborted := c.Get(responseAbortedKey)
+		if writer.hijacked && !aborted {
+			cause, aborted = "connection hijacked", true
+		}
+		if aborted {
+			if err := h.record(c, http.StatusInternalServerError, map[string]string{"error": fmt.Sprint(cause)}); err != nil {
+				log.Printf("Failed to record aborted %s %s in the audit log: %v", c.Request.Method, c.FullPath(), err)
+			}
+			return
+		}
+
+		if !writer.recorded {
+			writer.WriteHeaderNow()
+		}
+	}
+}
+
+// errAuditFailed answers a request whose audit entry could not be written.
+var errAuditFailed = apperr.ErrInternal.WithMessage("the request could not be recorded in the audit log; check whether it took effect before retrying")
+
+// record adds the request to the audit log with status and, on top of what the
+// handler added, extra details.
+func (h AuditHandler) record(c *gin.Context, status int, extra map[string]string) error {
+	actor := unauthenticatedActor
+	if identity, ok := auth.IdentityFrom(c); ok {
+		actor = identity.Subject
+	}
+	entry := entity.AuditEntry{
+		Actor:    actor,
+		Source:   audit.SourceAPI,
+		ClientIP: c.ClientIP(),
+		Action:   c.Request.Method + " " + c.FullPath(),
+		Target:   c.Request.URL.Path,
+		Status:   status,
+	}
+	if details, ok := c.Get(auditDetailsKey); ok {
+		entry.Details = maps.Clone(details.(map[string]string))
+	}
+	if len(extra) > 0 {
+		if entry.Details == nil {
+			entry.Details = make(map[string]string, len(extra))
+		}
+		maps.Copy(entry.Details, extra)
+	}
+	if files, ok := c.Get(auditFilesKey); ok {
+		entry.Files = files.([]entity.AuditFile)
+	}
+
+	// The entry is written even when the caller has gone away.
+	ctx := context.WithoutCancel(c.Request.Context())
+	if _, err := h.AuditSvc.Record(ctx, entry); err != nil {
+		log.Printf("Failed to record %s by %s (status %d) in the audit log: %v", entry.Action, entry.Actor, entry.Status, err)
+		return err
+	}
+	return nil
+}
+
+// auditResponseWriter writes the audit entry of the request before the response
+// status is sent. Once the entry has failed, the handler's output is dropped.
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	commit   func() bool
+	recorded bool
+	failed   bool
+	hijacked bool
+}
+
+// ready reports whether the handler's output may be sent, writing the entry the
+// first time it is asked.
+func (w *auditResponseWriter) ready() bool {
+	if !w.recorded && !w.failed {
+		w.recorded = w.commit()
+	}
+	return w.recorded
+}
+
+func (w *auditResponseWriter) WriteHeaderNow() {
+	if w.ready() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	if !w.ready() {
+		return 0, errAuditFailed
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditResponseWriter) WriteString(s string) (int, error) {
+	if !w.ready() {
+		return 0, errAuditFailed
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *auditResponseWriter) Flush() {
+	if w.ready() {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *auditResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return w.ResponseWriter.Hijack()
+}
+
+// Unwrap gives abortStream the connection to close. After a failed entry the
+// error response has been sent in full, so there is nothing to close.
+func (w *auditResponseWriter) Unwrap() http.ResponseWriter {
+	if w.failed {
+		return nil
+	}
+	if unwrapper, ok := w.ResponseWriter.(interface{ Unwrap() http.ResponseWriter }); ok {
+		return unwrapper.Unwrap()
+	}
+	return w.ResponseWriter
+}
+
+// addAuditDetail adds a detail to the audit entry of the request.
+func addAuditDetail(c *gin.Context, key, value string) {
+	details, ok := c.Get(auditDetailsKey)
+	if !ok {
+		details = make(map[string]string)
+		c.Set(auditDetailsKey, details)
+	}
+	details.(map[string]string)[key] = value
+}
+
+// addAuditFiles adds the uploaded files to the audit entry of the request.
+func addAuditFiles(c *gin.Context, files ...entity.AuditFile) {
+	existing, _ := c.Get(auditFilesKey)
+	current, _ := existing.([]entity.AuditFile)
+	c.Set(auditFilesKey, append(current, files...))
+}
+
+func (h AuditHandler) ListEntries(c *gin.Context) {
+	filter, err := auditFilter(c, defaultAuditLimit)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+	if filter.Limit > maxAuditLimit {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.WithMessage("limit must be at most 1000; use the export for more"))
+		return
+	}
+
+	result, err := h.AuditSvc.ListEntries(c.Request.Context(), filter)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	response := make([]auditEntryResponse, 0, len(result))
+	for _, entry := range result {
+		response = append(response, toAuditEntryResponse(entry))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportEntries streams the matching entries, every one of them unless a limit is
+// given, in the format of the format parameter or the Accept header.
+func (h AuditHandler) ExportEntries(c *gin.Context) {
+	filter, err := auditFilter(c, 0)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	format, err := reportFormat(c)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	streamAttachment(c, format.ContentType(), "audit-log"+format.Extension(), func(w io.Writer) error {
+		return h.AuditSvc.ExportEntries(c.Request.Context(), filter, format, w)
+	})
+}
+
+func (h AuditHandler) VerifyChain(c *gin.Context) {
+	result, err := h.AuditSvc.Verify(c.Request.Context())
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, auditVerificationResponse{
+		Entries:     result.Entries,
+		Valid:       result.Valid,
+		FirstBroken: result.FirstBroken,
+		Reason:      result.Reason,
+		LastHash:    result.LastHash,
+	})
+}
+
+// auditFilter reads the actor, action, from, to, after and limit parameters. From
+// and to are RFC 3339 times or dates; a date in to covers the whole day.
+func auditFilter(c *gin.Context, defaultLimit int64) (entity.AuditFilter, error) {
+	filter := entity.AuditFilter{
+		Actor:  c.Query("actor"),
+		Action: c.Query("action"),
+		Limit:  defaultLimit,
+	}
+
+	var err error
+	if value := c.Query("from"); value != "" {
+		if filter.From, err = parseAuditTime(value, 0); err != nil {
+			return filter, err
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if filter.To, err = parseAuditTime(value, 24*time.Hour); err != nil {
+			return filter, err
+		}
+	}
+
+	numbers := []struct {
+		name   string
+		target *int64
+	}{
+		{name: "after", target: &filter.AfterSequence},
+		{name: "limit", target: &filter.Limit},
+	}
+	for _, number := range numbers {
+		value := c.Query(number.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			return filter, apperr.ErrInvalidArgument.WithMessage(number.name + " must be a non-negative number")
+		}
+		*number.target = parsed
+	}
+
+	return filter, nil
+}
+
+// parseAuditTime parses an RFC 3339 time, or a date to which dateOffset is added.
+func parseAuditTime(value string, dateOffset time.Duration) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t.Add(dateOffset), nil
+	}
+	return time.Time{}, apperr.ErrInvalidArgument.WithMessage("from and to must be RFC 3339 times or YYYY-MM-DD dates")
+}
+
+func toAuditEntryResponse(entry entity.AuditEntry) auditEntryResponse {
+	response := auditEntryResponse{
+		Sequence:   entry.Sequence,
+		OccurredAt: entry.OccurredAt,
+		Actor:      entry.Actor,
+		Source:     entry.Source,
+		ClientIP:   entry.ClientIP,
+		Action:     entry.Action,
+		Target:     entry.Target,
+		Status:     entry.Status,
+		Details:    entry.Details,
+		PrevHash:   entry.PrevHash,
+		Hash:       entry.Hash,
+	}
+	for _, file := range entry.Files {
+		response.Files = append(response.Files, auditFileResponse{Name: file.Name, SHA256: file.SHA256, Size: file.Size})
+	}
+	return response
+}
@@ -0,0 +1,248 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	"github.com/sirawong/point-accumulate-interview/internal/services/audit"
+	auditmocks "github.com/sirawong/point-accumulate-interview/internal/services/audit/mocks"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type AuditHandlerTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockService *auditmocks.MockAuditService
+	router      *gin.Engine
+	recorder    *httptest.ResponseRecorder
+	flushed     string
+}
+
+func TestAuditHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditHandlerTestSuite))
+}
+
+func (suite *AuditHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockService = auditmocks.NewMockAuditService(suite.mockCtrl)
+	handler := NewAuditHandler(suite.mockService)
+
+	authenticator, err := auth.New(&config.Config{AuthDisabled: true})
+	suite.Require().NoError(err)
+
+	suite.router = gin.New()
+	suite.router.Use(handler.Middleware(), authenticator.Middleware())
+	suite.router.PUT("/rules/:id", func(c *gin.Context) {
+		addAuditDetail(c, "rule_id", c.Param("id"))
+		addAuditFiles(c, entity.AuditFile{Name: "rules.csv", SHA256: "abc", Size: 3})
+		c.Header("Content-Disposition", `attachment; filename="rules.csv"`)
+		c.String(http.StatusOK, "saved")
+	})
+	suite.router.POST("/refused", func(c *gin.Context) {
+		c.Status(http.StatusForbidden)
+	})
+	suite.router.POST("/stream", func(c *gin.Context) {
+		addAuditDetail(c, "records", "2")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("first part")
+		c.Writer.Flush()
+		suite.flushed = suite.recorder.Body.String()
+		abortStream(c, apperr.ErrInternal.WithMessage("report storage went away"))
+	})
+	suite.router.GET("/audit", handler.ListEntries)
+	suite.router.GET("/audit/export", handler.ExportEntries)
+	suite.router.GET("/audit/verify", handler.VerifyChain)
+}
+
+func (suite *AuditHandlerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *AuditHandlerTestSuite) serve(method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	suite.recorder = httptest.NewRecorder()
+	suite.router.ServeHTTP(suite.recorder, req)
+	return suite.recorder
+}
+
+func (suite *AuditHandlerTestSuite) TestMiddleware_RecordsChanges() {
+	suite.mockService.EXPECT().
+		Record(gomock.Any(), entity.AuditEntry{
+			Actor:    "anonymous",
+			Source:   audit.SourceAPI,
+			ClientIP: "192.0.2.1",
+			Action:   "PUT /rules/:id",
+			Target:   "/rules/R1",
+			Status:   http.StatusOK,
+			Files:    []entity.AuditFile{{Name: "rules.csv", SHA256: "abc", Size: 3}},
+			Details:  map[string]string{"rule_id": "R1"},
+		}).
+		Return(&entity.AuditEntry{Sequence: 1}, nil)
+
+	w := suite.serve("PUT", "/rules/R1")
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal("saved", w.Body.String())
+	suite.Equal(`attachment; filename="rules.csv"`, w.Header().Get("Content-Disposition"))
+}
+
+func (suite *AuditHandlerTestSuite) TestMiddleware_RecordsRefusals() {
+	suite.mockService.EXPECT().
+		Record(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, entry entity.AuditEntry) (*entity.AuditEntry, error) {
+			suite.Equal("POST /refused", entry.Action)
+			suite.Equal(http.StatusForbidden, entry.Status)
+			suite.Nil(entry.Details)
+			return &entry, nil
+		})
+
+	w := suite.serve("POST", "/refused")
+
+	suite.Equal(http.StatusForbidden, w.Code)
+}
+
+func (suite *AuditHandlerTestSuite) TestMiddleware_RecordFailureAnswers500() {
+	suite.mockService.EXPECT().
+		Record(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, entry entity.AuditEntry) (*entity.AuditEntry, error) {
+			suite.Equal(http.StatusOK, entry.Status)
+			return nil, apperr.ErrInternal
+		})
+
+	w := suite.serve("PUT", "/rules/R1")
+
+	suite.Equal(http.StatusInternalServerError, w.Code)
+	suite.Empty(w.Header().Get("Content-Disposition"))
+	suite.NotContains(w.Body.String(), "saved")
+	suite.Contains(w.Body.String(), "could not be recorded in the audit log")
+}
+
+func (suite *AuditHandlerTestSuite) TestMiddleware_RecordFailureWithoutBody() {
+	suite.mockService.EXPECT().
+		Record(gomock.Any(), gomock.Any()).
+		Return(nil, apperr.ErrInternal)
+
+	w := suite.serve("POST", "/refused")
+
+	suite.Equal(http.StatusInternalServerError, w.Code)
+	suite.Contains(w.Body.String(), "could not be recorded in the audit log")
+}
+
+func (suite *AuditHandlerTestSuite) TestMiddleware_StreamsAndRecordsAbortedResponses() {
+	var recorded []entity.AuditEntry
+	suite.mockService.EXPECT().
+		Record(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(_ context.Context, entry entity.AuditEntry) (*entity.AuditEntry, error) {
+			recorded = append(recorded, entry)
+			return &entry, nil
+		})
+
+	suite.serve("POST", "/stream")
+
+	// The body reached the client while the handler was still running.
+	suite.Equal("first part", suite.flushed)
+	suite.Require().Len(recorded, 2)
+	suite.Equal(http.StatusOK, recorded[0].Status)
+	suite.Equal(map[string]string{"records": "2"}, recorded[0].Details)
+	suite.Equal(http.StatusInternalServerError, recorded[1].Status)
+	suite.Equal("2", recorded[1].Details["records"])
+	suite.Contains(recorded[1].Details["error"], "report storage went away")
+}
+
+func (suite *AuditHandlerTestSuite) TestMiddleware_SkipsReadsAndUnknownRoutes() {
+	suite.mockService.EXPECT().
+		ListEntries(gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+
+	suite.Equal(http.StatusOK, suite.serve("GET", "/audit").Code)
+	suite.Equal(http.StatusNotFound, suite.serve("POST", "/missing").Code)
+}
+
+func (suite *AuditHandlerTestSuite) TestListEntries() {
+	suite.mockService.EXPECT().
+		ListEntries(gomock.Any(), entity.AuditFilter{
+			Actor:         "alice",
+			Action:        "POST /api/v1/rules",
+			From:          time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			To:            time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC),
+			AfterSequence: 10,
+			Limit:         defaultAuditLimit,
+		}).
+		Return([]entity.AuditEntry{{
+			Sequence:   11,
+			OccurredAt: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+			Actor:      "alice",
+			Source:     audit.SourceAPI,
+			Action:     "POST /api/v1/rules",
+			Target:     "/api/v1/rules",
+			Status:     http.StatusCreated,
+			Details:    map[string]string{"rule_id": "R1"},
+			PrevHash:   "aaa",
+			Hash:       "bbb",
+		}}, nil)
+
+	w := suite.serve("GET", "/audit?actor=alice&action=POST+/api/v1/rules&from=2025-01-15&to=2025-01-16&after=10")
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`[{
+		"sequence": 11,
+		"occurred_at": "2025-01-15T10:00:00Z",
+		"actor": "alice",
+		"source": "api",
+		"action": "POST /api/v1/rules",
+		"target": "/api/v1/rules",
+		"status": 201,
+		"details": {"rule_id": "R1"},
+		"prev_hash": "aaa",
+		"hash": "bbb"
+	}]`, w.Body.String())
+}
+
+func (suite *AuditHandlerTestSuite) TestListEntries_Invalid() {
+	for _, query := range []string{"limit=5000", "limit=-1", "after=x", "from=yesterday"} {
+		w := suite.serve("GET", "/audit?"+query)
+
+		suite.Equal(http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func (suite *AuditHandlerTestSuite) TestExportEntries() {
+	suite.mockService.EXPECT().
+		ExportEntries(gomock.Any(), entity.AuditFilter{Actor: "alice"}, report.FormatJSON, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ entity.AuditFilter, _ report.Format, w io.Writer) error {
+			_, err := io.WriteString(w, "[]")
+			return err
+		})
+
+	w := suite.serve("GET", "/audit/export?actor=alice&format=json")
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal(`attachment; filename="audit-log.json"`, w.Header().Get("Content-Disposition"))
+	suite.Equal("[]", strings.TrimSpace(w.Body.String()))
+}
+
+func (suite *AuditHandlerTestSuite) TestVerifyChain() {
+	suite.mockService.EXPECT().
+		Verify(gomock.Any()).
+		Return(&entity.AuditVerification{Entries: 3, FirstBroken: 2, Reason: "hash does not match the entry's content", LastHash: "ccc"}, nil)
+
+	w := suite.serve("GET", "/audit/verify")
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"entries": 3, "valid": false, "first_broken": 2, "reason": "hash does not match the entry's content", "last_hash": "ccc"}`, w.Body.String())
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
+)
+
+// apiKey is an entry of the API keys file. Only the SHA-256 of the key is kept, so
+// the file does not give the keys away.
+type apiKey struct {
+	Name     string   `json:"name"`
+	SHA256   string   `json:"sha256"`
+	Roles    []Role   `json:"roles"`
+	Branches []string `json:"branches"`
+	Tenant   string   `json:"tenant"`
+}
+
+func loadAPIKeys(path string) (map[[sha256.Size]byte]Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read API keys: %w", err)
+	}
+
+	var entries []apiKey
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("could not parse API keys: %w", err)
+	}
+
+	keys := make(map[[sha256.Size]byte]Identity, len(entries))
+	for i, entry := range entries {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("API key %d: name is required", i+1)
+		}
+
+		hash, err := hex.DecodeString(entry.SHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("API key %s: sha256 must be 64 hex digits", entry.Name)
+		}
+		if len(entry.Roles) == 0 {
+			return nil, fmt.Errorf("API key %s: at least one role is required", entry.Name)
+		}
+		for _, role := range entry.Roles {
+			if !slices.Contains(Roles, role) {
+				return nil, fmt.Errorf("API key %s: unknown role %q", entry.Name, role)
+			}
+		}
+
+		if entry.Tenant != AnyTenant {
+			if err = tenant.Validate(entry.Tenant); err != nil {
+				return nil, fmt.Errorf("API key %s: %w", entry.Name, err)
+			}
+		}
+		identity := Identity{Subject: entry.Name, Roles: entry.Roles, Branches: entry.Branches, Tenant: entry.Tenant}
+		if err = identity.validateTenant(); err != nil {
+			return nil, fmt.Errorf("API key %s: %w", entry.Name, err)
+		}
+
+		key := [sha256.Size]byte(hash)
+		if _, ok := keys[key]; ok {
+			return nil, fmt.Errorf("API key %s: the same key is listed twice", entry.Name)
+		}
+		keys[key] = identity
+	}
+
+	return keys, nil
+}
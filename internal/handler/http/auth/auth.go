@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	TenantHeader = "X-Tenant-ID"
+
+	// AnyTenant is the tenant of a caller that picks the program of each request
+	// with the X-Tenant-ID header. Only admins without branches may hold it.
+	AnyTenant = "*"
+
+	identityKey = "auth.identity"
+)
+
+type Role string
+
+// An uploader sends purchase files, a rule-admin manages rules and branch groups,
+// a customer-admin changes customers' points and accounts, a reader sees
+// reports, rules and customers, and an auditor reads the audit log. An admin may
+// do everything.
+const (
+	RoleAdmin         Role = "admin"
+	RoleUploader      Role = "uploader"
+	RoleRuleAdmin     Role = "rule-admin"
+	RoleCustomerAdmin Role = "customer-admin"
+	RoleReader        Role = "reader"
+	RoleAuditor       Role = "auditor"
+)
+
+var Roles = []Role{RoleAdmin, RoleUploader, RoleRuleAdmin, RoleCustomerAdmin, RoleReader, RoleAuditor}
+
+// Identity is the caller of a request. Branches, when set, are the only branches
+// the caller's uploads may credit, as for a store's key. Tenant is the only
+// loyalty program the caller may work on, the default one when empty, or
+// AnyTenant.
+type Identity struct {
+	Subject  string
+	Roles    []Role
+	Branches []string
+	Tenant   string
+}
+
+// HasRole reports whether the identity holds one of roles. An admin holds them all.
+func (i Identity) HasRole(roles ...Role) bool {
+	for _, role := range i.Roles {
+		if role == RoleAdmin || slices.Contains(roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTenant keeps AnyTenant to callers that are trusted with every program.
+func (i Identity) validateTenant() error {
+	if i.Tenant == AnyTenant && (!slices.Contains(i.Roles, RoleAdmin) || len(i.Branches) > 0) {
+		return fmt.Errorf("tenant %q is only allowed for admins without branches", AnyTenant)
+	}
+	return nil
+}
+
+type Authenticator struct {
+	disabled bool
+	apiKeys  map[[sha256.Size]byte]Identity
+	jwt      *jwtVerifier
+	tenants  []string
+}
+
+// New builds the authenticator from the API keys file and the JWT secret or JWKS
+// file. At least one of them is required unless authentication is disabled.
+func New(cfg *config.Config) (*Authenticator, error) {
+	if cfg.AuthDisabled {
+		return &Authenticator{disabled: true, tenants: cfg.Tenants}, nil
+	}
+
+	a := &Authenticator{tenants: cfg.Tenants}
+	if cfg.AuthAPIKeysFile != "" {
+		keys, err := loadAPIKeys(cfg.AuthAPIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		a.apiKeys = keys
+	}
+	if cfg.AuthJWTSecret != "" || cfg.AuthJWKSFile != "" {
+		verifier, err := newJWTVerifier(cfg.AuthJWTSecret, cfg.AuthJWKSFile, cfg.AuthJWTIssuer, cfg.AuthJWTAudience)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = verifier
+	}
+
+	if len(a.apiKeys) == 0 && a.jwt == nil {
+		return nil, fmt.Errorf("authentication needs AUTH_API_KEYS_FILE, AUTH_JWT_SECRET or AUTH_JWKS_FILE; set AUTH_DISABLED=true to run without it")
+	}
+
+	return a, nil
+}
+
+// Middleware authenticates every request by its X-API-Key header or its bearer
+// token, and answers 401 when neither identifies a caller. It then puts the
+// loyalty program the request works on into the request's context.
+func (a *Authenticator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, err := a.authenticate(c.Request, time.Now())
+		if err != nil {
+			errors.RespondWithError(c, err)
+			c.Abort()
+			return
+		}
+		c.Set(identityKey, identity)
+
+		id, err := a.resolveTenant(identity, c.GetHeader(TenantHeader))
+		if err != nil {
+			errors.RespondWithError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+func (a *Authenticator) authenticate(r *http.Request, now time.Time) (Identity, error) {
+	if a.disabled {
+		return Identity{Subject: "anonymous", Roles: []Role{RoleAdmin}, Tenant: AnyTenant}, nil
+	}
+
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		// Keys are looked up by their hash, so the lookup time says nothing about
+		// how close a guess was.
+		identity, ok := a.apiKeys[sha256.Sum256([]byte(key))]
+		if !ok {
+			return Identity{}, apperr.ErrUnauthenticated.WithMessage("unknown API key")
+		}
+		return identity, nil
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return Identity{}, apperr.ErrUnauthenticated.WithMessage("an API key or bearer token is required")
+	}
+	if a.jwt == nil {
+		return Identity{}, apperr.ErrUnauthenticated.WithMessage("bearer tokens are not accepted")
+	}
+
+	return a.jwt.verify(token, now)
+}
+
+// resolveTenant picks the program of a request. A caller with AnyTenant names one
+// with the X-Tenant-ID header, and works on the default program without it. Any
+// other caller always works on its own program, the default one when it has
+// none, so a store's key cannot credit another program.
+func (a *Authenticator) resolveTenant(identity Identity, header string) (string, error) {
+	id := identity.Tenant
+	if id == AnyTenant {
+		id = header
+	} else if header != "" && header != id {
+		if id == tenant.Default {
+			return "", apperr.ErrPermissionDenied.WithMessage("this caller may only work on the default tenant")
+		}
+		return "", apperr.ErrPermissionDenied.WithMessage(fmt.Sprintf("this caller may only work on tenant %s", id))
+	}
+
+	if id != tenant.Default && !slices.Contains(a.tenants, id) {
+		return "", apperr.ErrNotFound.WithMessage(fmt.Sprintf("unknown tenant %s", id))
+	}
+	return id, nil
+}
+
+// RequireRole lets a request through only when its caller holds one of roles.
+func RequireRole(roles ...Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, _ := IdentityFrom(c)
+		if !identity.HasRole(roles...) {
+			errors.RespondWithError(c, apperr.ErrPermissionDenied.WithMessage(fmt.Sprintf("one of the roles %v is required", roles)))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// IdentityFrom returns the caller that Middleware authenticated.
+func IdentityFrom(c *gin.Context) (Identity, bool) {
+	value, ok := c.Get(identityKey)
+	if !ok {
+		return Identity{}, false
+	}
+	identity, ok := value.(Identity)
+	return identity, ok
+}
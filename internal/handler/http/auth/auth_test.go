@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthTestSuite struct {
+	suite.Suite
+	dir    string
+	router *gin.Engine
+}
+
+func TestAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
+
+func (suite *AuthTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.dir = suite.T().TempDir()
+
+	keysFile := suite.writeFile("api-keys.json", `[
+		{"name": "store-br0001", "sha256": "`+hashKey("store-key")+`", "roles": ["uploader"], "branches": ["BR0001"]},
+		{"name": "ops", "sha256": "`+hashKey("admin-key")+`", "roles": ["admin"], "tenant": "*"},
+		{"name": "default-ops", "sha256": "`+hashKey("default-admin-key")+`", "roles": ["admin"]},
+		{"name": "brand-b-ops", "sha256": "`+hashKey("brand-b-key")+`", "roles": ["admin"], "tenant": "brand_b"}
+	]`)
+	authenticator, err := New(&config.Config{AuthAPIKeysFile: keysFile, AuthJWTSecret: "secret", Tenants: []string{"brand_b", "brand_c"}})
+	suite.Require().NoError(err)
+
+	suite.router = suite.newRouter(authenticator)
+}
+
+func (suite *AuthTestSuite) newRouter(authenticator *Authenticator) *gin.Engine {
+	router := gin.New()
+	router.Use(authenticator.Middleware())
+	router.POST("/upload", RequireRole(RoleUploader), func(c *gin.Context) {
+		identity, _ := IdentityFrom(c)
+		c.JSON(http.StatusOK, gin.H{"subject": identity.Subject, "branches": identity.Branches})
+	})
+	router.PUT("/rules", RequireRole(RoleRuleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/tenant", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"tenant": tenant.FromContext(c.Request.Context())})
+	})
+	return router
+}
+
+func (suite *AuthTestSuite) writeFile(name, content string) string {
+	path := filepath.Join(suite.dir, name)
+	suite.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (suite *AuthTestSuite) serve(method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for name, values := range header {
+		req.Header.Set(name, values[0])
+	}
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *AuthTestSuite) TestAPIKey() {
+	w := suite.serve("POST", "/upload", http.Header{APIKeyHeader: {"store-key"}})
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"subject": "store-br0001", "branches": ["BR0001"]}`, w.Body.String())
+}
+
+func (suite *AuthTestSuite) TestUnknownAPIKey() {
+	w := suite.serve("POST", "/upload", http.Header{APIKeyHeader: {"guess"}})
+
+	suite.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (suite *AuthTestSuite) TestMissingCredentials() {
+	w := suite.serve("POST", "/upload", nil)
+
+	suite.Equal(http.StatusUnauthorized, w.Code)
+	suite.Contains(w.Body.String(), "API key or bearer token is required")
+}
+
+func (suite *AuthTestSuite) TestWrongRole() {
+	w := suite.serve("PUT", "/rules", http.Header{APIKeyHeader: {"store-key"}})
+
+	suite.Equal(http.StatusForbidden, w.Code)
+}
+
+func (suite *AuthTestSuite) TestAdminHoldsEveryRole() {
+	w := suite.serve("PUT", "/rules", http.Header{APIKeyHeader: {"admin-key"}})
+
+	suite.Equal(http.StatusNoContent, w.Code)
+}
+
+func (suite *AuthTestSuite) TestBearerToken() {
+	token := signHS256("secret", `{"sub": "alice", "exp": 4102444800, "roles": ["rule-admin"]}`)
+
+	w := suite.serve("PUT", "/rules", http.Header{"Authorization": {"Bearer " + token}})
+
+	suite.Equal(http.StatusNoContent, w.Code)
+}
+
+func (suite *AuthTestSuite) TestDisabled() {
+	authenticator, err := New(&config.Config{AuthDisabled: true})
+	suite.Require().NoError(err)
+	suite.router = suite.newRouter(authenticator)
+
+	w := suite.serve("PUT", "/rules", nil)
+
+	suite.Equal(http.StatusNoContent, w.Code)
+}
+
+func (suite *AuthTestSuite) TestNew_NothingConfigured() {
+	_, err := New(&config.Config{})
+
+	suite.ErrorContains(err, "AUTH_DISABLED=true")
+}
+
+func (suite *AuthTestSuite) TestNew_InvalidAPIKeys() {
+	testCases := map[string]struct {
+		content string
+		want    string
+	}{
+		"unknown role":  {content: `[{"name": "a", "sha256": "` + hashKey("a") + `", "roles": ["owner"]}]`, want: `unknown role "owner"`},
+		"no roles":      {content: `[{"name": "a", "sha256": "` + hashKey("a") + `"}]`, want: "at least one role"},
+		"bad hash":      {content: `[{"name": "a", "sha256": "abc", "roles": ["reader"]}]`, want: "64 hex digits"},
+		"bad tenant":    {content: `[{"name": "a", "sha256": "` + hashKey("a") + `", "roles": ["reader"], "tenant": "../b"}]`, want: "lowercase letters"},
+		"duplicate key": {content: `[{"name": "a", "sha256": "` + hashKey("a") + `", "roles": ["reader"]}, {"name": "b", "sha256": "` + hashKey("a") + `", "roles": ["reader"]}]`, want: "listed twice"},
+		"any tenant, not admin": {
+			content: `[{"name": "a", "sha256": "` + hashKey("a") + `", "roles": ["uploader"], "tenant": "*"}]`,
+			want:    "only allowed for admins without branches",
+		},
+		"any tenant, branches": {
+			content: `[{"name": "a", "sha256": "` + hashKey("a") + `", "roles": ["admin"], "branches": ["BR0001"], "tenant": "*"}]`,
+			want:    "only allowed for admins without branches",
+		},
+	}
+
+	for name, tc := range testCases {
+		suite.Run(name, func() {
+			_, err := New(&config.Config{AuthAPIKeysFile: suite.writeFile("keys.json", tc.content)})
+
+			suite.ErrorContains(err, tc.want)
+		})
+	}
+}
+
+func (suite *AuthTestSuite) TestTenant() {
+	testCases := map[string]struct {
+		header http.Header
+		code   int
+		want   string
+	}{
+		"default program": {header: http.Header{APIKeyHeader: {"admin-key"}}, code: http.StatusOK, want: `{"tenant": ""}`},
+		"unbound key":     {header: http.Header{APIKeyHeader: {"default-admin-key"}}, code: http.StatusOK, want: `{"tenant": ""}`},
+		"header":          {header: http.Header{APIKeyHeader: {"admin-key"}, TenantHeader: {"brand_c"}}, code: http.StatusOK, want: `{"tenant": "brand_c"}`},
+		"bound key":       {header: http.Header{APIKeyHeader: {"brand-b-key"}}, code: http.StatusOK, want: `{"tenant": "brand_b"}`},
+		"bound key, same header": {
+			header: http.Header{APIKeyHeader: {"brand-b-key"}, TenantHeader: {"brand_b"}},
+			code:   http.StatusOK,
+			want:   `{"tenant": "brand_b"}`,
+		},
+		"bound token": {
+			header: http.Header{"Authorization": {"Bearer " + signHS256("secret", `{"sub": "alice", "exp": 4102444800, "roles": ["reader"], "tenant": "brand_c"}`)}},
+			code:   http.StatusOK,
+			want:   `{"tenant": "brand_c"}`,
+		},
+		"any tenant token": {
+			header: http.Header{"Authorization": {"Bearer " + signHS256("secret", `{"sub": "alice", "exp": 4102444800, "roles": ["admin"], "tenant": "*"}`)}, TenantHeader: {"brand_b"}},
+			code:   http.StatusOK,
+			want:   `{"tenant": "brand_b"}`,
+		},
+	}
+
+	for name, tc := range testCases {
+		suite.Run(name, func() {
+			w := suite.serve("GET", "/tenant", tc.header)
+
+			suite.Equal(tc.code, w.Code)
+			suite.JSONEq(tc.want, w.Body.String())
+		})
+	}
+}
+
+func (suite *AuthTestSuite) TestTenant_OtherProgramForbidden() {
+	w := suite.serve("GET", "/tenant", http.Header{APIKeyHeader: {"brand-b-key"}, TenantHeader: {"brand_c"}})
+
+	suite.Equal(http.StatusForbidden, w.Code)
+	suite.Contains(w.Body.String(), "only work on tenant brand_b")
+}
+
+func (suite *AuthTestSuite) TestTenant_UnboundCallerStaysInDefault() {
+	testCases := map[string]http.Header{
+		"store key":      {APIKeyHeader: {"store-key"}, TenantHeader: {"brand_b"}},
+		"admin key":      {APIKeyHeader: {"default-admin-key"}, TenantHeader: {"brand_b"}},
+		"unbound token":  {"Authorization": {"Bearer " + signHS256("secret", `{"sub": "alice", "exp": 4102444800, "roles": ["admin"]}`)}, TenantHeader: {"brand_b"}},
+		"uploader token": {"Authorization": {"Bearer " + signHS256("secret", `{"sub": "alice", "exp": 4102444800, "roles": ["uploader"], "branches": ["BR0001"]}`)}, TenantHeader: {"brand_c"}},
+	}
+
+	for name, header := range testCases {
+		suite.Run(name, func() {
+			w := suite.serve("GET", "/tenant", header)
+
+			suite.Equal(http.StatusForbidden, w.Code)
+			suite.Contains(w.Body.String(), "only work on the default tenant")
+		})
+	}
+}
+
+func (suite *AuthTestSuite) TestTenant_AnyTenantTokenNeedsAdmin() {
+	token := signHS256("secret", `{"sub": "alice", "exp": 4102444800, "roles": ["uploader"], "tenant": "*"}`)
+
+	w := suite.serve("GET", "/tenant", http.Header{"Authorization": {"Bearer " + token}, TenantHeader: {"brand_b"}})
+
+	suite.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (suite *AuthTestSuite) TestTenant_Unknown() {
+	w := suite.serve("GET", "/tenant", http.Header{APIKeyHeader: {"admin-key"}, TenantHeader: {"brand_x"}})
+
+	suite.Equal(http.StatusNotFound, w.Code)
+	suite.Contains(w.Body.String(), "unknown tenant brand_x")
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+)
+
+const (
+	// minRSAKeyBits is the smallest JWKS key accepted; shorter keys can be factored.
+	minRSAKeyBits = 2048
+)
+
+var (
+	hmacMethods = []string{"HS256", "HS384", "HS512"}
+	rsaMethods  = []string{"RS256", "RS384", "RS512"}
+)
+
+// jwtVerifier checks HS* tokens against a shared secret and RS* tokens against
+// the RSA keys of a local JWKS file. Only the algorithms of the configured keys
+// are accepted, so a token cannot pick how it is checked, as an HS256 token
+// signed with an RSA public key or an unsigned one would.
+type jwtVerifier struct {
+	secret   []byte
+	keys     map[string]*rsa.PublicKey
+	methods  []string
+	issuer   string
+	audience string
+}
+
+// jwtClaims are the registered claims the service checks, and the roles,
+// branches and program of the caller.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Roles    []Role   `json:"roles"`
+	Branches []string `json:"branches"`
+	Tenant   string   `json:"tenant"`
+}
+
+// Validate is called by the parser once the registered claims have been checked.
+func (c jwtClaims) Validate() error {
+	if c.Subject == "" {
+		return errors.New("sub is required")
+	}
+	return nil
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+func newJWTVerifier(secret, jwksFile, issuer, audience string) (*jwtVerifier, error) {
+	verifier := &jwtVerifier{issuer: issuer, audience: audience}
+	if secret != "" {
+		verifier.secret = []byte(secret)
+		verifier.methods = append(verifier.methods, hmacMethods...)
+	}
+	if jwksFile != "" {
+		keys, err := loadJWKS(jwksFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = keys
+		verifier.methods = append(verifier.methods, rsaMethods...)
+	}
+	if len(verifier.methods) == 0 {
+		return nil, errors.New("JWT verification needs a secret or a JWKS file")
+	}
+	return verifier, nil
+}
+
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read JWKS: %w", err)
+	}
+
+	var set jwks
+	if err = json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("could not parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: invalid n: %w", key.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: invalid e: %w", key.Kid, err)
+		}
+
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 2 || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("JWKS key %q: unsupported exponent", key.Kid)
+		}
+		modulus := new(big.Int).SetBytes(n)
+		if modulus.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("JWKS key %q: %d bits is shorter than the %d required", key.Kid, modulus.BitLen(), minRSAKeyBits)
+		}
+		keys[key.Kid] = &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS %s has no RSA keys", path)
+	}
+
+	return keys, nil
+}
+
+func (v *jwtVerifier) verify(token string, now time.Time) (Identity, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(v.methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	}
+	if v.issuer != "" {
+		options = append(options, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		options = append(options, jwt.WithAudience(v.audience))
+	}
+
+	var claims jwtClaims
+	if _, err := jwt.ParseWithClaims(token, &claims, v.key, options...); err != nil {
+		return Identity{}, tokenError(err)
+	}
+
+	identity := Identity{Subject: claims.Subject, Roles: claims.Roles, Branches: claims.Branches, Tenant: claims.Tenant}
+	if err := identity.validateTenant(); err != nil {
+		return Identity{}, invalidToken(err.Error())
+	}
+
+	return identity, nil
+}
+
+// key returns the key that checks token. The parser has already refused any
+// algorithm outside v.methods.
+func (v *jwtVerifier) key(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.secret, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		if key := v.rsaKey(kid); key != nil {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key %q", kid)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", token.Method.Alg())
+	}
+}
+
+// rsaKey returns the JWKS key with the kid, or the only key when the token names none.
+func (v *jwtVerifier) rsaKey(kid string) *rsa.PublicKey {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key
+		}
+	}
+	return v.keys[kid]
+}
+
+// tokenError keeps the reasons of the common claim failures short, and otherwise
+// passes on the parser's.
+func tokenError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return invalidToken("malformed token")
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return invalidToken("token expired")
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return invalidToken("token not valid yet")
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return invalidToken("wrong issuer")
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return invalidToken("wrong audience")
+	default:
+		return invalidToken(err.Error())
+	}
+}
+
+func invalidToken(reason string) error {
+	return apperr.ErrUnauthenticated.WithMessage("invalid token: " + reason)
+}
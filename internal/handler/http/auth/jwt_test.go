@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type JWTTestSuite struct {
+	suite.Suite
+	key  *rsa.PrivateKey
+	now  time.Time
+	jwks string
+}
+
+func TestJWTTestSuite(t *testing.T) {
+	suite.Run(t, new(JWTTestSuite))
+}
+
+func (suite *JWTTestSuite) SetupSuite() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().NoError(err)
+	suite.key = key
+	suite.now = time.Unix(1735689600, 0)
+
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	suite.jwks = filepath.Join(suite.T().TempDir(), "jwks.json")
+	content := `{"keys": [{"kty": "RSA", "kid": "k1", "n": "` + n + `", "e": "` + e + `"}, {"kty": "EC", "kid": "k2"}]}`
+	suite.Require().NoError(os.WriteFile(suite.jwks, []byte(content), 0o600))
+}
+
+func encodeSegment(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func signHS256(secret, claims string) string {
+	signed := encodeSegment(`{"alg": "HS256", "typ": "JWT"}`) + "." + encodeSegment(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (suite *JWTTestSuite) signRS256(kid, claims string) string {
+	signed := encodeSegment(`{"alg": "RS256", "kid": "`+kid+`"}`) + "." + encodeSegment(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, suite.key, crypto.SHA256, digest[:])
+	suite.Require().NoError(err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (suite *JWTTestSuite) TestVerify_HS256() {
+	verifier, err := newJWTVerifier("secret", "", "", "")
+	suite.Require().NoError(err)
+
+	identity, err := verifier.verify(signHS256("secret", `{"sub": "alice", "exp": 1735693200, "roles": ["reader"], "branches": ["BR0001"]}`), suite.now)
+
+	suite.NoError(err)
+	suite.Equal(Identity{Subject: "alice", Roles: []Role{RoleReader}, Branches: []string{"BR0001"}}, identity)
+}
+
+func (suite *JWTTestSuite) TestVerify_RS256() {
+	verifier, err := newJWTVerifier("", suite.jwks, "https://idp.example.com", "points")
+	suite.Require().NoError(err)
+
+	identity, err := verifier.verify(suite.signRS256("k1", `{"sub": "bob", "iss": "https://idp.example.com", "aud": ["points", "other"], "exp": 1735693200, "roles": ["uploader"]}`), suite.now)
+
+	suite.NoError(err)
+	suite.Equal("bob", identity.Subject)
+}
+
+func (suite *JWTTestSuite) TestVerify_Rejected() {
+	verifier, err := newJWTVerifier("secret", suite.jwks, "", "points")
+	suite.Require().NoError(err)
+	valid := `{"sub": "bob", "aud": "points", "exp": 1735693200}`
+
+	testCases := map[string]struct {
+		token string
+		want  string
+	}{
+		"expired":         {token: signHS256("secret", `{"sub": "bob", "aud": "points", "exp": 1735689600}`), want: "token expired"},
+		"not valid yet":   {token: signHS256("secret", `{"sub": "bob", "aud": "points", "exp": 1735693200, "nbf": 1735690000}`), want: "not valid yet"},
+		"no expiry":       {token: signHS256("secret", `{"sub": "bob", "aud": "points"}`), want: "exp claim is required"},
+		"no subject":      {token: signHS256("secret", `{"aud": "points", "exp": 1735693200}`), want: "sub is required"},
+		"wrong secret":    {token: signHS256("guess", valid), want: "signature is invalid"},
+		"wrong audience":  {token: signHS256("secret", `{"sub": "bob", "aud": "other", "exp": 1735693200}`), want: "wrong audience"},
+		"unknown key":     {token: suite.signRS256("k9", valid), want: `unknown key "k9"`},
+		"unsigned":        {token: encodeSegment(`{"alg": "none"}`) + "." + encodeSegment(valid) + ".", want: "signing method none is invalid"},
+		"malformed token": {token: "not-a-token", want: "malformed token"},
+	}
+
+	for name, tc := range testCases {
+		suite.Run(name, func() {
+			_, err := verifier.verify(tc.token, suite.now)
+
+			suite.Equal(apperr.ErrUnauthenticated.Code, apperr.GetCode(err))
+			suite.ErrorContains(err, tc.want)
+		})
+	}
+}
+
+func (suite *JWTTestSuite) TestVerify_HMACNotAccepted() {
+	verifier, err := newJWTVerifier("", suite.jwks, "", "")
+	suite.Require().NoError(err)
+
+	_, err = verifier.verify(signHS256("", `{"sub": "bob", "exp": 1735693200}`), suite.now)
+
+	suite.ErrorContains(err, "signing method HS256 is invalid")
+}
+
+func (suite *JWTTestSuite) TestVerify_AlgorithmConfusion() {
+	// An HS256 token signed with the RSA public key must not verify against it,
+	// whether or not a secret is configured as well.
+	publicKey, err := x509.MarshalPKIXPublicKey(&suite.key.PublicKey)
+	suite.Require().NoError(err)
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+	claims := `{"sub": "mallory", "exp": 1735693200, "roles": ["admin"]}`
+
+	for _, secret := range []string{"", "secret"} {
+		verifier, err := newJWTVerifier(secret, suite.jwks, "", "")
+		suite.Require().NoError(err)
+
+		for _, key := range []string{pemKey, string(suite.key.N.Bytes())} {
+			_, err = verifier.verify(signHS256(key, claims), suite.now)
+
+			suite.Equal(apperr.ErrUnauthenticated.Code, apperr.GetCode(err))
+		}
+	}
+}
+
+func (suite *JWTTestSuite) TestNewJWTVerifier_ShortRSAKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	suite.Require().NoError(err)
+
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	path := filepath.Join(suite.T().TempDir(), "jwks.json")
+	content := `{"keys": [{"kty": "RSA", "kid": "weak", "n": "` + n + `", "e": "` + e + `"}]}`
+	suite.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+
+	_, err = newJWTVerifier("", path, "", "")
+
+	suite.ErrorContains(err, "1024 bits is shorter than the 2048 required")
+}
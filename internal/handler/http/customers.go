@@ -0,0 +1,207 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/audit"
+	"github.com/sirawong/point-accumulate-interview/internal/services/customers"
+)
+
+const (
+	tierChangePromotion = "PROMOTION"
+	tierChangeDemotion  = "DEMOTION"
+
+	AttributesCSVKey = "csv_file"
+)
+
+type CustomerHandler struct {
+	CustomerSvc customers.CustomerService
+}
+
+func NewCustomerHandler(CustomerSvc customers.CustomerService) *CustomerHandler {
+	return &CustomerHandler{CustomerSvc: CustomerSvc}
+}
+
+type tierChangeResponse struct {
+	Date   string `json:"date"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Change string `json:"change"`
+}
+
+type customerTierResponse struct {
+	Name             string               `json:"name"`
+	Multiplier       decimal.Decimal      `json:"multiplier"`
+	Since            string               `json:"since,omitempty"`
+	QualifyingPoints int64                `json:"qualifying_points"`
+	QualifyingSpend  decimal.Decimal      `json:"qualifying_spend"`
+	History          []tierChangeResponse `json:"history"`
+}
+
+type customerAttributesResponse struct {
+	SignupDate string `json:"signup_date,omitempty"`
+	BirthMonth int    `json:"birth_month,omitempty"`
+	Segment    string `json:"segment,omitempty"`
+}
+
+type customerResponse struct {
+	CustomerID       string                     `json:"customer_id"`
+	Points           int64                      `json:"points"`
+	HouseholdID      string                     `json:"household_id,omitempty"`
+	MergedInto       string                     `json:"merged_into,omitempty"`
+	Aliases          []string                   `json:"aliases,omitempty"`
+	LastPurchaseDate string                     `json:"last_purchase_date"`
+	AsOf             string                     `json:"as_of"`
+	Attributes       customerAttributesResponse `json:"attributes"`
+	Tier             customerTierResponse       `json:"tier"`
+}
+
+type importAttributesResponse struct {
+	Customers int `json:"customers"`
+}
+
+type tierResponse struct {
+	Name       string           `json:"name"`
+	Rank       int              `json:"rank"`
+	Basis      entity.TierBasis `json:"basis"`
+	Threshold  decimal.Decimal  `json:"threshold"`
+	Multiplier decimal.Decimal  `json:"multiplier"`
+}
+
+// GetCustomer returns a customer's balance and the tier they hold at the end of
+// as_of, today by default.
+func (h CustomerHandler) GetCustomer(c *gin.Context) {
+	asOf := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := c.Query("as_of"); value != "" {
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			errors.RespondWithError(c, apperr.ErrInvalidArgument.WithMessage("as_of must be in YYYY-MM-DD format"))
+			return
+		}
+		asOf = date
+	}
+
+	result, err := h.CustomerSvc.GetCustomer(c.Request.Context(), c.Param("id"), asOf)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCustomerResponse(result, asOf))
+}
+
+// ImportAttributes stores the customer attributes from an uploaded CSV with the
+// columns customer_id, signup_date, birth_month and segment.
+func (h CustomerHandler) ImportAttributes(c *gin.Context) {
+	fileHeader, err := c.FormFile(AttributesCSVKey)
+	if err != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.WithMessage("no file uploaded"))
+		return
+	}
+
+	if err = validateFileType(fileHeader); err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		errors.RespondWithError(c, apperr.ErrInternal.Wrap(err))
+		return
+	}
+	defer file.Close()
+
+	digest, err := audit.HashFile(fileHeader.Filename, file)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+	addAuditFiles(c, digest)
+
+	count, err := h.CustomerSvc.ImportAttributes(c.Request.Context(), file)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+	addAuditDetail(c, "customers", strconv.Itoa(count))
+
+	c.JSON(http.StatusOK, importAttributesResponse{Customers: count})
+}
+
+func (h CustomerHandler) ListTiers(c *gin.Context) {
+	result, err := h.CustomerSvc.GetTiers(c.Request.Context())
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	response := make([]tierResponse, 0, len(result))
+	for _, tier := range result {
+		response = append(response, tierResponse{
+			Name:       tier.Name,
+			Rank:       tier.Rank,
+			Basis:      tier.Basis,
+			Threshold:  tier.Threshold,
+			Multiplier: tier.Multiplier,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func toCustomerResponse(profile *entity.CustomerProfile, asOf time.Time) customerResponse {
+	tier := customerTierResponse{
+		Name:             profile.Tier.Tier,
+		Multiplier:       profile.Tier.Multiplier,
+		QualifyingPoints: profile.Tier.QualifyingPoints,
+		QualifyingSpend:  profile.Tier.QualifyingSpend,
+		History:          make([]tierChangeResponse, 0, len(profile.Tier.History)),
+	}
+	if profile.Tier.Since != nil {
+		tier.Since = profile.Tier.Since.Format(time.DateOnly)
+	}
+	for _, change := range profile.Tier.History {
+		kind := tierChangeDemotion
+		if change.Promotion {
+			kind = tierChangePromotion
+		}
+		tier.History = append(tier.History, tierChangeResponse{
+			Date:   change.Date.Format(time.DateOnly),
+			From:   change.From,
+			To:     change.To,
+			Change: kind,
+		})
+	}
+
+	var lastPurchaseDate string
+	if !profile.Customer.LastPurchaseDate.IsZero() {
+		lastPurchaseDate = profile.Customer.LastPurchaseDate.Format(time.DateOnly)
+	}
+
+	attributes := customerAttributesResponse{
+		BirthMonth: profile.Customer.Attributes.BirthMonth,
+		Segment:    profile.Customer.Attributes.Segment,
+	}
+	if profile.Customer.Attributes.SignupDate != nil {
+		attributes.SignupDate = profile.Customer.Attributes.SignupDate.Format(time.DateOnly)
+	}
+
+	return customerResponse{
+		CustomerID:       profile.Customer.CustomerID,
+		Points:           profile.Customer.Points,
+		HouseholdID:      profile.Customer.HouseholdID,
+		MergedInto:       profile.Customer.MergedInto,
+		Aliases:          profile.Customer.Aliases,
+		LastPurchaseDate: lastPurchaseDate,
+		AsOf:             asOf.Format(time.DateOnly),
+		Attributes:       attributes,
+		Tier:             tier,
+	}
+}
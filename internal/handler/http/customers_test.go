@@ -0,0 +1,185 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	customermocks "github.com/sirawong/point-accumulate-interview/internal/services/customers/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type CustomerHandlerTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockService *customermocks.MockCustomerService
+	router      *gin.Engine
+}
+
+func TestCustomerHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(CustomerHandlerTestSuite))
+}
+
+func (suite *CustomerHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockService = customermocks.NewMockCustomerService(suite.mockCtrl)
+	handler := NewCustomerHandler(suite.mockService)
+
+	suite.router = gin.New()
+	suite.router.POST("/customers/attributes", handler.ImportAttributes)
+	suite.router.GET("/customers/:id", handler.GetCustomer)
+	suite.router.GET("/tiers", handler.ListTiers)
+}
+
+func (suite *CustomerHandlerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *CustomerHandlerTestSuite) TestGetCustomer() {
+	asOf := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	since := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	signup := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	suite.mockService.EXPECT().
+		GetCustomer(gomock.Any(), "U000001", asOf).
+		Return(&entity.CustomerProfile{
+			Customer: entity.Customer{
+				CustomerID:       "U000001",
+				Points:           1200,
+				LastPurchaseDate: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+				Attributes:       entity.CustomerAttributes{SignupDate: &signup, BirthMonth: 7},
+			},
+			Tier: entity.CustomerTier{
+				Tier:             "GOLD",
+				Multiplier:       decimal.RequireFromString("1.25"),
+				Since:            &since,
+				QualifyingPoints: 1200,
+				QualifyingSpend:  decimal.NewFromInt(50000),
+				History: []entity.TierChange{
+					{Date: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), To: "SILVER", Promotion: true},
+					{Date: since, From: "SILVER", To: "GOLD", Promotion: true},
+				},
+			},
+		}, nil)
+
+	req := httptest.NewRequest("GET", "/customers/U000001?as_of=2025-03-01", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{
+		"customer_id": "U000001",
+		"points": 1200,
+		"last_purchase_date": "2025-02-01",
+		"as_of": "2025-03-01",
+		"attributes": {"signup_date": "2024-05-20", "birth_month": 7},
+		"tier": {
+			"name": "GOLD",
+			"multiplier": "1.25",
+			"since": "2025-02-01",
+			"qualifying_points": 1200,
+			"qualifying_spend": "50000",
+			"history": [
+				{"date": "2024-06-01", "from": "", "to": "SILVER", "change": "PROMOTION"},
+				{"date": "2025-02-01", "from": "SILVER", "to": "GOLD", "change": "PROMOTION"}
+			]
+		}
+	}`, w.Body.String())
+}
+
+func (suite *CustomerHandlerTestSuite) TestGetCustomer_InvalidAsOf() {
+	req := httptest.NewRequest("GET", "/customers/U000001?as_of=01-03-2025", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.Contains(w.Body.String(), "YYYY-MM-DD")
+}
+
+func (suite *CustomerHandlerTestSuite) TestGetCustomer_NotFound() {
+	suite.mockService.EXPECT().
+		GetCustomer(gomock.Any(), "U404", gomock.Any()).
+		Return(nil, apperr.ErrNotFound.WithMessage("customer not found"))
+
+	req := httptest.NewRequest("GET", "/customers/U404", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusNotFound, w.Code)
+}
+
+func (suite *CustomerHandlerTestSuite) TestListTiers() {
+	suite.mockService.EXPECT().
+		GetTiers(gomock.Any()).
+		Return(entity.Tiers{
+			{Name: "SILVER", Rank: 1, Basis: entity.TierBasisPoints, Threshold: decimal.Zero, Multiplier: decimal.NewFromInt(1)},
+			{Name: "PLATINUM", Rank: 3, Basis: entity.TierBasisSpend, Threshold: decimal.NewFromInt(100000), Multiplier: decimal.RequireFromString("1.5")},
+		}, nil)
+
+	req := httptest.NewRequest("GET", "/tiers", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`[
+		{"name": "SILVER", "rank": 1, "basis": "POINTS", "threshold": "0", "multiplier": "1"},
+		{"name": "PLATINUM", "rank": 3, "basis": "SPEND", "threshold": "100000", "multiplier": "1.5"}
+	]`, w.Body.String())
+}
+
+func (suite *CustomerHandlerTestSuite) TestImportAttributes() {
+	csvContent := "customer_id,signup_date,birth_month,segment\n" +
+		"U000001,2024-05-20,7,VIP"
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreatePart(map[string][]string{
+		"Content-Disposition": {`form-data; name="csv_file"; filename="attributes.csv"`},
+		"Content-Type":        {"text/csv"},
+	})
+	suite.NoError(err)
+	_, err = part.Write([]byte(csvContent))
+	suite.NoError(err)
+	suite.NoError(writer.Close())
+
+	suite.mockService.EXPECT().
+		ImportAttributes(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ any, reader io.ReadSeeker) (int, error) {
+			content, err := io.ReadAll(reader)
+			suite.NoError(err)
+			suite.Equal(csvContent, string(content))
+			return 1, nil
+		})
+
+	req := httptest.NewRequest("POST", "/customers/attributes", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"customers": 1}`, w.Body.String())
+}
+
+func (suite *CustomerHandlerTestSuite) TestImportAttributes_NoFile() {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	suite.NoError(writer.Close())
+
+	req := httptest.NewRequest("POST", "/customers/attributes", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.Contains(w.Body.String(), "no file uploaded")
+}
@@ -16,6 +16,10 @@ func mapErrorToHTTPStatus(code string) int {
 		return http.StatusBadRequest
 	case apperr.ErrUnauthenticated.Code:
 		return http.StatusUnauthorized
+	case apperr.ErrPermissionDenied.Code:
+		return http.StatusForbidden
+	case apperr.ErrConflict.Code:
+		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
 	}
@@ -2,20 +2,25 @@ package http
 
 import (
 	"archive/zip"
+	stderrors "errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
-	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
 	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
 	"github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints"
+	"github.com/sirawong/point-accumulate-interview/internal/services/audit"
 	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+	"github.com/sirawong/point-accumulate-interview/pkg/storage"
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
 	"github.com/sirawong/point-accumulate-interview/pkg/utils"
 )
 
@@ -23,18 +28,30 @@ const (
 	CSVFile         = ".csv"
 	CSVtContentType = "text/csv"
 	CSVKey          = "csv_files"
+	FormatKey       = "format"
+	ZipContentType  = "application/zip"
+	JSONContentType = "application/json"
+	ZipFileName     = "point-reports.zip"
+	ManifestName    = "manifest.json"
 )
 
 type AccumulatePointHandler struct {
 	AccumulatePointSvc accumulatepoints.AccumulatePointService
+	store              storage.Storage
 	cfg                *config.Config
 }
 
-func NewAccumulatePointHandler(AccumulatePointSvc accumulatepoints.AccumulatePointService, cfg *config.Config) *AccumulatePointHandler {
-	return &AccumulatePointHandler{AccumulatePointSvc: AccumulatePointSvc, cfg: cfg}
+func NewAccumulatePointHandler(AccumulatePointSvc accumulatepoints.AccumulatePointService, store storage.Storage, cfg *config.Config) *AccumulatePointHandler {
+	return &AccumulatePointHandler{AccumulatePointSvc: AccumulatePointSvc, store: store, cfg: cfg}
 }
 
 func (h AccumulatePointHandler) UploadCSV(c *gin.Context) {
+	format, err := uploadFormat(c)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
 	form, err := c.MultipartForm()
 	if err != nil {
 		errors.RespondWithError(c, apperr.ErrInvalidArgument.Wrap(err))
@@ -51,6 +68,9 @@ func (h AccumulatePointHandler) UploadCSV(c *gin.Context) {
 		return files[i].Filename < files[j].Filename
 	})
 
+	// A store's key may only credit purchases made at its own branches.
+	identity, _ := auth.IdentityFrom(c)
+
 	var fileReaders []accumulatepoints.FileInput
 	for _, fileHeader := range files {
 
@@ -65,6 +85,13 @@ func (h AccumulatePointHandler) UploadCSV(c *gin.Context) {
 			return
 		}
 
+		digest, err := audit.HashFile(fileHeader.Filename, file)
+		if err != nil {
+			errors.RespondWithError(c, err)
+			return
+		}
+		addAuditFiles(c, digest)
+
 		purchasedDate, err := parseDateFromFilename(fileHeader.Filename)
 		if err != nil {
 			errors.RespondWithError(c, err)
@@ -72,41 +99,141 @@ func (h AccumulatePointHandler) UploadCSV(c *gin.Context) {
 		}
 
 		fileReaders = append(fileReaders, accumulatepoints.FileInput{
+			Name:          fileHeader.Filename,
 			PurchasedDate: purchasedDate,
 			Reader:        file,
+			Branches:      identity.Branches,
 		})
 	}
 
-	err = h.AccumulatePointSvc.ExecuteMultipleFiles(c.Request.Context(), fileReaders)
+	result, err := h.AccumulatePointSvc.ExecuteMultipleFiles(c.Request.Context(), fileReaders)
 	if err != nil {
 		errors.RespondWithError(c, err)
 		return
 	}
+	for key, value := range audit.ProcessDetails(result) {
+		addAuditDetail(c, key, value)
+	}
 
-	zipWriter := zip.NewWriter(c.Writer)
-	defer zipWriter.Close()
-	if err = h.responseFile(fileReaders, zipWriter); err != nil {
-		c.JSON(500, gin.H{"error": "Failed to create zip"})
+	response := newUploadResponse(result, fileReaders, format)
+
+	if c.NegotiateFormat(ZipContentType, JSONContentType) == JSONContentType {
+		response.Reports = plannedReports(fileReaders, format)
+		c.JSON(http.StatusOK, response)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
-}
+	streamAttachment(c, ZipContentType, ZipFileName, func(w io.Writer) error {
+		zipWriter := zip.NewWriter(w)
 
-func (h AccumulatePointHandler) responseFile(fileReaders []accumulatepoints.FileInput, zipWriter *zip.Writer) error {
-	for _, file := range fileReaders {
-		date := file.PurchasedDate.Format(time.DateOnly)
-		filePath := fmt.Sprintf(h.cfg.FilePath, date)
+		reports, err := h.responseFile(c, fileReaders, format, zipWriter)
+		if err != nil {
+			return err
+		}
 
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			continue
+		response.Reports = reports
+		if err = addManifest(zipWriter, response); err != nil {
+			return err
 		}
 
-		if err := utils.AddFileToZip(zipWriter, filePath, fmt.Sprintf("point-summary_%s.csv", date)); err != nil {
+		if err = zipWriter.Close(); err != nil {
 			return apperr.ErrInternal.Wrap(err)
 		}
+		return nil
+	})
+}
+
+// responseFile packs the summary and the delta for each uploaded date into the
+// zip and returns the entries it wrote. CSV summaries are served as stored;
+// everything else is rendered from the current balances.
+func (h AccumulatePointHandler) responseFile(c *gin.Context, fileReaders []accumulatepoints.FileInput, format report.Format, zipWriter *zip.Writer) ([]uploadReport, error) {
+	ctx := c.Request.Context()
+
+	reports := make([]uploadReport, 0)
+	for _, planned := range plannedReports(fileReaders, format) {
+		date, _ := time.Parse(time.DateOnly, planned.Date)
+
+		switch planned.Type {
+		case reportTypeSummary:
+			added, err := h.addSummary(c, date, format, zipWriter)
+			if err != nil {
+				return nil, err
+			}
+			if !added {
+				continue
+			}
+		case reportTypeDelta:
+			writer, err := zipWriter.Create(planned.Name)
+			if err != nil {
+				return nil, apperr.ErrInternal.Wrap(err)
+			}
+			if err = h.AccumulatePointSvc.WriteDelta(ctx, date, format, writer); err != nil {
+				return nil, err
+			}
+		}
+
+		reports = append(reports, planned)
 	}
-	return nil
+	return reports, nil
+}
+
+// addSummary adds the summary for purchasedDate to the zip. It reports false when
+// a CSV summary was never stored.
+func (h AccumulatePointHandler) addSummary(c *gin.Context, purchasedDate time.Time, format report.Format, zipWriter *zip.Writer) (bool, error) {
+	date := purchasedDate.Format(time.DateOnly)
+	name := reportFileName(reportTypeSummary, date, format)
+
+	if format != report.FormatCSV {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			return false, apperr.ErrInternal.Wrap(err)
+		}
+		return true, h.AccumulatePointSvc.WriteSummary(c.Request.Context(), purchasedDate, format, writer)
+	}
+
+	stored, err := h.store.Open(c.Request.Context(), fmt.Sprintf(tenant.Path(c.Request.Context(), h.cfg.FilePath), date))
+	if stderrors.Is(err, storage.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, apperr.ErrInternal.Wrap(err)
+	}
+	defer stored.Close()
+
+	if err = utils.AddReaderToZip(zipWriter, stored, name); err != nil {
+		return false, apperr.ErrInternal.Wrap(err)
+	}
+	return true, nil
+}
+
+// reportFormat picks the report format from the format query parameter, then the
+// Accept header, and falls back to CSV.
+func reportFormat(c *gin.Context) (report.Format, error) {
+	if c.Query(FormatKey) != "" {
+		return uploadFormat(c)
+	}
+
+	if format, ok := report.FromAccept(c.GetHeader("Accept")); ok {
+		return format, nil
+	}
+
+	return report.FormatCSV, nil
+}
+
+// uploadFormat picks the format of the reports in an upload response from the
+// format query parameter alone, since the Accept header of an upload chooses
+// between the zip and the JSON result.
+func uploadFormat(c *gin.Context) (report.Format, error) {
+	value := c.Query(FormatKey)
+	if value == "" {
+		return report.FormatCSV, nil
+	}
+
+	format, err := report.ParseFormat(value)
+	if err != nil {
+		return "", apperr.ErrInvalidArgument.WithMessage(err.Error())
+	}
+	return format, nil
 }
 
 func validateFileType(fileHeader *multipart.FileHeader) error {
@@ -124,11 +251,5 @@ func validateFileType(fileHeader *multipart.FileHeader) error {
 }
 
 func parseDateFromFilename(filename string) (time.Time, error) {
-	var dateRegex = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
-	dateStr := dateRegex.FindString(filename)
-	if dateStr == "" {
-		errMsg := fmt.Errorf("date in YYYY-MM-DD format not found in filename: %s", filename)
-		return time.Time{}, apperr.ErrInvalidArgument.Wrap(errMsg)
-	}
-	return time.Parse(time.DateOnly, dateStr)
+	return accumulatepoints.PurchasedDateFromFilename(filename)
 }
@@ -1,21 +1,31 @@
 package http
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
 	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
 	"github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints"
 	"github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints/mocks"
 	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+	"github.com/sirawong/point-accumulate-interview/pkg/storage"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
 )
@@ -46,10 +56,12 @@ func (suite *AccumulatePointHandlerTestSuite) SetupTest() {
 	suite.cfg = &config.Config{
 		FilePath: filepath.Join(tempDir, "output_%s.csv"),
 	}
-	suite.handler = NewAccumulatePointHandler(suite.mockService, suite.cfg)
+	suite.handler = NewAccumulatePointHandler(suite.mockService, storage.NewLocal(""), suite.cfg)
 
 	suite.router = gin.New()
 	suite.router.POST("/upload", suite.handler.UploadCSV)
+	suite.router.POST("/recompute", suite.handler.Recompute)
+	suite.router.POST("/recompute/swap", suite.handler.SwapRecompute)
 }
 
 func (suite *AccumulatePointHandlerTestSuite) TearDownTest() {
@@ -85,16 +97,21 @@ func (suite *AccumulatePointHandlerTestSuite) TestUploadCSV_Success() {
 
 	suite.mockService.EXPECT().
 		ExecuteMultipleFiles(gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, files []accumulatepoints.FileInput) error {
+		DoAndReturn(func(ctx context.Context, files []accumulatepoints.FileInput) (*entity.ProcessResult, error) {
 			suite.Len(files, 2)
 
 			expectedDate1 := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
 			expectedDate2 := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
 			suite.Equal(expectedDate1, files[0].PurchasedDate)
 			suite.Equal(expectedDate2, files[1].PurchasedDate)
-			return nil
+			return &entity.ProcessResult{Records: 2, Customers: 1, PointsAwarded: 2}, nil
 		})
 
+	suite.mockService.EXPECT().
+		WriteDelta(gomock.Any(), gomock.Any(), report.FormatCSV, gomock.Any()).
+		Times(2).
+		Return(nil)
+
 	req := httptest.NewRequest("POST", "/upload", &buf)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
@@ -102,7 +119,67 @@ func (suite *AccumulatePointHandlerTestSuite) TestUploadCSV_Success() {
 	suite.router.ServeHTTP(w, req)
 
 	suite.Equal(http.StatusOK, w.Code)
-	suite.Contains(w.Body.String(), "\"status\":\"ok\"")
+	suite.Equal("application/zip", w.Header().Get("Content-Type"))
+	suite.Equal(`attachment; filename="point-reports.zip"`, w.Header().Get("Content-Disposition"))
+
+	zipReader, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	suite.Require().NoError(err)
+
+	names := make([]string, 0, len(zipReader.File))
+	for _, file := range zipReader.File {
+		names = append(names, file.Name)
+	}
+	suite.Equal([]string{"point-delta_2025-01-15.csv", "point-delta_2025-01-16.csv", "manifest.json"}, names)
+
+	manifest, err := zipReader.Open("manifest.json")
+	suite.Require().NoError(err)
+	var result uploadResponse
+	suite.NoError(json.NewDecoder(manifest).Decode(&result))
+	suite.Equal("processed", result.Status)
+	suite.Equal(2, result.Records)
+	suite.Equal(int64(2), result.PointsAwarded)
+	suite.Len(result.Files, 2)
+	suite.Len(result.Reports, 2)
+}
+
+func (suite *AccumulatePointHandlerTestSuite) TestUploadCSV_JSONResult() {
+	csvContent := "customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency\n" +
+		"U000001,123191,CT1001,ELECTRONICS,BR3451,100.50,THB"
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreatePart(map[string][]string{
+		"Content-Disposition": {`form-data; name="csv_files"; filename="purchases_2025-01-15.csv"`},
+		"Content-Type":        {"text/csv"},
+	})
+	suite.NoError(err)
+	_, err = part.Write([]byte(csvContent))
+	suite.NoError(err)
+	suite.NoError(writer.Close())
+
+	suite.mockService.EXPECT().
+		ExecuteMultipleFiles(gomock.Any(), gomock.Any()).
+		Return(&entity.ProcessResult{Records: 1, Customers: 1, PointsAwarded: 1}, nil)
+
+	req := httptest.NewRequest("POST", "/upload?format=xlsx", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Header().Get("Content-Type"), "application/json")
+	suite.Empty(w.Header().Get("Content-Disposition"))
+
+	var result uploadResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &result))
+	suite.Equal(report.FormatXLSX, result.Format)
+	suite.Equal([]uploadFile{{Name: "purchases_2025-01-15.csv", PurchasedDate: "2025-01-15"}}, result.Files)
+	suite.Equal([]uploadReport{
+		{Name: "point-summary_2025-01-15.xlsx", Type: "summary", Date: "2025-01-15", URL: "/api/v1/reports/2025-01-15?format=xlsx"},
+		{Name: "point-delta_2025-01-15.xlsx", Type: "delta", Date: "2025-01-15", URL: "/api/v1/reports/2025-01-15/delta?format=xlsx"},
+	}, result.Reports)
 }
 
 func (suite *AccumulatePointHandlerTestSuite) TestUploadCSV_NoFiles() {
@@ -165,7 +242,7 @@ func (suite *AccumulatePointHandlerTestSuite) TestUploadCSV_ServiceError() {
 	serviceError := apperr.ErrInternal.WithMessage("database connection failed")
 	suite.mockService.EXPECT().
 		ExecuteMultipleFiles(gomock.Any(), gomock.Any()).
-		Return(serviceError)
+		Return(nil, serviceError)
 
 	req := httptest.NewRequest("POST", "/upload", &buf)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
@@ -177,6 +254,238 @@ func (suite *AccumulatePointHandlerTestSuite) TestUploadCSV_ServiceError() {
 	suite.Contains(w.Body.String(), "database connection failed")
 }
 
+// newTestAuthenticator returns an authenticator that knows identities by API
+// key, the key of each being its subject.
+func newTestAuthenticator(t *testing.T, identities ...auth.Identity) *auth.Authenticator {
+	t.Helper()
+
+	keys := make([]map[string]any, 0, len(identities))
+	for _, identity := range identities {
+		sum := sha256.Sum256([]byte(identity.Subject))
+		keys = append(keys, map[string]any{
+			"name":     identity.Subject,
+			"sha256":   hex.EncodeToString(sum[:]),
+			"roles":    identity.Roles,
+			"branches": identity.Branches,
+			"tenant":   identity.Tenant,
+		})
+	}
+	data, err := json.Marshal(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keysFile := filepath.Join(t.TempDir(), "api-keys.json")
+	if err = os.WriteFile(keysFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := auth.New(&config.Config{AuthAPIKeysFile: keysFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return authenticator
+}
+
+func (suite *AccumulatePointHandlerTestSuite) TestUploadCSV_BranchScopedKey() {
+	authenticator := newTestAuthenticator(suite.T(), auth.Identity{Subject: "store-key", Roles: []auth.Role{auth.RoleUploader}, Branches: []string{"BR3451"}})
+
+	router := gin.New()
+	router.Use(authenticator.Middleware())
+	router.POST("/upload", auth.RequireRole(auth.RoleUploader), suite.handler.UploadCSV)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreatePart(map[string][]string{
+		"Content-Disposition": {`form-data; name="csv_files"; filename="purchases_2025-01-15.csv"`},
+		"Content-Type":        {"text/csv"},
+	})
+	suite.NoError(err)
+	_, err = part.Write([]byte("customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency\n" +
+		"U000001,123191,CT1001,ELECTRONICS,BR0002,100.50,THB"))
+	suite.NoError(err)
+	suite.NoError(writer.Close())
+
+	suite.mockService.EXPECT().
+		ExecuteMultipleFiles(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, files []accumulatepoints.FileInput) (*entity.ProcessResult, error) {
+			suite.Require().Len(files, 1)
+			suite.Equal([]string{"BR3451"}, files[0].Branches)
+			return nil, apperr.ErrPermissionDenied.WithMessage("2025-01-15 row 1: branch BR0002 is outside the branches this upload may credit")
+		})
+
+	req := httptest.NewRequest("POST", "/upload", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set(auth.APIKeyHeader, "store-key")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusForbidden, w.Code)
+}
+
+func (suite *AccumulatePointHandlerTestSuite) TestResponseFile_ReadsReportsFromStorage() {
+	store := storage.NewMemory()
+	handler := NewAccumulatePointHandler(suite.mockService, store, &config.Config{FilePath: "reports/output_%s.csv"})
+
+	err := store.Put(context.Background(), "reports/output_2025-01-15.csv", strings.NewReader("customer_id,points\n"), -1)
+	suite.NoError(err)
+
+	suite.mockService.EXPECT().
+		WriteDelta(gomock.Any(), gomock.Any(), report.FormatCSV, gomock.Any()).
+		Times(2).
+		Return(nil)
+
+	files := []accumulatepoints.FileInput{
+		{PurchasedDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{PurchasedDate: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+	}
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/upload", nil)
+
+	reports, err := handler.responseFile(c, files, report.FormatCSV, zipWriter)
+	suite.NoError(err)
+	suite.Len(reports, 3)
+	suite.NoError(zipWriter.Close())
+
+	zipReader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	suite.Require().NoError(err)
+	suite.Require().Len(zipReader.File, 3)
+	suite.Equal("point-summary_2025-01-15.csv", zipReader.File[0].Name)
+	suite.Equal("point-delta_2025-01-15.csv", zipReader.File[1].Name)
+	suite.Equal("point-delta_2025-01-16.csv", zipReader.File[2].Name)
+}
+
+func (suite *AccumulatePointHandlerTestSuite) TestResponseFile_RendersRequestedFormat() {
+	files := []accumulatepoints.FileInput{
+		{PurchasedDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{PurchasedDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	suite.mockService.EXPECT().
+		WriteSummary(gomock.Any(), files[0].PurchasedDate, report.FormatXLSX, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, date time.Time, format report.Format, w io.Writer) error {
+			_, err := w.Write([]byte("xlsx"))
+			return err
+		})
+
+	suite.mockService.EXPECT().
+		WriteDelta(gomock.Any(), files[0].PurchasedDate, report.FormatXLSX, gomock.Any()).
+		Return(nil)
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/upload", nil)
+
+	_, err := suite.handler.responseFile(c, files, report.FormatXLSX, zipWriter)
+	suite.NoError(err)
+	suite.NoError(zipWriter.Close())
+
+	zipReader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	suite.Require().NoError(err)
+	suite.Require().Len(zipReader.File, 2)
+	suite.Equal("point-summary_2025-01-15.xlsx", zipReader.File[0].Name)
+	suite.Equal("point-delta_2025-01-15.xlsx", zipReader.File[1].Name)
+}
+
+func (suite *AccumulatePointHandlerTestSuite) TestUploadCSV_UnsupportedFormat() {
+	req := httptest.NewRequest("POST", "/upload?format=pdf", nil)
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.Contains(w.Body.String(), "unsupported report format")
+}
+
+func (suite *AccumulatePointHandlerTestSuite) TestReportFormat() {
+	cases := []struct {
+		url    string
+		accept string
+		want   report.Format
+	}{
+		{url: "/upload", want: report.FormatCSV},
+		{url: "/upload", accept: "*/*", want: report.FormatCSV},
+		{url: "/upload", accept: "application/vnd.apache.parquet", want: report.FormatParquet},
+		{url: "/upload?format=json", accept: "application/vnd.apache.parquet", want: report.FormatJSON},
+	}
+
+	for _, tc := range cases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", tc.url, nil)
+		c.Request.Header.Set("Accept", tc.accept)
+
+		format, err := reportFormat(c)
+		suite.NoError(err)
+		suite.Equal(tc.want, format, tc.url+" "+tc.accept)
+	}
+}
+
+func (suite *AccumulatePointHandlerTestSuite) TestRecompute_Success() {
+	snapshotAt := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	suite.mockService.EXPECT().
+		Recompute(gomock.Any()).
+		Return(&entity.RecomputeResult{
+			SnapshotAt:      snapshotAt,
+			Customers:       2,
+			Changed:         1,
+			LegacyPurchases: 3,
+			Diffs: []entity.BalanceDiff{
+				{CustomerID: "U000001", CurrentPoints: 20, RecomputedPoints: 13, Delta: -7},
+			},
+		}, nil)
+
+	req := httptest.NewRequest("POST", "/recompute", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"snapshot_at":"2025-01-03T00:00:00Z"`)
+	suite.Contains(w.Body.String(), `"legacy_purchases":3`)
+	suite.Contains(w.Body.String(), `"customer_id":"U000001","current_points":20,"recomputed_points":13,"delta":-7`)
+}
+
+func (suite *AccumulatePointHandlerTestSuite) TestSwapRecompute_Conflict() {
+	snapshotAt := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	suite.mockService.EXPECT().
+		SwapRecompute(gomock.Any(), snapshotAt).
+		Return(nil, apperr.ErrConflict.WithMessage("1 customers changed since the recompute snapshot"))
+
+	req := httptest.NewRequest("POST", "/recompute/swap", strings.NewReader(`{"snapshot_at":"2025-01-03T00:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusConflict, w.Code)
+}
+
+func (suite *AccumulatePointHandlerTestSuite) TestSwapRecompute_ReportsSkippedCustomers() {
+	snapshotAt := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	suite.mockService.EXPECT().
+		SwapRecompute(gomock.Any(), snapshotAt).
+		Return(&entity.SwapResult{Swapped: 2, Skipped: []string{"U000003"}}, nil)
+
+	req := httptest.NewRequest("POST", "/recompute/swap", strings.NewReader(`{"snapshot_at":"2025-01-03T00:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"status":"ok","swapped":2,"skipped":["U000003"]}`, w.Body.String())
+}
+
+func (suite *AccumulatePointHandlerTestSuite) TestSwapRecompute_MissingSnapshot() {
+	req := httptest.NewRequest("POST", "/recompute/swap", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
+
 type UtilityFunctionsTestSuite struct {
 	suite.Suite
 }
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/households"
+)
+
+type HouseholdHandler struct {
+	HouseholdSvc households.HouseholdService
+}
+
+func NewHouseholdHandler(HouseholdSvc households.HouseholdService) *HouseholdHandler {
+	return &HouseholdHandler{HouseholdSvc: HouseholdSvc}
+}
+
+type householdRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type householdMemberRequest struct {
+	CustomerID string `json:"customer_id"`
+}
+
+type householdResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Members   []string  `json:"members"`
+	Points    int64     `json:"points"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (h HouseholdHandler) CreateHousehold(c *gin.Context) {
+	var req householdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.Wrap(err))
+		return
+	}
+
+	result, err := h.HouseholdSvc.CreateHousehold(c.Request.Context(), entity.Household{ID: req.ID, Name: req.Name})
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	addAuditDetail(c, "household_id", result.ID)
+
+	c.JSON(http.StatusCreated, toHouseholdResponse(*result))
+}
+
+func (h HouseholdHandler) GetHousehold(c *gin.Context) {
+	result, err := h.HouseholdSvc.GetHousehold(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toHouseholdResponse(*result))
+}
+
+func (h HouseholdHandler) JoinHousehold(c *gin.Context) {
+	var req householdMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.Wrap(err))
+		return
+	}
+
+	result, err := h.HouseholdSvc.JoinHousehold(c.Request.Context(), c.Param("id"), req.CustomerID)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toHouseholdResponse(*result))
+}
+
+func (h HouseholdHandler) LeaveHousehold(c *gin.Context) {
+	result, err := h.HouseholdSvc.LeaveHousehold(c.Request.Context(), c.Param("id"), c.Param("customer_id"))
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toHouseholdResponse(*result))
+}
+
+func toHouseholdResponse(household entity.Household) householdResponse {
+	members := household.Members
+	if members == nil {
+		members = []string{}
+	}
+
+	return householdResponse{
+		ID:        household.ID,
+		Name:      household.Name,
+		Members:   members,
+		Points:    household.Points,
+		CreatedAt: household.CreatedAt,
+	}
+}
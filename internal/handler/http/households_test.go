@@ -0,0 +1,103 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	householdmocks "github.com/sirawong/point-accumulate-interview/internal/services/households/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type HouseholdHandlerTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockService *householdmocks.MockHouseholdService
+	router      *gin.Engine
+}
+
+func TestHouseholdHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HouseholdHandlerTestSuite))
+}
+
+func (suite *HouseholdHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockService = householdmocks.NewMockHouseholdService(suite.mockCtrl)
+	handler := NewHouseholdHandler(suite.mockService)
+
+	suite.router = gin.New()
+	suite.router.POST("/households", handler.CreateHousehold)
+	suite.router.GET("/households/:id", handler.GetHousehold)
+	suite.router.POST("/households/:id/members", handler.JoinHousehold)
+	suite.router.DELETE("/households/:id/members/:customer_id", handler.LeaveHousehold)
+}
+
+func (suite *HouseholdHandlerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *HouseholdHandlerTestSuite) request(method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *HouseholdHandlerTestSuite) TestCreateHousehold() {
+	createdAt := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	suite.mockService.EXPECT().
+		CreateHousehold(gomock.Any(), entity.Household{ID: "HH0001", Name: "Smith"}).
+		Return(&entity.Household{ID: "HH0001", Name: "Smith", CreatedAt: createdAt}, nil)
+
+	w := suite.request("POST", "/households", `{"id":"HH0001","name":"Smith"}`)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.JSONEq(`{
+		"id": "HH0001",
+		"name": "Smith",
+		"members": [],
+		"points": 0,
+		"created_at": "2025-03-01T09:00:00Z"
+	}`, w.Body.String())
+}
+
+func (suite *HouseholdHandlerTestSuite) TestGetHousehold() {
+	suite.mockService.EXPECT().
+		GetHousehold(gomock.Any(), "HH0001").
+		Return(&entity.Household{ID: "HH0001", Members: []string{"U000001", "U000002"}, Points: 250}, nil)
+
+	w := suite.request("GET", "/households/HH0001", "")
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"members":["U000001","U000002"]`)
+	suite.Contains(w.Body.String(), `"points":250`)
+}
+
+func (suite *HouseholdHandlerTestSuite) TestJoinHousehold_Full() {
+	suite.mockService.EXPECT().
+		JoinHousehold(gomock.Any(), "HH0001", "U000003").
+		Return(nil, apperr.ErrConflict.WithMessage("household is full"))
+
+	w := suite.request("POST", "/households/HH0001/members", `{"customer_id":"U000003"}`)
+
+	suite.Equal(http.StatusConflict, w.Code)
+}
+
+func (suite *HouseholdHandlerTestSuite) TestLeaveHousehold() {
+	suite.mockService.EXPECT().
+		LeaveHousehold(gomock.Any(), "HH0001", "U000001").
+		Return(&entity.Household{ID: "HH0001"}, nil)
+
+	w := suite.request("DELETE", "/households/HH0001/members/U000001", "")
+
+	suite.Equal(http.StatusOK, w.Code)
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/merges"
+)
+
+type MergeHandler struct {
+	MergeSvc merges.MergeService
+}
+
+func NewMergeHandler(MergeSvc merges.MergeService) *MergeHandler {
+	return &MergeHandler{MergeSvc: MergeSvc}
+}
+
+type mergeRequest struct {
+	SourceCustomerID string `json:"source_customer_id"`
+	TargetCustomerID string `json:"target_customer_id"`
+	Reason           string `json:"reason"`
+}
+
+type mergeResponse struct {
+	ID               string    `json:"id"`
+	SourceCustomerID string    `json:"source_customer_id"`
+	TargetCustomerID string    `json:"target_customer_id"`
+	Reason           string    `json:"reason"`
+	RequestedBy      string    `json:"requested_by"`
+	MergedAt         time.Time `json:"merged_at"`
+	Points           int64     `json:"points"`
+	Records          int       `json:"records"`
+	Duplicates       int       `json:"duplicates"`
+	DuplicatePoints  int64     `json:"duplicate_points"`
+}
+
+// MergeCustomers merges the source customer into the target customer.
+func (h MergeHandler) MergeCustomers(c *gin.Context) {
+	var req mergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.Wrap(err))
+		return
+	}
+
+	identity, _ := auth.IdentityFrom(c)
+	result, err := h.MergeSvc.MergeCustomers(c.Request.Context(), entity.Merge{
+		SourceCustomerID: req.SourceCustomerID,
+		TargetCustomerID: req.TargetCustomerID,
+		Reason:           req.Reason,
+		RequestedBy:      identity.Subject,
+	})
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	addAuditDetail(c, "merge_id", result.ID)
+	addAuditDetail(c, "source_customer_id", result.SourceCustomerID)
+	addAuditDetail(c, "target_customer_id", result.TargetCustomerID)
+	addAuditDetail(c, "points", strconv.FormatInt(result.Points, 10))
+
+	c.JSON(http.StatusCreated, toMergeResponse(*result))
+}
+
+func (h MergeHandler) ListMerges(c *gin.Context) {
+	result, err := h.MergeSvc.ListMerges(c.Request.Context(), c.Query("customer_id"))
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	response := make([]mergeResponse, 0, len(result))
+	for _, merge := range result {
+		response = append(response, toMergeResponse(merge))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func toMergeResponse(merge entity.Merge) mergeResponse {
+	return mergeResponse{
+		ID:               merge.ID,
+		SourceCustomerID: merge.SourceCustomerID,
+		TargetCustomerID: merge.TargetCustomerID,
+		Reason:           merge.Reason,
+		RequestedBy:      merge.RequestedBy,
+		MergedAt:         merge.MergedAt,
+		Points:           merge.Points,
+		Records:          merge.Records,
+		Duplicates:       merge.Duplicates,
+		DuplicatePoints:  merge.DuplicatePoints,
+	}
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	mergemocks "github.com/sirawong/point-accumulate-interview/internal/services/merges/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type MergeHandlerTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockService *mergemocks.MockMergeService
+	router      *gin.Engine
+}
+
+func TestMergeHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(MergeHandlerTestSuite))
+}
+
+func (suite *MergeHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockService = mergemocks.NewMockMergeService(suite.mockCtrl)
+	handler := NewMergeHandler(suite.mockService)
+
+	authenticator := newTestAuthenticator(suite.T(),
+		auth.Identity{Subject: "alice", Roles: []auth.Role{auth.RoleCustomerAdmin}},
+	)
+
+	suite.router = gin.New()
+	suite.router.Use(authenticator.Middleware())
+	suite.router.POST("/merges", handler.MergeCustomers)
+	suite.router.GET("/merges", handler.ListMerges)
+}
+
+func (suite *MergeHandlerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *MergeHandlerTestSuite) post(path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auth.APIKeyHeader, "alice")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *MergeHandlerTestSuite) TestMergeCustomers() {
+	merge := entity.Merge{
+		SourceCustomerID: "U000002",
+		TargetCustomerID: "U000001",
+		Reason:           "same person",
+		RequestedBy:      "alice",
+	}
+	suite.mockService.EXPECT().
+		MergeCustomers(gomock.Any(), merge).
+		DoAndReturn(func(_ context.Context, merge entity.Merge) (*entity.Merge, error) {
+			merge.ID = "M1"
+			merge.MergedAt = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+			merge.Points = 25
+			merge.Records = 2
+			merge.Duplicates = 1
+			merge.DuplicatePoints = 10
+			return &merge, nil
+		})
+
+	w := suite.post("/merges", `{"source_customer_id":"U000002","target_customer_id":"U000001","reason":"same person","requested_by":"mallory"}`)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.JSONEq(`{
+		"id": "M1",
+		"source_customer_id": "U000002",
+		"target_customer_id": "U000001",
+		"reason": "same person",
+		"requested_by": "alice",
+		"merged_at": "2025-03-01T09:00:00Z",
+		"points": 25,
+		"records": 2,
+		"duplicates": 1,
+		"duplicate_points": 10
+	}`, w.Body.String())
+}
+
+func (suite *MergeHandlerTestSuite) TestMergeCustomers_Conflict() {
+	suite.mockService.EXPECT().
+		MergeCustomers(gomock.Any(), gomock.Any()).
+		Return(nil, apperr.ErrConflict.WithMessage("customer U000001 changed, try again"))
+
+	w := suite.post("/merges", `{"source_customer_id":"U000002","target_customer_id":"U000001"}`)
+
+	suite.Equal(http.StatusConflict, w.Code)
+}
+
+func (suite *MergeHandlerTestSuite) TestListMerges() {
+	suite.mockService.EXPECT().
+		ListMerges(gomock.Any(), "U000001").
+		Return([]entity.Merge{{ID: "M1", SourceCustomerID: "U000002", TargetCustomerID: "U000001"}}, nil)
+
+	req := httptest.NewRequest("GET", "/merges?customer_id=U000001", nil)
+	req.Header.Set(auth.APIKeyHeader, "alice")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"id":"M1"`)
+}
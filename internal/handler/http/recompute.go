@@ -0,0 +1,92 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
+)
+
+type balanceDiffResponse struct {
+	CustomerID       string `json:"customer_id"`
+	CurrentPoints    int64  `json:"current_points"`
+	RecomputedPoints int64  `json:"recomputed_points"`
+	Delta            int64  `json:"delta"`
+}
+
+type recomputeResponse struct {
+	SnapshotAt      time.Time             `json:"snapshot_at"`
+	Customers       int                   `json:"customers"`
+	Changed         int                   `json:"changed"`
+	LegacyPurchases int                   `json:"legacy_purchases"`
+	Diffs           []balanceDiffResponse `json:"diffs"`
+}
+
+type swapRecomputeRequest struct {
+	SnapshotAt time.Time `json:"snapshot_at" binding:"required"`
+}
+
+type swapRecomputeResponse struct {
+	Status  string   `json:"status"`
+	Swapped int      `json:"swapped"`
+	Skipped []string `json:"skipped"`
+}
+
+func (h AccumulatePointHandler) Recompute(c *gin.Context) {
+	result, err := h.AccumulatePointSvc.Recompute(c.Request.Context())
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	addAuditDetail(c, "customers", strconv.Itoa(result.Customers))
+	addAuditDetail(c, "changed", strconv.Itoa(result.Changed))
+	addAuditDetail(c, "legacy_purchases", strconv.Itoa(result.LegacyPurchases))
+	addAuditDetail(c, "snapshot_at", result.SnapshotAt.Format(time.RFC3339Nano))
+
+	c.JSON(http.StatusOK, toRecomputeResponse(result))
+}
+
+func (h AccumulatePointHandler) SwapRecompute(c *gin.Context) {
+	var req swapRecomputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.Wrap(err))
+		return
+	}
+
+	addAuditDetail(c, "snapshot_at", req.SnapshotAt.Format(time.RFC3339Nano))
+	result, err := h.AccumulatePointSvc.SwapRecompute(c.Request.Context(), req.SnapshotAt)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	addAuditDetail(c, "swapped", strconv.Itoa(result.Swapped))
+	addAuditDetail(c, "skipped", strconv.Itoa(len(result.Skipped)))
+
+	c.JSON(http.StatusOK, swapRecomputeResponse{Status: "ok", Swapped: result.Swapped, Skipped: result.Skipped})
+}
+
+func toRecomputeResponse(result *entity.RecomputeResult) recomputeResponse {
+	diffs := make([]balanceDiffResponse, 0, len(result.Diffs))
+	for _, diff := range result.Diffs {
+		diffs = append(diffs, balanceDiffResponse{
+			CustomerID:       diff.CustomerID,
+			CurrentPoints:    diff.CurrentPoints,
+			RecomputedPoints: diff.RecomputedPoints,
+			Delta:            diff.Delta,
+		})
+	}
+
+	return recomputeResponse{
+		SnapshotAt:      result.SnapshotAt,
+		Customers:       result.Customers,
+		Changed:         result.Changed,
+		LegacyPurchases: result.LegacyPurchases,
+		Diffs:           diffs,
+	}
+}
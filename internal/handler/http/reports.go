@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+)
+
+type ReportHandler struct {
+	AccumulatePointSvc accumulatepoints.AccumulatePointService
+}
+
+func NewReportHandler(AccumulatePointSvc accumulatepoints.AccumulatePointService) *ReportHandler {
+	return &ReportHandler{AccumulatePointSvc: AccumulatePointSvc}
+}
+
+type reportResponse struct {
+	Date string `json:"date"`
+	Key  string `json:"key"`
+	URL  string `json:"url"`
+}
+
+// ListReports lists the summaries stored by earlier uploads. Any other date can
+// still be requested from GetReport.
+func (h ReportHandler) ListReports(c *gin.Context) {
+	result, err := h.AccumulatePointSvc.ListReports(c.Request.Context())
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toReportResponses(result))
+}
+
+// GetReport renders the point summary as of a date from the current balances.
+func (h ReportHandler) GetReport(c *gin.Context) {
+	h.writeDatedReport(c, "point-summary", h.AccumulatePointSvc.WriteSummary)
+}
+
+// GetDelta renders what each customer earned on a date and how their rank moved.
+func (h ReportHandler) GetDelta(c *gin.Context) {
+	h.writeDatedReport(c, "point-delta", h.AccumulatePointSvc.WriteDelta)
+}
+
+func (h ReportHandler) writeDatedReport(c *gin.Context, name string, write func(context.Context, time.Time, report.Format, io.Writer) error) {
+	date, err := time.Parse(time.DateOnly, c.Param("date"))
+	if err != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.WithMessage("date must be in YYYY-MM-DD format"))
+		return
+	}
+
+	format, err := reportFormat(c)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("%s_%s%s", name, date.Format(time.DateOnly), format.Extension())
+	streamAttachment(c, format.ContentType(), fileName, func(w io.Writer) error {
+		return write(c.Request.Context(), date, format, w)
+	})
+}
+
+// GetBreakdown renders points, qualifying transactions and purchased amount per
+// branch, category, rule or source for the purchases between from and to, inclusive.
+func (h ReportHandler) GetBreakdown(c *gin.Context) {
+	breakdown, err := accumulatepoints.ParseBreakdown(c.Query("by"))
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	from, errFrom := time.Parse(time.DateOnly, c.Query("from"))
+	to, errTo := time.Parse(time.DateOnly, c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.WithMessage("from and to must be in YYYY-MM-DD format"))
+		return
+	}
+	if to.Before(from) {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.WithMessage("to must not be before from"))
+		return
+	}
+
+	format, err := reportFormat(c)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("point-breakdown-%s_%s_%s%s", breakdown, from.Format(time.DateOnly), to.Format(time.DateOnly), format.Extension())
+	streamAttachment(c, format.ContentType(), fileName, func(w io.Writer) error {
+		return h.AccumulatePointSvc.WriteBreakdown(c.Request.Context(), breakdown, from, to, format, w)
+	})
+}
+
+func toReportResponses(reports []entity.Report) []reportResponse {
+	result := make([]reportResponse, 0, len(reports))
+	for _, report := range reports {
+		date := report.Date.Format(time.DateOnly)
+		result = append(result, reportResponse{
+			Date: date,
+			Key:  report.Key,
+			URL:  "/api/v1/reports/" + date,
+		})
+	}
+	return result
+}
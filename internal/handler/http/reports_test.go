@@ -0,0 +1,195 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints/mocks"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type ReportHandlerTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockService *mocks.MockAccumulatePointService
+	router      *gin.Engine
+}
+
+func TestReportHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportHandlerTestSuite))
+}
+
+func (suite *ReportHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockService = mocks.NewMockAccumulatePointService(suite.mockCtrl)
+	handler := NewReportHandler(suite.mockService)
+
+	suite.router = gin.New()
+	suite.router.GET("/reports", handler.ListReports)
+	suite.router.GET("/reports/breakdown", handler.GetBreakdown)
+	suite.router.GET("/reports/:date", handler.GetReport)
+	suite.router.GET("/reports/:date/delta", handler.GetDelta)
+}
+
+func (suite *ReportHandlerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *ReportHandlerTestSuite) TestListReports() {
+	suite.mockService.EXPECT().
+		ListReports(gomock.Any()).
+		Return([]entity.Report{
+			{Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), Key: "output_files/point-summary_2025-01-15.csv"},
+		}, nil)
+
+	req := httptest.NewRequest("GET", "/reports", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`[{"date":"2025-01-15","key":"output_files/point-summary_2025-01-15.csv","url":"/api/v1/reports/2025-01-15"}]`, w.Body.String())
+}
+
+func (suite *ReportHandlerTestSuite) TestGetReport_CSV() {
+	suite.mockService.EXPECT().
+		WriteSummary(gomock.Any(), time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), report.FormatCSV, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, date time.Time, format report.Format, w io.Writer) error {
+			_, err := io.WriteString(w, "customer_id,points,last_purchase_date\n")
+			return err
+		})
+
+	req := httptest.NewRequest("GET", "/reports/2025-01-15", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal("text/csv", w.Header().Get("Content-Type"))
+	suite.Equal(`attachment; filename="point-summary_2025-01-15.csv"`, w.Header().Get("Content-Disposition"))
+	suite.Equal("customer_id,points,last_purchase_date\n", w.Body.String())
+}
+
+func (suite *ReportHandlerTestSuite) TestGetReport_AcceptHeader() {
+	suite.mockService.EXPECT().
+		WriteSummary(gomock.Any(), gomock.Any(), report.FormatJSON, gomock.Any()).
+		Return(nil)
+
+	req := httptest.NewRequest("GET", "/reports/2025-01-15", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal("application/json", w.Header().Get("Content-Type"))
+	suite.Contains(w.Header().Get("Content-Disposition"), "point-summary_2025-01-15.json")
+}
+
+func (suite *ReportHandlerTestSuite) TestGetReport_InvalidDate() {
+	req := httptest.NewRequest("GET", "/reports/15-01-2025", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+	suite.Contains(w.Body.String(), "YYYY-MM-DD")
+}
+
+func (suite *ReportHandlerTestSuite) TestGetBreakdown() {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	suite.mockService.EXPECT().
+		WriteBreakdown(gomock.Any(), entity.BreakdownBranch, from, to, report.FormatCSV, gomock.Any()).
+		Return(nil)
+
+	req := httptest.NewRequest("GET", "/reports/breakdown?by=branch&from=2025-01-01&to=2025-01-31", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal(`attachment; filename="point-breakdown-branch_2025-01-01_2025-01-31.csv"`, w.Header().Get("Content-Disposition"))
+}
+
+func (suite *ReportHandlerTestSuite) TestGetBreakdown_InvalidParameters() {
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{query: "by=store&from=2025-01-01&to=2025-01-31", want: "breakdown must be one of"},
+		{query: "by=rule&from=2025-01-01", want: "from and to must be"},
+		{query: "by=rule&from=2025-01-31&to=2025-01-01", want: "to must not be before from"},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/reports/breakdown?"+tc.query, nil)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		suite.Equal(http.StatusBadRequest, w.Code, tc.query)
+		suite.Contains(w.Body.String(), tc.want, tc.query)
+	}
+}
+
+func (suite *ReportHandlerTestSuite) TestGetDelta() {
+	suite.mockService.EXPECT().
+		WriteDelta(gomock.Any(), time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), report.FormatParquet, gomock.Any()).
+		Return(nil)
+
+	req := httptest.NewRequest("GET", "/reports/2025-01-15/delta?format=parquet", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Equal("application/vnd.apache.parquet", w.Header().Get("Content-Type"))
+	suite.Equal(`attachment; filename="point-delta_2025-01-15.parquet"`, w.Header().Get("Content-Disposition"))
+}
+
+func (suite *ReportHandlerTestSuite) TestGetReport_ErrorBeforeOutput() {
+	suite.mockService.EXPECT().
+		WriteSummary(gomock.Any(), gomock.Any(), report.FormatCSV, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, date time.Time, format report.Format, w io.Writer) error {
+			_, _ = io.WriteString(w, "customer_id,points,last_purchase_date\n")
+			return apperr.ErrInternal
+		})
+
+	req := httptest.NewRequest("GET", "/reports/2025-01-15", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusInternalServerError, w.Code)
+	suite.Empty(w.Header().Get("Content-Disposition"))
+	suite.NotContains(w.Body.String(), "customer_id")
+}
+
+func (suite *ReportHandlerTestSuite) TestGetReport_ErrorMidStreamDropsConnection() {
+	suite.mockService.EXPECT().
+		WriteSummary(gomock.Any(), gomock.Any(), report.FormatCSV, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, date time.Time, format report.Format, w io.Writer) error {
+			row := "U000001,10,2025-01-15\n"
+			for written := 0; written <= 2*streamBufferSize; written += len(row) {
+				if _, err := io.WriteString(w, row); err != nil {
+					return err
+				}
+			}
+			return apperr.ErrInternal
+		})
+
+	server := httptest.NewServer(suite.router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/reports/2025-01-15")
+	suite.Require().NoError(err)
+	defer resp.Body.Close()
+
+	suite.Equal(http.StatusOK, resp.StatusCode)
+	_, err = io.ReadAll(resp.Body)
+	suite.ErrorIs(err, io.ErrUnexpectedEOF)
+}
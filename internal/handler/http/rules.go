@@ -0,0 +1,297 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/rules"
+)
+
+type RuleHandler struct {
+	RuleSvc rules.RuleService
+}
+
+func NewRuleHandler(RuleSvc rules.RuleService) *RuleHandler {
+	return &RuleHandler{RuleSvc: RuleSvc}
+}
+
+type ruleConditions struct {
+	MinAmount   decimal.Decimal `json:"min_amount"`
+	BranchID    string          `json:"branch_id"`
+	CategoryIDs []string        `json:"category_ids"`
+
+	BranchIDs    []string `json:"branch_ids,omitempty"`
+	BranchGroups []string `json:"branch_groups,omitempty"`
+
+	ProductIDs         []string `json:"product_ids,omitempty"`
+	ExcludeProductIDs  []string `json:"exclude_product_ids,omitempty"`
+	ExcludeCategoryIDs []string `json:"exclude_category_ids,omitempty"`
+
+	MinCategories      int      `json:"min_categories,omitempty"`
+	RequiredProductIDs []string `json:"required_product_ids,omitempty"`
+
+	VisitCount int `json:"visit_count,omitempty"`
+	StreakDays int `json:"streak_days,omitempty"`
+
+	Tiers         []string   `json:"tiers,omitempty"`
+	SignupFrom    *time.Time `json:"signup_from,omitempty"`
+	SignupTo      *time.Time `json:"signup_to,omitempty"`
+	BirthdayMonth bool       `json:"birthday_month,omitempty"`
+	Segments      []string   `json:"segments,omitempty"`
+	FirstPurchase bool       `json:"first_purchase,omitempty"`
+
+	Expression string `json:"expression,omitempty"`
+}
+
+type ruleReward struct {
+	Value      int64    `json:"value"`
+	RatioUnit  *float64 `json:"ratio_unit,omitempty"`
+	Expression string   `json:"expression,omitempty"`
+}
+
+type ruleRequest struct {
+	Name          string          `json:"name"`
+	RuleType      entity.RuleType `json:"rule_type"`
+	Conditions    ruleConditions  `json:"conditions"`
+	Reward        ruleReward      `json:"reward"`
+	Status        string          `json:"status"`
+	EffectiveFrom *time.Time      `json:"effective_from,omitempty"`
+	EffectiveTo   *time.Time      `json:"effective_to,omitempty"`
+}
+
+type ruleResponse struct {
+	ID            string          `json:"id"`
+	Version       int64           `json:"version"`
+	Name          string          `json:"name"`
+	RuleType      entity.RuleType `json:"rule_type"`
+	Conditions    ruleConditions  `json:"conditions"`
+	Reward        ruleReward      `json:"reward"`
+	Status        string          `json:"status"`
+	EffectiveFrom *time.Time      `json:"effective_from,omitempty"`
+	EffectiveTo   *time.Time      `json:"effective_to,omitempty"`
+	CreatedBy     string          `json:"created_by"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type branchGroupRequest struct {
+	BranchIDs []string `json:"branch_ids"`
+}
+
+type branchGroupResponse struct {
+	Name      string   `json:"name"`
+	BranchIDs []string `json:"branch_ids"`
+}
+
+func (h RuleHandler) ListRules(c *gin.Context) {
+	var asOf *time.Time
+	if value := c.Query("as_of"); value != "" {
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			errors.RespondWithError(c, apperr.ErrInvalidArgument.WithMessage("as_of must be in YYYY-MM-DD format"))
+			return
+		}
+		asOf = &date
+	}
+
+	result, err := h.RuleSvc.GetRules(c.Request.Context(), asOf)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRuleResponses(result))
+}
+
+func (h RuleHandler) ListRuleVersions(c *gin.Context) {
+	result, err := h.RuleSvc.GetRuleVersions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRuleResponses(result))
+}
+
+// CreateRule stores version 1 of a rule, created by the caller.
+func (h RuleHandler) CreateRule(c *gin.Context) {
+	var req ruleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.Wrap(err))
+		return
+	}
+
+	identity, _ := auth.IdentityFrom(c)
+	result, err := h.RuleSvc.CreateRule(c.Request.Context(), req.toDomain(), identity.Subject)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	addRuleAuditDetails(c, *result)
+
+	c.JSON(http.StatusCreated, toRuleResponse(*result))
+}
+
+// UpdateRule stores the next version of a rule, created by the caller.
+func (h RuleHandler) UpdateRule(c *gin.Context) {
+	var req ruleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.Wrap(err))
+		return
+	}
+
+	identity, _ := auth.IdentityFrom(c)
+	result, err := h.RuleSvc.UpdateRule(c.Request.Context(), c.Param("id"), req.toDomain(), identity.Subject)
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	addRuleAuditDetails(c, *result)
+
+	c.JSON(http.StatusOK, toRuleResponse(*result))
+}
+
+// addRuleAuditDetails records which version of which rule a request wrote.
+func addRuleAuditDetails(c *gin.Context, rule entity.Rule) {
+	addAuditDetail(c, "rule_id", rule.ID)
+	addAuditDetail(c, "version", strconv.FormatInt(rule.Version, 10))
+}
+
+func (h RuleHandler) ListBranchGroups(c *gin.Context) {
+	result, err := h.RuleSvc.GetBranchGroups(c.Request.Context())
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	response := make([]branchGroupResponse, 0, len(result))
+	for _, group := range result {
+		response = append(response, toBranchGroupResponse(group))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h RuleHandler) PutBranchGroup(c *gin.Context) {
+	var req branchGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.Wrap(err))
+		return
+	}
+
+	result, err := h.RuleSvc.PutBranchGroup(c.Request.Context(), entity.BranchGroup{Name: c.Param("name"), BranchIDs: req.BranchIDs})
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toBranchGroupResponse(*result))
+}
+
+func toBranchGroupResponse(group entity.BranchGroup) branchGroupResponse {
+	return branchGroupResponse{Name: group.Name, BranchIDs: group.BranchIDs}
+}
+
+func (r ruleRequest) toDomain() entity.Rule {
+	return entity.Rule{
+		Name:     r.Name,
+		RuleType: r.RuleType,
+		Conditions: entity.Conditions{
+			MinAmount:  r.Conditions.MinAmount,
+			BranchID:   r.Conditions.BranchID,
+			CategoryID: r.Conditions.CategoryIDs,
+
+			BranchIDs:    r.Conditions.BranchIDs,
+			BranchGroups: r.Conditions.BranchGroups,
+
+			ProductIDs:         r.Conditions.ProductIDs,
+			ExcludeProductIDs:  r.Conditions.ExcludeProductIDs,
+			ExcludeCategoryIDs: r.Conditions.ExcludeCategoryIDs,
+
+			MinCategories:      r.Conditions.MinCategories,
+			RequiredProductIDs: r.Conditions.RequiredProductIDs,
+
+			VisitCount: r.Conditions.VisitCount,
+			StreakDays: r.Conditions.StreakDays,
+
+			Tiers:         r.Conditions.Tiers,
+			SignupFrom:    r.Conditions.SignupFrom,
+			SignupTo:      r.Conditions.SignupTo,
+			BirthdayMonth: r.Conditions.BirthdayMonth,
+			Segments:      r.Conditions.Segments,
+			FirstPurchase: r.Conditions.FirstPurchase,
+
+			Expression: r.Conditions.Expression,
+		},
+		Reward: entity.Reward{
+			Value:      r.Reward.Value,
+			RatioUnit:  r.Reward.RatioUnit,
+			Expression: r.Reward.Expression,
+		},
+		Status:        r.Status,
+		EffectiveFrom: r.EffectiveFrom,
+		EffectiveTo:   r.EffectiveTo,
+	}
+}
+
+func toRuleResponse(rule entity.Rule) ruleResponse {
+	return ruleResponse{
+		ID:       rule.ID,
+		Version:  rule.Version,
+		Name:     rule.Name,
+		RuleType: rule.RuleType,
+		Conditions: ruleConditions{
+			MinAmount:   rule.Conditions.MinAmount,
+			BranchID:    rule.Conditions.BranchID,
+			CategoryIDs: rule.Conditions.CategoryID,
+
+			BranchIDs:    rule.Conditions.BranchIDs,
+			BranchGroups: rule.Conditions.BranchGroups,
+
+			ProductIDs:         rule.Conditions.ProductIDs,
+			ExcludeProductIDs:  rule.Conditions.ExcludeProductIDs,
+			ExcludeCategoryIDs: rule.Conditions.ExcludeCategoryIDs,
+
+			MinCategories:      rule.Conditions.MinCategories,
+			RequiredProductIDs: rule.Conditions.RequiredProductIDs,
+
+			VisitCount: rule.Conditions.VisitCount,
+			StreakDays: rule.Conditions.StreakDays,
+
+			Tiers:         rule.Conditions.Tiers,
+			SignupFrom:    rule.Conditions.SignupFrom,
+			SignupTo:      rule.Conditions.SignupTo,
+			BirthdayMonth: rule.Conditions.BirthdayMonth,
+			Segments:      rule.Conditions.Segments,
+			FirstPurchase: rule.Conditions.FirstPurchase,
+
+			Expression: rule.Conditions.Expression,
+		},
+		Reward: ruleReward{
+			Value:      rule.Reward.Value,
+			RatioUnit:  rule.Reward.RatioUnit,
+			Expression: rule.Reward.Expression,
+		},
+		Status:        rule.Status,
+		EffectiveFrom: rule.EffectiveFrom,
+		EffectiveTo:   rule.EffectiveTo,
+		CreatedBy:     rule.CreatedBy,
+		CreatedAt:     rule.CreatedAt,
+	}
+}
+
+func toRuleResponses(rules []entity.Rule) []ruleResponse {
+	result := make([]ruleResponse, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, toRuleResponse(rule))
+	}
+	return result
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	"github.com/sirawong/point-accumulate-interview/internal/services/rules/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type RuleHandlerTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockService *mocks.MockRuleService
+	router      *gin.Engine
+}
+
+func TestRuleHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(RuleHandlerTestSuite))
+}
+
+func (suite *RuleHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockService = mocks.NewMockRuleService(suite.mockCtrl)
+	handler := NewRuleHandler(suite.mockService)
+	authenticator := newTestAuthenticator(suite.T(),
+		auth.Identity{Subject: "alice", Roles: []auth.Role{auth.RoleRuleAdmin}},
+		auth.Identity{Subject: "bob", Roles: []auth.Role{auth.RoleRuleAdmin}},
+	)
+
+	suite.router = gin.New()
+	suite.router.Use(authenticator.Middleware())
+	suite.router.GET("/rules", handler.ListRules)
+	suite.router.POST("/rules", handler.CreateRule)
+	suite.router.PUT("/rules/:id", handler.UpdateRule)
+	suite.router.GET("/rules/:id/versions", handler.ListRuleVersions)
+	suite.router.PUT("/branch-groups/:name", handler.PutBranchGroup)
+}
+
+func (suite *RuleHandlerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *RuleHandlerTestSuite) TestListRules_AsOf() {
+	suite.mockService.EXPECT().
+		GetRules(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, asOf *time.Time) ([]entity.Rule, error) {
+			suite.Equal(time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), *asOf)
+			return []entity.Rule{{ID: "rule-1", Version: 2, CreatedBy: "alice"}}, nil
+		})
+
+	req := httptest.NewRequest("GET", "/rules?as_of=2025-01-15", nil)
+	req.Header.Set(auth.APIKeyHeader, "alice")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"id":"rule-1","version":2`)
+	suite.Contains(w.Body.String(), `"created_by":"alice"`)
+}
+
+func (suite *RuleHandlerTestSuite) TestListRules_InvalidAsOf() {
+	req := httptest.NewRequest("GET", "/rules?as_of=15-01-2025", nil)
+	req.Header.Set(auth.APIKeyHeader, "alice")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (suite *RuleHandlerTestSuite) TestCreateRule_Success() {
+	body := `{"name":"FOOD","rule_type":"RATIO","conditions":{"min_amount":"100.00","branch_id":"BR3444","category_ids":["CT1003"]},` +
+		`"reward":{"value":1,"ratio_unit":100},"status":"ACTIVE","created_by":"mallory"}`
+
+	suite.mockService.EXPECT().
+		CreateRule(gomock.Any(), gomock.Any(), "alice").
+		DoAndReturn(func(ctx context.Context, rule entity.Rule, createdBy string) (*entity.Rule, error) {
+			suite.Equal(entity.RatioRule, rule.RuleType)
+			suite.Equal("100", rule.Conditions.MinAmount.String())
+			suite.Equal([]string{"CT1003"}, rule.Conditions.CategoryID)
+			rule.ID = "rule-1"
+			rule.Version = 1
+			return &rule, nil
+		})
+
+	req := httptest.NewRequest("POST", "/rules", strings.NewReader(body))
+	req.Header.Set(auth.APIKeyHeader, "alice")
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Contains(w.Body.String(), `"version":1`)
+}
+
+func (suite *RuleHandlerTestSuite) TestUpdateRule_Conflict() {
+	suite.mockService.EXPECT().
+		UpdateRule(gomock.Any(), "rule-1", gomock.Any(), "bob").
+		Return(nil, apperr.ErrConflict.WithMessage("rule was changed by someone else"))
+
+	req := httptest.NewRequest("PUT", "/rules/rule-1", strings.NewReader(`{"name":"x"}`))
+	req.Header.Set(auth.APIKeyHeader, "bob")
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusConflict, w.Code)
+}
+
+func (suite *RuleHandlerTestSuite) TestListRuleVersions() {
+	suite.mockService.EXPECT().
+		GetRuleVersions(gomock.Any(), "rule-1").
+		Return([]entity.Rule{{ID: "rule-1", Version: 1}, {ID: "rule-1", Version: 2}}, nil)
+
+	req := httptest.NewRequest("GET", "/rules/rule-1/versions", nil)
+	req.Header.Set(auth.APIKeyHeader, "alice")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"version":2`)
+}
+
+func (suite *RuleHandlerTestSuite) TestPutBranchGroup() {
+	group := entity.BranchGroup{Name: "NORTH", BranchIDs: []string{"BR0001", "BR0002"}}
+	suite.mockService.EXPECT().PutBranchGroup(gomock.Any(), group).Return(&group, nil)
+
+	req := httptest.NewRequest("PUT", "/branch-groups/NORTH", strings.NewReader(`{"branch_ids": ["BR0001", "BR0002"]}`))
+	req.Header.Set(auth.APIKeyHeader, "alice")
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"name": "NORTH", "branch_ids": ["BR0001", "BR0002"]}`, w.Body.String())
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
 	"github.com/sirawong/point-accumulate-interview/pkg/config"
 
 	"github.com/gin-gonic/gin"
@@ -13,13 +14,61 @@ type HttpServer struct {
 	*gin.Engine
 }
 
-func NewRouter(apHandler *AccumulatePointHandler) *HttpServer {
+func NewRouter(authenticator *auth.Authenticator, apHandler *AccumulatePointHandler, ruleHandler *RuleHandler, reportHandler *ReportHandler, customerHandler *CustomerHandler, adjustmentHandler *AdjustmentHandler, transferHandler *TransferHandler, householdHandler *HouseholdHandler, mergeHandler *MergeHandler, auditHandler *AuditHandler) *HttpServer {
 	router := gin.New()
 
 	router.Use(gin.Recovery())
 	router.Use(gin.Logger())
+	// The audit log wraps authentication, so it also records refused callers.
+	router.Use(auditHandler.Middleware())
+	router.Use(authenticator.Middleware())
 
-	router.POST("/api/v1/point/accumulate/upload", apHandler.UploadCSV)
+	uploader := auth.RequireRole(auth.RoleUploader)
+	admin := auth.RequireRole(auth.RoleAdmin)
+	ruleAdmin := auth.RequireRole(auth.RoleRuleAdmin)
+	customerAdmin := auth.RequireRole(auth.RoleCustomerAdmin)
+	ruleReader := auth.RequireRole(auth.RoleReader, auth.RoleRuleAdmin)
+	customerReader := auth.RequireRole(auth.RoleReader, auth.RoleCustomerAdmin)
+	reader := auth.RequireRole(auth.RoleReader)
+	auditor := auth.RequireRole(auth.RoleAuditor)
+
+	router.POST("/api/v1/point/accumulate/upload", uploader, apHandler.UploadCSV)
+	router.POST("/api/v1/point/recompute", admin, apHandler.Recompute)
+	router.POST("/api/v1/point/recompute/swap", admin, apHandler.SwapRecompute)
+
+	router.GET("/api/v1/rules", ruleReader, ruleHandler.ListRules)
+	router.POST("/api/v1/rules", ruleAdmin, ruleHandler.CreateRule)
+	router.PUT("/api/v1/rules/:id", ruleAdmin, ruleHandler.UpdateRule)
+	router.GET("/api/v1/rules/:id/versions", ruleReader, ruleHandler.ListRuleVersions)
+	router.GET("/api/v1/branch-groups", ruleReader, ruleHandler.ListBranchGroups)
+	router.PUT("/api/v1/branch-groups/:name", ruleAdmin, ruleHandler.PutBranchGroup)
+
+	router.GET("/api/v1/reports", reader, reportHandler.ListReports)
+	router.GET("/api/v1/reports/breakdown", reader, reportHandler.GetBreakdown)
+	router.GET("/api/v1/reports/:date", reader, reportHandler.GetReport)
+	router.GET("/api/v1/reports/:date/delta", reader, reportHandler.GetDelta)
+
+	router.POST("/api/v1/customers/attributes", customerAdmin, customerHandler.ImportAttributes)
+	router.GET("/api/v1/customers/:id", customerReader, customerHandler.GetCustomer)
+	router.GET("/api/v1/tiers", customerReader, customerHandler.ListTiers)
+
+	router.POST("/api/v1/customers/:id/adjustments", customerAdmin, adjustmentHandler.RequestAdjustment)
+	router.GET("/api/v1/adjustments", customerReader, adjustmentHandler.ListAdjustments)
+	router.POST("/api/v1/adjustments/:id/approve", customerAdmin, adjustmentHandler.ApproveAdjustment)
+	router.POST("/api/v1/adjustments/:id/reject", customerAdmin, adjustmentHandler.RejectAdjustment)
+
+	router.POST("/api/v1/customers/:id/transfers", customerAdmin, transferHandler.TransferPoints)
+	router.POST("/api/v1/households", customerAdmin, householdHandler.CreateHousehold)
+	router.GET("/api/v1/households/:id", customerReader, householdHandler.GetHousehold)
+	router.POST("/api/v1/households/:id/members", customerAdmin, householdHandler.JoinHousehold)
+	router.DELETE("/api/v1/households/:id/members/:customer_id", customerAdmin, householdHandler.LeaveHousehold)
+
+	router.POST("/api/v1/merges", customerAdmin, mergeHandler.MergeCustomers)
+	router.GET("/api/v1/merges", customerReader, mergeHandler.ListMerges)
+
+	router.GET("/api/v1/audit", auditor, auditHandler.ListEntries)
+	router.GET("/api/v1/audit/export", auditor, auditHandler.ExportEntries)
+	router.GET("/api/v1/audit/verify", auditor, auditHandler.VerifyChain)
 
 	return &HttpServer{router}
 }
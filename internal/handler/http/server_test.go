@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	"github.com/sirawong/point-accumulate-interview/internal/services/audit"
+	auditmocks "github.com/sirawong/point-accumulate-interview/internal/services/audit/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type ServerTestSuite struct {
+	suite.Suite
+	mockCtrl  *gomock.Controller
+	mockAudit *auditmocks.MockAuditService
+	server    *HttpServer
+}
+
+func TestServerTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
+
+func (suite *ServerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockAudit = auditmocks.NewMockAuditService(suite.mockCtrl)
+	authenticator := newTestAuthenticator(suite.T(), auth.Identity{Subject: "dashboard", Roles: []auth.Role{auth.RoleReader}})
+
+	// Refused requests never reach the handlers, so they need no services.
+	suite.server = NewRouter(authenticator, &AccumulatePointHandler{}, &RuleHandler{}, &ReportHandler{}, &CustomerHandler{},
+		&AdjustmentHandler{}, &TransferHandler{}, &HouseholdHandler{}, &MergeHandler{}, NewAuditHandler(suite.mockAudit))
+}
+
+func (suite *ServerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *ServerTestSuite) TestRejectedUploadIsAudited() {
+	testCases := map[string]struct {
+		apiKey string
+		actor  string
+		status int
+	}{
+		"no credentials": {actor: unauthenticatedActor, status: http.StatusUnauthorized},
+		"unknown key":    {apiKey: "guess", actor: unauthenticatedActor, status: http.StatusUnauthorized},
+		"wrong role":     {apiKey: "dashboard", actor: "dashboard", status: http.StatusForbidden},
+	}
+
+	for name, tc := range testCases {
+		suite.Run(name, func() {
+			suite.mockAudit.EXPECT().
+				Record(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, entry entity.AuditEntry) (*entity.AuditEntry, error) {
+					suite.Equal(tc.actor, entry.Actor)
+					suite.Equal(audit.SourceAPI, entry.Source)
+					suite.Equal("POST /api/v1/point/accumulate/upload", entry.Action)
+					suite.Equal(tc.status, entry.Status)
+					return &entry, nil
+				})
+
+			req := httptest.NewRequest("POST", "/api/v1/point/accumulate/upload", nil)
+			if tc.apiKey != "" {
+				req.Header.Set(auth.APIKeyHeader, tc.apiKey)
+			}
+			w := httptest.NewRecorder()
+			suite.server.ServeHTTP(w, req)
+
+			suite.Equal(tc.status, w.Code)
+		})
+	}
+}
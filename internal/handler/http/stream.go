@@ -0,0 +1,68 @@
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
+)
+
+// streamBufferSize is how much output is held back before the response is
+// committed. An error inside it is still answered with a proper error response.
+const streamBufferSize = 32 << 10
+
+// streamAttachment sends what write produces as a download named fileName without
+// holding the whole body in memory.
+func streamAttachment(c *gin.Context, contentType, fileName string, write func(io.Writer) error) {
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Status(http.StatusOK)
+
+	buf := bufio.NewWriterSize(c.Writer, streamBufferSize)
+	err := write(buf)
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err == nil {
+		return
+	}
+
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	abortStream(c, err)
+}
+
+// abortStream ends a response whose status line has already been sent. The
+// connection is closed, so the client sees a truncated body rather than a file
+// that looks complete.
+func abortStream(c *gin.Context, err error) {
+	log.Printf("%s %s: response aborted: %v", c.Request.Method, c.Request.URL.Path, err)
+	_ = c.Error(err)
+	c.Set(responseAbortedKey, err)
+	c.Abort()
+
+	// gin's own Hijack panics when the underlying writer cannot be hijacked, so
+	// ask the underlying writer directly. HTTP/2 streams cannot be hijacked.
+	unwrapper, ok := c.Writer.(interface{ Unwrap() http.ResponseWriter })
+	if !ok || c.Request.ProtoMajor != 1 {
+		return
+	}
+
+	hijacker, ok := unwrapper.Unwrap().(http.Hijacker)
+	if !ok {
+		return
+	}
+
+	if conn, _, err := hijacker.Hijack(); err == nil {
+		conn.Close()
+	}
+}
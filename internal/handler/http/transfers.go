@@ -0,0 +1,73 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/transfers"
+)
+
+type TransferHandler struct {
+	TransferSvc transfers.TransferService
+}
+
+func NewTransferHandler(TransferSvc transfers.TransferService) *TransferHandler {
+	return &TransferHandler{TransferSvc: TransferSvc}
+}
+
+type transferRequest struct {
+	ToCustomerID string `json:"to_customer_id"`
+	Points       int64  `json:"points"`
+	Reference    string `json:"reference"`
+}
+
+type transferResponse struct {
+	ID             string    `json:"id"`
+	FromCustomerID string    `json:"from_customer_id"`
+	ToCustomerID   string    `json:"to_customer_id"`
+	Points         int64     `json:"points"`
+	Reference      string    `json:"reference"`
+	RequestedBy    string    `json:"requested_by"`
+	Date           time.Time `json:"date"`
+}
+
+// TransferPoints moves points from the customer in the path to another customer.
+func (h TransferHandler) TransferPoints(c *gin.Context) {
+	var req transferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, apperr.ErrInvalidArgument.Wrap(err))
+		return
+	}
+
+	identity, _ := auth.IdentityFrom(c)
+	result, err := h.TransferSvc.Transfer(c.Request.Context(), entity.Transfer{
+		FromCustomerID: c.Param("id"),
+		ToCustomerID:   req.ToCustomerID,
+		Points:         req.Points,
+		Reference:      req.Reference,
+		RequestedBy:    identity.Subject,
+	})
+	if err != nil {
+		errors.RespondWithError(c, err)
+		return
+	}
+
+	addAuditDetail(c, "to_customer_id", result.ToCustomerID)
+	addAuditDetail(c, "points", strconv.FormatInt(result.Points, 10))
+
+	c.JSON(http.StatusCreated, transferResponse{
+		ID:             result.ID,
+		FromCustomerID: result.FromCustomerID,
+		ToCustomerID:   result.ToCustomerID,
+		Points:         result.Points,
+		Reference:      result.Reference,
+		RequestedBy:    result.RequestedBy,
+		Date:           result.Date,
+	})
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/handler/http/auth"
+	transfermocks "github.com/sirawong/point-accumulate-interview/internal/services/transfers/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type TransferHandlerTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockService *transfermocks.MockTransferService
+	router      *gin.Engine
+}
+
+func TestTransferHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(TransferHandlerTestSuite))
+}
+
+func (suite *TransferHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockService = transfermocks.NewMockTransferService(suite.mockCtrl)
+	handler := NewTransferHandler(suite.mockService)
+
+	authenticator := newTestAuthenticator(suite.T(),
+		auth.Identity{Subject: "alice", Roles: []auth.Role{auth.RoleCustomerAdmin}},
+	)
+
+	suite.router = gin.New()
+	suite.router.Use(authenticator.Middleware())
+	suite.router.POST("/customers/:id/transfers", handler.TransferPoints)
+}
+
+func (suite *TransferHandlerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *TransferHandlerTestSuite) post(path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auth.APIKeyHeader, "alice")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *TransferHandlerTestSuite) TestTransferPoints() {
+	transfer := entity.Transfer{
+		FromCustomerID: "U000001",
+		ToCustomerID:   "U000002",
+		Points:         300,
+		Reference:      "gift",
+		RequestedBy:    "alice",
+	}
+	suite.mockService.EXPECT().
+		Transfer(gomock.Any(), transfer).
+		DoAndReturn(func(_ context.Context, transfer entity.Transfer) (*entity.Transfer, error) {
+			transfer.ID = "T1"
+			transfer.Date = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+			return &transfer, nil
+		})
+
+	w := suite.post("/customers/U000001/transfers", `{"to_customer_id":"U000002","points":300,"reference":"gift","requested_by":"mallory"}`)
+
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.JSONEq(`{
+		"id": "T1",
+		"from_customer_id": "U000001",
+		"to_customer_id": "U000002",
+		"points": 300,
+		"reference": "gift",
+		"requested_by": "alice",
+		"date": "2025-03-01T00:00:00Z"
+	}`, w.Body.String())
+}
+
+func (suite *TransferHandlerTestSuite) TestTransferPoints_Conflict() {
+	suite.mockService.EXPECT().
+		Transfer(gomock.Any(), gomock.Any()).
+		Return(nil, apperr.ErrConflict.WithMessage("the balance or today's transfers changed, try again"))
+
+	w := suite.post("/customers/U000001/transfers", `{"to_customer_id":"U000002","points":300}`)
+
+	suite.Equal(http.StatusConflict, w.Code)
+}
+
+func (suite *TransferHandlerTestSuite) TestTransferPoints_InvalidBody() {
+	w := suite.post("/customers/U000001/transfers", `{"points":"many"}`)
+
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+)
+
+const (
+	reportTypeSummary = "summary"
+	reportTypeDelta   = "delta"
+)
+
+// uploadResponse is the JSON result of an upload, and the manifest.json inside
+// the zip when the reports are downloaded instead.
+type uploadResponse struct {
+	Status        string         `json:"status"`
+	ProcessedAt   time.Time      `json:"processed_at"`
+	Format        report.Format  `json:"format"`
+	Files         []uploadFile   `json:"files"`
+	Records       int            `json:"records"`
+	Customers     int            `json:"customers"`
+	PointsAwarded int64          `json:"points_awarded"`
+	Reports       []uploadReport `json:"reports"`
+}
+
+type uploadFile struct {
+	Name          string `json:"name"`
+	PurchasedDate string `json:"purchased_date"`
+}
+
+type uploadReport struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Date string `json:"date"`
+	URL  string `json:"url"`
+}
+
+func newUploadResponse(result *entity.ProcessResult, files []accumulatepoints.FileInput, format report.Format) uploadResponse {
+	response := uploadResponse{
+		Status:      "processed",
+		ProcessedAt: time.Now().UTC(),
+		Format:      format,
+		Files:       make([]uploadFile, 0, len(files)),
+	}
+
+	for _, file := range files {
+		response.Files = append(response.Files, uploadFile{
+			Name:          file.Name,
+			PurchasedDate: file.PurchasedDate.Format(time.DateOnly),
+		})
+	}
+
+	if result != nil {
+		response.Records = result.Records
+		response.Customers = result.Customers
+		response.PointsAwarded = result.PointsAwarded
+	}
+
+	return response
+}
+
+// plannedReports lists the summary and the delta for each uploaded date, in the
+// order they are packed into the zip.
+func plannedReports(files []accumulatepoints.FileInput, format report.Format) []uploadReport {
+	seen := make(map[string]struct{})
+	reports := make([]uploadReport, 0, 2*len(files))
+	for _, file := range files {
+		date := file.PurchasedDate.Format(time.DateOnly)
+		if _, ok := seen[date]; ok {
+			continue
+		}
+		seen[date] = struct{}{}
+
+		reports = append(reports,
+			uploadReport{
+				Name: reportFileName(reportTypeSummary, date, format),
+				Type: reportTypeSummary,
+				Date: date,
+				URL:  fmt.Sprintf("/api/v1/reports/%s?format=%s", date, format),
+			},
+			uploadReport{
+				Name: reportFileName(reportTypeDelta, date, format),
+				Type: reportTypeDelta,
+				Date: date,
+				URL:  fmt.Sprintf("/api/v1/reports/%s/delta?format=%s", date, format),
+			},
+		)
+	}
+	return reports
+}
+
+func reportFileName(reportType, date string, format report.Format) string {
+	return fmt.Sprintf("point-%s_%s%s", reportType, date, format.Extension())
+}
+
+func addManifest(zipWriter *zip.Writer, response uploadResponse) error {
+	writer, err := zipWriter.Create(ManifestName)
+	if err != nil {
+		return apperr.ErrInternal.Wrap(err)
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(response); err != nil {
+		return apperr.ErrInternal.Wrap(err)
+	}
+	return nil
+}
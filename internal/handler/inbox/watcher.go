@@ -0,0 +1,279 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints"
+	"github.com/sirawong/point-accumulate-interview/internal/services/audit"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
+)
+
+const (
+	CSVFile      = ".csv"
+	ProcessedDir = "processed"
+	FailedDir    = "failed"
+	ResultSuffix = ".result.json"
+
+	StatusProcessed = "processed"
+	StatusFailed    = "failed"
+)
+
+// Result is written next to every file moved out of the inbox.
+type Result struct {
+	File          string    `json:"file"`
+	Status        string    `json:"status"`
+	PurchasedDate string    `json:"purchased_date,omitempty"`
+	ProcessedAt   time.Time `json:"processed_at"`
+	Reports       []string  `json:"reports,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Watcher polls an inbox directory for dated CSV files and feeds them to the
+// accumulate point service one file at a time, recording each in the audit log.
+// Files in the inbox itself credit the default program; each program in TENANTS
+// has a subdirectory of its own, named after it.
+type Watcher struct {
+	AccumulatePointSvc accumulatepoints.AccumulatePointService
+	AuditSvc           audit.AuditService
+	cfg                *config.Config
+	now                func() time.Time
+}
+
+func NewWatcher(AccumulatePointSvc accumulatepoints.AccumulatePointService, AuditSvc audit.AuditService, cfg *config.Config) *Watcher {
+	return &Watcher{AccumulatePointSvc: AccumulatePointSvc, AuditSvc: AuditSvc, cfg: cfg, now: time.Now}
+}
+
+// Run polls until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) error {
+	for _, id := range w.cfg.Tenants {
+		if id == ProcessedDir || id == FailedDir {
+			return fmt.Errorf("tenant %q clashes with the inbox's %s directory", id, id)
+		}
+	}
+
+	for _, id := range w.tenants() {
+		tenantCtx := tenant.WithTenant(ctx, id)
+		for _, dir := range []string{w.inbox(tenantCtx), w.dir(tenantCtx, ProcessedDir), w.dir(tenantCtx, FailedDir)} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create inbox directory %s: %w", dir, err)
+			}
+		}
+	}
+
+	ticker := time.NewTicker(w.cfg.InboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.poll(ctx); err != nil {
+			log.Printf("inbox poll failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll processes the settled files of every program, the default one first.
+func (w *Watcher) poll(ctx context.Context) error {
+	var errs []error
+	for _, id := range w.tenants() {
+		if err := w.pollTenant(tenant.WithTenant(ctx, id)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *Watcher) pollTenant(ctx context.Context) error {
+	files, err := w.settledFiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		if ctx.Err() != nil {
+			return nil
+		}
+		w.process(ctx, name)
+	}
+
+	return nil
+}
+
+// tenants returns the programs with an inbox: the default one and those in TENANTS.
+func (w *Watcher) tenants() []string {
+	return append([]string{tenant.Default}, w.cfg.Tenants...)
+}
+
+// settledFiles lists the CSV files that have not been modified for the settle
+// time, so files still being uploaded are left for a later poll. A directory in
+// the default program's inbox that belongs to no program is reported, and its
+// files are left alone rather than credited to any program.
+func (w *Watcher) settledFiles(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(w.inbox(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := w.now().Add(-w.cfg.InboxSettleTime)
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if tenant.FromContext(ctx) == tenant.Default && !w.knownDir(entry.Name()) {
+				log.Printf("inbox: %s is not a program in TENANTS; its files are not processed", entry.Name())
+			}
+			continue
+		}
+		if !strings.EqualFold(filepath.Ext(entry.Name()), CSVFile) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(cutoff) {
+			continue
+		}
+
+		files = append(files, entry.Name())
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// process feeds name to the service and archives it. Only a file the service
+// rejects as invalid is moved to failed/: any other error, such as a database
+// outage or shutdown, leaves it in the inbox for the next poll.
+func (w *Watcher) process(ctx context.Context, name string) {
+	result := Result{File: name, Status: StatusFailed}
+	err := w.execute(ctx, name, &result)
+	if err != nil {
+		if apperr.GetCode(err) != apperr.ErrInvalidArgument.Code {
+			log.Printf("inbox: %s left for the next poll: %v", name, err)
+			return
+		}
+		result.Error = err.Error()
+		log.Printf("inbox: %s failed: %v", name, err)
+	} else {
+		result.Status = StatusProcessed
+		log.Printf("inbox: %s processed", name)
+	}
+
+	result.ProcessedAt = w.now().UTC()
+	if err := w.archive(ctx, name, result); err != nil {
+		log.Printf("inbox: failed to archive %s: %v", name, err)
+	}
+}
+
+// execute processes name, filling in what result knows about it.
+func (w *Watcher) execute(ctx context.Context, name string, result *Result) error {
+	purchasedDate, err := accumulatepoints.PurchasedDateFromFilename(name)
+	if err != nil {
+		return err
+	}
+	date := purchasedDate.Format(time.DateOnly)
+	result.PurchasedDate = date
+
+	file, err := os.Open(w.dir(ctx, name))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	digest, err := audit.HashFile(name, file)
+	if err != nil {
+		return err
+	}
+
+	processed, err := w.AccumulatePointSvc.ExecuteMultipleFiles(ctx, []accumulatepoints.FileInput{
+		{Name: name, PurchasedDate: purchasedDate, Reader: file},
+	})
+	w.record(ctx, digest, processed, err)
+	if err != nil {
+		return err
+	}
+
+	result.Reports = []string{fmt.Sprintf(tenant.Path(ctx, w.cfg.FilePath), date)}
+	return nil
+}
+
+// record adds the processing of a file to the audit log. A failure to record is
+// logged, since the file has been processed either way.
+func (w *Watcher) record(ctx context.Context, file entity.AuditFile, processed *entity.ProcessResult, err error) {
+	entry := entity.AuditEntry{
+		Actor:  audit.SourceInbox,
+		Source: audit.SourceInbox,
+		Action: audit.ActionUpload,
+		Target: file.Name,
+		Files:  []entity.AuditFile{file},
+	}
+	if err != nil {
+		entry.Details = map[string]string{"error": err.Error()}
+	} else if processed != nil {
+		entry.Details = audit.ProcessDetails(processed)
+	}
+
+	if _, err := w.AuditSvc.Record(ctx, entry); err != nil {
+		log.Printf("inbox: failed to record %s in the audit log: %v", file.Name, err)
+	}
+}
+
+// archive moves name into the processed or failed folder and writes its sidecar.
+// A file dropped again under the same name gets a timestamp to avoid overwriting.
+func (w *Watcher) archive(ctx context.Context, name string, result Result) error {
+	folder := w.dir(ctx, FailedDir)
+	if result.Status == StatusProcessed {
+		folder = w.dir(ctx, ProcessedDir)
+	}
+
+	target := filepath.Join(folder, name)
+	if _, err := os.Stat(target); err == nil {
+		ext := filepath.Ext(name)
+		stamp := result.ProcessedAt.Format("20060102T150405Z")
+		target = filepath.Join(folder, fmt.Sprintf("%s.%s%s", strings.TrimSuffix(name, ext), stamp, ext))
+	}
+
+	if err := os.Rename(w.dir(ctx, name), target); err != nil {
+		return err
+	}
+
+	sidecar, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(target+ResultSuffix, sidecar, 0644)
+}
+
+// inbox returns the directory the program of ctx drops its files in.
+func (w *Watcher) inbox(ctx context.Context) string {
+	return filepath.Join(w.cfg.InboxDir, tenant.FromContext(ctx))
+}
+
+func (w *Watcher) dir(ctx context.Context, name string) string {
+	return filepath.Join(w.inbox(ctx), name)
+}
+
+// knownDir reports whether name is a directory the watcher itself uses in the
+// default program's inbox.
+func (w *Watcher) knownDir(name string) bool {
+	return name == ProcessedDir || name == FailedDir || slices.Contains(w.cfg.Tenants, name)
+}
@@ -0,0 +1,264 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints"
+	"github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints/mocks"
+	"github.com/sirawong/point-accumulate-interview/internal/services/audit"
+	auditmocks "github.com/sirawong/point-accumulate-interview/internal/services/audit/mocks"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+const csvContent = "customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency\n" +
+	"U000001,123191,CT1001,ELECTRONICS,BR3451,100.50,THB"
+
+type WatcherTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockService *mocks.MockAccumulatePointService
+	mockAudit   *auditmocks.MockAuditService
+	cfg         *config.Config
+	watcher     *Watcher
+	now         time.Time
+}
+
+func TestWatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(WatcherTestSuite))
+}
+
+func (suite *WatcherTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockService = mocks.NewMockAccumulatePointService(suite.mockCtrl)
+	suite.mockAudit = auditmocks.NewMockAuditService(suite.mockCtrl)
+
+	tempDir := suite.T().TempDir()
+	suite.cfg = &config.Config{
+		FilePath:        filepath.Join(tempDir, "reports", "point-summary_%s.csv"),
+		InboxDir:        filepath.Join(tempDir, "inbox"),
+		InboxSettleTime: time.Minute,
+	}
+	for _, dir := range []string{suite.cfg.InboxDir, filepath.Join(suite.cfg.InboxDir, ProcessedDir), filepath.Join(suite.cfg.InboxDir, FailedDir)} {
+		suite.NoError(os.MkdirAll(dir, 0755))
+	}
+
+	suite.now = time.Date(2025, 1, 3, 8, 0, 0, 0, time.UTC)
+	suite.watcher = NewWatcher(suite.mockService, suite.mockAudit, suite.cfg)
+	suite.watcher.now = func() time.Time { return suite.now }
+}
+
+func (suite *WatcherTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *WatcherTestSuite) drop(name string, modTime time.Time) {
+	path := filepath.Join(suite.cfg.InboxDir, name)
+	suite.NoError(os.WriteFile(path, []byte(csvContent), 0644))
+	suite.NoError(os.Chtimes(path, modTime, modTime))
+}
+
+func (suite *WatcherTestSuite) readResult(path string) Result {
+	content, err := os.ReadFile(path + ResultSuffix)
+	suite.NoError(err)
+
+	var result Result
+	suite.NoError(json.Unmarshal(content, &result))
+	return result
+}
+
+func (suite *WatcherTestSuite) TestPoll_ProcessesSettledFiles() {
+	settled := suite.now.Add(-2 * time.Minute)
+	suite.drop("2025-01-02.csv", settled)
+	suite.drop("2025-01-01.csv", settled)
+
+	var processed []time.Time
+	suite.mockService.EXPECT().
+		ExecuteMultipleFiles(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(ctx context.Context, files []accumulatepoints.FileInput) (*entity.ProcessResult, error) {
+			suite.Len(files, 1)
+			content, err := io.ReadAll(files[0].Reader)
+			suite.NoError(err)
+			suite.Equal(csvContent, string(content))
+			processed = append(processed, files[0].PurchasedDate)
+			return &entity.ProcessResult{Records: 1, Customers: 1, PointsAwarded: 5}, nil
+		})
+
+	var recorded []entity.AuditEntry
+	suite.mockAudit.EXPECT().
+		Record(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(ctx context.Context, entry entity.AuditEntry) (*entity.AuditEntry, error) {
+			recorded = append(recorded, entry)
+			return &entry, nil
+		})
+
+	suite.NoError(suite.watcher.poll(context.Background()))
+
+	suite.Equal([]time.Time{
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+	}, processed)
+
+	target := filepath.Join(suite.cfg.InboxDir, ProcessedDir, "2025-01-02.csv")
+	suite.FileExists(target)
+	suite.NoFileExists(filepath.Join(suite.cfg.InboxDir, "2025-01-02.csv"))
+
+	result := suite.readResult(target)
+	suite.Equal(StatusProcessed, result.Status)
+	suite.Equal("2025-01-02", result.PurchasedDate)
+	suite.Equal([]string{filepath.Join(filepath.Dir(suite.cfg.FilePath), "point-summary_2025-01-02.csv")}, result.Reports)
+
+	suite.Require().Len(recorded, 2)
+	suite.Equal(entity.AuditEntry{
+		Actor:  audit.SourceInbox,
+		Source: audit.SourceInbox,
+		Action: audit.ActionUpload,
+		Target: "2025-01-01.csv",
+		Files: []entity.AuditFile{{
+			Name:   "2025-01-01.csv",
+			SHA256: "b471e9acb000f6235ffeb3c5073fa6bc6cf24d30de245131ef23f416781bc14f",
+			Size:   int64(len(csvContent)),
+		}},
+		Details: map[string]string{"records": "1", "customers": "1", "points_awarded": "5"},
+	}, recorded[0])
+}
+
+func (suite *WatcherTestSuite) TestPoll_SkipsFilesStillBeingWritten() {
+	suite.drop("2025-01-02.csv", suite.now.Add(-10*time.Second))
+
+	suite.NoError(suite.watcher.poll(context.Background()))
+
+	suite.FileExists(filepath.Join(suite.cfg.InboxDir, "2025-01-02.csv"))
+}
+
+func (suite *WatcherTestSuite) TestPoll_ServiceErrorMovesToFailed() {
+	suite.drop("2025-01-02.csv", suite.now.Add(-2*time.Minute))
+
+	suite.mockService.EXPECT().
+		ExecuteMultipleFiles(gomock.Any(), gomock.Any()).
+		Return(nil, apperr.ErrInvalidArgument.WithMessage("CSV header mismatch"))
+
+	suite.mockAudit.EXPECT().
+		Record(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, entry entity.AuditEntry) (*entity.AuditEntry, error) {
+			suite.Contains(entry.Details["error"], "CSV header mismatch")
+			return &entry, nil
+		})
+
+	suite.NoError(suite.watcher.poll(context.Background()))
+
+	target := filepath.Join(suite.cfg.InboxDir, FailedDir, "2025-01-02.csv")
+	suite.FileExists(target)
+
+	result := suite.readResult(target)
+	suite.Equal(StatusFailed, result.Status)
+	suite.Contains(result.Error, "CSV header mismatch")
+	suite.Empty(result.Reports)
+}
+
+func (suite *WatcherTestSuite) TestPoll_TransientErrorLeavesFileInInbox() {
+	for _, err := range []error{
+		apperr.ErrInternal.Wrap(errors.New("server selection timeout")),
+		context.Canceled,
+	} {
+		suite.drop("2025-01-02.csv", suite.now.Add(-2*time.Minute))
+
+		suite.mockService.EXPECT().
+			ExecuteMultipleFiles(gomock.Any(), gomock.Any()).
+			Return(nil, err)
+
+		suite.mockAudit.EXPECT().
+			Record(gomock.Any(), gomock.Any()).
+			Return(&entity.AuditEntry{}, nil)
+
+		suite.NoError(suite.watcher.poll(context.Background()))
+
+		suite.FileExists(filepath.Join(suite.cfg.InboxDir, "2025-01-02.csv"))
+		suite.NoFileExists(filepath.Join(suite.cfg.InboxDir, FailedDir, "2025-01-02.csv"))
+		suite.NoFileExists(filepath.Join(suite.cfg.InboxDir, ProcessedDir, "2025-01-02.csv"))
+	}
+}
+
+func (suite *WatcherTestSuite) TestPoll_TenantSubdirectoryCreditsTenant() {
+	suite.cfg.Tenants = []string{"brand_b"}
+	suite.NoError(os.MkdirAll(filepath.Join(suite.cfg.InboxDir, "brand_b", ProcessedDir), 0755))
+	suite.drop(filepath.Join("brand_b", "2025-01-02.csv"), suite.now.Add(-2*time.Minute))
+
+	suite.mockService.EXPECT().
+		ExecuteMultipleFiles(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, files []accumulatepoints.FileInput) (*entity.ProcessResult, error) {
+			suite.Equal("brand_b", tenant.FromContext(ctx))
+			return &entity.ProcessResult{}, nil
+		})
+
+	suite.mockAudit.EXPECT().
+		Record(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, entry entity.AuditEntry) (*entity.AuditEntry, error) {
+			suite.Equal("brand_b", tenant.FromContext(ctx))
+			return &entry, nil
+		})
+
+	suite.NoError(suite.watcher.poll(context.Background()))
+
+	result := suite.readResult(filepath.Join(suite.cfg.InboxDir, "brand_b", ProcessedDir, "2025-01-02.csv"))
+	suite.Equal([]string{filepath.Join(filepath.Dir(suite.cfg.FilePath), "brand_b", "point-summary_2025-01-02.csv")}, result.Reports)
+	suite.NoFileExists(filepath.Join(suite.cfg.InboxDir, ProcessedDir, "2025-01-02.csv"))
+}
+
+func (suite *WatcherTestSuite) TestPoll_UnknownTenantIsLeftAlone() {
+	suite.NoError(os.MkdirAll(filepath.Join(suite.cfg.InboxDir, "brand_x"), 0755))
+	suite.drop(filepath.Join("brand_x", "2025-01-02.csv"), suite.now.Add(-2*time.Minute))
+
+	suite.NoError(suite.watcher.poll(context.Background()))
+
+	suite.FileExists(filepath.Join(suite.cfg.InboxDir, "brand_x", "2025-01-02.csv"))
+}
+
+func (suite *WatcherTestSuite) TestRun_TenantClashingWithArchive() {
+	suite.cfg.Tenants = []string{FailedDir}
+
+	suite.Error(suite.watcher.Run(context.Background()))
+}
+
+func (suite *WatcherTestSuite) TestPoll_UndatedFileMovesToFailed() {
+	suite.drop("latest.csv", suite.now.Add(-2*time.Minute))
+
+	suite.NoError(suite.watcher.poll(context.Background()))
+
+	result := suite.readResult(filepath.Join(suite.cfg.InboxDir, FailedDir, "latest.csv"))
+	suite.Equal(StatusFailed, result.Status)
+	suite.Contains(result.Error, "date in YYYY-MM-DD format not found")
+}
+
+func (suite *WatcherTestSuite) TestPoll_RedroppedFileKeepsEarlierCopy() {
+	suite.NoError(os.WriteFile(filepath.Join(suite.cfg.InboxDir, ProcessedDir, "2025-01-02.csv"), []byte("old"), 0644))
+	suite.drop("2025-01-02.csv", suite.now.Add(-2*time.Minute))
+
+	suite.mockService.EXPECT().
+		ExecuteMultipleFiles(gomock.Any(), gomock.Any()).
+		Return(&entity.ProcessResult{}, nil)
+
+	suite.mockAudit.EXPECT().
+		Record(gomock.Any(), gomock.Any()).
+		Return(&entity.AuditEntry{}, nil)
+
+	suite.NoError(suite.watcher.poll(context.Background()))
+
+	suite.FileExists(filepath.Join(suite.cfg.InboxDir, ProcessedDir, "2025-01-02.20250103T080000Z.csv"))
+	content, err := os.ReadFile(filepath.Join(suite.cfg.InboxDir, ProcessedDir, "2025-01-02.csv"))
+	suite.NoError(err)
+	suite.Equal("old", string(content))
+}
@@ -0,0 +1,50 @@
+package mongodb
+
+import (
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Adjustment struct {
+	ID          primitive.ObjectID      `bson:"_id,omitempty"`
+	CustomerID  string                  `bson:"customer_id"`
+	Points      int64                   `bson:"points"`
+	ReasonCode  entity.AdjustmentReason `bson:"reason_code"`
+	Reference   string                  `bson:"reference"`
+	Status      entity.AdjustmentStatus `bson:"status"`
+	RequestedBy string                  `bson:"requested_by"`
+	RequestedAt time.Time               `bson:"requested_at"`
+	DecidedBy   string                  `bson:"decided_by,omitempty"`
+	DecidedAt   *time.Time              `bson:"decided_at,omitempty"`
+}
+
+func (a Adjustment) ToDomain() *entity.Adjustment {
+	return &entity.Adjustment{
+		ID:          a.ID.Hex(),
+		CustomerID:  a.CustomerID,
+		Points:      a.Points,
+		ReasonCode:  a.ReasonCode,
+		Reference:   a.Reference,
+		Status:      a.Status,
+		RequestedBy: a.RequestedBy,
+		RequestedAt: a.RequestedAt,
+		DecidedBy:   a.DecidedBy,
+		DecidedAt:   a.DecidedAt,
+	}
+}
+
+func fromAdjustment(adjustment entity.Adjustment) Adjustment {
+	return Adjustment{
+		CustomerID:  adjustment.CustomerID,
+		Points:      adjustment.Points,
+		ReasonCode:  adjustment.ReasonCode,
+		Reference:   adjustment.Reference,
+		Status:      adjustment.Status,
+		RequestedBy: adjustment.RequestedBy,
+		RequestedAt: adjustment.RequestedAt,
+		DecidedBy:   adjustment.DecidedBy,
+		DecidedAt:   adjustment.DecidedAt,
+	}
+}
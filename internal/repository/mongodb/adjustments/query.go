@@ -0,0 +1,35 @@
+package mongodb
+
+import (
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func filterAdjustments(filter entity.AdjustmentFilter) bson.M {
+	result := bson.M{}
+	if filter.CustomerID != "" {
+		result["customer_id"] = filter.CustomerID
+	}
+	if filter.Status != "" {
+		result["status"] = filter.Status
+	}
+	return result
+}
+
+// updateStatus sets the decision, or clears it when decidedAt is nil.
+func updateStatus(status entity.AdjustmentStatus, decidedBy string, decidedAt *time.Time) bson.M {
+	if decidedAt == nil {
+		return bson.M{
+			"$set":   bson.M{"status": status},
+			"$unset": bson.M{"decided_by": "", "decided_at": ""},
+		}
+	}
+
+	return bson.M{"$set": bson.M{
+		"status":     status,
+		"decided_by": decidedBy,
+		"decided_at": decidedAt,
+	}}
+}
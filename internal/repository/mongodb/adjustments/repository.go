@@ -0,0 +1,99 @@
+package mongodb
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	"github.com/sirawong/point-accumulate-interview/internal/errors"
+	database "github.com/sirawong/point-accumulate-interview/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const AdjustmentCollection = "adjustments"
+
+type adjustmentRepository struct {
+	collection database.Collection
+}
+
+func NewAdjustmentRepository(db *mongo.Database) repository.AdjustmentRepository {
+	return &adjustmentRepository{
+		collection: database.NewCollection(db, AdjustmentCollection),
+	}
+}
+
+func (r adjustmentRepository) CreateAdjustment(ctx context.Context, adjustment entity.Adjustment) (*entity.Adjustment, error) {
+	document := fromAdjustment(adjustment)
+	document.ID = primitive.NewObjectID()
+
+	if _, err := r.collection.For(ctx).InsertOne(ctx, document); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return document.ToDomain(), nil
+}
+
+func (r adjustmentRepository) GetAdjustment(ctx context.Context, id string) (*entity.Adjustment, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.ErrInvalidArgument.Wrap(err)
+	}
+
+	var adjustment Adjustment
+	err = r.collection.For(ctx).FindOne(ctx, bson.M{"_id": objectID}).Decode(&adjustment)
+	if stderrors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errors.ErrNotFound.WithMessage("adjustment not found")
+	}
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return adjustment.ToDomain(), nil
+}
+
+func (r adjustmentRepository) ListAdjustments(ctx context.Context, filter entity.AdjustmentFilter) ([]entity.Adjustment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "requested_at", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.collection.For(ctx).Find(ctx, filterAdjustments(filter), opts)
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	var adjustments []Adjustment
+	if err = cursor.All(ctx, &adjustments); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	result := make([]entity.Adjustment, 0, len(adjustments))
+	for _, adjustment := range adjustments {
+		result = append(result, *adjustment.ToDomain())
+	}
+
+	return result, nil
+}
+
+func (r adjustmentRepository) UpdateAdjustmentStatus(ctx context.Context, id string, from, to entity.AdjustmentStatus, decidedBy string, decidedAt *time.Time) (*entity.Adjustment, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.ErrInvalidArgument.Wrap(err)
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var adjustment Adjustment
+	err = r.collection.For(ctx).FindOneAndUpdate(ctx, bson.M{"_id": objectID, "status": from}, updateStatus(to, decidedBy, decidedAt), opts).Decode(&adjustment)
+	if stderrors.Is(err, mongo.ErrNoDocuments) {
+		if _, err = r.GetAdjustment(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, errors.ErrConflict.WithMessage("adjustment is no longer " + string(from))
+	}
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return adjustment.ToDomain(), nil
+}
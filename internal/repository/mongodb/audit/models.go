@@ -0,0 +1,74 @@
+package mongodb
+
+import (
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+)
+
+// AuditEntry is keyed by its sequence, so the _id index keeps two entries from
+// taking the same place in the chain.
+type AuditEntry struct {
+	Sequence   int64             `bson:"_id"`
+	OccurredAt time.Time         `bson:"occurred_at"`
+	Actor      string            `bson:"actor"`
+	Source     string            `bson:"source"`
+	ClientIP   string            `bson:"client_ip,omitempty"`
+	Action     string            `bson:"action"`
+	Target     string            `bson:"target,omitempty"`
+	Status     int               `bson:"status,omitempty"`
+	Files      []AuditFile       `bson:"files,omitempty"`
+	Details    map[string]string `bson:"details,omitempty"`
+	PrevHash   string            `bson:"prev_hash"`
+	Hash       string            `bson:"hash"`
+}
+
+type AuditFile struct {
+	Name   string `bson:"name"`
+	SHA256 string `bson:"sha256"`
+	Size   int64  `bson:"size"`
+}
+
+func (a AuditEntry) ToDomain() entity.AuditEntry {
+	var files []entity.AuditFile
+	for _, file := range a.Files {
+		files = append(files, entity.AuditFile{Name: file.Name, SHA256: file.SHA256, Size: file.Size})
+	}
+
+	return entity.AuditEntry{
+		Sequence:   a.Sequence,
+		OccurredAt: a.OccurredAt.UTC(),
+		Actor:      a.Actor,
+		Source:     a.Source,
+		ClientIP:   a.ClientIP,
+		Action:     a.Action,
+		Target:     a.Target,
+		Status:     a.Status,
+		Files:      files,
+		Details:    a.Details,
+		PrevHash:   a.PrevHash,
+		Hash:       a.Hash,
+	}
+}
+
+func fromAuditEntry(entry entity.AuditEntry) AuditEntry {
+	var files []AuditFile
+	for _, file := range entry.Files {
+		files = append(files, AuditFile{Name: file.Name, SHA256: file.SHA256, Size: file.Size})
+	}
+
+	return AuditEntry{
+		Sequence:   entry.Sequence,
+		OccurredAt: entry.OccurredAt,
+		Actor:      entry.Actor,
+		Source:     entry.Source,
+		ClientIP:   entry.ClientIP,
+		Action:     entry.Action,
+		Target:     entry.Target,
+		Status:     entry.Status,
+		Files:      files,
+		Details:    entry.Details,
+		PrevHash:   entry.PrevHash,
+		Hash:       entry.Hash,
+	}
+}
@@ -0,0 +1,32 @@
+package mongodb
+
+import (
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func filterAuditEntries(filter entity.AuditFilter) bson.M {
+	result := bson.M{}
+	if filter.Actor != "" {
+		result["actor"] = filter.Actor
+	}
+	if filter.Action != "" {
+		result["action"] = filter.Action
+	}
+	if filter.AfterSequence > 0 {
+		result["_id"] = bson.M{"$gt": filter.AfterSequence}
+	}
+
+	occurredAt := bson.M{}
+	if !filter.From.IsZero() {
+		occurredAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		occurredAt["$lt"] = filter.To
+	}
+	if len(occurredAt) > 0 {
+		result["occurred_at"] = occurredAt
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+package mongodb
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	"github.com/sirawong/point-accumulate-interview/internal/errors"
+	database "github.com/sirawong/point-accumulate-interview/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const AuditCollection = "audit_log"
+
+type auditRepository struct {
+	collection database.Collection
+}
+
+func NewAuditRepository(db *mongo.Database) repository.AuditRepository {
+	return &auditRepository{
+		collection: database.NewCollection(db, AuditCollection),
+	}
+}
+
+func (r auditRepository) LastAuditEntry(ctx context.Context) (*entity.AuditEntry, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	var entry AuditEntry
+	err := r.collection.For(ctx).FindOne(ctx, bson.M{}, opts).Decode(&entry)
+	if stderrors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	result := entry.ToDomain()
+	return &result, nil
+}
+
+func (r auditRepository) CreateAuditEntry(ctx context.Context, entry entity.AuditEntry) error {
+	if _, err := r.collection.For(ctx).InsertOne(ctx, fromAuditEntry(entry)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.ErrConflict.WithMessage(fmt.Sprintf("audit entry %d already exists", entry.Sequence))
+		}
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	return nil
+}
+
+func (r auditRepository) StreamAuditEntries(ctx context.Context, filter entity.AuditFilter, fn func(entity.AuditEntry) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+
+	cursor, err := r.collection.For(ctx).Find(ctx, filterAuditEntries(filter), opts)
+	if err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var entry AuditEntry
+		if err = cursor.Decode(&entry); err != nil {
+			return errors.ErrInternal.Wrap(err)
+		}
+
+		if err = fn(entry.ToDomain()); err != nil {
+			return err
+		}
+	}
+
+	if err = cursor.Err(); err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	return nil
+}
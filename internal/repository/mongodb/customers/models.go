@@ -20,13 +20,59 @@ type Customer struct {
 	UpdatedAt        time.Time          `bson:"updated_at"`
 	Records          []Record           `bson:"records"`
 	PointsByDate     map[string]int64   `bson:"points_by_date"`
+	Attributes       Attributes         `bson:"attributes,omitempty"`
+	HouseholdID      string             `bson:"household_id,omitempty"`
+	MergedInto       string             `bson:"merged_into,omitempty"`
+	Aliases          []string           `bson:"aliases,omitempty"`
+}
+
+// ShadowSnapshot records when the customers in the shadow collection were read
+// from the live ones.
+type ShadowSnapshot struct {
+	ID         string    `bson:"_id"`
+	SnapshotAt time.Time `bson:"snapshot_at"`
+}
+
+type Attributes struct {
+	SignupDate *time.Time `bson:"signup_date,omitempty"`
+	BirthMonth int        `bson:"birth_month,omitempty"`
+	Segment    string     `bson:"segment,omitempty"`
+}
+
+func (a Attributes) ToDomain() entity.CustomerAttributes {
+	return entity.CustomerAttributes{
+		SignupDate: a.SignupDate,
+		BirthMonth: a.BirthMonth,
+		Segment:    a.Segment,
+	}
+}
+
+func fromAttributes(attributes entity.CustomerAttributes) Attributes {
+	return Attributes{
+		SignupDate: attributes.SignupDate,
+		BirthMonth: attributes.BirthMonth,
+		Segment:    attributes.Segment,
+	}
 }
 
 type Record struct {
 	ProductID    string               `bson:"product_id"`
+	CategoryID   string               `bson:"category_id"`
 	BranchID     string               `bson:"branch_id"`
+	ReceiptID    string               `bson:"receipt_id,omitempty"`
 	Amount       primitive.Decimal128 `bson:"amount"`
 	PurchaseDate time.Time            `bson:"purchase_date"`
+	RuleID       string               `bson:"rule_id,omitempty"`
+	RuleVersion  int64                `bson:"rule_version,omitempty"`
+	Tier         string               `bson:"tier,omitempty"`
+	Points       int64                `bson:"points"`
+
+	Type                 entity.TransactionType  `bson:"type,omitempty"`
+	OriginalPurchaseDate *time.Time              `bson:"original_purchase_date,omitempty"`
+	AdjustmentID         string                  `bson:"adjustment_id,omitempty"`
+	ReasonCode           entity.AdjustmentReason `bson:"reason_code,omitempty"`
+	TransferID           string                  `bson:"transfer_id,omitempty"`
+	CounterpartyID       string                  `bson:"counterparty_id,omitempty"`
 }
 
 func (u Customer) ToDomain() (*entity.Customer, error) {
@@ -40,9 +86,22 @@ func (u Customer) ToDomain() (*entity.Customer, error) {
 
 		records = append(records, entity.Record{
 			ProductID:    record.ProductID,
+			CategoryID:   record.CategoryID,
 			BranchID:     record.BranchID,
+			ReceiptID:    record.ReceiptID,
 			Amount:       value,
 			PurchaseDate: record.PurchaseDate,
+			RuleID:       record.RuleID,
+			RuleVersion:  record.RuleVersion,
+			Tier:         record.Tier,
+			Points:       record.Points,
+
+			Type:                 record.Type,
+			OriginalPurchaseDate: record.OriginalPurchaseDate,
+			AdjustmentID:         record.AdjustmentID,
+			ReasonCode:           record.ReasonCode,
+			TransferID:           record.TransferID,
+			CounterpartyID:       record.CounterpartyID,
 		})
 	}
 
@@ -54,6 +113,10 @@ func (u Customer) ToDomain() (*entity.Customer, error) {
 		UpdatedAt:        u.UpdatedAt,
 		Records:          records,
 		PointsByDate:     u.PointsByDate,
+		Attributes:       u.Attributes.ToDomain(),
+		HouseholdID:      u.HouseholdID,
+		MergedInto:       u.MergedInto,
+		Aliases:          u.Aliases,
 	}, nil
 }
 
@@ -83,10 +146,88 @@ func fromRecords(records []entity.Record) ([]Record, error) {
 
 		result = append(result, Record{
 			ProductID:    record.ProductID,
+			CategoryID:   record.CategoryID,
 			BranchID:     record.BranchID,
+			ReceiptID:    record.ReceiptID,
 			Amount:       value,
 			PurchaseDate: record.PurchaseDate,
+			RuleID:       record.RuleID,
+			RuleVersion:  record.RuleVersion,
+			Tier:         record.Tier,
+			Points:       record.Points,
+
+			Type:                 record.Type,
+			OriginalPurchaseDate: record.OriginalPurchaseDate,
+			AdjustmentID:         record.AdjustmentID,
+			ReasonCode:           record.ReasonCode,
+			TransferID:           record.TransferID,
+			CounterpartyID:       record.CounterpartyID,
 		})
 	}
 	return result, nil
 }
+
+func fromCustomer(customer entity.Customer) (Customer, error) {
+	records, err := fromRecords(customer.Records)
+	if err != nil {
+		return Customer{}, err
+	}
+
+	return Customer{
+		CustomerID:       customer.CustomerID,
+		Points:           customer.Points,
+		LastPurchaseDate: customer.LastPurchaseDate,
+		CreatedAt:        customer.CreatedAt,
+		UpdatedAt:        customer.UpdatedAt,
+		Records:          records,
+		PointsByDate:     customer.PointsByDate,
+		Attributes:       fromAttributes(customer.Attributes),
+		HouseholdID:      customer.HouseholdID,
+		MergedInto:       customer.MergedInto,
+		Aliases:          customer.Aliases,
+	}, nil
+}
+
+type PointSummary struct {
+	CustomerID       string               `bson:"customer_id"`
+	Points           int64                `bson:"points"`
+	LastPurchaseDate string               `bson:"last_purchase_date"`
+	QualifyingPoints int64                `bson:"qualifying_points"`
+	QualifyingSpend  primitive.Decimal128 `bson:"qualifying_spend"`
+}
+
+func (p PointSummary) ToDomain() (entity.PointSummary, error) {
+	spend, err := decimal.NewFromString(p.QualifyingSpend.String())
+	if err != nil {
+		return entity.PointSummary{}, errors.ErrInternal.Wrap(err)
+	}
+
+	return entity.PointSummary{
+		CustomerID:       p.CustomerID,
+		Points:           p.Points,
+		LastPurchaseDate: p.LastPurchaseDate,
+		QualifyingPoints: p.QualifyingPoints,
+		QualifyingSpend:  spend,
+	}, nil
+}
+
+type BreakdownTotal struct {
+	Key             string               `bson:"_id"`
+	Points          int64                `bson:"points"`
+	Transactions    int64                `bson:"transactions"`
+	PurchasedAmount primitive.Decimal128 `bson:"purchased_amount"`
+}
+
+func (b BreakdownTotal) ToDomain() (entity.BreakdownTotal, error) {
+	amount, err := decimal.NewFromString(b.PurchasedAmount.String())
+	if err != nil {
+		return entity.BreakdownTotal{}, errors.ErrInternal.Wrap(err)
+	}
+
+	return entity.BreakdownTotal{
+		Key:             b.Key,
+		Points:          b.Points,
+		Transactions:    b.Transactions,
+		PurchasedAmount: amount,
+	}, nil
+}
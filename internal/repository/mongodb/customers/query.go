@@ -9,6 +9,7 @@ import (
 	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func filterCustomerIDs(customerIDs []string) bson.M {
@@ -18,6 +19,43 @@ func filterCustomerIDs(customerIDs []string) bson.M {
 	return bson.M{"customer_id": bson.M{"$in": customerIDs}}
 }
 
+func filterUpdatedAfter(snapshotAt time.Time) bson.M {
+	return bson.M{"updated_at": bson.M{"$gt": snapshotAt}}
+}
+
+func filterCustomerIDsUpdatedAfter(customerIDs []string, snapshotAt time.Time) bson.M {
+	return bson.M{"customer_id": bson.M{"$in": customerIDs}, "updated_at": bson.M{"$gt": snapshotAt}}
+}
+
+// indexesCustomer are the indexes of the customers collection, as
+// scripts/init-mongo.js creates them. The shadow collection gets them too, so it
+// can be queried like the live one while a recompute is reviewed.
+func indexesCustomer() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "customer_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "records.purchase_date", Value: 1}}},
+		{Keys: bson.D{{Key: "points_by_date.$**", Value: 1}}},
+	}
+}
+
+func filterShadowSnapshot() bson.M {
+	return bson.M{"_id": ShadowCustomerCollection}
+}
+
+// operationReplaceUnchanged replaces each live customer with its recomputed
+// document, as long as nothing was written to the customer after snapshotAt.
+func operationReplaceUnchanged(documents []Customer, snapshotAt time.Time) []mongo.WriteModel {
+	operations := make([]mongo.WriteModel, 0, len(documents))
+	for _, document := range documents {
+		filter := bson.M{"customer_id": document.CustomerID, "updated_at": bson.M{"$not": bson.M{"$gt": snapshotAt}}}
+		operations = append(operations, mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(document))
+	}
+	return operations
+}
+
 func operationUpdateCustomerPoint(updateCustomer []entity.UpdateCustomer) ([]mongo.WriteModel, error) {
 	if len(updateCustomer) == 0 {
 		return nil, apperr.ErrInvalidArgument.Wrap(errors.New("updateCustomer is empty"))
@@ -42,8 +80,10 @@ func operationUpdateCustomerPoint(updateCustomer []entity.UpdateCustomer) ([]mon
 			SetUpdate(bson.M{
 				"$inc": incPayload,
 				"$set": bson.M{
+					"updated_at": time.Now(),
+				},
+				"$max": bson.M{
 					"last_purchase_date": customer.LastPurchaseDate,
-					"updated_at":         time.Now(),
 				},
 				"$push": bson.M{
 					"records": bson.M{"$each": records},
@@ -60,3 +100,296 @@ func operationUpdateCustomerPoint(updateCustomer []entity.UpdateCustomer) ([]mon
 
 	return operations, nil
 }
+
+func operationUpdateCustomerAttributes(updates []entity.UpdateCustomerAttributes) ([]mongo.WriteModel, error) {
+	if len(updates) == 0 {
+		return nil, apperr.ErrInvalidArgument.Wrap(errors.New("updates is empty"))
+	}
+
+	now := time.Now()
+	operations := make([]mongo.WriteModel, 0, len(updates))
+	for _, update := range updates {
+		model := mongo.NewUpdateOneModel().
+			SetFilter(bson.M{
+				"customer_id": update.CustomerID,
+			}).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"attributes": fromAttributes(update.Attributes),
+					"updated_at": now,
+				},
+				"$setOnInsert": bson.M{
+					"customer_id":    update.CustomerID,
+					"points":         0,
+					"points_by_date": bson.M{},
+					"records":        bson.A{},
+					"created_at":     now,
+				},
+			}).
+			SetUpsert(true)
+
+		operations = append(operations, model)
+	}
+
+	return operations, nil
+}
+
+func filterPointsOn(date string) bson.M {
+	return bson.M{"points_by_date." + date: bson.M{"$exists": true}}
+}
+
+// projectionActiveOn keeps the points and movements of a single date.
+func projectionActiveOn(date string, purchaseDate time.Time) bson.M {
+	return bson.M{
+		"customer_id":            1,
+		"points_by_date." + date: 1,
+		"records": bson.M{"$filter": bson.M{
+			"input": "$records",
+			"as":    "record",
+			"cond":  bson.M{"$eq": bson.A{"$$record.purchase_date", purchaseDate}},
+		}},
+	}
+}
+
+// pipelinePointSummaries sums points_by_date up to asOf on the server and sorts
+// there, so the summary can be streamed without loading customers into memory.
+// It also totals the points and the distinct purchases in the tier window,
+// leaving out points transferred or pooled in or out.
+func pipelinePointSummaries(asOf string, excludeIDs []string) (mongo.Pipeline, error) {
+	asOfDate, err := time.Parse(time.DateOnly, asOf)
+	if err != nil {
+		return nil, apperr.ErrInvalidArgument.Wrap(err)
+	}
+	windowStart := entity.TierWindowStart(asOfDate)
+
+	pipeline := mongo.Pipeline{}
+	if len(excludeIDs) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"customer_id": bson.M{"$nin": excludeIDs}}}})
+	}
+
+	inWindow := bson.M{"$and": bson.A{
+		bson.M{"$gte": bson.A{"$$record.purchase_date", windowStart}},
+		bson.M{"$lte": bson.A{"$$record.purchase_date", asOfDate}},
+	}}
+
+	transferInWindow := bson.M{"$and": bson.A{
+		inWindow,
+		bson.M{"$in": bson.A{"$$record.type", bson.A{entity.TransferTransaction, entity.PoolTransaction}}},
+	}}
+
+	return append(pipeline,
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":         0,
+			"customer_id": 1,
+			"transferred": bson.M{"$sum": bson.M{"$map": bson.M{
+				"input": bson.M{"$filter": bson.M{"input": "$records", "as": "record", "cond": transferInWindow}},
+				"as":    "record",
+				"in":    "$$record.points",
+			}}},
+			"dates": bson.M{"$filter": bson.M{
+				"input": bson.M{"$objectToArray": "$points_by_date"},
+				"as":    "date",
+				"cond":  bson.M{"$lte": bson.A{"$$date.k", asOf}},
+			}},
+			"purchases": bson.M{"$setUnion": bson.A{bson.M{"$map": bson.M{
+				"input": bson.M{"$filter": bson.M{"input": "$records", "as": "record", "cond": inWindow}},
+				"as":    "record",
+				"in": bson.M{
+					"product_id":    "$$record.product_id",
+					"category_id":   "$$record.category_id",
+					"branch_id":     "$$record.branch_id",
+					"amount":        "$$record.amount",
+					"purchase_date": "$$record.purchase_date",
+				},
+			}}}},
+		}}},
+		bson.D{{Key: "$match", Value: bson.M{"dates.0": bson.M{"$exists": true}}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"customer_id":        1,
+			"points":             bson.M{"$sum": "$dates.v"},
+			"last_purchase_date": bson.M{"$max": "$dates.k"},
+			"qualifying_points": bson.M{"$subtract": bson.A{
+				bson.M{"$sum": bson.M{"$map": bson.M{
+					"input": bson.M{"$filter": bson.M{
+						"input": "$dates",
+						"as":    "date",
+						"cond":  bson.M{"$gte": bson.A{"$$date.k", windowStart.Format(time.DateOnly)}},
+					}},
+					"as": "date",
+					"in": "$$date.v",
+				}}},
+				"$transferred",
+			}},
+			"qualifying_spend": bson.M{"$toDecimal": bson.M{"$sum": "$purchases.amount"}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "points", Value: -1},
+			{Key: "last_purchase_date", Value: -1},
+			{Key: "customer_id", Value: 1},
+		}}},
+	), nil
+}
+
+// pipelineBreakdown groups movements purchased between from and to by field. The
+// first group collapses the movements of one purchase, so a purchase that earned
+// points from several rules is counted once. With purchasesOnly, adjustments,
+// transfers and pooling are left out, since they have no branch, category or rule
+// to be grouped by.
+func pipelineBreakdown(field string, purchasesOnly bool, from, to time.Time) mongo.Pipeline {
+	conditions := bson.A{
+		bson.M{"$gte": bson.A{"$$record.purchase_date", from}},
+		bson.M{"$lte": bson.A{"$$record.purchase_date", to}},
+	}
+	if purchasesOnly {
+		// Purchases are stored without a type.
+		conditions = append(conditions, bson.M{"$in": bson.A{
+			bson.M{"$ifNull": bson.A{"$$record.type", entity.PurchaseTransaction}},
+			bson.A{entity.PurchaseTransaction, entity.ReturnTransaction, entity.VoidTransaction},
+		}})
+	}
+	inRange := bson.M{"$and": conditions}
+
+	return mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{"records.purchase_date": bson.M{"$gte": from, "$lte": to}}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":         0,
+			"customer_id": 1,
+			"records":     bson.M{"$filter": bson.M{"input": "$records", "as": "record", "cond": inRange}},
+		}}},
+		bson.D{{Key: "$unwind", Value: "$records"}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"key":           "$records." + field,
+				"customer_id":   "$customer_id",
+				"product_id":    "$records.product_id",
+				"category_id":   "$records.category_id",
+				"branch_id":     "$records.branch_id",
+				"amount":        "$records.amount",
+				"purchase_date": "$records.purchase_date",
+				"type":          "$records.type",
+				"adjustment_id": "$records.adjustment_id",
+			},
+			"points": bson.M{"$sum": "$records.points"},
+		}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":              bson.M{"$ifNull": bson.A{"$_id.key", ""}},
+			"points":           bson.M{"$sum": "$points"},
+			"transactions":     bson.M{"$sum": 1},
+			"purchased_amount": bson.M{"$sum": "$_id.amount"},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "points", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+}
+
+func breakdownField(breakdown entity.Breakdown) (string, error) {
+	switch breakdown {
+	case entity.BreakdownBranch:
+		return "branch_id", nil
+	case entity.BreakdownCategory:
+		return "category_id", nil
+	case entity.BreakdownRule:
+		return "rule_id", nil
+	case entity.BreakdownSource:
+		return "type", nil
+	default:
+		return "", apperr.ErrInvalidArgument.WithMessage(fmt.Sprintf("unknown breakdown %q", breakdown))
+	}
+}
+
+// filterTransferFrom matches the sender while they have the points to transfer
+// and, when dailyLimit is set, the transfers out they already made on the date
+// leave room for it.
+func filterTransferFrom(transfer entity.Transfer, dailyLimit int64) bson.M {
+	filter := bson.M{
+		"customer_id": transfer.FromCustomerID,
+		"points":      bson.M{"$gte": transfer.Points},
+	}
+	if dailyLimit <= 0 {
+		return filter
+	}
+
+	sentToday := bson.M{"$sum": bson.M{"$map": bson.M{
+		"input": bson.M{"$filter": bson.M{
+			"input": "$records",
+			"as":    "record",
+			"cond": bson.M{"$and": bson.A{
+				bson.M{"$eq": bson.A{"$$record.type", entity.TransferTransaction}},
+				bson.M{"$eq": bson.A{"$$record.purchase_date", transfer.Date}},
+				bson.M{"$lt": bson.A{"$$record.points", 0}},
+			}},
+		}},
+		"as": "record",
+		"in": "$$record.points",
+	}}}
+	filter["$expr"] = bson.M{"$gte": bson.A{sentToday, transfer.Points - dailyLimit}}
+
+	return filter
+}
+
+// updateMovement adds a single movement to a customer's balance and history.
+func updateMovement(movement Record) bson.M {
+	return bson.M{
+		"$inc": bson.M{
+			"points": movement.Points,
+			"points_by_date." + movement.PurchaseDate.Format(time.DateOnly): movement.Points,
+		},
+		"$set":  bson.M{"updated_at": time.Now()},
+		"$push": bson.M{"records": movement},
+	}
+}
+
+// updateUndoTransfer takes a transfer's movement back off a customer.
+func updateUndoTransfer(movement Record) bson.M {
+	return bson.M{
+		"$inc": bson.M{
+			"points": -movement.Points,
+			"points_by_date." + movement.PurchaseDate.Format(time.DateOnly): -movement.Points,
+		},
+		"$set":  bson.M{"updated_at": time.Now()},
+		"$pull": bson.M{"records": bson.M{"transfer_id": movement.TransferID}},
+	}
+}
+
+func filterHousehold(customerID, householdID string) bson.M {
+	if householdID == "" {
+		return bson.M{"customer_id": customerID, "household_id": bson.M{"$in": bson.A{"", nil}}}
+	}
+	return bson.M{"customer_id": customerID, "household_id": householdID}
+}
+
+func updateHousehold(householdID string) bson.M {
+	if householdID == "" {
+		return bson.M{"$unset": bson.M{"household_id": ""}, "$set": bson.M{"updated_at": time.Now()}}
+	}
+	return bson.M{"$set": bson.M{"household_id": householdID, "updated_at": time.Now()}}
+}
+
+// filterUnchanged matches the customer only while nothing was written to it since
+// it was read and it was not merged away.
+func filterUnchanged(customer entity.Customer) bson.M {
+	return bson.M{
+		"customer_id": customer.CustomerID,
+		"updated_at":  customer.UpdatedAt,
+		"merged_into": bson.M{"$in": bson.A{"", nil}},
+	}
+}
+
+// updateMergedCustomer replaces the balance, history and aliases of a customer
+// taking part in a merge.
+func updateMergedCustomer(customer Customer) bson.M {
+	set := bson.M{
+		"points":             customer.Points,
+		"points_by_date":     customer.PointsByDate,
+		"records":            customer.Records,
+		"last_purchase_date": customer.LastPurchaseDate,
+		"attributes":         customer.Attributes,
+		"aliases":            customer.Aliases,
+		"updated_at":         time.Now(),
+	}
+	if customer.MergedInto == "" {
+		return bson.M{"$set": set, "$unset": bson.M{"merged_into": ""}}
+	}
+
+	set["merged_into"] = customer.MergedInto
+	return bson.M{"$set": set}
+}
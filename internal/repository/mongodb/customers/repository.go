@@ -2,30 +2,46 @@ package mongodb
 
 import (
 	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
 
 	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
 	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
 	"github.com/sirawong/point-accumulate-interview/internal/errors"
+	database "github.com/sirawong/point-accumulate-interview/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
-	CustomerCollection = "customers"
+	CustomerCollection       = "customers"
+	ShadowCustomerCollection = "customers_shadow"
+	RecomputeCollection      = "recompute_snapshots"
+
+	// customerBatchSize is how many customers a recompute or swap reads or writes
+	// at a time.
+	customerBatchSize = 1000
 )
 
 type customerRepository struct {
-	collection *mongo.Collection
+	collection database.Collection
+	shadow     database.Collection
+	snapshots  database.Collection
 }
 
 func NewCustomerRepository(db *mongo.Database) repository.CustomerRepository {
 	return &customerRepository{
-		collection: db.Collection(CustomerCollection),
+		collection: database.NewCollection(db, CustomerCollection),
+		shadow:     database.NewCollection(db, ShadowCustomerCollection),
+		snapshots:  database.NewCollection(db, RecomputeCollection),
 	}
 }
 
 func (c customerRepository) GetCustomers(ctx context.Context, customerIDs []string) ([]entity.Customer, error) {
-	cursor, err := c.collection.Find(ctx, filterCustomerIDs(customerIDs), nil)
+	cursor, err := c.collection.For(ctx).Find(ctx, filterCustomerIDs(customerIDs), nil)
 	if err != nil {
 		return nil, errors.ErrInternal.Wrap(err)
 	}
@@ -46,10 +62,413 @@ func (c customerRepository) UpdateBulkCustomers(ctx context.Context, updateCusto
 	}
 
 	opts := options.BulkWrite().SetOrdered(false)
-	_, err = c.collection.BulkWrite(ctx, operations, opts)
+	_, err = c.collection.For(ctx).BulkWrite(ctx, operations, opts)
+	if err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	return nil
+}
+
+func (c customerRepository) UpdateCustomerAttributes(ctx context.Context, updates []entity.UpdateCustomerAttributes) error {
+	operations, err := operationUpdateCustomerAttributes(updates)
+	if err != nil {
+		return err
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+	_, err = c.collection.For(ctx).BulkWrite(ctx, operations, opts)
+	if err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	return nil
+}
+
+func (c customerRepository) StreamCustomers(ctx context.Context, fn func(entity.Customer) error) error {
+	opts := options.Find().SetBatchSize(customerBatchSize)
+	cursor, err := c.collection.For(ctx).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var customer Customer
+		if err = cursor.Decode(&customer); err != nil {
+			return errors.ErrInternal.Wrap(err)
+		}
+
+		value, err := customer.ToDomain()
+		if err != nil {
+			return err
+		}
+
+		if err = fn(*value); err != nil {
+			return err
+		}
+	}
+
+	if err = cursor.Err(); err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	return nil
+}
+
+// ReplaceShadowCustomers drops the shadow collection and fills it with what fill
+// adds, in batches, so a recompute can be inspected before it replaces the live
+// customers. The snapshot is only recorded once the shadow is complete, so a
+// half-built one cannot be swapped in.
+func (c customerRepository) ReplaceShadowCustomers(ctx context.Context, snapshotAt time.Time, fill func(add func(entity.Customer) error) error) error {
+	if _, err := c.snapshots.For(ctx).DeleteOne(ctx, filterShadowSnapshot()); err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	if err := c.shadow.For(ctx).Drop(ctx); err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	if _, err := c.shadow.For(ctx).Indexes().CreateMany(ctx, indexesCustomer()); err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	documents := make([]interface{}, 0, customerBatchSize)
+	flush := func() error {
+		if len(documents) == 0 {
+			return nil
+		}
+		if _, err := c.shadow.For(ctx).InsertMany(ctx, documents); err != nil {
+			return errors.ErrInternal.Wrap(err)
+		}
+		documents = documents[:0]
+		return nil
+	}
+
+	err := fill(func(customer entity.Customer) error {
+		document, err := fromCustomer(customer)
+		if err != nil {
+			return err
+		}
+
+		documents = append(documents, document)
+		if len(documents) < customerBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	if err = flush(); err != nil {
+		return err
+	}
+
+	snapshot := ShadowSnapshot{ID: ShadowCustomerCollection, SnapshotAt: snapshotAt}
+	opts := options.Replace().SetUpsert(true)
+	if _, err = c.snapshots.For(ctx).ReplaceOne(ctx, filterShadowSnapshot(), snapshot, opts); err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	return nil
+}
+
+// SwapShadowCustomers replaces each live customer with its shadow document.
+// snapshotAt must be the snapshot recorded with the shadow, so only the recompute
+// the caller reviewed is applied. It refuses when any customer was written after
+// that snapshot. Every replacement only matches a customer still unchanged since
+// the snapshot, so a write that lands during the swap is never lost: it either
+// comes first and keeps that customer's live document, or applies on top of the
+// recomputed one.
+func (c customerRepository) SwapShadowCustomers(ctx context.Context, snapshotAt time.Time) (*entity.SwapResult, error) {
+	collection, shadow := c.collection.For(ctx), c.shadow.For(ctx)
+
+	var snapshot ShadowSnapshot
+	err := c.snapshots.For(ctx).FindOne(ctx, filterShadowSnapshot()).Decode(&snapshot)
+	if stderrors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errors.ErrNotFound.WithMessage("no recomputed balances to swap")
+	}
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+	if !snapshot.SnapshotAt.Equal(snapshotAt) {
+		return nil, errors.ErrConflict.WithMessage(fmt.Sprintf("the recomputed balances are from snapshot %s, not %s", snapshot.SnapshotAt.Format(time.RFC3339Nano), snapshotAt.Format(time.RFC3339Nano)))
+	}
+
+	names, err := shadow.Database().ListCollectionNames(ctx, bson.M{"name": shadow.Name()})
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+	if len(names) == 0 {
+		return nil, errors.ErrNotFound.WithMessage("no recomputed balances to swap")
+	}
+
+	changed, err := collection.CountDocuments(ctx, filterUpdatedAfter(snapshot.SnapshotAt))
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+	if changed > 0 {
+		return nil, errors.ErrConflict.WithMessage(fmt.Sprintf("%d customers changed since the recompute snapshot", changed))
+	}
+
+	// The snapshot goes first, so a swap that fails part way cannot be repeated
+	// over customers it already replaced.
+	if _, err = c.snapshots.For(ctx).DeleteOne(ctx, filterShadowSnapshot()); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	cursor, err := shadow.Find(ctx, bson.M{}, options.Find().SetBatchSize(customerBatchSize))
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+	defer cursor.Close(ctx)
+
+	result := &entity.SwapResult{Skipped: []string{}}
+	documents := make([]Customer, 0, customerBatchSize)
+	flush := func() error {
+		if len(documents) == 0 {
+			return nil
+		}
+		if err := c.replaceUnchanged(ctx, documents, snapshot.SnapshotAt, result); err != nil {
+			return err
+		}
+		documents = documents[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var document Customer
+		if err = cursor.Decode(&document); err != nil {
+			return nil, errors.ErrInternal.Wrap(err)
+		}
+		// The live document keeps its own _id.
+		document.ID = primitive.NilObjectID
+
+		documents = append(documents, document)
+		if len(documents) < customerBatchSize {
+			continue
+		}
+		if err = flush(); err != nil {
+			return nil, err
+		}
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+	if err = flush(); err != nil {
+		return nil, err
+	}
+
+	if err = shadow.Drop(ctx); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return result, nil
+}
+
+// replaceUnchanged replaces the live customers of documents that were not written
+// after snapshotAt, and adds the others to the result's skipped customers.
+func (c customerRepository) replaceUnchanged(ctx context.Context, documents []Customer, snapshotAt time.Time, result *entity.SwapResult) error {
+	opts := options.BulkWrite().SetOrdered(false)
+	written, err := c.collection.For(ctx).BulkWrite(ctx, operationReplaceUnchanged(documents, snapshotAt), opts)
 	if err != nil {
 		return errors.ErrInternal.Wrap(err)
 	}
 
+	result.Swapped += int(written.MatchedCount)
+	if int(written.MatchedCount) == len(documents) {
+		return nil
+	}
+
+	customerIDs := make([]string, 0, len(documents))
+	for _, document := range documents {
+		customerIDs = append(customerIDs, document.CustomerID)
+	}
+
+	findOpts := options.Find().SetProjection(bson.M{"customer_id": 1})
+	cursor, err := c.collection.For(ctx).Find(ctx, filterCustomerIDsUpdatedAfter(customerIDs, snapshotAt), findOpts)
+	if err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	var skipped Customers
+	if err = cursor.All(ctx, &skipped); err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+	for _, customer := range skipped {
+		result.Skipped = append(result.Skipped, customer.CustomerID)
+	}
+
+	return nil
+}
+
+func (c customerRepository) StreamPointSummaries(ctx context.Context, asOf string, excludeIDs []string, fn func(entity.PointSummary) error) error {
+	pipeline, err := pipelinePointSummaries(asOf, excludeIDs)
+	if err != nil {
+		return err
+	}
+
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := c.collection.For(ctx).Aggregate(ctx, pipeline, opts)
+	if err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var summary PointSummary
+		if err = cursor.Decode(&summary); err != nil {
+			return errors.ErrInternal.Wrap(err)
+		}
+
+		value, err := summary.ToDomain()
+		if err != nil {
+			return err
+		}
+
+		if err = fn(value); err != nil {
+			return err
+		}
+	}
+
+	if err = cursor.Err(); err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	return nil
+}
+
+func (c customerRepository) GetCustomersActiveOn(ctx context.Context, date time.Time) ([]entity.Customer, error) {
+	dateString := date.Format(time.DateOnly)
+
+	opts := options.Find().SetProjection(projectionActiveOn(dateString, date))
+	cursor, err := c.collection.For(ctx).Find(ctx, filterPointsOn(dateString), opts)
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	var customers Customers
+	err = cursor.All(ctx, &customers)
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return customers.ToDomain(), nil
+}
+
+func (c customerRepository) GetBreakdown(ctx context.Context, breakdown entity.Breakdown, from, to time.Time) ([]entity.BreakdownTotal, error) {
+	field, err := breakdownField(breakdown)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	pipeline := pipelineBreakdown(field, breakdown != entity.BreakdownSource, from, to)
+	cursor, err := c.collection.For(ctx).Aggregate(ctx, pipeline, opts)
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	var totals []BreakdownTotal
+	if err = cursor.All(ctx, &totals); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	result := make([]entity.BreakdownTotal, 0, len(totals))
+	for _, total := range totals {
+		value, err := total.ToDomain()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+
+	return result, nil
+}
+
+func (c customerRepository) TransferPoints(ctx context.Context, transfer entity.Transfer, dailyLimit int64) (*entity.Transfer, error) {
+	transfer.ID = primitive.NewObjectID().Hex()
+	from, to := transfer.Movements()
+	movements, err := fromRecords([]entity.Record{from, to})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.collection.For(ctx).UpdateOne(ctx, filterTransferFrom(transfer, dailyLimit), updateMovement(movements[0]))
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, errors.ErrConflict.WithMessage("the balance or today's transfers changed, try again")
+	}
+
+	result, err = c.collection.For(ctx).UpdateOne(ctx, bson.M{"customer_id": transfer.ToCustomerID}, updateMovement(movements[1]))
+	if err != nil {
+		err = errors.ErrInternal.Wrap(err)
+	} else if result.MatchedCount == 0 {
+		err = errors.ErrNotFound.WithMessage("customer to transfer to not found")
+	}
+	if err != nil {
+		if _, undoErr := c.collection.For(ctx).UpdateOne(ctx, bson.M{"customer_id": transfer.FromCustomerID}, updateUndoTransfer(movements[0])); undoErr != nil {
+			return nil, errors.ErrInternal.Wrap(fmt.Errorf("%w; transfer %s left debited: %w", err, transfer.ID, undoErr))
+		}
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+func (c customerRepository) SetHousehold(ctx context.Context, customerID, from, to string) error {
+	result, err := c.collection.For(ctx).UpdateOne(ctx, filterHousehold(customerID, from), updateHousehold(to))
+	if err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+	if result.MatchedCount == 0 {
+		return errors.ErrConflict.WithMessage("customer is not in the expected household")
+	}
+
+	return nil
+}
+
+func (c customerRepository) MergeCustomers(ctx context.Context, source, target entity.Customer) error {
+	restore, err := fromCustomer(source)
+	if err != nil {
+		return err
+	}
+	merged, err := fromCustomer(target)
+	if err != nil {
+		return err
+	}
+	tombstone := Customer{
+		CustomerID:       source.CustomerID,
+		LastPurchaseDate: source.LastPurchaseDate,
+		Records:          []Record{},
+		PointsByDate:     map[string]int64{},
+		Attributes:       fromAttributes(source.Attributes),
+		MergedInto:       target.CustomerID,
+	}
+
+	result, err := c.collection.For(ctx).UpdateOne(ctx, filterUnchanged(source), updateMergedCustomer(tombstone))
+	if err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+	if result.MatchedCount == 0 {
+		return errors.ErrConflict.WithMessage(fmt.Sprintf("customer %s changed, try again", source.CustomerID))
+	}
+
+	result, err = c.collection.For(ctx).UpdateOne(ctx, filterUnchanged(target), updateMergedCustomer(merged))
+	if err != nil {
+		err = errors.ErrInternal.Wrap(err)
+	} else if result.MatchedCount == 0 {
+		err = errors.ErrConflict.WithMessage(fmt.Sprintf("customer %s changed, try again", target.CustomerID))
+	}
+	if err != nil {
+		filter := bson.M{"customer_id": source.CustomerID, "merged_into": target.CustomerID}
+		if _, undoErr := c.collection.For(ctx).UpdateOne(ctx, filter, updateMergedCustomer(restore)); undoErr != nil {
+			return errors.ErrInternal.Wrap(fmt.Errorf("%w; customer %s left merged without its history: %w", err, source.CustomerID, undoErr))
+		}
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,270 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+)
+
+type CustomerRepositoryTestSuite struct {
+	suite.Suite
+	mt         *mtest.T
+	snapshotAt time.Time
+}
+
+func TestCustomerRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(CustomerRepositoryTestSuite))
+}
+
+func (suite *CustomerRepositoryTestSuite) SetupTest() {
+	// The mock deployment answers with the queued responses, so no server is needed.
+	suite.mt = mtest.New(suite.T(), mtest.NewOptions().ClientType(mtest.Mock))
+	suite.snapshotAt = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+}
+
+// commands returns the name and target of every command the repository sent.
+func (suite *CustomerRepositoryTestSuite) commands(mt *mtest.T) [][2]string {
+	var commands [][2]string
+	for _, event := range mt.GetAllStartedEvents() {
+		target, _ := event.Command.Lookup(event.CommandName).StringValueOK()
+		commands = append(commands, [2]string{event.CommandName, target})
+	}
+	return commands
+}
+
+func (suite *CustomerRepositoryTestSuite) snapshotResponse(mt *mtest.T, snapshotAt time.Time) bson.D {
+	return mtest.CreateCursorResponse(0, mt.DB.Name()+"."+RecomputeCollection, mtest.FirstBatch, bson.D{
+		{Key: "_id", Value: ShadowCustomerCollection},
+		{Key: "snapshot_at", Value: snapshotAt},
+	})
+}
+
+func (suite *CustomerRepositoryTestSuite) TestReplaceShadowCustomers_IndexesAndSnapshot() {
+	suite.mt.Run("replace", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+
+		err := NewCustomerRepository(mt.DB).ReplaceShadowCustomers(context.Background(), suite.snapshotAt, func(add func(entity.Customer) error) error {
+			return add(entity.Customer{CustomerID: "U000001"})
+		})
+
+		suite.NoError(err)
+		suite.Equal([][2]string{
+			{"delete", RecomputeCollection},
+			{"drop", ShadowCustomerCollection},
+			{"createIndexes", ShadowCustomerCollection},
+			{"insert", ShadowCustomerCollection},
+			{"update", RecomputeCollection},
+		}, suite.commands(mt))
+
+		// The shadow gets every index of the live collection, so it can be
+		// queried the same way while it is reviewed.
+		events := mt.GetAllStartedEvents()
+		indexes, err := events[2].Command.Lookup("indexes").Array().Values()
+		suite.Require().NoError(err)
+		var keys []string
+		for _, index := range indexes {
+			keys = append(keys, index.Document().Lookup("key").Document().Index(0).Key())
+		}
+		suite.Equal([]string{"customer_id", "records.purchase_date", "points_by_date.$**"}, keys)
+
+		snapshot := events[4].Command.Lookup("updates", "0", "u").Document()
+		suite.Equal(suite.snapshotAt, snapshot.Lookup("snapshot_at").Time().UTC())
+	})
+}
+
+func (suite *CustomerRepositoryTestSuite) TestStreamCustomers() {
+	suite.mt.Run("stream", func(mt *mtest.T) {
+		ns := mt.DB.Name() + "." + CustomerCollection
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, bson.D{{Key: "customer_id", Value: "U000001"}}),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch, bson.D{{Key: "customer_id", Value: "U000002"}}),
+		)
+
+		var customerIDs []string
+		err := NewCustomerRepository(mt.DB).StreamCustomers(context.Background(), func(customer entity.Customer) error {
+			customerIDs = append(customerIDs, customer.CustomerID)
+			return nil
+		})
+
+		suite.NoError(err)
+		suite.Equal([]string{"U000001", "U000002"}, customerIDs)
+		suite.Equal([][2]string{{"find", CustomerCollection}, {"getMore", ""}}, suite.commands(mt))
+	})
+}
+
+// swapResponses queues the snapshot, shadow and unchanged-customer checks a swap
+// starts with, followed by the snapshot delete and the shadow customers.
+func (suite *CustomerRepositoryTestSuite) swapResponses(mt *mtest.T, shadow ...bson.D) {
+	mt.AddMockResponses(
+		suite.snapshotResponse(mt, suite.snapshotAt),
+		mtest.CreateCursorResponse(0, mt.DB.Name()+".$cmd.listCollections", mtest.FirstBatch, bson.D{{Key: "name", Value: ShadowCustomerCollection}}),
+		mtest.CreateCursorResponse(0, mt.DB.Name()+"."+CustomerCollection, mtest.FirstBatch, bson.D{{Key: "n", Value: 0}}),
+		mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		mtest.CreateCursorResponse(0, mt.DB.Name()+"."+ShadowCustomerCollection, mtest.FirstBatch, shadow...),
+	)
+}
+
+func (suite *CustomerRepositoryTestSuite) TestSwapShadowCustomers() {
+	suite.mt.Run("swap", func(mt *mtest.T) {
+		suite.swapResponses(mt,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "customer_id", Value: "U000001"}, {Key: "points", Value: 30}},
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "customer_id", Value: "U000002"}, {Key: "points", Value: 50}},
+		)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		result, err := NewCustomerRepository(mt.DB).SwapShadowCustomers(context.Background(), suite.snapshotAt)
+
+		suite.NoError(err)
+		suite.Equal(&entity.SwapResult{Swapped: 2, Skipped: []string{}}, result)
+		suite.Equal([][2]string{
+			{"find", RecomputeCollection},
+			{"listCollections", ""},
+			{"aggregate", CustomerCollection},
+			{"delete", RecomputeCollection},
+			{"find", ShadowCustomerCollection},
+			{"update", CustomerCollection},
+			{"drop", ShadowCustomerCollection},
+		}, suite.commands(mt))
+
+		// Live customers are checked against the recorded snapshot.
+		events := mt.GetAllStartedEvents()
+		match := events[2].Command.Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match")
+		suite.Equal(suite.snapshotAt, match.Document().Lookup("updated_at", "$gt").Time().UTC())
+
+		// Each replacement only matches a customer nobody wrote to since the
+		// snapshot, and leaves the live _id alone.
+		replace := events[5].Command.Lookup("updates", "0").Document()
+		suite.Equal("U000001", replace.Lookup("q", "customer_id").StringValue())
+		suite.Equal(suite.snapshotAt, replace.Lookup("q", "updated_at", "$not", "$gt").Time().UTC())
+		_, err = replace.Lookup("u").Document().LookupErr("_id")
+		suite.Error(err)
+		suite.EqualValues(30, replace.Lookup("u", "points").AsInt64())
+	})
+}
+
+func (suite *CustomerRepositoryTestSuite) TestSwapShadowCustomers_WrittenDuringSwap() {
+	suite.mt.Run("written during swap", func(mt *mtest.T) {
+		suite.swapResponses(mt,
+			bson.D{{Key: "customer_id", Value: "U000001"}},
+			bson.D{{Key: "customer_id", Value: "U000002"}},
+		)
+		mt.AddMockResponses(
+			// U000002 earned points after the checks, so its replacement matched nothing.
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateCursorResponse(0, mt.DB.Name()+"."+CustomerCollection, mtest.FirstBatch, bson.D{{Key: "customer_id", Value: "U000002"}}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		result, err := NewCustomerRepository(mt.DB).SwapShadowCustomers(context.Background(), suite.snapshotAt)
+
+		suite.NoError(err)
+		suite.Equal(&entity.SwapResult{Swapped: 1, Skipped: []string{"U000002"}}, result)
+
+		commands := suite.commands(mt)
+		suite.Equal([2]string{"find", CustomerCollection}, commands[6])
+		suite.Equal([2]string{"drop", ShadowCustomerCollection}, commands[7])
+
+		events := mt.GetAllStartedEvents()
+		customerIDs, err := events[6].Command.Lookup("filter", "customer_id", "$in").Array().Values()
+		suite.Require().NoError(err)
+		suite.Len(customerIDs, 2)
+		suite.Equal(suite.snapshotAt, events[6].Command.Lookup("filter", "updated_at", "$gt").Time().UTC())
+	})
+}
+
+func (suite *CustomerRepositoryTestSuite) TestSwapShadowCustomers_OtherSnapshot() {
+	suite.mt.Run("other snapshot", func(mt *mtest.T) {
+		mt.AddMockResponses(suite.snapshotResponse(mt, suite.snapshotAt))
+
+		// A snapshot time in the future would have let every recent write through.
+		_, err := NewCustomerRepository(mt.DB).SwapShadowCustomers(context.Background(), suite.snapshotAt.Add(24*time.Hour))
+
+		suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+		suite.Len(mt.GetAllStartedEvents(), 1)
+	})
+}
+
+func (suite *CustomerRepositoryTestSuite) TestSwapShadowCustomers_ChangedSinceSnapshot() {
+	suite.mt.Run("changed", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			suite.snapshotResponse(mt, suite.snapshotAt),
+			mtest.CreateCursorResponse(0, mt.DB.Name()+".$cmd.listCollections", mtest.FirstBatch, bson.D{{Key: "name", Value: ShadowCustomerCollection}}),
+			mtest.CreateCursorResponse(0, mt.DB.Name()+"."+CustomerCollection, mtest.FirstBatch, bson.D{{Key: "n", Value: 2}}),
+		)
+
+		_, err := NewCustomerRepository(mt.DB).SwapShadowCustomers(context.Background(), suite.snapshotAt)
+
+		suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+		suite.Len(mt.GetAllStartedEvents(), 3)
+	})
+}
+
+func (suite *CustomerRepositoryTestSuite) TestSwapShadowCustomers_NoRecompute() {
+	suite.mt.Run("no recompute", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+"."+RecomputeCollection, mtest.FirstBatch))
+
+		_, err := NewCustomerRepository(mt.DB).SwapShadowCustomers(context.Background(), suite.snapshotAt)
+
+		suite.Equal(apperr.ErrNotFound.Code, apperr.GetCode(err))
+	})
+}
+
+// breakdownConditions returns the conditions records must meet to be counted by
+// the breakdown aggregation the repository sent.
+func (suite *CustomerRepositoryTestSuite) breakdownConditions(mt *mtest.T) []bson.RawValue {
+	events := mt.GetAllStartedEvents()
+	suite.Require().Len(events, 1)
+	project := events[0].Command.Lookup("pipeline").Array().Index(1).Value().Document().Lookup("$project")
+	conditions, err := project.Document().Lookup("records", "$filter", "cond", "$and").Array().Values()
+	suite.Require().NoError(err)
+	return conditions
+}
+
+func (suite *CustomerRepositoryTestSuite) TestGetBreakdown_BranchCountsPurchasesOnly() {
+	suite.mt.Run("branch", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+"."+CustomerCollection, mtest.FirstBatch))
+
+		_, err := NewCustomerRepository(mt.DB).GetBreakdown(context.Background(), entity.BreakdownBranch, suite.snapshotAt, suite.snapshotAt)
+
+		suite.NoError(err)
+		// Adjustments, transfers and pooling have no branch and would total up as
+		// an empty one.
+		conditions := suite.breakdownConditions(mt)
+		suite.Len(conditions, 3)
+		types, err := conditions[2].Document().Lookup("$in").Array().Index(1).Value().Array().Values()
+		suite.Require().NoError(err)
+		var names []string
+		for _, value := range types {
+			names = append(names, value.StringValue())
+		}
+		suite.Equal([]string{"", "RETURN", "VOID"}, names)
+	})
+}
+
+func (suite *CustomerRepositoryTestSuite) TestGetBreakdown_SourceCountsEveryMovement() {
+	suite.mt.Run("source", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+"."+CustomerCollection, mtest.FirstBatch))
+
+		_, err := NewCustomerRepository(mt.DB).GetBreakdown(context.Background(), entity.BreakdownSource, suite.snapshotAt, suite.snapshotAt)
+
+		suite.NoError(err)
+		suite.Len(suite.breakdownConditions(mt), 2)
+	})
+}
@@ -0,0 +1,37 @@
+package mongodb
+
+import (
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+)
+
+type Household struct {
+	ID        string    `bson:"_id"`
+	Name      string    `bson:"name"`
+	Members   []string  `bson:"members"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func (h Household) ToDomain() *entity.Household {
+	return &entity.Household{
+		ID:        h.ID,
+		Name:      h.Name,
+		Members:   h.Members,
+		CreatedAt: h.CreatedAt,
+	}
+}
+
+func fromHousehold(household entity.Household) Household {
+	members := household.Members
+	if members == nil {
+		members = []string{}
+	}
+
+	return Household{
+		ID:        household.ID,
+		Name:      household.Name,
+		Members:   members,
+		CreatedAt: household.CreatedAt,
+	}
+}
@@ -0,0 +1,17 @@
+package mongodb
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// filterRoomFor matches the household while the customer is not a member and it
+// has fewer than maxMembers.
+func filterRoomFor(householdID, customerID string, maxMembers int) bson.M {
+	filter := bson.M{"_id": householdID, "members": bson.M{"$ne": customerID}}
+	if maxMembers > 0 {
+		filter[fmt.Sprintf("members.%d", maxMembers-1)] = bson.M{"$exists": false}
+	}
+	return filter
+}
@@ -0,0 +1,82 @@
+package mongodb
+
+import (
+	"context"
+	stderrors "errors"
+	"slices"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	"github.com/sirawong/point-accumulate-interview/internal/errors"
+	database "github.com/sirawong/point-accumulate-interview/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const HouseholdCollection = "households"
+
+type householdRepository struct {
+	collection database.Collection
+}
+
+func NewHouseholdRepository(db *mongo.Database) repository.HouseholdRepository {
+	return &householdRepository{
+		collection: database.NewCollection(db, HouseholdCollection),
+	}
+}
+
+func (r householdRepository) CreateHousehold(ctx context.Context, household entity.Household) (*entity.Household, error) {
+	document := fromHousehold(household)
+	if _, err := r.collection.For(ctx).InsertOne(ctx, document); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.ErrConflict.WithMessage("household already exists")
+		}
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return document.ToDomain(), nil
+}
+
+func (r householdRepository) GetHousehold(ctx context.Context, id string) (*entity.Household, error) {
+	var household Household
+	err := r.collection.For(ctx).FindOne(ctx, bson.M{"_id": id}).Decode(&household)
+	if stderrors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errors.ErrNotFound.WithMessage("household not found")
+	}
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return household.ToDomain(), nil
+}
+
+func (r householdRepository) AddMember(ctx context.Context, householdID, customerID string, maxMembers int) error {
+	result, err := r.collection.For(ctx).UpdateOne(ctx, filterRoomFor(householdID, customerID, maxMembers), bson.M{"$push": bson.M{"members": customerID}})
+	if err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	household, err := r.GetHousehold(ctx, householdID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(household.Members, customerID) {
+		return errors.ErrConflict.WithMessage("customer is already a member of the household")
+	}
+	return errors.ErrConflict.WithMessage("household is full")
+}
+
+func (r householdRepository) RemoveMember(ctx context.Context, householdID, customerID string) error {
+	result, err := r.collection.For(ctx).UpdateOne(ctx, bson.M{"_id": householdID}, bson.M{"$pull": bson.M{"members": customerID}})
+	if err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound.WithMessage("household not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package mongodb
+
+import (
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Merge struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	SourceCustomerID string             `bson:"source_customer_id"`
+	TargetCustomerID string             `bson:"target_customer_id"`
+	Reason           string             `bson:"reason"`
+	RequestedBy      string             `bson:"requested_by"`
+	MergedAt         time.Time          `bson:"merged_at"`
+	Points           int64              `bson:"points"`
+	Records          int                `bson:"records"`
+	Duplicates       int                `bson:"duplicates"`
+	DuplicatePoints  int64              `bson:"duplicate_points"`
+}
+
+func (m Merge) ToDomain() *entity.Merge {
+	return &entity.Merge{
+		ID:               m.ID.Hex(),
+		SourceCustomerID: m.SourceCustomerID,
+		TargetCustomerID: m.TargetCustomerID,
+		Reason:           m.Reason,
+		RequestedBy:      m.RequestedBy,
+		MergedAt:         m.MergedAt,
+		Points:           m.Points,
+		Records:          m.Records,
+		Duplicates:       m.Duplicates,
+		DuplicatePoints:  m.DuplicatePoints,
+	}
+}
+
+func fromMerge(merge entity.Merge) Merge {
+	return Merge{
+		SourceCustomerID: merge.SourceCustomerID,
+		TargetCustomerID: merge.TargetCustomerID,
+		Reason:           merge.Reason,
+		RequestedBy:      merge.RequestedBy,
+		MergedAt:         merge.MergedAt,
+		Points:           merge.Points,
+		Records:          merge.Records,
+		Duplicates:       merge.Duplicates,
+		DuplicatePoints:  merge.DuplicatePoints,
+	}
+}
@@ -0,0 +1,13 @@
+package mongodb
+
+import "go.mongodb.org/mongo-driver/bson"
+
+func filterMerges(customerID string) bson.M {
+	if customerID == "" {
+		return bson.M{}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{"source_customer_id": customerID},
+		bson.M{"target_customer_id": customerID},
+	}}
+}
@@ -0,0 +1,57 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	"github.com/sirawong/point-accumulate-interview/internal/errors"
+	database "github.com/sirawong/point-accumulate-interview/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const MergeCollection = "merges"
+
+type mergeRepository struct {
+	collection database.Collection
+}
+
+func NewMergeRepository(db *mongo.Database) repository.MergeRepository {
+	return &mergeRepository{
+		collection: database.NewCollection(db, MergeCollection),
+	}
+}
+
+func (r mergeRepository) CreateMerge(ctx context.Context, merge entity.Merge) (*entity.Merge, error) {
+	document := fromMerge(merge)
+	document.ID = primitive.NewObjectID()
+
+	if _, err := r.collection.For(ctx).InsertOne(ctx, document); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return document.ToDomain(), nil
+}
+
+func (r mergeRepository) ListMerges(ctx context.Context, customerID string) ([]entity.Merge, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "merged_at", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.collection.For(ctx).Find(ctx, filterMerges(customerID), opts)
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	var merges []Merge
+	if err = cursor.All(ctx, &merges); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	result := make([]entity.Merge, 0, len(merges))
+	for _, merge := range merges {
+		result = append(result, *merge.ToDomain())
+	}
+
+	return result, nil
+}
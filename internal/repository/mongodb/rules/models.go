@@ -2,6 +2,7 @@ package mongodb
 
 import (
 	"log"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
@@ -10,23 +11,66 @@ import (
 )
 
 type Rule struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty"`
-	Name       string             `bson:"name"`
-	RuleType   entity.RuleType    `bson:"rule_type"`
-	Conditions Conditions         `bson:"conditions"`
-	Reward     Reward             `bson:"reward"`
-	Status     string             `bson:"status"`
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	Name          string             `bson:"name"`
+	RuleType      entity.RuleType    `bson:"rule_type"`
+	Conditions    Conditions         `bson:"conditions"`
+	Reward        Reward             `bson:"reward"`
+	Status        string             `bson:"status"`
+	EffectiveFrom *time.Time         `bson:"effective_from,omitempty"`
+	EffectiveTo   *time.Time         `bson:"effective_to,omitempty"`
+	Version       int64              `bson:"version"`
+	CreatedBy     string             `bson:"created_by"`
+	CreatedAt     time.Time          `bson:"created_at"`
+}
+
+// RuleVersion is an immutable snapshot of a rule, written every time the rule changes.
+type RuleVersion struct {
+	RuleID        primitive.ObjectID `bson:"rule_id"`
+	Version       int64              `bson:"version"`
+	Name          string             `bson:"name"`
+	RuleType      entity.RuleType    `bson:"rule_type"`
+	Conditions    Conditions         `bson:"conditions"`
+	Reward        Reward             `bson:"reward"`
+	Status        string             `bson:"status"`
+	EffectiveFrom *time.Time         `bson:"effective_from,omitempty"`
+	EffectiveTo   *time.Time         `bson:"effective_to,omitempty"`
+	CreatedBy     string             `bson:"created_by"`
+	CreatedAt     time.Time          `bson:"created_at"`
 }
 
 type Reward struct {
-	Value     int64    `bson:"value"`
-	RatioUnit *float64 `bson:"ratio_unit,omitempty"`
+	Value      int64    `bson:"value"`
+	RatioUnit  *float64 `bson:"ratio_unit,omitempty"`
+	Expression string   `bson:"expression,omitempty"`
 }
 
 type Conditions struct {
 	MinAmount   primitive.Decimal128 `bson:"min_amount"`
 	BranchID    string               `bson:"branch_id"`
 	CategoryIDs []string             `bson:"category_ids"`
+
+	BranchIDs    []string `bson:"branch_ids,omitempty"`
+	BranchGroups []string `bson:"branch_groups,omitempty"`
+
+	ProductIDs         []string `bson:"product_ids,omitempty"`
+	ExcludeProductIDs  []string `bson:"exclude_product_ids,omitempty"`
+	ExcludeCategoryIDs []string `bson:"exclude_category_ids,omitempty"`
+
+	MinCategories      int      `bson:"min_categories,omitempty"`
+	RequiredProductIDs []string `bson:"required_product_ids,omitempty"`
+
+	VisitCount int `bson:"visit_count,omitempty"`
+	StreakDays int `bson:"streak_days,omitempty"`
+
+	Tiers         []string   `bson:"tiers,omitempty"`
+	SignupFrom    *time.Time `bson:"signup_from,omitempty"`
+	SignupTo      *time.Time `bson:"signup_to,omitempty"`
+	BirthdayMonth bool       `bson:"birthday_month,omitempty"`
+	Segments      []string   `bson:"segments,omitempty"`
+	FirstPurchase bool       `bson:"first_purchase,omitempty"`
+
+	Expression string `bson:"expression,omitempty"`
 }
 
 func (r Rule) ToDomain() (*entity.Rule, error) {
@@ -36,22 +80,141 @@ func (r Rule) ToDomain() (*entity.Rule, error) {
 	}
 
 	return &entity.Rule{
-		ID:       r.ID.Hex(),
-		Name:     r.Name,
-		RuleType: r.RuleType,
-		Status:   r.Status,
+		ID:            r.ID.Hex(),
+		Name:          r.Name,
+		RuleType:      r.RuleType,
+		Status:        r.Status,
+		EffectiveFrom: r.EffectiveFrom,
+		EffectiveTo:   r.EffectiveTo,
+		Version:       r.Version,
+		CreatedBy:     r.CreatedBy,
+		CreatedAt:     r.CreatedAt,
 		Reward: entity.Reward{
-			Value:     r.Reward.Value,
-			RatioUnit: r.Reward.RatioUnit,
+			Value:      r.Reward.Value,
+			RatioUnit:  r.Reward.RatioUnit,
+			Expression: r.Reward.Expression,
 		},
 		Conditions: entity.Conditions{
 			MinAmount:  value,
 			BranchID:   r.Conditions.BranchID,
 			CategoryID: r.Conditions.CategoryIDs,
+
+			BranchIDs:    r.Conditions.BranchIDs,
+			BranchGroups: r.Conditions.BranchGroups,
+
+			ProductIDs:         r.Conditions.ProductIDs,
+			ExcludeProductIDs:  r.Conditions.ExcludeProductIDs,
+			ExcludeCategoryIDs: r.Conditions.ExcludeCategoryIDs,
+
+			MinCategories:      r.Conditions.MinCategories,
+			RequiredProductIDs: r.Conditions.RequiredProductIDs,
+
+			VisitCount: r.Conditions.VisitCount,
+			StreakDays: r.Conditions.StreakDays,
+
+			Tiers:         r.Conditions.Tiers,
+			SignupFrom:    r.Conditions.SignupFrom,
+			SignupTo:      r.Conditions.SignupTo,
+			BirthdayMonth: r.Conditions.BirthdayMonth,
+			Segments:      r.Conditions.Segments,
+			FirstPurchase: r.Conditions.FirstPurchase,
+
+			Expression: r.Conditions.Expression,
+		},
+	}, nil
+}
+
+func (v RuleVersion) ToDomain() (*entity.Rule, error) {
+	return Rule{
+		ID:            v.RuleID,
+		Name:          v.Name,
+		RuleType:      v.RuleType,
+		Conditions:    v.Conditions,
+		Reward:        v.Reward,
+		Status:        v.Status,
+		EffectiveFrom: v.EffectiveFrom,
+		EffectiveTo:   v.EffectiveTo,
+		Version:       v.Version,
+		CreatedBy:     v.CreatedBy,
+		CreatedAt:     v.CreatedAt,
+	}.ToDomain()
+}
+
+func fromRule(rule entity.Rule) (Rule, error) {
+	minAmount, err := primitive.ParseDecimal128(rule.Conditions.MinAmount.String())
+	if err != nil {
+		return Rule{}, errors.ErrInvalidArgument.Wrap(err)
+	}
+
+	var id primitive.ObjectID
+	if rule.ID != "" {
+		id, err = primitive.ObjectIDFromHex(rule.ID)
+		if err != nil {
+			return Rule{}, errors.ErrInvalidArgument.Wrap(err)
+		}
+	}
+
+	return Rule{
+		ID:       id,
+		Name:     rule.Name,
+		RuleType: rule.RuleType,
+		Conditions: Conditions{
+			MinAmount:   minAmount,
+			BranchID:    rule.Conditions.BranchID,
+			CategoryIDs: rule.Conditions.CategoryID,
+
+			BranchIDs:    rule.Conditions.BranchIDs,
+			BranchGroups: rule.Conditions.BranchGroups,
+
+			ProductIDs:         rule.Conditions.ProductIDs,
+			ExcludeProductIDs:  rule.Conditions.ExcludeProductIDs,
+			ExcludeCategoryIDs: rule.Conditions.ExcludeCategoryIDs,
+
+			MinCategories:      rule.Conditions.MinCategories,
+			RequiredProductIDs: rule.Conditions.RequiredProductIDs,
+
+			VisitCount: rule.Conditions.VisitCount,
+			StreakDays: rule.Conditions.StreakDays,
+
+			Tiers:         rule.Conditions.Tiers,
+			SignupFrom:    rule.Conditions.SignupFrom,
+			SignupTo:      rule.Conditions.SignupTo,
+			BirthdayMonth: rule.Conditions.BirthdayMonth,
+			Segments:      rule.Conditions.Segments,
+			FirstPurchase: rule.Conditions.FirstPurchase,
+
+			Expression: rule.Conditions.Expression,
+		},
+		Reward: Reward{
+			Value:      rule.Reward.Value,
+			RatioUnit:  rule.Reward.RatioUnit,
+			Expression: rule.Reward.Expression,
 		},
+		Status:        rule.Status,
+		EffectiveFrom: rule.EffectiveFrom,
+		EffectiveTo:   rule.EffectiveTo,
+		Version:       rule.Version,
+		CreatedBy:     rule.CreatedBy,
+		CreatedAt:     rule.CreatedAt,
 	}, nil
 }
 
+func (r Rule) toVersion() RuleVersion {
+	return RuleVersion{
+		RuleID:        r.ID,
+		Version:       r.Version,
+		Name:          r.Name,
+		RuleType:      r.RuleType,
+		Conditions:    r.Conditions,
+		Reward:        r.Reward,
+		Status:        r.Status,
+		EffectiveFrom: r.EffectiveFrom,
+		EffectiveTo:   r.EffectiveTo,
+		CreatedBy:     r.CreatedBy,
+		CreatedAt:     r.CreatedAt,
+	}
+}
+
 type Rules []*Rule
 
 func (r Rules) ToDomain() []entity.Rule {
@@ -66,3 +229,40 @@ func (r Rules) ToDomain() []entity.Rule {
 	}
 	return rules
 }
+
+type RuleVersions []*RuleVersion
+
+func (v RuleVersions) ToDomain() []entity.Rule {
+	rules := make([]entity.Rule, 0, len(v))
+	for _, version := range v {
+		value, err := version.ToDomain()
+		if err != nil {
+			log.Println(errors.ErrInternal, err)
+			continue
+		}
+		rules = append(rules, *value)
+	}
+	return rules
+}
+
+type BranchGroup struct {
+	Name      string   `bson:"name"`
+	BranchIDs []string `bson:"branch_ids"`
+}
+
+func (g BranchGroup) ToDomain() entity.BranchGroup {
+	return entity.BranchGroup{
+		Name:      g.Name,
+		BranchIDs: g.BranchIDs,
+	}
+}
+
+type BranchGroups []BranchGroup
+
+func (g BranchGroups) ToDomain() entity.BranchGroups {
+	result := make(entity.BranchGroups, 0, len(g))
+	for _, group := range g {
+		result = append(result, group.ToDomain())
+	}
+	return result
+}
@@ -1,24 +1,99 @@
 package mongodb
 
 import (
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
 	"github.com/sirawong/point-accumulate-interview/internal/errors"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func filterActiveBranchIDWithCategoryIDs(branchIDWithCategoryIDs map[string][]string) (bson.M, error) {
+func filterActive() bson.M {
+	return bson.M{"status": entity.RuleStatusActive}
+}
+
+// filterActiveBranchIDWithCategoryIDs matches the active rules of each branch whose
+// category and product lists, when set, include something bought there. A rule
+// is a branch's when it names the branch or one of its groups, or names no
+// branch at all. The exclusion lists are left to the caller.
+func filterActiveBranchIDWithCategoryIDs(branchIDWithCategoryIDs, branchIDWithProductIDs, branchIDWithGroups map[string][]string) (bson.M, error) {
 	if len(branchIDWithCategoryIDs) == 0 {
 		return nil, errors.ErrInvalidArgument.WithMessage("branchIDWithCategoryIDs is empty")
 	}
 
 	filter := bson.A{}
 	for branchID, CategoryIDs := range branchIDWithCategoryIDs {
-		value := bson.M{"conditions.branch_id": branchID}
+		lists := bson.A{filterBranch(branchID, branchIDWithGroups[branchID])}
 		if len(CategoryIDs) > 0 {
-			value["conditions.category_ids"] = bson.M{"$in": CategoryIDs}
+			lists = append(lists, filterUnsetOrIn("conditions.category_ids", CategoryIDs))
 		}
+		if productIDs := branchIDWithProductIDs[branchID]; len(productIDs) > 0 {
+			lists = append(lists, filterUnsetOrIn("conditions.product_ids", productIDs))
+		}
+
+		filter = append(filter, bson.M{"$and": lists})
+	}
 
-		filter = append(filter, value)
+	return bson.M{"status": entity.RuleStatusActive, "$or": filter}, nil
+}
+
+func filterBranch(branchID string, groups []string) bson.M {
+	branches := bson.A{
+		bson.M{"conditions.branch_id": branchID},
+		bson.M{"conditions.branch_ids": branchID},
+		filterGlobal(),
+	}
+	if len(groups) > 0 {
+		branches = append(branches, bson.M{"conditions.branch_groups": bson.M{"$in": groups}})
 	}
+	return bson.M{"$or": branches}
+}
 
-	return bson.M{"status": "ACTIVE", "$or": filter}, nil
+// filterGlobal matches rules that name no branch. Rules written before branch
+// lists have an empty branch_id and no lists.
+func filterGlobal() bson.M {
+	return bson.M{
+		"conditions.branch_id":       bson.M{"$in": bson.A{"", nil}},
+		"conditions.branch_ids.0":    bson.M{"$exists": false},
+		"conditions.branch_groups.0": bson.M{"$exists": false},
+	}
+}
+
+// filterUnsetOrIn matches documents whose list at field is empty or missing, or
+// shares a value with values.
+func filterUnsetOrIn(field string, values []string) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{field: bson.M{"$in": values}},
+		bson.M{field + ".0": bson.M{"$exists": false}},
+	}}
+}
+
+// filterRuleVersion matches a rule only while it is still at version. Rules seeded
+// before versioning have no version field and are treated as version 0.
+func filterRuleVersion(id primitive.ObjectID, version int64) bson.M {
+	if version == 0 {
+		return bson.M{"_id": id, "version": bson.M{"$exists": false}}
+	}
+	return bson.M{"_id": id, "version": version}
+}
+
+func filterRuleID(ruleID primitive.ObjectID) bson.M {
+	return bson.M{"rule_id": ruleID}
+}
+
+func filterVersionOfRule(ruleID primitive.ObjectID, version int64) bson.M {
+	return bson.M{"rule_id": ruleID, "version": version}
+}
+
+// pipelineRuleVersionsAsOf picks the latest version of every rule created before the given time.
+func pipelineRuleVersionsAsOf(before time.Time) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": bson.M{"$lt": before}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "rule_id", Value: 1}, {Key: "version", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$rule_id", "doc": bson.M{"$first": "$$ROOT"}}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$doc"}}},
+		{{Key: "$sort", Value: bson.D{{Key: "rule_id", Value: 1}}}},
+	}
 }
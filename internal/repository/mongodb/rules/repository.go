@@ -2,34 +2,202 @@ package mongodb
 
 import (
 	"context"
+	stderrors "errors"
+	"time"
 
 	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
 	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
 	"github.com/sirawong/point-accumulate-interview/internal/errors"
+	database "github.com/sirawong/point-accumulate-interview/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
-	RuleCollection = "rules"
+	RuleCollection        = "rules"
+	RuleVersionCollection = "rule_versions"
+	BranchGroupCollection = "branch_groups"
 )
 
 type ruleRepository struct {
-	collection *mongo.Collection
+	collection   database.Collection
+	versions     database.Collection
+	branchGroups database.Collection
 }
 
 func NewRuleRepository(db *mongo.Database) repository.RuleRepository {
 	return &ruleRepository{
-		collection: db.Collection(RuleCollection),
+		collection:   database.NewCollection(db, RuleCollection),
+		versions:     database.NewCollection(db, RuleVersionCollection),
+		branchGroups: database.NewCollection(db, BranchGroupCollection),
 	}
 }
 
-func (r ruleRepository) GetActiveRules(ctx context.Context, branchIDWithCategoryIDs map[string][]string) ([]entity.Rule, error) {
-	filter, err := filterActiveBranchIDWithCategoryIDs(branchIDWithCategoryIDs)
+func (r ruleRepository) GetActiveRules(ctx context.Context, branchIDWithCategoryIDs, branchIDWithProductIDs, branchIDWithGroups map[string][]string) ([]entity.Rule, error) {
+	filter, err := filterActiveBranchIDWithCategoryIDs(branchIDWithCategoryIDs, branchIDWithProductIDs, branchIDWithGroups)
 	if err != nil {
 		return nil, err
 	}
 
-	cursor, err := r.collection.Find(ctx, filter)
+	return r.findRules(ctx, filter)
+}
+
+func (r ruleRepository) GetAllActiveRules(ctx context.Context) ([]entity.Rule, error) {
+	return r.findRules(ctx, filterActive())
+}
+
+func (r ruleRepository) GetRules(ctx context.Context) ([]entity.Rule, error) {
+	return r.findRules(ctx, bson.M{})
+}
+
+func (r ruleRepository) GetRule(ctx context.Context, id string) (*entity.Rule, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.ErrInvalidArgument.Wrap(err)
+	}
+
+	var rule Rule
+	err = r.collection.For(ctx).FindOne(ctx, bson.M{"_id": objectID}).Decode(&rule)
+	if stderrors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errors.ErrNotFound.WithMessage("rule not found")
+	}
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return rule.ToDomain()
+}
+
+// CreateRule stores rule as version 1. The version snapshot is written first so a
+// rule never exists without its history, and removed again when the rule cannot
+// be written.
+func (r ruleRepository) CreateRule(ctx context.Context, rule entity.Rule) (*entity.Rule, error) {
+	document, err := fromRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	document.ID = primitive.NewObjectID()
+	document.Version = 1
+
+	if _, err = r.versions.For(ctx).InsertOne(ctx, document.toVersion()); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	if _, err = r.collection.For(ctx).InsertOne(ctx, document); err != nil {
+		return nil, r.removeVersion(ctx, document, errors.ErrInternal.Wrap(err))
+	}
+
+	return document.ToDomain()
+}
+
+// UpdateRule writes rule as the version after previousVersion. The unique
+// (rule_id, version) index lets only one concurrent editor claim the next version.
+// The claimed version is given back when the rule itself cannot be replaced, so
+// a failed edit does not block every later one.
+func (r ruleRepository) UpdateRule(ctx context.Context, rule entity.Rule, previousVersion int64) (*entity.Rule, error) {
+	document, err := fromRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	document.Version = previousVersion + 1
+
+	if _, err = r.versions.For(ctx).InsertOne(ctx, document.toVersion()); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.ErrConflict.WithMessage("rule was changed by someone else")
+		}
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	result, err := r.collection.For(ctx).ReplaceOne(ctx, filterRuleVersion(document.ID, previousVersion), document)
+	if err != nil {
+		return nil, r.removeVersion(ctx, document, errors.ErrInternal.Wrap(err))
+	}
+	if result.MatchedCount == 0 {
+		return nil, r.removeVersion(ctx, document, errors.ErrConflict.WithMessage("rule was changed by someone else"))
+	}
+
+	return document.ToDomain()
+}
+
+// removeVersion deletes the version snapshot of document after writing the rule
+// failed with cause, and returns cause. The delete runs even when ctx has been
+// cancelled, since that is often why the write failed.
+func (r ruleRepository) removeVersion(ctx context.Context, document Rule, cause error) error {
+	ctx = context.WithoutCancel(ctx)
+	if _, err := r.versions.For(ctx).DeleteOne(ctx, filterVersionOfRule(document.ID, document.Version)); err != nil {
+		return errors.ErrInternal.Wrap(stderrors.Join(cause, err))
+	}
+	return cause
+}
+
+func (r ruleRepository) GetRuleVersions(ctx context.Context, id string) ([]entity.Rule, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.ErrInvalidArgument.Wrap(err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+	cursor, err := r.versions.For(ctx).Find(ctx, filterRuleID(objectID), opts)
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+	defer cursor.Close(ctx)
+
+	var versions RuleVersions
+	if err = cursor.All(ctx, &versions); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return versions.ToDomain(), nil
+}
+
+func (r ruleRepository) GetRulesAsOf(ctx context.Context, asOf time.Time) ([]entity.Rule, error) {
+	cursor, err := r.versions.For(ctx).Aggregate(ctx, pipelineRuleVersionsAsOf(asOf))
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+	defer cursor.Close(ctx)
+
+	var versions RuleVersions
+	if err = cursor.All(ctx, &versions); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return versions.ToDomain(), nil
+}
+
+func (r ruleRepository) GetBranchGroups(ctx context.Context) (entity.BranchGroups, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.branchGroups.For(ctx).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+	defer cursor.Close(ctx)
+
+	var groups BranchGroups
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	return groups.ToDomain(), nil
+}
+
+func (r ruleRepository) PutBranchGroup(ctx context.Context, group entity.BranchGroup) error {
+	document := BranchGroup{Name: group.Name, BranchIDs: group.BranchIDs}
+
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.branchGroups.For(ctx).ReplaceOne(ctx, bson.M{"name": group.Name}, document, opts)
+	if err != nil {
+		return errors.ErrInternal.Wrap(err)
+	}
+
+	return nil
+}
+
+func (r ruleRepository) findRules(ctx context.Context, filter bson.M) ([]entity.Rule, error) {
+	cursor, err := r.collection.For(ctx).Find(ctx, filter)
 	if err != nil {
 		return nil, errors.ErrInternal.Wrap(err)
 	}
@@ -0,0 +1,118 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+)
+
+type RuleRepositoryTestSuite struct {
+	suite.Suite
+	mt *mtest.T
+}
+
+func TestRuleRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(RuleRepositoryTestSuite))
+}
+
+func (suite *RuleRepositoryTestSuite) SetupTest() {
+	// The mock deployment answers with the queued responses, so no server is needed.
+	suite.mt = mtest.New(suite.T(), mtest.NewOptions().ClientType(mtest.Mock))
+}
+
+func (suite *RuleRepositoryTestSuite) rule() entity.Rule {
+	return entity.Rule{
+		ID:         primitive.NewObjectID().Hex(),
+		Name:       "FOOD",
+		RuleType:   entity.RatioRule,
+		Conditions: entity.Conditions{MinAmount: decimal.NewFromInt(100)},
+		Status:     entity.RuleStatusActive,
+		CreatedBy:  "alice",
+	}
+}
+
+// commands returns the name and collection of every command the repository sent.
+func (suite *RuleRepositoryTestSuite) commands(mt *mtest.T) [][2]string {
+	var commands [][2]string
+	for _, event := range mt.GetAllStartedEvents() {
+		commands = append(commands, [2]string{event.CommandName, event.Command.Lookup(event.CommandName).StringValue()})
+	}
+	return commands
+}
+
+func (suite *RuleRepositoryTestSuite) TestUpdateRule_LostRaceRemovesVersion() {
+	suite.mt.Run("lost race", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+		rule := suite.rule()
+
+		_, err := NewRuleRepository(mt.DB).UpdateRule(context.Background(), rule, 2)
+
+		suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+		suite.Equal([][2]string{
+			{"insert", RuleVersionCollection},
+			{"update", RuleCollection},
+			{"delete", RuleVersionCollection},
+		}, suite.commands(mt))
+
+		filter := mt.GetAllStartedEvents()[2].Command.Lookup("deletes", "0", "q").Document()
+		suite.Equal(rule.ID, filter.Lookup("rule_id").ObjectID().Hex())
+		suite.Equal(int64(3), filter.Lookup("version").Int64())
+	})
+}
+
+func (suite *RuleRepositoryTestSuite) TestUpdateRule_FailedReplaceRemovesVersion() {
+	suite.mt.Run("failed replace", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad value"}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+
+		_, err := NewRuleRepository(mt.DB).UpdateRule(context.Background(), suite.rule(), 2)
+
+		suite.Equal(apperr.ErrInternal.Code, apperr.GetCode(err))
+		suite.Equal([2]string{"delete", RuleVersionCollection}, suite.commands(mt)[2])
+	})
+}
+
+func (suite *RuleRepositoryTestSuite) TestUpdateRule_ClaimedVersionKept() {
+	suite.mt.Run("version taken", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}))
+
+		_, err := NewRuleRepository(mt.DB).UpdateRule(context.Background(), suite.rule(), 2)
+
+		suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+		suite.Equal([][2]string{{"insert", RuleVersionCollection}}, suite.commands(mt))
+	})
+}
+
+func (suite *RuleRepositoryTestSuite) TestCreateRule_FailedInsertRemovesVersion() {
+	suite.mt.Run("failed insert", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad value"}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+
+		_, err := NewRuleRepository(mt.DB).CreateRule(context.Background(), suite.rule())
+
+		suite.Equal(apperr.ErrInternal.Code, apperr.GetCode(err))
+		suite.Equal([][2]string{
+			{"insert", RuleVersionCollection},
+			{"insert", RuleCollection},
+			{"delete", RuleVersionCollection},
+		}, suite.commands(mt))
+	})
+}
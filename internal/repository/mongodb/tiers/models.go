@@ -0,0 +1,37 @@
+package mongodb
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Tier struct {
+	ID         primitive.ObjectID   `bson:"_id,omitempty"`
+	Name       string               `bson:"name"`
+	Rank       int                  `bson:"rank"`
+	Basis      entity.TierBasis     `bson:"basis"`
+	Threshold  primitive.Decimal128 `bson:"threshold"`
+	Multiplier primitive.Decimal128 `bson:"multiplier"`
+}
+
+func (t Tier) ToDomain() (entity.Tier, error) {
+	threshold, err := decimal.NewFromString(t.Threshold.String())
+	if err != nil {
+		return entity.Tier{}, errors.ErrInternal.Wrap(err)
+	}
+
+	multiplier, err := decimal.NewFromString(t.Multiplier.String())
+	if err != nil {
+		return entity.Tier{}, errors.ErrInternal.Wrap(err)
+	}
+
+	return entity.Tier{
+		Name:       t.Name,
+		Rank:       t.Rank,
+		Basis:      t.Basis,
+		Threshold:  threshold,
+		Multiplier: multiplier,
+	}, nil
+}
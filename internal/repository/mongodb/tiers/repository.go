@@ -0,0 +1,49 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	"github.com/sirawong/point-accumulate-interview/internal/errors"
+	database "github.com/sirawong/point-accumulate-interview/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const TierCollection = "tiers"
+
+type tierRepository struct {
+	collection database.Collection
+}
+
+func NewTierRepository(db *mongo.Database) repository.TierRepository {
+	return &tierRepository{
+		collection: database.NewCollection(db, TierCollection),
+	}
+}
+
+func (t tierRepository) GetTiers(ctx context.Context) (entity.Tiers, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "rank", Value: 1}})
+	cursor, err := t.collection.For(ctx).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	var tiers []Tier
+	if err = cursor.All(ctx, &tiers); err != nil {
+		return nil, errors.ErrInternal.Wrap(err)
+	}
+
+	result := make([]entity.Tier, 0, len(tiers))
+	for _, tier := range tiers {
+		value, err := tier.ToDomain()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+
+	return entity.SortTiers(result), nil
+}
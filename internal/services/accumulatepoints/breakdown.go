@@ -0,0 +1,132 @@
+package accumulatepoints
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+)
+
+func ParseBreakdown(s string) (entity.Breakdown, error) {
+	switch breakdown := entity.Breakdown(s); breakdown {
+	case entity.BreakdownBranch, entity.BreakdownCategory, entity.BreakdownRule, entity.BreakdownSource:
+		return breakdown, nil
+	default:
+		return "", apperr.ErrInvalidArgument.WithMessage("breakdown must be one of branch, category, rule or source")
+	}
+}
+
+type BranchBreakdownRecord struct {
+	BranchID        string          `csv:"branch_id"`
+	Points          int64           `csv:"points"`
+	Transactions    int64           `csv:"transactions"`
+	PurchasedAmount decimal.Decimal `csv:"purchased_amount"`
+}
+
+type CategoryBreakdownRecord struct {
+	CategoryID      string          `csv:"category_id"`
+	Points          int64           `csv:"points"`
+	Transactions    int64           `csv:"transactions"`
+	PurchasedAmount decimal.Decimal `csv:"purchased_amount"`
+}
+
+type RuleBreakdownRecord struct {
+	RuleID          string          `csv:"rule_id"`
+	RuleName        string          `csv:"rule_name"`
+	Points          int64           `csv:"points"`
+	Transactions    int64           `csv:"transactions"`
+	PurchasedAmount decimal.Decimal `csv:"purchased_amount"`
+}
+
+// SourceBreakdownRecord is one transaction type; points from purchases, returns
+// and manual adjustments are reported apart.
+type SourceBreakdownRecord struct {
+	Source          string          `csv:"source"`
+	Points          int64           `csv:"points"`
+	Transactions    int64           `csv:"transactions"`
+	PurchasedAmount decimal.Decimal `csv:"purchased_amount"`
+}
+
+// WriteBreakdown encodes the points earned between from and to, both inclusive,
+// grouped by branch, category, rule or source. The totals are computed by the database.
+func (a accumulatePointService) WriteBreakdown(ctx context.Context, breakdown entity.Breakdown, from, to time.Time, format report.Format, w io.Writer) error {
+	groups, err := a.customerRepo.GetBreakdown(ctx, breakdown, from, to)
+	if err != nil {
+		return err
+	}
+
+	var rows any
+	switch breakdown {
+	case entity.BreakdownBranch:
+		records := make([]BranchBreakdownRecord, 0, len(groups))
+		for _, group := range groups {
+			records = append(records, BranchBreakdownRecord{
+				BranchID:        group.Key,
+				Points:          group.Points,
+				Transactions:    group.Transactions,
+				PurchasedAmount: group.PurchasedAmount,
+			})
+		}
+		rows = records
+	case entity.BreakdownCategory:
+		records := make([]CategoryBreakdownRecord, 0, len(groups))
+		for _, group := range groups {
+			records = append(records, CategoryBreakdownRecord{
+				CategoryID:      group.Key,
+				Points:          group.Points,
+				Transactions:    group.Transactions,
+				PurchasedAmount: group.PurchasedAmount,
+			})
+		}
+		rows = records
+	case entity.BreakdownRule:
+		rules, err := a.ruleRepo.GetRules(ctx)
+		if err != nil {
+			return err
+		}
+
+		names := make(map[string]string, len(rules))
+		for _, rule := range rules {
+			names[rule.ID] = rule.Name
+		}
+
+		records := make([]RuleBreakdownRecord, 0, len(groups))
+		for _, group := range groups {
+			records = append(records, RuleBreakdownRecord{
+				RuleID:          group.Key,
+				RuleName:        names[group.Key],
+				Points:          group.Points,
+				Transactions:    group.Transactions,
+				PurchasedAmount: group.PurchasedAmount,
+			})
+		}
+		rows = records
+	case entity.BreakdownSource:
+		records := make([]SourceBreakdownRecord, 0, len(groups))
+		for _, group := range groups {
+			source := group.Key
+			if source == string(entity.PurchaseTransaction) {
+				source = "PURCHASE"
+			}
+			records = append(records, SourceBreakdownRecord{
+				Source:          source,
+				Points:          group.Points,
+				Transactions:    group.Transactions,
+				PurchasedAmount: group.PurchasedAmount,
+			})
+		}
+		rows = records
+	default:
+		return apperr.ErrInvalidArgument.WithMessage("unknown breakdown")
+	}
+
+	if err = report.Encode(format, w, rows); err != nil {
+		return apperr.ErrInternal.Wrap(err)
+	}
+
+	return nil
+}
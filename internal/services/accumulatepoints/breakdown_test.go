@@ -0,0 +1,114 @@
+package accumulatepoints
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_customer"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_rule"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+	"github.com/sirawong/point-accumulate-interview/pkg/storage"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type BreakdownTestSuite struct {
+	suite.Suite
+	mockCtrl     *gomock.Controller
+	mockRuleRepo *mocks_rule.MockRuleRepository
+	mockCustRepo *mocks_customer.MockCustomerRepository
+	service      AccumulatePointService
+}
+
+func TestBreakdownTestSuite(t *testing.T) {
+	suite.Run(t, new(BreakdownTestSuite))
+}
+
+func (suite *BreakdownTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRuleRepo = mocks_rule.NewMockRuleRepository(suite.mockCtrl)
+	suite.mockCustRepo = mocks_customer.NewMockCustomerRepository(suite.mockCtrl)
+	suite.service = NewAccumulatePointService(suite.mockRuleRepo, suite.mockCustRepo, nil, storage.NewMemory(), &config.Config{})
+}
+
+func (suite *BreakdownTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *BreakdownTestSuite) write(breakdown entity.Breakdown, totals []entity.BreakdownTotal) string {
+	ctx := context.Background()
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	suite.mockCustRepo.EXPECT().
+		GetBreakdown(ctx, breakdown, from, to).
+		Return(totals, nil)
+
+	var buf strings.Builder
+	suite.NoError(suite.service.WriteBreakdown(ctx, breakdown, from, to, report.FormatCSV, &buf))
+	return buf.String()
+}
+
+func (suite *BreakdownTestSuite) TestWriteBreakdown_Branch() {
+	totals := []entity.BreakdownTotal{
+		{Key: "BR1", Points: 25, Transactions: 2, PurchasedAmount: decimal.NewFromInt(200)},
+		{Key: "BR2", Points: 10, Transactions: 1, PurchasedAmount: decimal.NewFromInt(40)},
+	}
+
+	suite.Equal("branch_id,points,transactions,purchased_amount\n"+
+		"BR1,25,2,200\n"+
+		"BR2,10,1,40\n", suite.write(entity.BreakdownBranch, totals))
+}
+
+func (suite *BreakdownTestSuite) TestWriteBreakdown_Category() {
+	totals := []entity.BreakdownTotal{
+		{Key: "CT1", Points: 25, Transactions: 2, PurchasedAmount: decimal.RequireFromString("200.50")},
+	}
+
+	suite.Equal("category_id,points,transactions,purchased_amount\n"+
+		"CT1,25,2,200.5\n", suite.write(entity.BreakdownCategory, totals))
+}
+
+func (suite *BreakdownTestSuite) TestWriteBreakdown_Rule() {
+	suite.mockRuleRepo.EXPECT().
+		GetRules(gomock.Any()).
+		Return([]entity.Rule{{ID: "R1", Name: "Base points"}, {ID: "R2", Name: "Branch promotion"}}, nil)
+
+	totals := []entity.BreakdownTotal{
+		{Key: "R1", Points: 30, Transactions: 3, PurchasedAmount: decimal.NewFromInt(240)},
+		{Key: "R2", Points: 5, Transactions: 1, PurchasedAmount: decimal.NewFromInt(100)},
+		{Key: "R9", Points: 1, Transactions: 1, PurchasedAmount: decimal.NewFromInt(10)},
+	}
+
+	suite.Equal("rule_id,rule_name,points,transactions,purchased_amount\n"+
+		"R1,Base points,30,3,240\n"+
+		"R2,Branch promotion,5,1,100\n"+
+		"R9,,1,1,10\n", suite.write(entity.BreakdownRule, totals))
+}
+
+func (suite *BreakdownTestSuite) TestWriteBreakdown_Source() {
+	totals := []entity.BreakdownTotal{
+		{Key: "", Points: 40, Transactions: 4, PurchasedAmount: decimal.NewFromInt(400)},
+		{Key: "ADJUSTMENT", Points: 500, Transactions: 1, PurchasedAmount: decimal.Zero},
+		{Key: "RETURN", Points: -5, Transactions: 1, PurchasedAmount: decimal.NewFromInt(-50)},
+	}
+
+	suite.Equal("source,points,transactions,purchased_amount\n"+
+		"PURCHASE,40,4,400\n"+
+		"ADJUSTMENT,500,1,0\n"+
+		"RETURN,-5,1,-50\n", suite.write(entity.BreakdownSource, totals))
+}
+
+func (suite *BreakdownTestSuite) TestParseBreakdown() {
+	breakdown, err := ParseBreakdown("category")
+	suite.NoError(err)
+	suite.Equal(entity.BreakdownCategory, breakdown)
+
+	_, err = ParseBreakdown("product")
+	suite.Error(err)
+}
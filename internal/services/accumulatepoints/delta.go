@@ -0,0 +1,149 @@
+package accumulatepoints
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+)
+
+// rulesAppliedSeparator joins rule IDs in the rules_applied column.
+const rulesAppliedSeparator = ";"
+
+// CustomerDeltaRecord is one customer's activity on a single day. Ranks are
+// positions in the point summary; PreviousRank is 0 for customers who had no
+// points before the day, and RankChange is positive when the customer moved up.
+type CustomerDeltaRecord struct {
+	CustomerID   string `csv:"customer_id"`
+	PointsEarned int64  `csv:"points_earned"`
+	Purchases    int64  `csv:"purchases"`
+	RulesApplied string `csv:"rules_applied"`
+	Rank         int64  `csv:"rank"`
+	PreviousRank int64  `csv:"previous_rank"`
+	RankChange   int64  `csv:"rank_change"`
+}
+
+// errRanksFound stops the summary stream once every wanted rank is known.
+var errRanksFound = errors.New("ranks found")
+
+// WriteDelta encodes what each customer earned on date and how their rank moved.
+// Only the customers active on date are loaded; their ranks come from the
+// streamed summaries of date and the day before.
+func (a accumulatePointService) WriteDelta(ctx context.Context, date time.Time, format report.Format, w io.Writer) error {
+	customers, err := a.customerRepo.GetCustomersActiveOn(ctx, date)
+	if err != nil {
+		return err
+	}
+
+	customerIDs := make([]string, 0, len(customers))
+	for _, customer := range customers {
+		customerIDs = append(customerIDs, customer.CustomerID)
+	}
+
+	ranks, err := a.ranksOf(ctx, date.Format(time.DateOnly), customerIDs)
+	if err != nil {
+		return err
+	}
+
+	previousRanks, err := a.ranksOf(ctx, date.AddDate(0, 0, -1).Format(time.DateOnly), customerIDs)
+	if err != nil {
+		return err
+	}
+
+	if err = report.Encode(format, w, deltaRecords(customers, date, ranks, previousRanks)); err != nil {
+		return apperr.ErrInternal.Wrap(err)
+	}
+
+	return nil
+}
+
+// ranksOf returns the 1-based positions of customerIDs in the summary for dateString.
+// Customers without points by then have no rank.
+func (a accumulatePointService) ranksOf(ctx context.Context, dateString string, customerIDs []string) (map[string]int64, error) {
+	ranks := make(map[string]int64, len(customerIDs))
+	if len(customerIDs) == 0 {
+		return ranks, nil
+	}
+
+	wanted := make(map[string]struct{}, len(customerIDs))
+	for _, customerID := range customerIDs {
+		wanted[customerID] = struct{}{}
+	}
+
+	var rank int64
+	err := a.streamSummary(ctx, dateString, nil, nil, func(record *CustomerPointRecord) error {
+		rank++
+		if _, ok := wanted[record.CustomerID]; ok {
+			ranks[record.CustomerID] = rank
+			if len(ranks) == len(wanted) {
+				return errRanksFound
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRanksFound) {
+		return nil, err
+	}
+
+	return ranks, nil
+}
+
+func deltaRecords(customers []entity.Customer, date time.Time, ranks, previousRanks map[string]int64) []*CustomerDeltaRecord {
+	dateString := date.Format(time.DateOnly)
+
+	result := make([]*CustomerDeltaRecord, 0)
+	for _, customer := range customers {
+		earned, ok := customer.PointsByDate[dateString]
+		if !ok {
+			continue
+		}
+
+		purchases := make(map[historyKey]struct{})
+		rules := make(map[string]struct{})
+		for _, record := range customer.Records {
+			if !record.PurchaseDate.Equal(date) {
+				continue
+			}
+			if isReplayed(record) {
+				purchases[newHistoryKey(record)] = struct{}{}
+			}
+			if record.RuleID != "" {
+				rules[record.RuleID] = struct{}{}
+			}
+		}
+
+		ruleIDs := make([]string, 0, len(rules))
+		for ruleID := range rules {
+			ruleIDs = append(ruleIDs, ruleID)
+		}
+		sort.Strings(ruleIDs)
+
+		delta := &CustomerDeltaRecord{
+			CustomerID:   customer.CustomerID,
+			PointsEarned: earned,
+			Purchases:    int64(len(purchases)),
+			RulesApplied: strings.Join(ruleIDs, rulesAppliedSeparator),
+			Rank:         ranks[customer.CustomerID],
+			PreviousRank: previousRanks[customer.CustomerID],
+		}
+		if delta.PreviousRank > 0 {
+			delta.RankChange = delta.PreviousRank - delta.Rank
+		}
+		result = append(result, delta)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].PointsEarned != result[j].PointsEarned {
+			return result[i].PointsEarned > result[j].PointsEarned
+		}
+		return result[i].CustomerID < result[j].CustomerID
+	})
+
+	return result
+}
@@ -0,0 +1,110 @@
+package accumulatepoints
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_customer"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+	"github.com/sirawong/point-accumulate-interview/pkg/storage"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type DeltaTestSuite struct {
+	suite.Suite
+	mockCtrl     *gomock.Controller
+	mockCustRepo *mocks_customer.MockCustomerRepository
+	service      AccumulatePointService
+}
+
+func TestDeltaTestSuite(t *testing.T) {
+	suite.Run(t, new(DeltaTestSuite))
+}
+
+func (suite *DeltaTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockCustRepo = mocks_customer.NewMockCustomerRepository(suite.mockCtrl)
+	suite.service = NewAccumulatePointService(nil, suite.mockCustRepo, nil, storage.NewMemory(), &config.Config{})
+}
+
+func (suite *DeltaTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *DeltaTestSuite) TestWriteDelta() {
+	ctx := context.Background()
+	jan15 := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	active := []entity.Customer{
+		{
+			CustomerID:   "U000002",
+			PointsByDate: map[string]int64{"2025-01-15": 40},
+			Records: []entity.Record{
+				{ProductID: "P1", BranchID: "BR1", Amount: decimal.NewFromInt(100), PurchaseDate: jan15, RuleID: "R1", Points: 40},
+			},
+		},
+	}
+	all := []entity.Customer{
+		{CustomerID: "U000001", PointsByDate: map[string]int64{"2025-01-14": 50}},
+		{CustomerID: "U000002", PointsByDate: map[string]int64{"2025-01-14": 20, "2025-01-15": 40}},
+		{CustomerID: "U000003", PointsByDate: map[string]int64{"2025-01-14": 1}},
+	}
+
+	suite.mockCustRepo.EXPECT().GetCustomersActiveOn(ctx, jan15).Return(active, nil)
+	suite.mockCustRepo.EXPECT().
+		StreamPointSummaries(ctx, "2025-01-15", gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries(all))
+	suite.mockCustRepo.EXPECT().
+		StreamPointSummaries(ctx, "2025-01-14", gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries(all))
+
+	var buf strings.Builder
+	err := suite.service.WriteDelta(ctx, jan15, report.FormatCSV, &buf)
+
+	suite.NoError(err)
+	suite.Equal("customer_id,points_earned,purchases,rules_applied,rank,previous_rank,rank_change\n"+
+		"U000002,40,1,R1,1,2,1\n", buf.String())
+}
+
+func (suite *DeltaTestSuite) TestDeltaRecords() {
+	jan15 := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	customers := []entity.Customer{
+		{
+			CustomerID:   "U000001",
+			PointsByDate: map[string]int64{"2025-01-14": 50},
+		},
+		{
+			CustomerID:   "U000002",
+			PointsByDate: map[string]int64{"2025-01-14": 20, "2025-01-15": 40},
+			Records: []entity.Record{
+				{ProductID: "P1", BranchID: "BR1", Amount: decimal.NewFromInt(100), PurchaseDate: jan15, RuleID: "R2", Points: 30},
+				{ProductID: "P1", BranchID: "BR1", Amount: decimal.NewFromInt(100), PurchaseDate: jan15, RuleID: "R1", Points: 5},
+				{ProductID: "P2", BranchID: "BR1", Amount: decimal.NewFromInt(10), PurchaseDate: jan15, RuleID: "R1", Points: 5},
+				{ProductID: "P3", BranchID: "BR1", Amount: decimal.NewFromInt(10), PurchaseDate: jan15.AddDate(0, 0, -1), RuleID: "R3", Points: 20},
+			},
+		},
+		{
+			CustomerID:   "U000003",
+			PointsByDate: map[string]int64{"2025-01-15": 5},
+			Records: []entity.Record{
+				{ProductID: "P1", BranchID: "BR2", Amount: decimal.NewFromInt(50), PurchaseDate: jan15, RuleID: "R1", Points: 5},
+			},
+		},
+	}
+
+	ranks := map[string]int64{"U000002": 1, "U000003": 3}
+	previousRanks := map[string]int64{"U000002": 2}
+
+	records := deltaRecords(customers, jan15, ranks, previousRanks)
+
+	suite.Equal([]*CustomerDeltaRecord{
+		{CustomerID: "U000002", PointsEarned: 40, Purchases: 2, RulesApplied: "R1;R2", Rank: 1, PreviousRank: 2, RankChange: 1},
+		{CustomerID: "U000003", PointsEarned: 5, Purchases: 1, RulesApplied: "R1", Rank: 3, PreviousRank: 0, RankChange: 0},
+	}, records)
+}
@@ -0,0 +1,134 @@
+package accumulatepoints
+
+import (
+	"slices"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+)
+
+// newPurchaseHistory returns every purchase known for each customer in date
+// order: the stored purchases and the new ones in purchases. Returns are not
+// visits. Engagement rules look
+// at all of it rather than at what the batch has reached, so a day's award does
+// not depend on the order days arrive in.
+func newPurchaseHistory(purchases PurchaseRecords, customers entity.Customers) map[string]PurchaseRecords {
+	history := make(map[string]PurchaseRecords, len(customers))
+	for _, customer := range customers {
+		stored, _ := historyToPurchaseRecords(customer.CustomerID, uniqueHistoryRecords(customer.Records)).splitReturns()
+		history[customer.CustomerID] = stored
+	}
+
+	for _, record := range purchases {
+		if isStoredPurchase(*record, customers) {
+			continue
+		}
+		history[record.CustomerID] = append(history[record.CustomerID], record)
+	}
+
+	for customerID, records := range history {
+		history[customerID] = records.sortedByDate()
+	}
+
+	return history
+}
+
+// calculateEngagementPoints returns what an engagement rule awards a basket and the
+// line the movement is stored on. A visit is a day with a purchase in the rule's
+// scope, and a NEW_BRANCH visit is also per branch. The award goes to the first
+// such purchase of the visit, so it is made once however many baskets the visit
+// has, and never again for a visit that was already stored.
+func calculateEngagementPoints(rule entity.Rule, basket PurchaseRecords, history PurchaseRecords, purchaser entity.Purchaser) (points int64, line *PurchaseRecord, applied bool) {
+	if !rule.RuleType.IsEngagement() {
+		return 0, nil, false
+	}
+
+	date := basket[0].PurchaseDate
+	branchID := basket[0].BranchID
+
+	for _, record := range history {
+		if !record.PurchaseDate.Equal(date) || !inScope(rule, *record) {
+			continue
+		}
+		if rule.RuleType == entity.NewBranchRule && record.BranchID != branchID {
+			continue
+		}
+		line = record
+		break
+	}
+
+	if line == nil || !slices.Contains(basket, line) || !matchesLine(rule, *line, purchaser) {
+		return 0, nil, false
+	}
+
+	switch rule.RuleType {
+	case entity.VisitCountRule:
+		if monthVisits(rule, history, date) != rule.Conditions.VisitCount {
+			return 0, nil, false
+		}
+
+	case entity.StreakRule:
+		if streakDays(rule, history, date) != rule.Conditions.StreakDays {
+			return 0, nil, false
+		}
+
+	case entity.NewBranchRule:
+		if !isNewBranch(history, branchID, date) {
+			return 0, nil, false
+		}
+	}
+
+	return rule.Reward.Value, line, true
+}
+
+// monthVisits counts the visit days in date's calendar month up to and including date.
+func monthVisits(rule entity.Rule, history PurchaseRecords, date time.Time) int {
+	monthStart := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+
+	return len(visitDays(rule, history, func(day time.Time) bool {
+		return !day.Before(monthStart) && !day.After(date)
+	}))
+}
+
+// streakDays returns how many consecutive visit days end on date.
+func streakDays(rule entity.Rule, history PurchaseRecords, date time.Time) int {
+	days := visitDays(rule, history, func(day time.Time) bool { return !day.After(date) })
+
+	streak := 0
+	for day := date; ; day = day.AddDate(0, 0, -1) {
+		if _, ok := days[day.Format(time.DateOnly)]; !ok {
+			return streak
+		}
+		streak++
+	}
+}
+
+// isNewBranch reports whether date is the customer's first purchase at branchID
+// and they had purchased somewhere else before. A customer's first purchase ever
+// is left to first_purchase rules.
+func isNewBranch(history PurchaseRecords, branchID string, date time.Time) bool {
+	purchasedBefore := false
+	for _, record := range history {
+		if !record.PurchaseDate.Before(date) {
+			break
+		}
+		if record.BranchID == branchID {
+			return false
+		}
+		purchasedBefore = true
+	}
+
+	return purchasedBefore
+}
+
+// visitDays returns the days, as YYYY-MM-DD, with a purchase in the rule's scope
+// that include accepts.
+func visitDays(rule entity.Rule, history PurchaseRecords, include func(day time.Time) bool) map[string]struct{} {
+	days := make(map[string]struct{})
+	for _, record := range history {
+		if include(record.PurchaseDate) && inScope(rule, *record) {
+			days[record.PurchaseDate.Format(time.DateOnly)] = struct{}{}
+		}
+	}
+	return days
+}
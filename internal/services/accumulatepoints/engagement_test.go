@@ -0,0 +1,160 @@
+package accumulatepoints
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+type EngagementTestSuite struct {
+	suite.Suite
+}
+
+func TestEngagementTestSuite(t *testing.T) {
+	suite.Run(t, new(EngagementTestSuite))
+}
+
+func january(d int) time.Time {
+	return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+func purchaseOn(customerID, branchID, productID string, date time.Time) *PurchaseRecord {
+	return &PurchaseRecord{
+		CustomerID:      customerID,
+		ProductID:       productID,
+		CategoryID:      "CT1",
+		BranchID:        branchID,
+		PurchasedAmount: decimal.NewFromInt(100),
+		PurchaseDate:    date,
+	}
+}
+
+func storedOn(branchID, productID string, date time.Time) entity.Record {
+	return entity.Record{
+		ProductID:    productID,
+		CategoryID:   "CT1",
+		BranchID:     branchID,
+		Amount:       decimal.NewFromInt(100),
+		PurchaseDate: date,
+		RuleID:       "BASE",
+		Points:       1,
+	}
+}
+
+// awards returns the movements as "rule date product" in date order.
+func awards(updates []entity.UpdateCustomer) []string {
+	result := make([]string, 0)
+	for _, update := range updates {
+		for _, record := range update.Records {
+			result = append(result, record.RuleID+" "+record.PurchaseDate.Format(time.DateOnly)+" "+record.ProductID)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+func (suite *EngagementTestSuite) TestVisitCount() {
+	rules := []entity.Rule{
+		{ID: "THIRD", RuleType: entity.VisitCountRule, Conditions: entity.Conditions{VisitCount: 3}, Reward: entity.Reward{Value: 20}},
+	}
+	customers := entity.Customers{
+		{CustomerID: "U000001", Records: []entity.Record{storedOn("BR1", "P0", january(3)), storedOn("BR1", "P0", january(8))}},
+	}
+	// The third visit has baskets at two branches and arrives after the fourth.
+	records := PurchaseRecords{
+		purchaseOn("U000001", "BR1", "P3", january(12)),
+		purchaseOn("U000001", "BR2", "P2", january(10)),
+		purchaseOn("U000001", "BR1", "P1", january(10)),
+	}
+
+	result := calculateBatchPoints(rules, nil, records, customers)
+
+	suite.Equal([]string{"THIRD 2025-01-10 P2"}, awards(result))
+	suite.Equal(int64(20), result[0].PointsToAdd)
+}
+
+func (suite *EngagementTestSuite) TestVisitCount_CountsOnlyScope() {
+	rules := []entity.Rule{
+		{ID: "SECOND", RuleType: entity.VisitCountRule, Conditions: entity.Conditions{BranchID: "BR1", VisitCount: 2}, Reward: entity.Reward{Value: 20}},
+	}
+	records := PurchaseRecords{
+		purchaseOn("U000001", "BR2", "P1", january(2)),
+		purchaseOn("U000001", "BR1", "P2", january(3)),
+		purchaseOn("U000001", "BR1", "P3", january(4)),
+		// A new month starts the count again.
+		purchaseOn("U000001", "BR1", "P4", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)),
+	}
+
+	result := calculateBatchPoints(rules, nil, records, nil)
+
+	suite.Equal([]string{"SECOND 2025-01-04 P3"}, awards(result))
+}
+
+func (suite *EngagementTestSuite) TestStreak() {
+	rules := []entity.Rule{
+		{ID: "STREAK", RuleType: entity.StreakRule, Conditions: entity.Conditions{StreakDays: 3}, Reward: entity.Reward{Value: 30}},
+	}
+	customers := entity.Customers{
+		{CustomerID: "U000001", Records: []entity.Record{storedOn("BR1", "P0", january(1))}},
+	}
+	records := PurchaseRecords{
+		purchaseOn("U000001", "BR1", "P4", january(4)),
+		purchaseOn("U000001", "BR1", "P2", january(2)),
+		purchaseOn("U000001", "BR1", "P3", january(3)),
+		purchaseOn("U000002", "BR1", "P1", january(1)),
+		purchaseOn("U000002", "BR1", "P3", january(3)),
+		purchaseOn("U000002", "BR1", "P4", january(4)),
+	}
+
+	result := calculateBatchPoints(rules, nil, records, customers)
+
+	// U000001's streak reaches three days on the 3rd; U000002 missed the 2nd.
+	suite.Equal([]string{"STREAK 2025-01-03 P3"}, awards(result))
+
+	reversed := slices.Clone(records)
+	slices.Reverse(reversed)
+	suite.Equal(awards(result), awards(calculateBatchPoints(rules, nil, reversed, customers)))
+}
+
+func (suite *EngagementTestSuite) TestNewBranch() {
+	rules := []entity.Rule{
+		{ID: "NEW", RuleType: entity.NewBranchRule, Reward: entity.Reward{Value: 10}},
+	}
+	customers := entity.Customers{
+		{CustomerID: "U000001", Records: []entity.Record{storedOn("BR1", "P0", january(1))}},
+	}
+	records := PurchaseRecords{
+		purchaseOn("U000001", "BR1", "P1", january(2)),
+		purchaseOn("U000001", "BR2", "P2", january(2)),
+		purchaseOn("U000001", "BR2", "P3", january(2)),
+		purchaseOn("U000001", "BR2", "P4", january(3)),
+		// A first purchase ever is not a new branch.
+		purchaseOn("U000002", "BR3", "P1", january(2)),
+	}
+
+	result := calculateBatchPoints(rules, nil, records, customers)
+
+	suite.Equal([]string{"NEW 2025-01-02 P2"}, awards(result))
+}
+
+func (suite *EngagementTestSuite) TestStoredVisitIsNotAwardedAgain() {
+	rules := []entity.Rule{
+		{ID: "SECOND", RuleType: entity.VisitCountRule, Conditions: entity.Conditions{VisitCount: 2}, Reward: entity.Reward{Value: 20}},
+	}
+	customers := entity.Customers{
+		{CustomerID: "U000001", Records: []entity.Record{storedOn("BR1", "P0", january(1)), storedOn("BR1", "P1", january(2))}},
+	}
+	// The 2nd is uploaded again with another purchase the same day.
+	records := PurchaseRecords{
+		purchaseOn("U000001", "BR1", "P1", january(2)),
+		purchaseOn("U000001", "BR1", "P2", january(2)),
+	}
+
+	result := calculateBatchPoints(rules, nil, records, customers)
+
+	suite.Empty(awards(result))
+}
@@ -0,0 +1,70 @@
+package accumulatepoints
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+)
+
+// householdIDs returns the households the customers pool into.
+func householdIDs(customers entity.Customers) []string {
+	ids := make([]string, 0)
+	for _, customer := range customers {
+		if customer.HouseholdID != "" && !slices.Contains(ids, customer.HouseholdID) {
+			ids = append(ids, customer.HouseholdID)
+		}
+	}
+	return ids
+}
+
+// poolHouseholdPoints moves what household members earned, or had taken back, into
+// their households' balances. Each day a member's points changed gets a pool
+// movement on the member and a matching one on the household, so a member's
+// balance is left as it was and both histories show where the points went.
+func poolHouseholdPoints(updates []entity.UpdateCustomer, customers entity.Customers) []entity.UpdateCustomer {
+	recordsSetup := make(map[string]entity.UpdateCustomer, len(updates))
+	order := make([]string, 0, len(updates))
+	for _, update := range updates {
+		update.PointsByDate = maps.Clone(update.PointsByDate)
+		update.Records = slices.Clone(update.Records)
+		recordsSetup[update.CustomerID] = update
+		order = append(order, update.CustomerID)
+	}
+
+	households := make([]string, 0)
+	for _, update := range updates {
+		customer, _ := customers.GetCustomerByID(update.CustomerID)
+		if customer.HouseholdID == "" {
+			continue
+		}
+
+		for _, date := range slices.Sorted(maps.Keys(update.PointsByDate)) {
+			points := update.PointsByDate[date]
+			if points == 0 {
+				continue
+			}
+			day, _ := time.Parse(time.DateOnly, date)
+
+			out := entity.Record{PurchaseDate: day, Points: -points, Type: entity.PoolTransaction, CounterpartyID: customer.HouseholdID}
+			in := out
+			in.Points = points
+			in.CounterpartyID = customer.CustomerID
+
+			if _, ok := recordsSetup[customer.HouseholdID]; !ok {
+				households = append(households, customer.HouseholdID)
+			}
+			addRecord(recordsSetup, customer.CustomerID, out)
+			addRecord(recordsSetup, customer.HouseholdID, in)
+		}
+	}
+
+	slices.Sort(households)
+	result := make([]entity.UpdateCustomer, 0, len(recordsSetup))
+	for _, customerID := range append(order, households...) {
+		result = append(result, recordsSetup[customerID])
+	}
+
+	return result
+}
@@ -0,0 +1,80 @@
+package accumulatepoints
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+type HouseholdsTestSuite struct {
+	suite.Suite
+	rules []entity.Rule
+}
+
+func TestHouseholdsTestSuite(t *testing.T) {
+	suite.Run(t, new(HouseholdsTestSuite))
+}
+
+func (suite *HouseholdsTestSuite) SetupTest() {
+	suite.rules = []entity.Rule{{ID: "FIXED", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 10}}}
+}
+
+func (suite *HouseholdsTestSuite) TestPoolHouseholdPoints() {
+	jan10, jan12 := january(10), january(12)
+	customers := entity.Customers{{CustomerID: "U000001", HouseholdID: "HH0001"}}
+	records := PurchaseRecords{
+		{CustomerID: "U000001", ProductID: "P1", CategoryID: "CT1", BranchID: "BR1", PurchasedAmount: decimal.NewFromInt(100), PurchaseDate: jan10},
+		{CustomerID: "U000001", ProductID: "P2", CategoryID: "CT1", BranchID: "BR1", PurchasedAmount: decimal.NewFromInt(100), PurchaseDate: jan12},
+	}
+
+	earned := calculateBatchPoints(suite.rules, nil, records, customers)
+	pooled := poolHouseholdPoints(earned, customers)
+
+	suite.Require().Len(pooled, 2)
+	member, household := pooled[0], pooled[1]
+	suite.Equal(int64(0), member.PointsToAdd)
+	suite.Equal(map[string]int64{"2025-01-10": 0, "2025-01-12": 0}, member.PointsByDate)
+	suite.Equal("HH0001", household.CustomerID)
+	suite.Equal(int64(20), household.PointsToAdd)
+	suite.Equal(map[string]int64{"2025-01-10": 10, "2025-01-12": 10}, household.PointsByDate)
+	suite.Equal("U000001", household.Records[0].CounterpartyID)
+	suite.True(household.LastPurchaseDate.IsZero())
+
+	// The earned update is left as it was.
+	suite.Equal(int64(20), earned[0].PointsToAdd)
+}
+
+func (suite *HouseholdsTestSuite) TestPooledPointsDoNotQualifyForTiers() {
+	customers := entity.Customers{{CustomerID: "U000001", HouseholdID: "HH0001"}}
+	records := PurchaseRecords{
+		{CustomerID: "U000001", ProductID: "P1", CategoryID: "CT1", BranchID: "BR1", PurchasedAmount: decimal.NewFromInt(100), PurchaseDate: january(10)},
+	}
+
+	pooled := poolHouseholdPoints(calculateBatchPoints(suite.rules, nil, records, customers), customers)
+	balances := applyCustomerUpdates(customers, pooled)
+
+	memberPoints, memberSpend := balances[0].QualifyingTotals(january(1), january(31))
+	suite.Equal(int64(10), memberPoints)
+	suite.True(decimal.NewFromInt(100).Equal(memberSpend))
+
+	householdPoints, _ := balances[1].QualifyingTotals(january(1), january(31))
+	suite.Equal(int64(0), householdPoints)
+	suite.Equal(int64(10), balances[1].Points)
+}
+
+func (suite *HouseholdsTestSuite) TestRecomputeKeepsPoolMovements() {
+	customers := entity.Customers{{CustomerID: "U000001", HouseholdID: "HH0001"}}
+	records := PurchaseRecords{
+		{CustomerID: "U000001", ProductID: "P1", CategoryID: "CT1", BranchID: "BR1", PurchasedAmount: decimal.NewFromInt(100), PurchaseDate: january(10)},
+	}
+	balances := applyCustomerUpdates(customers, poolHouseholdPoints(calculateBatchPoints(suite.rules, nil, records, customers), customers))
+
+	for _, customer := range balances {
+		recomputed := recomputeCustomer(suite.rules, nil, customer, january(20))
+		suite.Equal(customer.Points, recomputed.Points, customer.CustomerID)
+		suite.Equal(customer.PointsByDate, recomputed.PointsByDate, customer.CustomerID)
+		suite.Equal(customer.HouseholdID, recomputed.HouseholdID)
+	}
+}
@@ -0,0 +1,73 @@
+package accumulatepoints
+
+import (
+	"context"
+	"slices"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+)
+
+// loadMergedInto adds the accounts that merged customers were merged into,
+// following merges of merged accounts, to customers.
+func (a accumulatePointService) loadMergedInto(ctx context.Context, customers entity.Customers) (entity.Customers, error) {
+	for {
+		missing := make([]string, 0)
+		for _, customer := range customers {
+			if customer.MergedInto == "" || slices.Contains(missing, customer.MergedInto) {
+				continue
+			}
+			if _, found := customers.GetCustomerByID(customer.MergedInto); !found {
+				missing = append(missing, customer.MergedInto)
+			}
+		}
+		if len(missing) == 0 {
+			return customers, nil
+		}
+
+		loaded, err := a.customerRepo.GetCustomers(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		if len(loaded) == 0 {
+			return customers, nil
+		}
+		customers = append(customers, loaded...)
+	}
+}
+
+// mergedIDs maps the ID of every merged customer to the account that now holds
+// their points.
+func mergedIDs(customers entity.Customers) map[string]string {
+	mergedInto := make(map[string]string)
+	for _, customer := range customers {
+		if customer.MergedInto != "" {
+			mergedInto[customer.CustomerID] = customer.MergedInto
+		}
+	}
+
+	result := make(map[string]string, len(mergedInto))
+	for id := range mergedInto {
+		surviving := id
+		// Bounded by the number of merged customers in case the merges form a loop.
+		for range len(mergedInto) {
+			next, ok := mergedInto[surviving]
+			if !ok {
+				break
+			}
+			surviving = next
+		}
+		result[id] = surviving
+	}
+	return result
+}
+
+// withMergedIDs credits purchases made under a merged customer's ID to the account
+// it was merged into.
+func (records PurchaseRecords) withMergedIDs(mergedIDs map[string]string) PurchaseRecords {
+	for _, record := range records {
+		if surviving, ok := mergedIDs[record.CustomerID]; ok {
+			record.CustomerID = surviving
+		}
+	}
+	return records
+}
@@ -11,9 +11,13 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
+	entity "github.com/sirawong/point-accumulate-interview/internal/domain/entity"
 	accumulatepoints "github.com/sirawong/point-accumulate-interview/internal/services/accumulatepoints"
+	report "github.com/sirawong/point-accumulate-interview/pkg/report"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,12 +45,28 @@ func (m *MockAccumulatePointService) EXPECT() *MockAccumulatePointServiceMockRec
 	return m.recorder
 }
 
+// DryRunMultipleFiles mocks base method.
+func (m *MockAccumulatePointService) DryRunMultipleFiles(ctx context.Context, files []accumulatepoints.FileInput, target accumulatepoints.ReportTarget) (*entity.ProcessResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRunMultipleFiles", ctx, files, target)
+	ret0, _ := ret[0].(*entity.ProcessResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DryRunMultipleFiles indicates an expected call of DryRunMultipleFiles.
+func (mr *MockAccumulatePointServiceMockRecorder) DryRunMultipleFiles(ctx, files, target any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRunMultipleFiles", reflect.TypeOf((*MockAccumulatePointService)(nil).DryRunMultipleFiles), ctx, files, target)
+}
+
 // ExecuteMultipleFiles mocks base method.
-func (m *MockAccumulatePointService) ExecuteMultipleFiles(ctx context.Context, files []accumulatepoints.FileInput) error {
+func (m *MockAccumulatePointService) ExecuteMultipleFiles(ctx context.Context, files []accumulatepoints.FileInput) (*entity.ProcessResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ExecuteMultipleFiles", ctx, files)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*entity.ProcessResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ExecuteMultipleFiles indicates an expected call of ExecuteMultipleFiles.
@@ -54,3 +74,90 @@ func (mr *MockAccumulatePointServiceMockRecorder) ExecuteMultipleFiles(ctx, file
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteMultipleFiles", reflect.TypeOf((*MockAccumulatePointService)(nil).ExecuteMultipleFiles), ctx, files)
 }
+
+// ListReports mocks base method.
+func (m *MockAccumulatePointService) ListReports(ctx context.Context) ([]entity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReports", ctx)
+	ret0, _ := ret[0].([]entity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReports indicates an expected call of ListReports.
+func (mr *MockAccumulatePointServiceMockRecorder) ListReports(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReports", reflect.TypeOf((*MockAccumulatePointService)(nil).ListReports), ctx)
+}
+
+// Recompute mocks base method.
+func (m *MockAccumulatePointService) Recompute(ctx context.Context) (*entity.RecomputeResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recompute", ctx)
+	ret0, _ := ret[0].(*entity.RecomputeResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recompute indicates an expected call of Recompute.
+func (mr *MockAccumulatePointServiceMockRecorder) Recompute(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recompute", reflect.TypeOf((*MockAccumulatePointService)(nil).Recompute), ctx)
+}
+
+// SwapRecompute mocks base method.
+func (m *MockAccumulatePointService) SwapRecompute(ctx context.Context, snapshotAt time.Time) (*entity.SwapResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwapRecompute", ctx, snapshotAt)
+	ret0, _ := ret[0].(*entity.SwapResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SwapRecompute indicates an expected call of SwapRecompute.
+func (mr *MockAccumulatePointServiceMockRecorder) SwapRecompute(ctx, snapshotAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwapRecompute", reflect.TypeOf((*MockAccumulatePointService)(nil).SwapRecompute), ctx, snapshotAt)
+}
+
+// WriteBreakdown mocks base method.
+func (m *MockAccumulatePointService) WriteBreakdown(ctx context.Context, breakdown entity.Breakdown, from, to time.Time, format report.Format, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteBreakdown", ctx, breakdown, from, to, format, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteBreakdown indicates an expected call of WriteBreakdown.
+func (mr *MockAccumulatePointServiceMockRecorder) WriteBreakdown(ctx, breakdown, from, to, format, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteBreakdown", reflect.TypeOf((*MockAccumulatePointService)(nil).WriteBreakdown), ctx, breakdown, from, to, format, w)
+}
+
+// WriteDelta mocks base method.
+func (m *MockAccumulatePointService) WriteDelta(ctx context.Context, date time.Time, format report.Format, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteDelta", ctx, date, format, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteDelta indicates an expected call of WriteDelta.
+func (mr *MockAccumulatePointServiceMockRecorder) WriteDelta(ctx, date, format, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteDelta", reflect.TypeOf((*MockAccumulatePointService)(nil).WriteDelta), ctx, date, format, w)
+}
+
+// WriteSummary mocks base method.
+func (m *MockAccumulatePointService) WriteSummary(ctx context.Context, date time.Time, format report.Format, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteSummary", ctx, date, format, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteSummary indicates an expected call of WriteSummary.
+func (mr *MockAccumulatePointServiceMockRecorder) WriteSummary(ctx, date, format, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSummary", reflect.TypeOf((*MockAccumulatePointService)(nil).WriteSummary), ctx, date, format, w)
+}
@@ -1,13 +1,20 @@
 package accumulatepoints
 
 import (
+	"fmt"
 	"io"
+	"regexp"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
 )
 
+var filenameDateRegex = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
 type PurchaseRecord struct {
 	CustomerID      string          `csv:"customer_id"`
 	ProductID       string          `csv:"product_id"`
@@ -16,18 +23,59 @@ type PurchaseRecord struct {
 	BranchID        string          `csv:"branch_id"`
 	PurchasedAmount decimal.Decimal `csv:"purchased_amount"`
 	Currency        string          `csv:"currency"`
+	ReceiptID       string          `csv:"receipt_id,omitempty"`
 	PurchaseDate    time.Time       `csv:"-"`
+
+	// TransactionType is empty for a purchase. Returns and voids name the date of
+	// the purchase they take back in OriginalPurchaseDate.
+	TransactionType      entity.TransactionType `csv:"transaction_type,omitempty"`
+	OriginalPurchaseDate string                 `csv:"original_purchase_date,omitempty"`
+	OriginalDate         *time.Time             `csv:"-"`
+}
+
+// toPurchase returns the purchase as a point movement without a rule.
+func (record PurchaseRecord) toPurchase() entity.Record {
+	return entity.Record{
+		ProductID:            record.ProductID,
+		CategoryID:           record.CategoryID,
+		BranchID:             record.BranchID,
+		ReceiptID:            record.ReceiptID,
+		Amount:               record.PurchasedAmount,
+		PurchaseDate:         record.PurchaseDate,
+		Type:                 record.TransactionType,
+		OriginalPurchaseDate: record.OriginalDate,
+	}
 }
 
 type FileInput struct {
+	// Name is the original file name. Inputs without a name are not archived.
+	Name          string
 	PurchasedDate time.Time
 	Reader        io.ReadSeeker
+	// Branches, when set, are the only branches the file may hold rows for.
+	Branches []string
+}
+
+// PurchasedDateFromFilename returns the first YYYY-MM-DD date found in filename.
+func PurchasedDateFromFilename(filename string) (time.Time, error) {
+	dateStr := filenameDateRegex.FindString(filename)
+	if dateStr == "" {
+		errMsg := fmt.Errorf("date in YYYY-MM-DD format not found in filename: %s", filename)
+		return time.Time{}, apperr.ErrInvalidArgument.Wrap(errMsg)
+	}
+
+	date, err := time.Parse(time.DateOnly, dateStr)
+	if err != nil {
+		return time.Time{}, apperr.ErrInvalidArgument.Wrap(err)
+	}
+	return date, nil
 }
 
 type CustomerPointRecord struct {
 	CustomerID       string `csv:"customer_id"`
 	Points           int64  `csv:"points"`
 	LastPurchaseDate string `csv:"last_purchase_date"`
+	Tier             string `csv:"tier"`
 }
 
 func entityToCustomerPointRecord(customers []entity.Customer, targetDate string) []*CustomerPointRecord {
@@ -66,18 +114,62 @@ type PurchaseRecords []*PurchaseRecord
 
 func (records PurchaseRecords) getUniqueCustomerIDs() []string {
 	customerIDUnique := make(map[string]struct{})
+	result := make([]string, 0, len(records))
 	for _, record := range records {
+		if _, ok := customerIDUnique[record.CustomerID]; ok {
+			continue
+		}
 		customerIDUnique[record.CustomerID] = struct{}{}
+		result = append(result, record.CustomerID)
 	}
 
-	result := make([]string, 0, len(records))
-	for id := range customerIDUnique {
-		result = append(result, id)
+	return result
+}
+
+func (records PurchaseRecords) sortedByDate() PurchaseRecords {
+	sorted := slices.Clone(records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].PurchaseDate.Before(sorted[j].PurchaseDate)
+	})
+	return sorted
+}
+
+// baskets groups records into the lines of one visit, in purchase order: a
+// customer's lines at a branch on a day, split by receipt when the file has
+// receipt IDs.
+func (records PurchaseRecords) baskets() []PurchaseRecords {
+	type basketKey struct {
+		CustomerID   string
+		BranchID     string
+		ReceiptID    string
+		PurchaseDate time.Time
+	}
+
+	index := make(map[basketKey]int)
+	result := make([]PurchaseRecords, 0)
+	for _, record := range records.sortedByDate() {
+		key := basketKey{
+			CustomerID:   record.CustomerID,
+			BranchID:     record.BranchID,
+			ReceiptID:    record.ReceiptID,
+			PurchaseDate: record.PurchaseDate,
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, nil)
+		}
+		result[i] = append(result[i], record)
 	}
 
 	return result
 }
 
+// getUniqueRecords drops rows repeated in the upload. Returns and voids of
+// different original purchases, and lines of different receipts, are different
+// rows even when they look alike.
 func (records PurchaseRecords) getUniqueRecords() PurchaseRecords {
 	type recordKey struct {
 		CustomerID      string
@@ -85,6 +177,9 @@ func (records PurchaseRecords) getUniqueRecords() PurchaseRecords {
 		BranchID        string
 		PurchasedAmount string
 		PurchaseDate    time.Time
+		TransactionType entity.TransactionType
+		OriginalDate    time.Time
+		ReceiptID       string
 	}
 
 	seen := make(map[recordKey]struct{})
@@ -97,6 +192,11 @@ func (records PurchaseRecords) getUniqueRecords() PurchaseRecords {
 			BranchID:        record.BranchID,
 			PurchasedAmount: record.PurchasedAmount.String(),
 			PurchaseDate:    record.PurchaseDate,
+			TransactionType: record.TransactionType,
+			ReceiptID:       record.ReceiptID,
+		}
+		if record.OriginalDate != nil {
+			key.OriginalDate = *record.OriginalDate
 		}
 
 		if _, ok := seen[key]; !ok {
@@ -109,23 +209,189 @@ func (records PurchaseRecords) getUniqueRecords() PurchaseRecords {
 }
 
 func (records PurchaseRecords) mapRecordsToBranchCategories() map[string][]string {
+	return records.mapRecordsToBranch(func(record *PurchaseRecord) string { return record.CategoryID })
+}
+
+func (records PurchaseRecords) mapRecordsToBranchProducts() map[string][]string {
+	return records.mapRecordsToBranch(func(record *PurchaseRecord) string { return record.ProductID })
+}
+
+// mapRecordsToBranchGroups returns the groups of each branch purchases were made
+// at. Branches in no group are left out.
+func (records PurchaseRecords) mapRecordsToBranchGroups(groups entity.BranchGroups) map[string][]string {
+	result := make(map[string][]string)
+	for _, record := range records {
+		if _, ok := result[record.BranchID]; ok {
+			continue
+		}
+		if names := groups.GroupsOf(record.BranchID); len(names) > 0 {
+			result[record.BranchID] = names
+		}
+	}
+	return result
+}
+
+// mapRecordsToBranch returns the distinct values of field bought at each branch.
+func (records PurchaseRecords) mapRecordsToBranch(field func(*PurchaseRecord) string) map[string][]string {
 	helperMap := make(map[string]map[string]struct{})
 
 	for _, record := range records {
 		if _, ok := helperMap[record.BranchID]; !ok {
 			helperMap[record.BranchID] = make(map[string]struct{})
 		}
-		helperMap[record.BranchID][record.CategoryID] = struct{}{}
+		helperMap[record.BranchID][field(record)] = struct{}{}
 	}
 
 	result := make(map[string][]string)
-	for branchID, categorySet := range helperMap {
-		categories := make([]string, 0, len(categorySet))
-		for categoryID := range categorySet {
-			categories = append(categories, categoryID)
+	for branchID, valueSet := range helperMap {
+		values := make([]string, 0, len(valueSet))
+		for value := range valueSet {
+			values = append(values, value)
 		}
-		result[branchID] = categories
+		result[branchID] = values
 	}
 
 	return result
 }
+
+type historyKey struct {
+	ProductID            string
+	CategoryID           string
+	BranchID             string
+	Amount               string
+	PurchaseDate         time.Time
+	Type                 entity.TransactionType
+	OriginalPurchaseDate time.Time
+	ReceiptID            string
+}
+
+func newHistoryKey(record entity.Record) historyKey {
+	key := historyKey{
+		ProductID:    record.ProductID,
+		CategoryID:   record.CategoryID,
+		BranchID:     record.BranchID,
+		Amount:       record.Amount.String(),
+		PurchaseDate: record.PurchaseDate,
+		Type:         record.Type,
+		ReceiptID:    record.ReceiptID,
+	}
+	if record.OriginalPurchaseDate != nil {
+		key.OriginalPurchaseDate = *record.OriginalPurchaseDate
+	}
+	return key
+}
+
+// uniqueHistoryRecords collapses stored movements to one entry per purchase, ordered
+// by purchase date. The rule fields of the returned records are cleared, and
+// movements that are not replayed are left out.
+func uniqueHistoryRecords(records []entity.Record) []entity.Record {
+	seen := make(map[historyKey]struct{})
+	result := make([]entity.Record, 0, len(records))
+	for _, record := range records {
+		if !isReplayed(record) {
+			continue
+		}
+		key := newHistoryKey(record)
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			result = append(result, entity.Record{
+				ProductID:    record.ProductID,
+				CategoryID:   record.CategoryID,
+				BranchID:     record.BranchID,
+				ReceiptID:    record.ReceiptID,
+				Amount:       record.Amount,
+				PurchaseDate: record.PurchaseDate,
+
+				Type:                 record.Type,
+				OriginalPurchaseDate: record.OriginalPurchaseDate,
+			})
+		}
+	}
+
+	sortRecordsByDate(result)
+
+	return result
+}
+
+// isReplayed reports whether the movement is worked out from the rules, so a
+// recompute replays it. Adjustments, transfers and pool movements are not.
+func isReplayed(record entity.Record) bool {
+	return record.IsPurchase() || record.IsReturn()
+}
+
+// unearnedRecords returns the purchases that have no movement in movements.
+func unearnedRecords(purchases, movements []entity.Record) []entity.Record {
+	earned := make(map[historyKey]struct{}, len(movements))
+	for _, movement := range movements {
+		earned[newHistoryKey(movement)] = struct{}{}
+	}
+
+	result := make([]entity.Record, 0)
+	for _, purchase := range purchases {
+		if _, ok := earned[newHistoryKey(purchase)]; !ok {
+			result = append(result, purchase)
+		}
+	}
+	return result
+}
+
+func sortRecordsByDate(records []entity.Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].PurchaseDate.Before(records[j].PurchaseDate)
+	})
+}
+
+func historyToPurchaseRecords(customerID string, records []entity.Record) PurchaseRecords {
+	result := make(PurchaseRecords, 0, len(records))
+	for _, record := range records {
+		result = append(result, &PurchaseRecord{
+			CustomerID:      customerID,
+			ProductID:       record.ProductID,
+			CategoryID:      record.CategoryID,
+			BranchID:        record.BranchID,
+			ReceiptID:       record.ReceiptID,
+			PurchasedAmount: record.Amount,
+			PurchaseDate:    record.PurchaseDate,
+			TransactionType: record.Type,
+			OriginalDate:    record.OriginalPurchaseDate,
+		})
+	}
+	return result
+}
+
+// applyCustomerUpdates applies updates to customers in memory, the way UpdateBulkCustomers
+// would apply them in the database.
+func applyCustomerUpdates(customers []entity.Customer, updates []entity.UpdateCustomer) []entity.Customer {
+	index := make(map[string]int, len(customers))
+	for i, customer := range customers {
+		index[customer.CustomerID] = i
+	}
+
+	for _, update := range updates {
+		i, ok := index[update.CustomerID]
+		if !ok {
+			customers = append(customers, entity.Customer{CustomerID: update.CustomerID})
+			i = len(customers) - 1
+			index[update.CustomerID] = i
+		}
+
+		customer := customers[i]
+		pointsByDate := make(map[string]int64, len(customer.PointsByDate)+len(update.PointsByDate))
+		for date, points := range customer.PointsByDate {
+			pointsByDate[date] = points
+		}
+		for date, points := range update.PointsByDate {
+			pointsByDate[date] += points
+		}
+
+		customer.Points += update.PointsToAdd
+		customer.PointsByDate = pointsByDate
+		customer.Records = append(slices.Clone(customer.Records), update.Records...)
+		if update.LastPurchaseDate.After(customer.LastPurchaseDate) {
+			customer.LastPurchaseDate = update.LastPurchaseDate
+		}
+		customers[i] = customer
+	}
+
+	return customers
+}
@@ -2,6 +2,7 @@ package accumulatepoints
 
 import (
 	"testing"
+	"time"
 
 	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
 	"github.com/stretchr/testify/suite"
@@ -76,3 +77,46 @@ func (suite *UtilityFunctionsTestSuite) TestSumValuesOnOrBeforeDate() {
 	suite.Equal("2025-01-20", lasttDate)
 	suite.Equal(int64(300), value)
 }
+
+func (suite *UtilityFunctionsTestSuite) TestApplyCustomerUpdates() {
+	customers := []entity.Customer{
+		{
+			CustomerID:   "U000001",
+			Points:       50,
+			PointsByDate: map[string]int64{"2025-01-14": 50},
+		},
+	}
+
+	updates := []entity.UpdateCustomer{
+		{
+			CustomerID:       "U000001",
+			PointsToAdd:      10,
+			LastPurchaseDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			Records:          []entity.Record{{ProductID: "P001"}},
+			PointsByDate:     map[string]int64{"2025-01-15": 10},
+		},
+		{
+			CustomerID:   "U000002",
+			PointsToAdd:  5,
+			PointsByDate: map[string]int64{"2025-01-15": 5},
+		},
+	}
+
+	result := applyCustomerUpdates(customers, updates)
+
+	suite.Len(result, 2)
+	suite.Equal(int64(60), result[0].Points)
+	suite.Equal(map[string]int64{"2025-01-14": 50, "2025-01-15": 10}, result[0].PointsByDate)
+	suite.Len(result[0].Records, 1)
+	suite.Equal("U000002", result[1].CustomerID)
+	suite.Equal(int64(5), result[1].Points)
+}
+
+func (suite *UtilityFunctionsTestSuite) TestPurchasedDateFromFilename() {
+	date, err := PurchasedDateFromFilename("store_2025-01-02.csv")
+	suite.NoError(err)
+	suite.Equal(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), date)
+
+	_, err = PurchasedDateFromFilename("2025-13-02.csv")
+	suite.Error(err)
+}
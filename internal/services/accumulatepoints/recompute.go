@@ -0,0 +1,155 @@
+package accumulatepoints
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+)
+
+// Recompute rebuilds every customer's balance from the stored purchase history
+// using the rules in force on each purchase date. The result is written to the
+// shadow collection and is only applied by SwapRecompute.
+func (a accumulatePointService) Recompute(ctx context.Context) (*entity.RecomputeResult, error) {
+	snapshotAt := time.Now().UTC().Truncate(time.Millisecond)
+
+	rules, err := a.ruleRepo.GetAllActiveRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	branchGroups, err := a.ruleRepo.GetBranchGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules = compileRules(branchGroups.Resolve(rules))
+
+	tiers, err := a.tierRepo.GetTiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.RecomputeResult{SnapshotAt: snapshotAt, Diffs: make([]entity.BalanceDiff, 0)}
+	err = a.customerRepo.ReplaceShadowCustomers(ctx, snapshotAt, func(add func(entity.Customer) error) error {
+		return a.customerRepo.StreamCustomers(ctx, func(customer entity.Customer) error {
+			recomputed := recomputeCustomer(rules, tiers, customer, snapshotAt)
+
+			result.Customers++
+			result.LegacyPurchases += countLegacyPurchases(customer.Records)
+			if diff, changed := diffBalance(customer, recomputed); changed {
+				result.Diffs = append(result.Diffs, diff)
+			}
+
+			return add(recomputed)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result.Diffs, func(i, j int) bool {
+		return result.Diffs[i].CustomerID < result.Diffs[j].CustomerID
+	})
+	result.Changed = len(result.Diffs)
+
+	return result, nil
+}
+
+// SwapRecompute applies the recompute recorded at snapshotAt. Customers written
+// to since are skipped and keep their live balances.
+func (a accumulatePointService) SwapRecompute(ctx context.Context, snapshotAt time.Time) (*entity.SwapResult, error) {
+	return a.customerRepo.SwapShadowCustomers(ctx, snapshotAt)
+}
+
+func recomputeCustomer(rules []entity.Rule, tiers entity.Tiers, customer entity.Customer, now time.Time) entity.Customer {
+	replayable := make([]entity.Record, 0, len(customer.Records))
+	for _, record := range customer.Records {
+		if !isLegacyRecord(record) {
+			replayable = append(replayable, record)
+		}
+	}
+	history := uniqueHistoryRecords(replayable)
+
+	result := entity.Customer{
+		CustomerID:       customer.CustomerID,
+		LastPurchaseDate: customer.LastPurchaseDate,
+		CreatedAt:        customer.CreatedAt,
+		UpdatedAt:        now,
+		PointsByDate:     make(map[string]int64),
+		Attributes:       customer.Attributes,
+		HouseholdID:      customer.HouseholdID,
+		MergedInto:       customer.MergedInto,
+		Aliases:          customer.Aliases,
+	}
+
+	for _, record := range history {
+		if record.IsPurchase() {
+			result.LastPurchaseDate = record.PurchaseDate
+		}
+	}
+
+	// Replay against the customer with only their adjustments, transfers, pool
+	// movements and legacy records, which are kept as they are, so every other
+	// purchase counts as new and the kept points still count towards tiers.
+	kept := make(map[string]entity.UpdateCustomer)
+	for _, record := range customer.Records {
+		if !isReplayed(record) || isLegacyRecord(record) {
+			addRecord(kept, customer.CustomerID, record)
+		}
+	}
+	replayed := entity.Customers{{CustomerID: customer.CustomerID, Attributes: customer.Attributes}}
+	replayed = applyCustomerUpdates(replayed, slices.Collect(maps.Values(kept)))
+
+	for _, update := range calculateBatchPoints(rules, tiers, historyToPurchaseRecords(customer.CustomerID, history), replayed) {
+		result.Points = update.PointsToAdd
+		result.PointsByDate = update.PointsByDate
+		result.Records = update.Records
+	}
+
+	result.Points += replayed[0].Points
+	for date, points := range replayed[0].PointsByDate {
+		result.PointsByDate[date] += points
+	}
+	result.Records = append(result.Records, replayed[0].Records...)
+	result.Records = append(result.Records, unearnedRecords(history, result.Records)...)
+	sortRecordsByDate(result.Records)
+
+	return result
+}
+
+// isLegacyRecord reports whether the purchase or return was stored without a
+// category, as every record was before categories were recorded. Replaying it
+// would drop what category rules awarded, so a recompute keeps it as it is.
+func isLegacyRecord(record entity.Record) bool {
+	return isReplayed(record) && record.CategoryID == ""
+}
+
+// countLegacyPurchases returns the number of distinct purchases in records that
+// a recompute keeps as they are.
+func countLegacyPurchases(records []entity.Record) int {
+	seen := make(map[historyKey]struct{})
+	for _, record := range records {
+		if record.IsPurchase() && isLegacyRecord(record) {
+			seen[newHistoryKey(record)] = struct{}{}
+		}
+	}
+	return len(seen)
+}
+
+// diffBalance reports how the recomputed balance of a customer differs from the
+// current one, and whether it does at all.
+func diffBalance(current, recomputed entity.Customer) (entity.BalanceDiff, bool) {
+	if current.Points == recomputed.Points && maps.Equal(current.PointsByDate, recomputed.PointsByDate) {
+		return entity.BalanceDiff{}, false
+	}
+
+	return entity.BalanceDiff{
+		CustomerID:       current.CustomerID,
+		CurrentPoints:    current.Points,
+		RecomputedPoints: recomputed.Points,
+		Delta:            recomputed.Points - current.Points,
+	}, true
+}
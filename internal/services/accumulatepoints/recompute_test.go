@@ -0,0 +1,253 @@
+package accumulatepoints
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_customer"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_rule"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_tier"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/storage"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type RecomputeTestSuite struct {
+	suite.Suite
+	mockCtrl     *gomock.Controller
+	mockRuleRepo *mocks_rule.MockRuleRepository
+	mockCustRepo *mocks_customer.MockCustomerRepository
+	mockTierRepo *mocks_tier.MockTierRepository
+	service      AccumulatePointService
+}
+
+func TestRecomputeTestSuite(t *testing.T) {
+	suite.Run(t, new(RecomputeTestSuite))
+}
+
+func (suite *RecomputeTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRuleRepo = mocks_rule.NewMockRuleRepository(suite.mockCtrl)
+	suite.mockCustRepo = mocks_customer.NewMockCustomerRepository(suite.mockCtrl)
+	suite.mockTierRepo = mocks_tier.NewMockTierRepository(suite.mockCtrl)
+	suite.mockTierRepo.EXPECT().GetTiers(gomock.Any()).Return(nil, nil).AnyTimes()
+	suite.mockRuleRepo.EXPECT().GetBranchGroups(gomock.Any()).Return(nil, nil).AnyTimes()
+	suite.service = NewAccumulatePointService(suite.mockRuleRepo, suite.mockCustRepo, suite.mockTierRepo, storage.NewMemory(), &config.Config{})
+}
+
+func (suite *RecomputeTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+// expectShadow streams customers to the recompute and passes check what it adds
+// to the shadow collection.
+func (suite *RecomputeTestSuite) expectShadow(ctx context.Context, customers []entity.Customer, check func(shadow []entity.Customer, snapshotAt time.Time)) {
+	suite.mockCustRepo.EXPECT().
+		StreamCustomers(ctx, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(entity.Customer) error) error {
+			for _, customer := range customers {
+				if err := fn(customer); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	suite.mockCustRepo.EXPECT().
+		ReplaceShadowCustomers(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, snapshotAt time.Time, fill func(add func(entity.Customer) error) error) error {
+			var shadow []entity.Customer
+			err := fill(func(customer entity.Customer) error {
+				shadow = append(shadow, customer)
+				return nil
+			})
+			check(shadow, snapshotAt)
+			return err
+		})
+}
+
+func (suite *RecomputeTestSuite) TestRecompute_UsesRulesInForceOnEachDate() {
+	ctx := context.Background()
+	day1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	rules := []entity.Rule{
+		{
+			ID:          "RULE001",
+			RuleType:    entity.FixedPointRule,
+			Conditions:  entity.Conditions{CategoryID: []string{"CT1001"}},
+			Reward:      entity.Reward{Value: 5},
+			EffectiveTo: &day2,
+		},
+		{
+			ID:            "RULE002",
+			RuleType:      entity.FixedPointRule,
+			Conditions:    entity.Conditions{CategoryID: []string{"CT1001"}},
+			Reward:        entity.Reward{Value: 8},
+			EffectiveFrom: &day2,
+		},
+	}
+
+	customers := []entity.Customer{
+		{
+			CustomerID: "U000001",
+			Points:     20,
+			Records: []entity.Record{
+				{ProductID: "P1", CategoryID: "CT1001", BranchID: "BR1", Amount: decimal.NewFromInt(100), PurchaseDate: day2},
+				{ProductID: "P1", CategoryID: "CT1001", BranchID: "BR1", Amount: decimal.NewFromInt(100), PurchaseDate: day1},
+				{ProductID: "P1", CategoryID: "CT1001", BranchID: "BR1", Amount: decimal.NewFromInt(100), PurchaseDate: day1},
+			},
+			PointsByDate: map[string]int64{"2025-01-01": 10, "2025-01-02": 10},
+		},
+		{
+			CustomerID: "U000002",
+			Points:     8,
+			Records: []entity.Record{
+				{ProductID: "P2", CategoryID: "CT1001", BranchID: "BR1", Amount: decimal.NewFromInt(50), PurchaseDate: day2},
+			},
+			PointsByDate: map[string]int64{"2025-01-02": 8},
+		},
+	}
+
+	suite.mockRuleRepo.EXPECT().GetAllActiveRules(ctx).Return(rules, nil)
+	var recordedSnapshot time.Time
+	suite.expectShadow(ctx, customers, func(shadow []entity.Customer, snapshotAt time.Time) {
+		recordedSnapshot = snapshotAt
+		suite.Len(shadow, 2)
+		suite.Equal(int64(13), shadow[0].Points)
+		suite.Equal(map[string]int64{"2025-01-01": 5, "2025-01-02": 8}, shadow[0].PointsByDate)
+		suite.Len(shadow[0].Records, 2)
+		suite.Equal(day2, shadow[0].LastPurchaseDate)
+		suite.Equal(int64(8), shadow[1].Points)
+	})
+
+	result, err := suite.service.Recompute(ctx)
+
+	suite.NoError(err)
+	suite.Equal(recordedSnapshot, result.SnapshotAt)
+	suite.Equal(2, result.Customers)
+	suite.Equal(1, result.Changed)
+	suite.Equal([]entity.BalanceDiff{
+		{CustomerID: "U000001", CurrentPoints: 20, RecomputedPoints: 13, Delta: -7},
+	}, result.Diffs)
+}
+
+func (suite *RecomputeTestSuite) TestRecompute_KeepsAdjustments() {
+	ctx := context.Background()
+	day1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	rules := []entity.Rule{{ID: "RULE001", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 5}}}
+	goodwill := entity.Adjustment{ID: "A1", Points: 100, ReasonCode: entity.AdjustmentReasonGoodwill}
+	again := entity.Adjustment{ID: "A2", Points: 100, ReasonCode: entity.AdjustmentReasonGoodwill}
+
+	customers := []entity.Customer{{
+		CustomerID:       "U000001",
+		Points:           205,
+		LastPurchaseDate: day2,
+		Records: []entity.Record{
+			goodwill.Movement(day1),
+			again.Movement(day1),
+			{ProductID: "P1", CategoryID: "CT1001", BranchID: "BR1", Amount: decimal.NewFromInt(100), PurchaseDate: day2, RuleID: "RULE001", Points: 5},
+		},
+		PointsByDate: map[string]int64{"2025-01-01": 200, "2025-01-02": 5},
+	}}
+
+	suite.mockRuleRepo.EXPECT().GetAllActiveRules(ctx).Return(rules, nil)
+	suite.expectShadow(ctx, customers, func(shadow []entity.Customer, _ time.Time) {
+		suite.Equal(int64(205), shadow[0].Points)
+		suite.Equal(customers[0].PointsByDate, shadow[0].PointsByDate)
+		suite.Equal(customers[0].Records, shadow[0].Records)
+		suite.Equal(day2, shadow[0].LastPurchaseDate)
+	})
+
+	result, err := suite.service.Recompute(ctx)
+
+	suite.NoError(err)
+	suite.Equal(0, result.Changed)
+}
+
+func (suite *RecomputeTestSuite) TestRecompute_KeepsLegacyRecordsWithoutCategory() {
+	ctx := context.Background()
+	day1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	rules := []entity.Rule{{
+		ID:         "RULE001",
+		RuleType:   entity.FixedPointRule,
+		Conditions: entity.Conditions{CategoryID: []string{"CT1001"}},
+		Reward:     entity.Reward{Value: 5},
+	}}
+	legacy := entity.Record{ProductID: "P1", BranchID: "BR1", Amount: decimal.NewFromInt(100), PurchaseDate: day1, RuleID: "RULE001", RuleVersion: 1, Points: 7}
+
+	customers := []entity.Customer{{
+		CustomerID:       "U000001",
+		Points:           14,
+		LastPurchaseDate: day2,
+		Records: []entity.Record{
+			legacy,
+			{ProductID: "P1", CategoryID: "CT1001", BranchID: "BR1", Amount: decimal.NewFromInt(100), PurchaseDate: day2, RuleID: "RULE001", RuleVersion: 1, Points: 7},
+		},
+		PointsByDate: map[string]int64{"2025-01-01": 7, "2025-01-02": 7},
+	}}
+
+	suite.mockRuleRepo.EXPECT().GetAllActiveRules(ctx).Return(rules, nil)
+	suite.expectShadow(ctx, customers, func(shadow []entity.Customer, _ time.Time) {
+		suite.Equal(int64(12), shadow[0].Points)
+		suite.Equal(map[string]int64{"2025-01-01": 7, "2025-01-02": 5}, shadow[0].PointsByDate)
+		suite.Len(shadow[0].Records, 2)
+		suite.Equal(legacy, shadow[0].Records[0])
+	})
+
+	result, err := suite.service.Recompute(ctx)
+
+	suite.NoError(err)
+	suite.Equal(1, result.LegacyPurchases)
+	suite.Equal([]entity.BalanceDiff{
+		{CustomerID: "U000001", CurrentPoints: 14, RecomputedPoints: 12, Delta: -2},
+	}, result.Diffs)
+}
+
+func (suite *RecomputeTestSuite) TestRecompute_ShadowError() {
+	ctx := context.Background()
+
+	suite.mockRuleRepo.EXPECT().GetAllActiveRules(ctx).Return(nil, nil)
+	suite.mockCustRepo.EXPECT().ReplaceShadowCustomers(ctx, gomock.Any(), gomock.Any()).Return(apperr.ErrInternal)
+
+	result, err := suite.service.Recompute(ctx)
+
+	suite.Nil(result)
+	suite.ErrorIs(err, apperr.ErrInternal)
+}
+
+func (suite *RecomputeTestSuite) TestRecompute_StreamError() {
+	ctx := context.Background()
+
+	suite.mockRuleRepo.EXPECT().GetAllActiveRules(ctx).Return(nil, nil)
+	suite.mockCustRepo.EXPECT().StreamCustomers(ctx, gomock.Any()).Return(apperr.ErrInternal)
+	suite.mockCustRepo.EXPECT().
+		ReplaceShadowCustomers(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ time.Time, fill func(add func(entity.Customer) error) error) error {
+			return fill(func(entity.Customer) error { return nil })
+		})
+
+	result, err := suite.service.Recompute(ctx)
+
+	suite.Nil(result)
+	suite.ErrorIs(err, apperr.ErrInternal)
+}
+
+func (suite *RecomputeTestSuite) TestSwapRecompute() {
+	ctx := context.Background()
+	snapshotAt := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	suite.mockCustRepo.EXPECT().SwapShadowCustomers(ctx, snapshotAt).Return(nil, apperr.ErrConflict)
+
+	_, err := suite.service.SwapRecompute(ctx, snapshotAt)
+
+	suite.ErrorIs(err, apperr.ErrConflict)
+}
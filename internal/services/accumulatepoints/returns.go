@@ -0,0 +1,174 @@
+package accumulatepoints
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+)
+
+// parseTransaction checks the transaction columns of a row read from a file. A
+// return's amount is stored negative whatever its sign in the file, and a void's
+// amount is worked out from the purchase it takes back.
+func (record *PurchaseRecord) parseTransaction() error {
+	switch record.TransactionType {
+	case "", "PURCHASE":
+		record.TransactionType = entity.PurchaseTransaction
+		if record.OriginalPurchaseDate != "" {
+			return fmt.Errorf("original_purchase_date is only for returns and voids")
+		}
+		if record.PurchasedAmount.IsNegative() {
+			return fmt.Errorf("purchased_amount must not be negative for a purchase; use transaction_type RETURN")
+		}
+		return nil
+
+	case entity.ReturnTransaction:
+		record.PurchasedAmount = record.PurchasedAmount.Abs().Neg()
+
+	case entity.VoidTransaction:
+		record.PurchasedAmount = decimal.Zero
+
+	default:
+		return fmt.Errorf("unknown transaction_type %s", record.TransactionType)
+	}
+
+	original, err := time.Parse(time.DateOnly, record.OriginalPurchaseDate)
+	if err != nil {
+		return fmt.Errorf("original_purchase_date must be in YYYY-MM-DD format")
+	}
+	if original.After(record.PurchaseDate) {
+		return fmt.Errorf("original_purchase_date must not be after the %s", record.TransactionType)
+	}
+	record.OriginalDate = &original
+
+	return nil
+}
+
+func (records PurchaseRecords) splitReturns() (purchases, returns PurchaseRecords) {
+	for _, record := range records {
+		if record.TransactionType == entity.PurchaseTransaction {
+			purchases = append(purchases, record)
+		} else {
+			returns = append(returns, record)
+		}
+	}
+	return purchases, returns
+}
+
+// calculateClawback returns the movements that take back what a return's original
+// purchase earned, from the customer's movements so far. The original purchase
+// is the customer's purchases of the product at the branch on the original date,
+// and of the receipt when the return names one.
+//
+// Each rule loses the share of its points that the returned amount is of the
+// purchase, rounded up. The share is worked out on everything returned so far,
+// so several partial returns never take back more, or less, than returning it
+// all at once. A return always has a movement, with no points when nothing was
+// left to take back, so recomputes replay it.
+func calculateClawback(record PurchaseRecord, movements []entity.Record) []entity.Record {
+	type ruleKey struct {
+		id      string
+		version int64
+	}
+
+	isOriginal := func(movement entity.Record) bool {
+		return movement.BranchID == record.BranchID &&
+			movement.ProductID == record.ProductID &&
+			(record.ReceiptID == "" || movement.ReceiptID == record.ReceiptID)
+	}
+
+	purchased, returnedBefore := decimal.Zero, decimal.Zero
+	seen := make(map[historyKey]struct{})
+	earned := make(map[ruleKey]int64)
+	clawed := make(map[ruleKey]int64)
+	tiers := make(map[ruleKey]string)
+	order := make([]ruleKey, 0)
+
+	for _, movement := range movements {
+		if !isOriginal(movement) {
+			continue
+		}
+
+		isPurchase := movement.IsPurchase() && movement.PurchaseDate.Equal(*record.OriginalDate)
+		isEarlierReturn := movement.IsReturn() && movement.OriginalPurchaseDate.Equal(*record.OriginalDate)
+		if !isPurchase && !isEarlierReturn {
+			continue
+		}
+
+		if _, ok := seen[newHistoryKey(movement)]; !ok {
+			seen[newHistoryKey(movement)] = struct{}{}
+			if isPurchase {
+				purchased = purchased.Add(movement.Amount)
+			} else {
+				returnedBefore = returnedBefore.Sub(movement.Amount)
+			}
+		}
+
+		if movement.RuleID == "" {
+			continue
+		}
+		key := ruleKey{id: movement.RuleID, version: movement.RuleVersion}
+		if isEarlierReturn {
+			clawed[key] -= movement.Points
+			continue
+		}
+		if _, ok := earned[key]; !ok {
+			order = append(order, key)
+			tiers[key] = movement.Tier
+		}
+		earned[key] += movement.Points
+	}
+
+	requested := record.PurchasedAmount.Neg()
+	if record.TransactionType == entity.VoidTransaction {
+		requested = decimal.Max(purchased.Sub(returnedBefore), decimal.Zero)
+	}
+	returned := decimal.Min(returnedBefore.Add(requested), purchased)
+
+	base := record.toPurchase()
+	base.Amount = requested.Neg()
+
+	result := make([]entity.Record, 0, len(order))
+	for _, key := range order {
+		if !purchased.IsPositive() {
+			break
+		}
+
+		target := decimal.NewFromInt(earned[key]).Mul(returned).Div(purchased).Ceil().IntPart()
+		points := target - clawed[key]
+		if points <= 0 {
+			continue
+		}
+
+		movement := base
+		movement.RuleID = key.id
+		movement.RuleVersion = key.version
+		movement.Tier = tiers[key]
+		movement.Points = -points
+		result = append(result, movement)
+	}
+
+	if len(result) == 0 {
+		result = append(result, base)
+	}
+
+	return result
+}
+
+// isStoredReturn reports whether the same return was already stored for the
+// customer by an earlier upload. Like getUniqueRecords, it tells returns apart by
+// their type and original purchase date too. Voids match whatever amount they
+// took back.
+func isStoredReturn(record PurchaseRecord, stored []entity.Record) bool {
+	return slices.ContainsFunc(stored, func(r entity.Record) bool {
+		return r.Type == record.TransactionType &&
+			r.PurchaseDate.Equal(record.PurchaseDate) &&
+			r.OriginalPurchaseDate.Equal(*record.OriginalDate) &&
+			r.BranchID == record.BranchID &&
+			r.ProductID == record.ProductID &&
+			r.ReceiptID == record.ReceiptID &&
+			(record.TransactionType == entity.VoidTransaction || r.Amount.Equal(record.PurchasedAmount))
+	})
+}
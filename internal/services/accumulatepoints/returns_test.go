@@ -0,0 +1,195 @@
+package accumulatepoints
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReturnsTestSuite struct {
+	suite.Suite
+	rules []entity.Rule
+}
+
+func TestReturnsTestSuite(t *testing.T) {
+	suite.Run(t, new(ReturnsTestSuite))
+}
+
+func (suite *ReturnsTestSuite) SetupTest() {
+	ratioUnit := 100.0
+	suite.rules = []entity.Rule{
+		{ID: "FIXED", Version: 1, RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 10}},
+		{ID: "RATIO", Version: 2, RuleType: entity.RatioRule, Reward: entity.Reward{Value: 5, RatioUnit: &ratioUnit}},
+	}
+}
+
+func returnOf(transactionType entity.TransactionType, amount int64, date, original time.Time) *PurchaseRecord {
+	return &PurchaseRecord{
+		CustomerID:      "U000001",
+		ProductID:       "P1",
+		CategoryID:      "CT1",
+		BranchID:        "BR1",
+		PurchasedAmount: decimal.NewFromInt(-amount),
+		PurchaseDate:    date,
+		TransactionType: transactionType,
+		OriginalDate:    &original,
+	}
+}
+
+func (suite *ReturnsTestSuite) TestPartialReturns() {
+	jan10, jan12, jan20 := january(10), january(12), january(20)
+	purchase := &PurchaseRecord{CustomerID: "U000001", ProductID: "P1", CategoryID: "CT1", BranchID: "BR1", PurchasedAmount: decimal.NewFromInt(1000), PurchaseDate: jan10}
+
+	// The purchase earns 10 + 50.
+	earned := calculateBatchPoints(suite.rules, nil, PurchaseRecords{purchase}, nil)
+	suite.Require().Len(earned, 1)
+	suite.Equal(int64(60), earned[0].PointsToAdd)
+	customers := entity.Customers(applyCustomerUpdates(nil, earned))
+
+	// Returning 300 of 1000 takes back 30%, rounded up: 3 + 15.
+	first := calculateBatchPoints(suite.rules, nil, PurchaseRecords{returnOf(entity.ReturnTransaction, 300, jan12, jan10)}, customers)
+	suite.Require().Len(first, 1)
+	suite.Equal(int64(-18), first[0].PointsToAdd)
+	suite.Equal(map[string]int64{"2025-01-12": -18}, first[0].PointsByDate)
+	suite.True(first[0].LastPurchaseDate.IsZero())
+	suite.Equal(decimal.NewFromInt(-300), first[0].Records[0].Amount)
+	suite.Equal("FIXED", first[0].Records[0].RuleID)
+	suite.Equal(int64(-3), first[0].Records[0].Points)
+	customers = applyCustomerUpdates(customers, first)
+
+	// Returning another 900 only has 700 left to take back.
+	second := calculateBatchPoints(suite.rules, nil, PurchaseRecords{returnOf(entity.ReturnTransaction, 900, jan20, jan10)}, customers)
+	suite.Require().Len(second, 1)
+	suite.Equal(int64(-42), second[0].PointsToAdd)
+	customers = applyCustomerUpdates(customers, second)
+
+	suite.Equal(int64(0), customers[0].Points)
+	suite.Equal(jan10, customers[0].LastPurchaseDate)
+
+	// The same return uploaded again is skipped.
+	suite.Nil(calculateBatchPoints(suite.rules, nil, PurchaseRecords{returnOf(entity.ReturnTransaction, 900, jan20, jan10)}, customers))
+}
+
+func (suite *ReturnsTestSuite) TestVoidInSameBatch() {
+	jan10 := january(10)
+	records := PurchaseRecords{
+		returnOf(entity.VoidTransaction, 0, jan10, jan10),
+		{CustomerID: "U000001", ProductID: "P1", CategoryID: "CT1", BranchID: "BR1", PurchasedAmount: decimal.NewFromInt(500), PurchaseDate: jan10},
+		{CustomerID: "U000001", ProductID: "P2", CategoryID: "CT1", BranchID: "BR1", PurchasedAmount: decimal.NewFromInt(200), PurchaseDate: jan10},
+	}
+
+	result := calculateBatchPoints(suite.rules, nil, records, nil)
+
+	suite.Require().Len(result, 1)
+	// P2 keeps its 10 + 10; P1's 10 + 25 are voided.
+	suite.Equal(int64(20), result[0].PointsToAdd)
+	suite.Equal(jan10, result[0].LastPurchaseDate)
+
+	voids := make([]entity.Record, 0)
+	for _, record := range result[0].Records {
+		if record.IsReturn() {
+			voids = append(voids, record)
+		}
+	}
+	suite.Require().Len(voids, 2)
+	suite.Equal(decimal.NewFromInt(-500), voids[0].Amount)
+	suite.Equal(int64(-35), voids[0].Points+voids[1].Points)
+}
+
+func (suite *ReturnsTestSuite) TestReturnWithoutPurchase() {
+	result := calculateBatchPoints(suite.rules, nil, PurchaseRecords{returnOf(entity.ReturnTransaction, 100, january(12), january(10))}, nil)
+
+	suite.Require().Len(result, 1)
+	suite.Equal(int64(0), result[0].PointsToAdd)
+	suite.Require().Len(result[0].Records, 1)
+	suite.Equal(entity.ReturnTransaction, result[0].Records[0].Type)
+	suite.Empty(result[0].Records[0].RuleID)
+}
+
+func (suite *ReturnsTestSuite) TestRecomputeReplaysReturns() {
+	jan10 := january(10)
+	purchase := &PurchaseRecord{CustomerID: "U000001", ProductID: "P1", CategoryID: "CT1", BranchID: "BR1", PurchasedAmount: decimal.NewFromInt(1000), PurchaseDate: jan10}
+	records := PurchaseRecords{purchase, returnOf(entity.ReturnTransaction, 500, january(12), jan10)}
+
+	customers := applyCustomerUpdates(nil, calculateBatchPoints(suite.rules, nil, records, nil))
+	suite.Equal(int64(30), customers[0].Points)
+
+	recomputed := recomputeCustomer(suite.rules, nil, customers[0], time.Now())
+
+	suite.Equal(customers[0].Points, recomputed.Points)
+	suite.Equal(customers[0].PointsByDate, recomputed.PointsByDate)
+	suite.Equal(jan10, recomputed.LastPurchaseDate)
+}
+
+func (suite *ReturnsTestSuite) TestVoidsOfDifferentPurchases() {
+	jan10, jan11, jan12 := january(10), january(11), january(12)
+	purchases := PurchaseRecords{
+		{CustomerID: "U000001", ProductID: "P1", CategoryID: "CT1", BranchID: "BR1", PurchasedAmount: decimal.NewFromInt(1000), PurchaseDate: jan10},
+		{CustomerID: "U000001", ProductID: "P1", CategoryID: "CT1", BranchID: "BR1", PurchasedAmount: decimal.NewFromInt(1000), PurchaseDate: jan11},
+	}
+	// Voids and same-amount returns of the purchases of two days are two rows.
+	for _, transactionType := range []entity.TransactionType{entity.VoidTransaction, entity.ReturnTransaction} {
+		customers := entity.Customers(applyCustomerUpdates(nil, calculateBatchPoints(suite.rules, nil, purchases, nil)))
+		suite.Equal(int64(120), customers[0].Points)
+
+		amount := int64(0)
+		if transactionType == entity.ReturnTransaction {
+			amount = 1000
+		}
+		records := PurchaseRecords{
+			returnOf(transactionType, amount, jan12, jan10),
+			returnOf(transactionType, amount, jan12, jan11),
+		}.getUniqueRecords()
+		suite.Len(records, 2, transactionType)
+
+		taken := calculateBatchPoints(suite.rules, nil, records, customers)
+		suite.Require().Len(taken, 1)
+		suite.Equal(int64(-120), taken[0].PointsToAdd, transactionType)
+
+		after := entity.Customers(applyCustomerUpdates(customers, taken))
+		suite.Zero(after[0].Points)
+
+		// Each is stored, so uploading either again takes nothing more.
+		suite.Nil(calculateBatchPoints(suite.rules, nil, PurchaseRecords{returnOf(transactionType, amount, jan12, jan11)}, after))
+
+		recomputed := recomputeCustomer(suite.rules, nil, after[0], time.Now())
+		suite.Zero(recomputed.Points, transactionType)
+	}
+}
+
+func (suite *ReturnsTestSuite) TestParseTransaction() {
+	jan12 := january(12)
+	testCases := map[string]struct {
+		record  PurchaseRecord
+		wantErr string
+	}{
+		"purchase":           {record: PurchaseRecord{PurchasedAmount: decimal.NewFromInt(10)}},
+		"explicit purchase":  {record: PurchaseRecord{TransactionType: "PURCHASE", PurchasedAmount: decimal.NewFromInt(10)}},
+		"return":             {record: PurchaseRecord{TransactionType: entity.ReturnTransaction, OriginalPurchaseDate: "2025-01-10", PurchasedAmount: decimal.NewFromInt(10)}},
+		"void":               {record: PurchaseRecord{TransactionType: entity.VoidTransaction, OriginalPurchaseDate: "2025-01-12"}},
+		"negative purchase":  {record: PurchaseRecord{PurchasedAmount: decimal.NewFromInt(-10)}, wantErr: "purchased_amount must not be negative"},
+		"purchase with date": {record: PurchaseRecord{OriginalPurchaseDate: "2025-01-10"}, wantErr: "original_purchase_date is only for returns"},
+		"unknown type":       {record: PurchaseRecord{TransactionType: "EXCHANGE"}, wantErr: "unknown transaction_type EXCHANGE"},
+		"missing date":       {record: PurchaseRecord{TransactionType: entity.ReturnTransaction}, wantErr: "YYYY-MM-DD"},
+		"future original":    {record: PurchaseRecord{TransactionType: entity.VoidTransaction, OriginalPurchaseDate: "2025-01-13"}, wantErr: "must not be after the VOID"},
+	}
+
+	for name, tc := range testCases {
+		suite.Run(name, func() {
+			record := tc.record
+			record.PurchaseDate = jan12
+
+			err := record.parseTransaction()
+
+			if tc.wantErr != "" {
+				suite.ErrorContains(err, tc.wantErr)
+				return
+			}
+			suite.NoError(err)
+			suite.False(record.PurchasedAmount.IsPositive() && record.TransactionType != entity.PurchaseTransaction)
+		})
+	}
+}
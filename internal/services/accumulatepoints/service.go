@@ -3,174 +3,474 @@ package accumulatepoints
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
+	"log"
+	"path"
 	"slices"
 	"sort"
+	"strings"
 	"time"
 
-	"github.com/gocarina/gocsv"
 	"github.com/shopspring/decimal"
 	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
 	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
 	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
 	"github.com/sirawong/point-accumulate-interview/pkg/config"
 	"github.com/sirawong/point-accumulate-interview/pkg/csv"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+	"github.com/sirawong/point-accumulate-interview/pkg/storage"
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
 )
 
 type accumulatePointService struct {
 	ruleRepo     repository.RuleRepository
 	customerRepo repository.CustomerRepository
+	tierRepo     repository.TierRepository
+	store        storage.Storage
 	cfg          *config.Config
 }
 
 //go:generate mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
 type AccumulatePointService interface {
-	ExecuteMultipleFiles(ctx context.Context, files []FileInput) error
+	ExecuteMultipleFiles(ctx context.Context, files []FileInput) (*entity.ProcessResult, error)
+	DryRunMultipleFiles(ctx context.Context, files []FileInput, target ReportTarget) (*entity.ProcessResult, error)
+	Recompute(ctx context.Context) (*entity.RecomputeResult, error)
+	SwapRecompute(ctx context.Context, snapshotAt time.Time) (*entity.SwapResult, error)
+	WriteSummary(ctx context.Context, date time.Time, format report.Format, w io.Writer) error
+	ListReports(ctx context.Context) ([]entity.Report, error)
+	WriteBreakdown(ctx context.Context, breakdown entity.Breakdown, from, to time.Time, format report.Format, w io.Writer) error
+	WriteDelta(ctx context.Context, date time.Time, format report.Format, w io.Writer) error
 }
 
-func NewAccumulatePointService(ruleRepo repository.RuleRepository, customerRepo repository.CustomerRepository, cfg *config.Config) AccumulatePointService {
+func NewAccumulatePointService(ruleRepo repository.RuleRepository, customerRepo repository.CustomerRepository, tierRepo repository.TierRepository, store storage.Storage, cfg *config.Config) AccumulatePointService {
 	return &accumulatePointService{
 		ruleRepo:     ruleRepo,
 		customerRepo: customerRepo,
+		tierRepo:     tierRepo,
+		store:        store,
 		cfg:          cfg,
 	}
 }
 
-func (a accumulatePointService) ExecuteMultipleFiles(ctx context.Context, files []FileInput) error {
+// ReportTarget is where the point summaries of an upload are written: one per
+// purchase date, under FilePath with %s replaced by the date.
+type ReportTarget struct {
+	Store    storage.Storage
+	FilePath string
+	Format   report.Format
+}
+
+// ExecuteMultipleFiles stores the points of files and writes the live reports.
+func (a accumulatePointService) ExecuteMultipleFiles(ctx context.Context, files []FileInput) (*entity.ProcessResult, error) {
+	target := ReportTarget{Store: a.store, FilePath: tenant.Path(ctx, a.cfg.FilePath), Format: report.FormatCSV}
+	return a.executeMultipleFiles(ctx, files, target, false)
+}
+
+// DryRunMultipleFiles writes the reports the upload would produce to target without
+// storing any points, so the live reports are left alone.
+func (a accumulatePointService) DryRunMultipleFiles(ctx context.Context, files []FileInput, target ReportTarget) (*entity.ProcessResult, error) {
+	return a.executeMultipleFiles(ctx, files, target, true)
+}
+
+func (a accumulatePointService) executeMultipleFiles(ctx context.Context, files []FileInput, target ReportTarget, dryRun bool) (*entity.ProcessResult, error) {
 	if len(files) == 0 {
-		return apperr.ErrInvalidArgument.WithMessage("no files")
+		return nil, apperr.ErrInvalidArgument.WithMessage("no files")
 	}
 
 	allRecords := make(PurchaseRecords, 0)
 	purchasedDate := make(map[time.Time]struct{})
 
+	uploadedAt := time.Now().UTC()
 	for _, file := range files {
 		var records PurchaseRecords
 		if err := csv.UnmarshalWithHeaderValidation(file.Reader, &records); err != nil {
-			return apperr.ErrInvalidArgument.Wrap(err)
+			return nil, apperr.ErrInvalidArgument.Wrap(err)
 		}
 
 		purchasedDate[file.PurchasedDate] = struct{}{}
-		for _, record := range records {
+		for i, record := range records {
 			record.PurchaseDate = file.PurchasedDate
+			if err := record.parseTransaction(); err != nil {
+				message := fmt.Sprintf("%s row %d: %s", file.PurchasedDate.Format(time.DateOnly), i+1, err)
+				return nil, apperr.ErrInvalidArgument.WithMessage(message)
+			}
+			if len(file.Branches) > 0 && !slices.Contains(file.Branches, record.BranchID) {
+				message := fmt.Sprintf("%s row %d: branch %s is outside the branches this upload may credit", file.PurchasedDate.Format(time.DateOnly), i+1, record.BranchID)
+				return nil, apperr.ErrPermissionDenied.WithMessage(message)
+			}
 			allRecords = append(allRecords, record)
 		}
 	}
 
+	// Inputs are only archived once every file has been read and may be credited,
+	// so a refused upload leaves nothing behind.
+	if !dryRun {
+		for _, file := range files {
+			if err := a.archiveInput(ctx, file, uploadedAt); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	allRecords = allRecords.getUniqueRecords()
 
-	rules, err := a.ruleRepo.GetActiveRules(ctx, allRecords.mapRecordsToBranchCategories())
+	branchGroups, err := a.ruleRepo.GetBranchGroups(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	rules, err := a.ruleRepo.GetActiveRules(ctx,
+		allRecords.mapRecordsToBranchCategories(),
+		allRecords.mapRecordsToBranchProducts(),
+		allRecords.mapRecordsToBranchGroups(branchGroups),
+	)
+	if err != nil {
+		return nil, err
+	}
+	rules = branchGroups.Resolve(rules)
+
+	tiers, err := a.tierRepo.GetTiers(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	customers, err := a.customerRepo.GetCustomers(ctx, allRecords.getUniqueCustomerIDs())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	customerAggregates := calculateBatchPoints(rules, allRecords, customers)
-	if len(customerAggregates) > 0 {
-		err = a.customerRepo.UpdateBulkCustomers(ctx, customerAggregates)
+	// Purchases under the ID of a merged account credit the account it was merged
+	// into, and may turn out to be duplicates there.
+	if ids := mergedIDs(customers); len(ids) > 0 {
+		customers, err = a.loadMergedInto(ctx, customers)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		allRecords = allRecords.withMergedIDs(mergedIDs(customers)).getUniqueRecords()
 	}
 
-	customersUpdated, err := a.customerRepo.GetCustomers(ctx, []string{})
-	if err != nil {
-		return err
+	customerAggregates := calculateBatchPoints(rules, tiers, allRecords, customers)
+
+	// Household members earn into the household's balance, so its account is
+	// loaded too for a dry run to report it.
+	updates := customerAggregates
+	if ids := householdIDs(customers); len(ids) > 0 {
+		households, err := a.customerRepo.GetCustomers(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		updates = poolHouseholdPoints(customerAggregates, customers)
+		customers = append(customers, households...)
+	}
+
+	if len(updates) > 0 && !dryRun {
+		err = a.customerRepo.UpdateBulkCustomers(ctx, updates)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A dry run stored nothing, so its reports merge the would-be balances of the
+	// affected customers into the stored ones.
+	var overrides []entity.Customer
+	if dryRun {
+		overrides = applyCustomerUpdates(customers, updates)
 	}
 
 	for date := range purchasedDate {
 		dateString := date.Format(time.DateOnly)
-		err = saveFile(customersUpdated, a.cfg.FilePath, dateString)
+		write := func(w io.Writer) error {
+			return a.writeSummary(ctx, dateString, tiers, overrides, target.Format, w)
+		}
+
+		err = saveFile(ctx, target.Store, write, target.FilePath, dateString)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
+	result := &entity.ProcessResult{
+		Records:   len(allRecords),
+		Customers: len(customerAggregates),
+		DryRun:    dryRun,
+	}
+	for _, aggregate := range customerAggregates {
+		result.PointsAwarded += aggregate.PointsToAdd
+	}
+
+	return result, nil
+}
+
+// archiveInput keeps a copy of an uploaded file so the purchases it carried can be
+// inspected or replayed later.
+func (a accumulatePointService) archiveInput(ctx context.Context, file FileInput, uploadedAt time.Time) error {
+	if a.cfg.InputPath == "" || file.Name == "" {
+		return nil
+	}
+
+	if _, err := file.Reader.Seek(0, io.SeekStart); err != nil {
+		return apperr.ErrInternal.Wrap(err)
+	}
+
+	key := fmt.Sprintf(tenant.Path(ctx, a.cfg.InputPath), uploadedAt.Format("20060102T150405Z")+"_"+file.Name)
+	if err := a.store.Put(ctx, key, file.Reader, -1); err != nil {
+		return apperr.ErrInternal.Wrap(err)
+	}
+
 	return nil
 }
 
-func saveFile(customers []entity.Customer, filePath, dateString string) error {
-	fullPath := fmt.Sprintf(filePath, dateString)
+// WriteSummary encodes the point summary as of date in the given format. Rows are
+// written as they arrive from the database, so the summary is never held in memory.
+func (a accumulatePointService) WriteSummary(ctx context.Context, date time.Time, format report.Format, w io.Writer) error {
+	tiers, err := a.tierRepo.GetTiers(ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.writeSummary(ctx, date.Format(time.DateOnly), tiers, nil, format, w)
+}
 
-	dir := filepath.Dir(fullPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return apperr.ErrInternal.Wrap(fmt.Errorf("failed to create directory %s: %w", dir, err))
+func (a accumulatePointService) writeSummary(ctx context.Context, dateString string, tiers entity.Tiers, overrides []entity.Customer, format report.Format, w io.Writer) error {
+	writer, err := report.NewWriter(format, w, CustomerPointRecord{})
+	if err != nil {
+		return apperr.ErrInternal.Wrap(err)
 	}
 
-	file, err := os.Create(fullPath)
+	err = a.streamSummary(ctx, dateString, tiers, overrides, func(record *CustomerPointRecord) error {
+		if err := writer.Write(record); err != nil {
+			return apperr.ErrInternal.Wrap(err)
+		}
+		return nil
+	})
 	if err != nil {
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
 		return apperr.ErrInternal.Wrap(err)
 	}
-	defer file.Close()
 
-	records := entityToCustomerPointRecord(customers, dateString)
+	return nil
+}
 
-	sort.Slice(records, func(i, j int) bool {
-		if records[i].Points != records[j].Points {
-			return records[i].Points > records[j].Points
+// streamSummary calls fn for each row of the summary as of dateString, in summary
+// order. overrides replace the stored balances of the same customers. Rows carry
+// a tier only when tiers are given.
+func (a accumulatePointService) streamSummary(ctx context.Context, dateString string, tiers entity.Tiers, overrides []entity.Customer, fn func(*CustomerPointRecord) error) error {
+	pending := summaryRecords(overrides, dateString)
+	if len(tiers) > 0 {
+		date, err := time.Parse(time.DateOnly, dateString)
+		if err != nil {
+			return apperr.ErrInvalidArgument.Wrap(err)
+		}
+
+		byID := make(map[string]entity.Customer, len(overrides))
+		for _, customer := range overrides {
+			byID[customer.CustomerID] = customer
+		}
+		for _, record := range pending {
+			tier, _ := tiers.TierAsOf(byID[record.CustomerID], date)
+			record.Tier = tier.Name
+		}
+	}
+
+	excludeIDs := make([]string, 0, len(overrides))
+	for _, customer := range overrides {
+		excludeIDs = append(excludeIDs, customer.CustomerID)
+	}
+
+	err := a.customerRepo.StreamPointSummaries(ctx, dateString, excludeIDs, func(summary entity.PointSummary) error {
+		record := &CustomerPointRecord{
+			CustomerID:       summary.CustomerID,
+			Points:           summary.Points,
+			LastPurchaseDate: summary.LastPurchaseDate,
+		}
+		if tier, ok := tiers.Qualify(summary.QualifyingPoints, summary.QualifyingSpend); ok {
+			record.Tier = tier.Name
 		}
 
-		return records[i].LastPurchaseDate > records[j].LastPurchaseDate
+		for len(pending) > 0 && lessSummary(pending[0], record) {
+			if err := fn(pending[0]); err != nil {
+				return err
+			}
+			pending = pending[1:]
+		}
+
+		return fn(record)
 	})
+	if err != nil {
+		return err
+	}
 
-	if err = gocsv.MarshalFile(&records, file); err != nil {
-		return apperr.ErrInternal.Wrap(err)
+	for _, record := range pending {
+		if err = fn(record); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func calculateBatchPoints(rules []entity.Rule, records PurchaseRecords, customers entity.Customers) []entity.UpdateCustomer {
+// ListReports returns the point summaries kept in storage, oldest first.
+func (a accumulatePointService) ListReports(ctx context.Context) ([]entity.Report, error) {
+	prefix, suffix, _ := strings.Cut(tenant.Path(ctx, a.cfg.FilePath), "%s")
+
+	keys, err := a.store.List(ctx, prefix)
+	if err != nil {
+		return nil, apperr.ErrInternal.Wrap(err)
+	}
+
+	reports := make([]entity.Report, 0, len(keys))
+	for _, key := range keys {
+		if !strings.HasSuffix(key, suffix) {
+			continue
+		}
+
+		date, err := PurchasedDateFromFilename(path.Base(key))
+		if err != nil {
+			continue
+		}
+		reports = append(reports, entity.Report{Date: date, Key: key})
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Date.Before(reports[j].Date)
+	})
+
+	return reports, nil
+}
+
+// saveFile stores what write produces under filePath for dateString. The output is
+// piped to the store as it is written rather than buffered.
+func saveFile(ctx context.Context, store storage.Storage, write func(io.Writer) error, filePath, dateString string) error {
+	pr, pw := io.Pipe()
+
+	// done is filled before the read side is closed, so a write that fails because
+	// the store gave up always finds the store's error waiting.
+	done := make(chan error, 1)
+	go func() {
+		err := store.Put(ctx, fmt.Sprintf(filePath, dateString), pr, -1)
+		done <- err
+		pr.CloseWithError(err)
+	}()
+
+	writeErr := write(pw)
+
+	select {
+	case putErr := <-done:
+		pw.Close()
+		if putErr != nil {
+			return apperr.ErrInternal.Wrap(putErr)
+		}
+		return writeErr
+	default:
+	}
+
+	pw.CloseWithError(writeErr)
+
+	putErr := <-done
+	if writeErr != nil {
+		return writeErr
+	}
+	if putErr != nil {
+		return apperr.ErrInternal.Wrap(putErr)
+	}
+
+	return nil
+}
+
+// summaryRecords returns the balances as of dateString in summary order.
+func summaryRecords(customers []entity.Customer, dateString string) []*CustomerPointRecord {
+	records := entityToCustomerPointRecord(customers, dateString)
+
+	sort.Slice(records, func(i, j int) bool {
+		return lessSummary(records[i], records[j])
+	})
+
+	return records
+}
+
+// lessSummary orders the summary: highest points first and the most recent purchase
+// first among ties, then by customer ID so ranks are stable.
+func lessSummary(a, b *CustomerPointRecord) bool {
+	if a.Points != b.Points {
+		return a.Points > b.Points
+	}
+
+	if a.LastPurchaseDate != b.LastPurchaseDate {
+		return a.LastPurchaseDate > b.LastPurchaseDate
+	}
+
+	return a.CustomerID < b.CustomerID
+}
+
+// calculateBatchPoints awards points for records, multiplied by the tier each
+// customer held at the end of the day before the purchase. Records are taken in
+// date order so points earned earlier in the batch count toward later tiers. A
+// day's returns are taken after its purchases, so a purchase can be returned the
+// day it was made.
+func calculateBatchPoints(rules []entity.Rule, tiers entity.Tiers, records PurchaseRecords, customers entity.Customers) []entity.UpdateCustomer {
+	rules = compileRules(rules)
+
 	recordsSetup := make(map[string]entity.UpdateCustomer, len(customers))
+	tierOf := newTierLookup(tiers, customers, recordsSetup)
+
+	purchases, returns := records.splitReturns()
+	returns = returns.sortedByDate()
+
+	purchaserOf := newPurchaserLookup(customers)
+	history := newPurchaseHistory(purchases, customers)
+
+	next := 0
+	for _, basket := range purchases.baskets() {
+		for ; next < len(returns) && returns[next].PurchaseDate.Before(basket[0].PurchaseDate); next++ {
+			addReturn(recordsSetup, returns[next], customers)
+		}
+
+		// Every line of a basket is the same customer on the same day.
+		tier := tierOf(basket[0].CustomerID, basket[0].PurchaseDate)
+
+		var basketPurchaser entity.Purchaser
+		for i, record := range basket {
+			purchaser := purchaserOf(record.CustomerID, tier)
+			if i == 0 {
+				basketPurchaser = purchaser
+			}
+
+			for _, rule := range rules {
+				points, applied := validateAndCalculatePoints(rule, *record, customers, purchaser)
+				if !applied {
+					continue
+				}
+
+				addMovement(recordsSetup, record, rule, tier, tier.Apply(points))
+			}
+		}
 
-	for _, record := range records {
 		for _, rule := range rules {
-			points, applied := validateAndCalculatePoints(rule, *record, customers)
+			points, line, applied := calculateBasketPoints(rule, basket, customers, basketPurchaser)
 			if !applied {
 				continue
 			}
 
-			customerID := record.CustomerID
-			if _, ok := recordsSetup[customerID]; !ok {
-				recordsSetup[customerID] = entity.UpdateCustomer{
-					CustomerID:       customerID,
-					PointsToAdd:      points,
-					LastPurchaseDate: record.PurchaseDate,
-					Records: []entity.Record{
-						{
-							ProductID:    record.ProductID,
-							BranchID:     record.BranchID,
-							Amount:       record.PurchasedAmount,
-							PurchaseDate: record.PurchaseDate,
-						},
-					},
-					PointsByDate: map[string]int64{record.PurchaseDate.Format(time.DateOnly): points},
-				}
-			} else {
-				customer := recordsSetup[customerID]
-				customer.PointsToAdd += points
-				customer.Records = append(customer.Records, entity.Record{
-					ProductID:    record.ProductID,
-					BranchID:     record.BranchID,
-					Amount:       record.PurchasedAmount,
-					PurchaseDate: record.PurchaseDate,
-				})
-
-				if record.PurchaseDate.After(customer.LastPurchaseDate) {
-					customer.LastPurchaseDate = record.PurchaseDate
-				}
-				customer.PointsByDate[record.PurchaseDate.Format(time.DateOnly)] += points
+			addMovement(recordsSetup, line, rule, tier, tier.Apply(points))
+		}
 
-				recordsSetup[customerID] = customer
+		for _, rule := range rules {
+			points, line, applied := calculateEngagementPoints(rule, basket, history[basket[0].CustomerID], basketPurchaser)
+			if !applied {
+				continue
 			}
+
+			addMovement(recordsSetup, line, rule, tier, tier.Apply(points))
 		}
 	}
 
+	for ; next < len(returns); next++ {
+		addReturn(recordsSetup, returns[next], customers)
+	}
+
 	if len(recordsSetup) == 0 {
 		return nil
 	}
@@ -183,30 +483,139 @@ func calculateBatchPoints(rules []entity.Rule, records PurchaseRecords, customer
 	return result
 }
 
-func validateAndCalculatePoints(rule entity.Rule, record PurchaseRecord, customers entity.Customers) (points int64, applied bool) {
-	customer, found := customers.GetCustomerByID(record.CustomerID)
-	if found {
-		isDuplicateRecord := slices.ContainsFunc(customer.Records, func(r entity.Record) bool {
-			return r.PurchaseDate.Equal(record.PurchaseDate) &&
-				r.BranchID == record.BranchID &&
-				r.ProductID == record.ProductID &&
-				r.Amount.Equal(record.PurchasedAmount)
-		})
+// compileRules compiles the rules' expressions once for the batch. A rule whose
+// expression no longer compiles is left out rather than failing the batch.
+func compileRules(rules []entity.Rule) []entity.Rule {
+	result := make([]entity.Rule, 0, len(rules))
+	for _, rule := range rules {
+		compiled, err := rule.Compile()
+		if err != nil {
+			log.Println(apperr.ErrInternal, "rule", rule.ID, err)
+			continue
+		}
+		result = append(result, compiled)
+	}
+	return result
+}
+
+func addMovement(recordsSetup map[string]entity.UpdateCustomer, record *PurchaseRecord, rule entity.Rule, tier entity.Tier, points int64) {
+	addRecord(recordsSetup, record.CustomerID, newMovement(record, rule, tier, points))
+}
+
+// addReturn takes back the points of the purchase a return refers to, unless the
+// return was already stored.
+func addReturn(recordsSetup map[string]entity.UpdateCustomer, record *PurchaseRecord, customers entity.Customers) {
+	customer, _ := customers.GetCustomerByID(record.CustomerID)
+	if isStoredReturn(*record, customer.Records) {
+		return
+	}
 
-		if isDuplicateRecord {
-			return 0, false
+	movements := append(slices.Clone(customer.Records), recordsSetup[record.CustomerID].Records...)
+	for _, movement := range calculateClawback(*record, movements) {
+		addRecord(recordsSetup, record.CustomerID, movement)
+	}
+}
+
+// addRecord adds a movement to the customer's update. Returns do not move the
+// last purchase date.
+func addRecord(recordsSetup map[string]entity.UpdateCustomer, customerID string, movement entity.Record) {
+	customer, ok := recordsSetup[customerID]
+	if !ok {
+		customer = entity.UpdateCustomer{
+			CustomerID:   customerID,
+			PointsByDate: make(map[string]int64),
 		}
 	}
 
-	if record.PurchasedAmount.LessThan(rule.Conditions.MinAmount) {
+	customer.PointsToAdd += movement.Points
+	customer.Records = append(customer.Records, movement)
+	customer.PointsByDate[movement.PurchaseDate.Format(time.DateOnly)] += movement.Points
+
+	if movement.IsPurchase() && movement.PurchaseDate.After(customer.LastPurchaseDate) {
+		customer.LastPurchaseDate = movement.PurchaseDate
+	}
+
+	recordsSetup[customerID] = customer
+}
+
+// newTierLookup returns the tier a customer holds at the end of the day before
+// date, from the stored history and what the batch has awarded so far. Only the
+// batch's earlier days fall in that window, and they are complete by the time a
+// later day is reached, so each customer and day is worked out once.
+func newTierLookup(tiers entity.Tiers, customers entity.Customers, batch map[string]entity.UpdateCustomer) func(customerID string, date time.Time) entity.Tier {
+	type key struct {
+		customerID string
+		date       time.Time
+	}
+	cache := make(map[key]entity.Tier)
+
+	return func(customerID string, date time.Time) entity.Tier {
+		if len(tiers) == 0 {
+			return entity.Tier{}
+		}
+
+		k := key{customerID: customerID, date: date}
+		if tier, ok := cache[k]; ok {
+			return tier
+		}
+
+		customer, found := customers.GetCustomerByID(customerID)
+		if !found {
+			customer = entity.Customer{CustomerID: customerID}
+		}
+		if update, ok := batch[customerID]; ok {
+			customer = applyCustomerUpdates([]entity.Customer{customer}, []entity.UpdateCustomer{update})[0]
+		}
+
+		tier, _ := tiers.TierAsOf(customer, date.AddDate(0, 0, -1))
+		cache[k] = tier
+		return tier
+	}
+}
+
+// newPurchaserLookup returns what rules know about the customer behind each
+// purchase, which must be asked for in purchase order. Only the first purchase of
+// a customer with no stored purchases is a first purchase, so a customer who
+// buys several products that day gets a first purchase bonus once.
+func newPurchaserLookup(customers entity.Customers) func(customerID string, tier entity.Tier) entity.Purchaser {
+	purchased := make(map[string]struct{})
+
+	return func(customerID string, tier entity.Tier) entity.Purchaser {
+		purchaser := entity.Purchaser{Tier: tier.Name}
+
+		customer, found := customers.GetCustomerByID(customerID)
+		if found {
+			purchaser.Attributes = customer.Attributes
+		}
+
+		if _, ok := purchased[customerID]; !ok {
+			purchased[customerID] = struct{}{}
+			purchaser.FirstPurchase = !slices.ContainsFunc(customer.Records, entity.Record.IsPurchase)
+		}
+
+		return purchaser
+	}
+}
+
+func newMovement(record *PurchaseRecord, rule entity.Rule, tier entity.Tier, points int64) entity.Record {
+	movement := record.toPurchase()
+	movement.RuleID = rule.ID
+	movement.RuleVersion = rule.Version
+	movement.Tier = tier.Name
+	movement.Points = points
+	return movement
+}
+
+func validateAndCalculatePoints(rule entity.Rule, record PurchaseRecord, customers entity.Customers, purchaser entity.Purchaser) (points int64, applied bool) {
+	if isStoredPurchase(record, customers) {
 		return 0, false
 	}
 
-	if rule.Conditions.BranchID != "" && rule.Conditions.BranchID != record.BranchID {
+	if record.PurchasedAmount.LessThan(rule.Conditions.MinAmount) {
 		return 0, false
 	}
 
-	if len(rule.Conditions.CategoryID) > 0 && !slices.Contains(rule.Conditions.CategoryID, record.CategoryID) {
+	if !matchesLine(rule, record, purchaser) {
 		return 0, false
 	}
 
@@ -224,7 +633,117 @@ func validateAndCalculatePoints(rule entity.Rule, record PurchaseRecord, custome
 			pointsDecimal := record.PurchasedAmount.Div(ratioUnitDecimal).Floor().Mul(decimal.NewFromInt(rule.Reward.Value))
 			return pointsDecimal.IntPart(), true
 		}
+
+	case entity.CustomRule:
+		points, applied, err := rule.Reward.Evaluate(record.toPurchase(), purchaser)
+		if err != nil {
+			log.Println(err, "rule", rule.ID, "customer", record.CustomerID, record.PurchaseDate.Format(time.DateOnly))
+		}
+		return points, applied
 	}
 
 	return 0, false
 }
+
+// calculateBasketPoints returns what a basket rule awards a basket and the line the
+// movement is stored on. Only the lines that meet the rule's line conditions
+// count towards the basket, and lines already stored for the customer are left out.
+func calculateBasketPoints(rule entity.Rule, basket PurchaseRecords, customers entity.Customers, purchaser entity.Purchaser) (points int64, line *PurchaseRecord, applied bool) {
+	if !rule.RuleType.IsBasket() {
+		return 0, nil, false
+	}
+
+	total := decimal.Zero
+	categories := make(map[string]struct{})
+	products := make(map[string]struct{})
+	for _, record := range basket {
+		if isStoredPurchase(*record, customers) || !matchesLine(rule, *record, purchaser) {
+			continue
+		}
+
+		if line == nil {
+			line = record
+		}
+		total = total.Add(record.PurchasedAmount)
+		categories[record.CategoryID] = struct{}{}
+		products[record.ProductID] = struct{}{}
+	}
+
+	if line == nil || total.LessThan(rule.Conditions.MinAmount) {
+		return 0, nil, false
+	}
+
+	switch rule.RuleType {
+	case entity.BasketCategoriesRule:
+		if len(categories) < rule.Conditions.MinCategories {
+			return 0, nil, false
+		}
+
+	case entity.BasketBundleRule:
+		for _, productID := range rule.Conditions.RequiredProductIDs {
+			if _, ok := products[productID]; !ok {
+				return 0, nil, false
+			}
+		}
+	}
+
+	return rule.Reward.Value, line, true
+}
+
+// isStoredPurchase reports whether the purchase was already stored for the
+// customer by an earlier upload. When the record carries a receipt, only lines of
+// that receipt count, so identical lines on two receipts are both credited.
+func isStoredPurchase(record PurchaseRecord, customers entity.Customers) bool {
+	customer, found := customers.GetCustomerByID(record.CustomerID)
+	if !found {
+		return false
+	}
+
+	return slices.ContainsFunc(customer.Records, func(r entity.Record) bool {
+		return r.IsPurchase() &&
+			r.PurchaseDate.Equal(record.PurchaseDate) &&
+			r.BranchID == record.BranchID &&
+			r.ProductID == record.ProductID &&
+			r.Amount.Equal(record.PurchasedAmount) &&
+			(record.ReceiptID == "" || r.ReceiptID == record.ReceiptID)
+	})
+}
+
+// matchesLine checks the conditions a rule puts on a single purchase line, apart
+// from MinAmount, which basket rules apply to the whole basket.
+func matchesLine(rule entity.Rule, record PurchaseRecord, purchaser entity.Purchaser) bool {
+	if !rule.InForce(record.PurchaseDate) {
+		return false
+	}
+
+	if !inScope(rule, record) {
+		return false
+	}
+
+	if !rule.Conditions.MatchesPurchaser(purchaser, record.PurchaseDate) {
+		return false
+	}
+
+	return rule.Conditions.MatchesExpression(record.toPurchase(), purchaser)
+}
+
+// inScope checks the branch, category and product conditions of a rule.
+func inScope(rule entity.Rule, record PurchaseRecord) bool {
+	if !rule.Conditions.MatchesBranch(record.BranchID) {
+		return false
+	}
+
+	if len(rule.Conditions.CategoryID) > 0 && !slices.Contains(rule.Conditions.CategoryID, record.CategoryID) {
+		return false
+	}
+
+	if slices.Contains(rule.Conditions.ExcludeCategoryIDs, record.CategoryID) {
+		return false
+	}
+
+	if len(rule.Conditions.ProductIDs) > 0 && !slices.Contains(rule.Conditions.ProductIDs, record.ProductID) {
+		return false
+	}
+
+	return !slices.Contains(rule.Conditions.ExcludeProductIDs, record.ProductID)
+}
@@ -3,8 +3,10 @@ package accumulatepoints
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -13,8 +15,12 @@ import (
 	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
 	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_customer"
 	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_rule"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_tier"
 	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
 	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+	"github.com/sirawong/point-accumulate-interview/pkg/storage"
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
 )
@@ -24,6 +30,7 @@ type AccumulatePointServiceTestSuite struct {
 	mockCtrl     *gomock.Controller
 	mockRuleRepo *mocks_rule.MockRuleRepository
 	mockCustRepo *mocks_customer.MockCustomerRepository
+	mockTierRepo *mocks_tier.MockTierRepository
 	service      AccumulatePointService
 	cfg          *config.Config
 	tempDir      string
@@ -37,6 +44,9 @@ func (suite *AccumulatePointServiceTestSuite) SetupTest() {
 	suite.mockCtrl = gomock.NewController(suite.T())
 	suite.mockRuleRepo = mocks_rule.NewMockRuleRepository(suite.mockCtrl)
 	suite.mockCustRepo = mocks_customer.NewMockCustomerRepository(suite.mockCtrl)
+	suite.mockTierRepo = mocks_tier.NewMockTierRepository(suite.mockCtrl)
+	suite.mockTierRepo.EXPECT().GetTiers(gomock.Any()).Return(nil, nil).AnyTimes()
+	suite.mockRuleRepo.EXPECT().GetBranchGroups(gomock.Any()).Return(nil, nil).AnyTimes()
 
 	tempDir, err := os.MkdirTemp("", "test_output_")
 	suite.NoError(err)
@@ -45,7 +55,7 @@ func (suite *AccumulatePointServiceTestSuite) SetupTest() {
 	suite.cfg = &config.Config{
 		FilePath: filepath.Join(tempDir, "output_%s.csv"),
 	}
-	suite.service = NewAccumulatePointService(suite.mockRuleRepo, suite.mockCustRepo, suite.cfg)
+	suite.service = NewAccumulatePointService(suite.mockRuleRepo, suite.mockCustRepo, suite.mockTierRepo, storage.NewLocal(""), suite.cfg)
 }
 
 func (suite *AccumulatePointServiceTestSuite) TearDownTest() {
@@ -58,7 +68,7 @@ func (suite *AccumulatePointServiceTestSuite) TearDownTest() {
 }
 
 func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_NoFiles() {
-	err := suite.service.ExecuteMultipleFiles(context.Background(), []FileInput{})
+	_, err := suite.service.ExecuteMultipleFiles(context.Background(), []FileInput{})
 
 	suite.Error(err)
 	suite.Contains(err.Error(), "no files")
@@ -76,12 +86,53 @@ func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_CSVUnmars
 		},
 	}
 
-	err := suite.service.ExecuteMultipleFiles(ctx, files)
+	_, err := suite.service.ExecuteMultipleFiles(ctx, files)
 
 	suite.Error(err)
 	suite.IsType(&apperr.AppError{}, err)
 }
 
+func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_InvalidReturn() {
+	ctx := context.Background()
+
+	csvData := "customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency,transaction_type,original_purchase_date\n" +
+		"U000001,123121,CT1001,ELECTRONICS,BR0001,600.00,THB,,\n" +
+		"U000001,123121,CT1001,ELECTRONICS,BR0001,600.00,THB,RETURN,2025-01-20"
+
+	files := []FileInput{
+		{
+			PurchasedDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			Reader:        strings.NewReader(csvData),
+		},
+	}
+
+	_, err := suite.service.ExecuteMultipleFiles(ctx, files)
+
+	suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+	suite.ErrorContains(err, "2025-01-15 row 2: original_purchase_date must not be after the RETURN")
+}
+
+func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_BranchOutsideScope() {
+	ctx := context.Background()
+
+	csvData := "customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency\n" +
+		"U000001,123121,CT1001,ELECTRONICS,BR0001,600.00,THB\n" +
+		"U000002,123121,CT1001,ELECTRONICS,BR0002,600.00,THB"
+
+	files := []FileInput{
+		{
+			PurchasedDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			Reader:        strings.NewReader(csvData),
+			Branches:      []string{"BR0001"},
+		},
+	}
+
+	_, err := suite.service.ExecuteMultipleFiles(ctx, files)
+
+	suite.Equal(apperr.ErrPermissionDenied.Code, apperr.GetCode(err))
+	suite.ErrorContains(err, "2025-01-15 row 2: branch BR0002 is outside the branches this upload may credit")
+}
+
 func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_NoPointsCalculated() {
 	ctx := context.Background()
 	purchaseDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
@@ -119,7 +170,7 @@ func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_NoPointsC
 	updatedCustomers := customers
 
 	suite.mockRuleRepo.EXPECT().
-		GetActiveRules(ctx, gomock.Any()).
+		GetActiveRules(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(rules, nil)
 
 	suite.mockCustRepo.EXPECT().
@@ -127,14 +178,151 @@ func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_NoPointsC
 		Return(customers, nil)
 
 	suite.mockCustRepo.EXPECT().
-		GetCustomers(ctx, []string{}).
-		Return(updatedCustomers, nil)
+		StreamPointSummaries(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries(updatedCustomers))
 
-	err := suite.service.ExecuteMultipleFiles(ctx, files)
+	_, err := suite.service.ExecuteMultipleFiles(ctx, files)
 
 	suite.NoError(err)
 }
 
+func (suite *AccumulatePointServiceTestSuite) TestDryRunMultipleFiles_ReceiptBaskets() {
+	ctx := context.Background()
+
+	csvData := "customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency,receipt_id\n" +
+		"U000001,123121,CT1001,ELECTRONICS,BR0001,600.00,THB,R1\n" +
+		"U000001,123122,CT1002,FASHION,BR0001,500.00,THB,R1\n" +
+		"U000001,123123,CT1002,FASHION,BR0001,900.00,THB,R2"
+
+	files := []FileInput{
+		{
+			PurchasedDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			Reader:        strings.NewReader(csvData),
+		},
+	}
+
+	rules := []entity.Rule{
+		{
+			ID:         "BASKET",
+			RuleType:   entity.BasketSpendRule,
+			Conditions: entity.Conditions{MinAmount: decimal.NewFromInt(1000)},
+			Reward:     entity.Reward{Value: 50},
+		},
+	}
+
+	suite.mockRuleRepo.EXPECT().
+		GetActiveRules(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(rules, nil)
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(ctx, []string{"U000001"}).
+		Return(nil, nil)
+	suite.mockCustRepo.EXPECT().
+		StreamPointSummaries(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries(nil))
+
+	target := ReportTarget{Store: storage.NewMemory(), FilePath: "preview/point-summary_%s.csv", Format: report.FormatCSV}
+	result, err := suite.service.DryRunMultipleFiles(ctx, files, target)
+
+	suite.NoError(err)
+	// Only receipt R1 reaches 1000 THB; R2 is a separate basket.
+	suite.Equal(int64(50), result.PointsAwarded)
+}
+
+func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_HouseholdPooling() {
+	ctx := context.Background()
+	purchaseDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	csvData := "customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency\n" +
+		"U000001,123121,CT1001,ELECTRONICS,BR0001,600.00,THB\n" +
+		"U000002,123121,CT1001,ELECTRONICS,BR0001,600.00,THB"
+
+	files := []FileInput{{PurchasedDate: purchaseDate, Reader: strings.NewReader(csvData)}}
+	rules := []entity.Rule{{ID: "RULE001", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 10}}}
+
+	suite.mockRuleRepo.EXPECT().
+		GetActiveRules(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(rules, nil)
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(ctx, []string{"U000001", "U000002"}).
+		Return([]entity.Customer{{CustomerID: "U000001", HouseholdID: "HH0001"}}, nil)
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(ctx, []string{"HH0001"}).
+		Return([]entity.Customer{{CustomerID: "HH0001", Points: 40}}, nil)
+	suite.mockCustRepo.EXPECT().
+		UpdateBulkCustomers(ctx, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, updates []entity.UpdateCustomer) error {
+			suite.Require().Len(updates, 3)
+			byID := make(map[string]entity.UpdateCustomer, len(updates))
+			for _, update := range updates {
+				byID[update.CustomerID] = update
+			}
+
+			member := byID["U000001"]
+			suite.Equal(int64(0), member.PointsToAdd)
+			suite.Equal(entity.PoolTransaction, member.Records[1].Type)
+			suite.Equal("HH0001", member.Records[1].CounterpartyID)
+			suite.Equal(int64(10), byID["U000002"].PointsToAdd)
+			suite.Equal(int64(10), byID["HH0001"].PointsToAdd)
+			suite.Equal(map[string]int64{"2025-01-15": 10}, byID["HH0001"].PointsByDate)
+			return nil
+		})
+	suite.mockCustRepo.EXPECT().
+		StreamPointSummaries(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries(nil))
+
+	result, err := suite.service.ExecuteMultipleFiles(ctx, files)
+
+	suite.NoError(err)
+	suite.Equal(2, result.Customers)
+	suite.Equal(int64(20), result.PointsAwarded)
+}
+
+func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_MergedCustomer() {
+	ctx := context.Background()
+	purchaseDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	csvData := "customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency\n" +
+		"U000009,123121,CT1001,ELECTRONICS,BR0001,600.00,THB\n" +
+		"U000009,123122,CT1001,ELECTRONICS,BR0001,50.00,THB\n" +
+		"U000001,123122,CT1001,ELECTRONICS,BR0001,50.00,THB"
+
+	files := []FileInput{{PurchasedDate: purchaseDate, Reader: strings.NewReader(csvData)}}
+	rules := []entity.Rule{{ID: "RULE001", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 10}}}
+	stored := entity.Record{ProductID: "123121", BranchID: "BR0001", Amount: decimal.RequireFromString("600.00"), PurchaseDate: purchaseDate, RuleID: "RULE001", Points: 10}
+
+	suite.mockRuleRepo.EXPECT().
+		GetActiveRules(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(rules, nil)
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(ctx, []string{"U000009", "U000001"}).
+		Return([]entity.Customer{
+			{CustomerID: "U000009", MergedInto: "U000002"},
+			{CustomerID: "U000001", Points: 10, Records: []entity.Record{stored}, Aliases: []string{"U000002", "U000009"}},
+		}, nil)
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(ctx, []string{"U000002"}).
+		Return([]entity.Customer{{CustomerID: "U000002", MergedInto: "U000001"}}, nil)
+	suite.mockCustRepo.EXPECT().
+		UpdateBulkCustomers(ctx, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, updates []entity.UpdateCustomer) error {
+			suite.Require().Len(updates, 1)
+			suite.Equal("U000001", updates[0].CustomerID)
+			suite.Equal(int64(10), updates[0].PointsToAdd)
+			suite.Require().Len(updates[0].Records, 1)
+			suite.Equal("123122", updates[0].Records[0].ProductID)
+			return nil
+		})
+	suite.mockCustRepo.EXPECT().
+		StreamPointSummaries(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries(nil))
+
+	result, err := suite.service.ExecuteMultipleFiles(ctx, files)
+
+	suite.NoError(err)
+	suite.Equal(2, result.Records)
+	suite.Equal(int64(10), result.PointsAwarded)
+}
+
 func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_MultipleFiles() {
 	ctx := context.Background()
 
@@ -202,7 +390,7 @@ func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_MultipleF
 	}
 
 	suite.mockRuleRepo.EXPECT().
-		GetActiveRules(ctx, gomock.Any()).
+		GetActiveRules(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(rules, nil)
 
 	suite.mockCustRepo.EXPECT().
@@ -214,10 +402,11 @@ func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_MultipleF
 		Return(nil)
 
 	suite.mockCustRepo.EXPECT().
-		GetCustomers(ctx, []string{}).
-		Return(updatedCustomers, nil)
+		StreamPointSummaries(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries(updatedCustomers)).
+		Times(2)
 
-	err := suite.service.ExecuteMultipleFiles(ctx, files)
+	_, err := suite.service.ExecuteMultipleFiles(ctx, files)
 
 	suite.NoError(err)
 
@@ -276,7 +465,7 @@ func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_Duplicate
 	updatedCustomers := customers
 
 	suite.mockRuleRepo.EXPECT().
-		GetActiveRules(ctx, gomock.Any()).
+		GetActiveRules(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(rules, nil)
 
 	suite.mockCustRepo.EXPECT().
@@ -298,10 +487,10 @@ func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_Duplicate
 		})
 
 	suite.mockCustRepo.EXPECT().
-		GetCustomers(ctx, []string{}).
-		Return(updatedCustomers, nil)
+		StreamPointSummaries(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries(updatedCustomers))
 
-	err := suite.service.ExecuteMultipleFiles(ctx, files)
+	_, err := suite.service.ExecuteMultipleFiles(ctx, files)
 
 	suite.NoError(err)
 }
@@ -361,8 +550,8 @@ func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_ComplexRu
 	updatedCustomers := customers
 
 	suite.mockRuleRepo.EXPECT().
-		GetActiveRules(ctx, gomock.Any()).
-		DoAndReturn(func(ctx context.Context, branchCategories map[string][]string) ([]entity.Rule, error) {
+		GetActiveRules(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, branchCategories, branchProducts, branchGroups map[string][]string) ([]entity.Rule, error) {
 
 			expectedMapping := map[string][]string{
 				"BR0001": {"CT1001"},
@@ -394,14 +583,356 @@ func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_ComplexRu
 		})
 
 	suite.mockCustRepo.EXPECT().
-		GetCustomers(ctx, []string{}).
-		Return(updatedCustomers, nil)
+		StreamPointSummaries(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries(updatedCustomers))
 
-	err := suite.service.ExecuteMultipleFiles(ctx, files)
+	_, err := suite.service.ExecuteMultipleFiles(ctx, files)
 
 	suite.NoError(err)
 }
 
+func (suite *AccumulatePointServiceTestSuite) TestDryRunMultipleFiles_DoesNotStorePoints() {
+	ctx := context.Background()
+	purchaseDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	csvData := "customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency\n" +
+		"U000001,123121,CT1001,ELECTRONICS,BR0001,100.00,THB"
+
+	files := []FileInput{
+		{
+			PurchasedDate: purchaseDate,
+			Reader:        strings.NewReader(csvData),
+		},
+	}
+
+	rules := []entity.Rule{
+		{
+			ID:       "RULE001",
+			RuleType: entity.FixedPointRule,
+			Reward:   entity.Reward{Value: 10},
+		},
+	}
+
+	existing := []entity.Customer{
+		{
+			CustomerID:   "U000002",
+			Points:       7,
+			PointsByDate: map[string]int64{"2025-01-14": 7},
+		},
+	}
+
+	suite.mockRuleRepo.EXPECT().
+		GetActiveRules(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(rules, nil)
+
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(ctx, []string{"U000001"}).
+		Return(nil, nil)
+
+	suite.mockCustRepo.EXPECT().
+		StreamPointSummaries(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries(existing))
+
+	store := storage.NewMemory()
+	target := ReportTarget{Store: store, FilePath: "preview/point-summary_%s.json", Format: report.FormatJSON}
+	_, err := suite.service.DryRunMultipleFiles(ctx, files, target)
+
+	suite.NoError(err)
+
+	file, err := store.Open(ctx, "preview/point-summary_2025-01-15.json")
+	suite.Require().NoError(err)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	suite.NoError(err)
+	suite.Contains(string(content), `"customer_id":"U000001","points":10,"last_purchase_date":"2025-01-15"`)
+	suite.Contains(string(content), `"customer_id":"U000002","points":7,"last_purchase_date":"2025-01-14"`)
+
+	// The live reports under FILE_PATH are left alone.
+	_, err = os.Stat(fmt.Sprintf(suite.cfg.FilePath, "2025-01-15"))
+	suite.ErrorIs(err, os.ErrNotExist)
+}
+
+func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_UsesStorage() {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	cfg := &config.Config{
+		FilePath:  "reports/point-summary_%s.csv",
+		InputPath: "inputs/%s",
+	}
+	service := NewAccumulatePointService(suite.mockRuleRepo, suite.mockCustRepo, suite.mockTierRepo, store, cfg)
+
+	csvData := "customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency\n" +
+		"U000001,123121,CT1001,ELECTRONICS,BR0001,100.00,THB"
+
+	files := []FileInput{
+		{
+			Name:          "purchases_2025-01-15.csv",
+			PurchasedDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			Reader:        strings.NewReader(csvData),
+		},
+	}
+
+	rules := []entity.Rule{
+		{
+			ID:       "RULE001",
+			RuleType: entity.FixedPointRule,
+			Reward:   entity.Reward{Value: 10},
+		},
+	}
+
+	suite.mockRuleRepo.EXPECT().
+		GetActiveRules(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(rules, nil)
+
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(ctx, []string{"U000001"}).
+		Return(nil, nil)
+
+	suite.mockCustRepo.EXPECT().
+		UpdateBulkCustomers(ctx, gomock.Any()).
+		Return(nil)
+
+	suite.mockCustRepo.EXPECT().
+		StreamPointSummaries(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries([]entity.Customer{
+			{
+				CustomerID:   "U000001",
+				Points:       10,
+				PointsByDate: map[string]int64{"2025-01-15": 10},
+			},
+		}))
+
+	result, err := service.ExecuteMultipleFiles(ctx, files)
+	suite.NoError(err)
+	suite.Equal(&entity.ProcessResult{Records: 1, Customers: 1, PointsAwarded: 10}, result)
+
+	inputs, err := store.List(ctx, "inputs/")
+	suite.NoError(err)
+	suite.Require().Len(inputs, 1)
+	suite.True(strings.HasSuffix(inputs[0], "_purchases_2025-01-15.csv"))
+
+	input, err := store.Open(ctx, inputs[0])
+	suite.Require().NoError(err)
+	archived, err := io.ReadAll(input)
+	suite.NoError(err)
+	suite.Equal(csvData, string(archived))
+
+	report, err := store.Open(ctx, "reports/point-summary_2025-01-15.csv")
+	suite.Require().NoError(err)
+	content, err := io.ReadAll(report)
+	suite.NoError(err)
+	suite.Contains(string(content), "U000001,10,2025-01-15")
+}
+
+func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_RefusedUploadIsNotArchived() {
+	ctx := context.Background()
+	header := "customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency\n"
+	valid := FileInput{
+		Name:          "purchases_2025-01-14.csv",
+		PurchasedDate: time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC),
+		Branches:      []string{"BR0001"},
+		Reader:        strings.NewReader(header + "U000001,123121,CT1001,ELECTRONICS,BR0001,100.00,THB"),
+	}
+
+	testCases := []struct {
+		name    string
+		invalid FileInput
+		code    string
+	}{
+		{
+			name: "malformed CSV",
+			invalid: FileInput{
+				Name:          "purchases_2025-01-15.csv",
+				PurchasedDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+				Reader:        strings.NewReader("customer_id,amount\nU000001,100"),
+			},
+			code: apperr.ErrInvalidArgument.Code,
+		},
+		{
+			name: "branch outside scope",
+			invalid: FileInput{
+				Name:          "purchases_2025-01-15.csv",
+				PurchasedDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+				Branches:      []string{"BR0001"},
+				Reader:        strings.NewReader(header + "U000001,123121,CT1001,ELECTRONICS,BR0002,100.00,THB"),
+			},
+			code: apperr.ErrPermissionDenied.Code,
+		},
+	}
+
+	for _, tc := range testCases {
+		store := storage.NewMemory()
+		cfg := &config.Config{
+			FilePath:  "reports/point-summary_%s.csv",
+			InputPath: "inputs/%s",
+		}
+		service := NewAccumulatePointService(suite.mockRuleRepo, suite.mockCustRepo, suite.mockTierRepo, store, cfg)
+
+		_, err := valid.Reader.Seek(0, io.SeekStart)
+		suite.Require().NoError(err)
+		_, err = service.ExecuteMultipleFiles(ctx, []FileInput{valid, tc.invalid})
+		suite.Equal(tc.code, apperr.GetCode(err), tc.name)
+
+		inputs, err := store.List(ctx, "inputs/")
+		suite.NoError(err)
+		suite.Empty(inputs, tc.name)
+	}
+}
+
+func (suite *AccumulatePointServiceTestSuite) TestExecuteMultipleFiles_TenantStorage() {
+	ctx := tenant.WithTenant(context.Background(), "brand_b")
+	store := storage.NewMemory()
+	cfg := &config.Config{
+		FilePath:  "reports/point-summary_%s.csv",
+		InputPath: "inputs/%s",
+	}
+	service := NewAccumulatePointService(suite.mockRuleRepo, suite.mockCustRepo, suite.mockTierRepo, store, cfg)
+
+	files := []FileInput{
+		{
+			Name:          "purchases_2025-01-15.csv",
+			PurchasedDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			Reader: strings.NewReader("customer_id,product_id,category_id,category_name,branch_id,purchased_amount,currency\n" +
+				"U000001,123121,CT1001,ELECTRONICS,BR0001,100.00,THB"),
+		},
+	}
+
+	suite.mockRuleRepo.EXPECT().
+		GetActiveRules(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]entity.Rule{{ID: "RULE001", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 10}}}, nil)
+
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(ctx, []string{"U000001"}).
+		Return(nil, nil)
+
+	suite.mockCustRepo.EXPECT().
+		UpdateBulkCustomers(ctx, gomock.Any()).
+		Return(nil)
+
+	suite.mockCustRepo.EXPECT().
+		StreamPointSummaries(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries(nil))
+
+	_, err := service.ExecuteMultipleFiles(ctx, files)
+	suite.NoError(err)
+
+	inputs, err := store.List(ctx, "inputs/brand_b/")
+	suite.NoError(err)
+	suite.Len(inputs, 1)
+
+	reports, err := store.List(ctx, "reports/")
+	suite.NoError(err)
+	suite.Equal([]string{"reports/brand_b/point-summary_2025-01-15.csv"}, reports)
+}
+
+func (suite *AccumulatePointServiceTestSuite) TestWriteSummary_JSON() {
+	ctx := context.Background()
+
+	suite.mockCustRepo.EXPECT().
+		StreamPointSummaries(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(streamSummaries([]entity.Customer{
+			{CustomerID: "U000001", PointsByDate: map[string]int64{"2025-01-14": 5, "2025-01-16": 50}},
+			{CustomerID: "U000002", PointsByDate: map[string]int64{"2025-01-15": 7}},
+		}))
+
+	var buf strings.Builder
+	err := suite.service.WriteSummary(ctx, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), report.FormatJSON, &buf)
+
+	suite.NoError(err)
+	suite.JSONEq(`[
+		{"customer_id":"U000002","points":7,"last_purchase_date":"2025-01-15","tier":""},
+		{"customer_id":"U000001","points":5,"last_purchase_date":"2025-01-14","tier":""}
+	]`, buf.String())
+}
+
+func (suite *AccumulatePointServiceTestSuite) TestWriteSummary_Tiers() {
+	ctx := context.Background()
+	tierRepo := mocks_tier.NewMockTierRepository(suite.mockCtrl)
+	service := NewAccumulatePointService(suite.mockRuleRepo, suite.mockCustRepo, tierRepo, storage.NewMemory(), suite.cfg)
+
+	tierRepo.EXPECT().GetTiers(ctx).Return(testTiers(), nil)
+	suite.mockCustRepo.EXPECT().
+		StreamPointSummaries(ctx, "2025-01-15", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ []string, fn func(entity.PointSummary) error) error {
+			suite.NoError(fn(entity.PointSummary{CustomerID: "U000001", Points: 150, LastPurchaseDate: "2025-01-15", QualifyingPoints: 120}))
+			return fn(entity.PointSummary{CustomerID: "U000002", Points: 5, LastPurchaseDate: "2025-01-15", QualifyingSpend: decimal.NewFromInt(20000)})
+		})
+
+	var buf strings.Builder
+	err := service.WriteSummary(ctx, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), report.FormatCSV, &buf)
+
+	suite.NoError(err)
+	suite.Equal("customer_id,points,last_purchase_date,tier\n"+
+		"U000001,150,2025-01-15,GOLD\n"+
+		"U000002,5,2025-01-15,PLATINUM\n", buf.String())
+}
+
+func (suite *AccumulatePointServiceTestSuite) TestListReports() {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	service := NewAccumulatePointService(suite.mockRuleRepo, suite.mockCustRepo, suite.mockTierRepo, store, &config.Config{FilePath: "reports/point-summary_%s.csv"})
+
+	for _, key := range []string{
+		"reports/point-summary_2025-01-16.csv",
+		"reports/point-summary_2025-01-15.csv",
+		"reports/point-summary_2025-01-15.csv.tmp",
+		"reports/other_2025-01-14.csv",
+		"inputs/point-summary_2025-01-13.csv",
+	} {
+		suite.NoError(store.Put(ctx, key, strings.NewReader(""), 0))
+	}
+
+	reports, err := service.ListReports(ctx)
+
+	suite.NoError(err)
+	suite.Equal([]entity.Report{
+		{Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), Key: "reports/point-summary_2025-01-15.csv"},
+		{Date: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC), Key: "reports/point-summary_2025-01-16.csv"},
+	}, reports)
+}
+
+func (suite *AccumulatePointServiceTestSuite) TestListReports_Tenant() {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	service := NewAccumulatePointService(suite.mockRuleRepo, suite.mockCustRepo, suite.mockTierRepo, store, &config.Config{FilePath: "reports/point-summary_%s.csv"})
+
+	suite.NoError(store.Put(ctx, "reports/point-summary_2025-01-15.csv", strings.NewReader(""), 0))
+	suite.NoError(store.Put(ctx, "reports/brand_b/point-summary_2025-01-16.csv", strings.NewReader(""), 0))
+
+	reports, err := service.ListReports(ctx)
+	suite.NoError(err)
+	suite.Equal([]entity.Report{
+		{Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), Key: "reports/point-summary_2025-01-15.csv"},
+	}, reports)
+
+	reports, err = service.ListReports(tenant.WithTenant(ctx, "brand_b"))
+	suite.NoError(err)
+	suite.Equal([]entity.Report{
+		{Date: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC), Key: "reports/brand_b/point-summary_2025-01-16.csv"},
+	}, reports)
+}
+
+// streamSummaries serves customers the way the StreamPointSummaries pipeline would.
+func streamSummaries(customers []entity.Customer) func(context.Context, string, []string, func(entity.PointSummary) error) error {
+	return func(_ context.Context, asOf string, excludeIDs []string, fn func(entity.PointSummary) error) error {
+		for _, record := range summaryRecords(customers, asOf) {
+			if slices.Contains(excludeIDs, record.CustomerID) {
+				continue
+			}
+
+			err := fn(entity.PointSummary{
+				CustomerID:       record.CustomerID,
+				Points:           record.Points,
+				LastPurchaseDate: record.LastPurchaseDate,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 type CalculateBatchPointsTestSuite struct {
 	suite.Suite
 }
@@ -450,7 +981,7 @@ func (suite *CalculateBatchPointsTestSuite) TestCalculateBatchPoints_MultipleRul
 		},
 	}
 
-	result := calculateBatchPoints(rules, records, customers)
+	result := calculateBatchPoints(rules, nil, records, customers)
 	suite.Len(result, 1)
 
 	update := result[0]
@@ -494,7 +1025,7 @@ func (suite *CalculateBatchPointsTestSuite) TestCalculateBatchPoints_ExistingCus
 		},
 	}
 
-	result := calculateBatchPoints(rules, records, customers)
+	result := calculateBatchPoints(rules, nil, records, customers)
 	suite.Len(result, 1)
 
 	update := result[0]
@@ -525,7 +1056,7 @@ func (suite *CalculateBatchPointsTestSuite) TestCalculateBatchPoints_MultipleTra
 	}
 	customers := entity.Customers{}
 
-	result := calculateBatchPoints(rules, records, customers)
+	result := calculateBatchPoints(rules, nil, records, customers)
 	suite.Len(result, 1)
 
 	update := result[0]
@@ -576,7 +1107,7 @@ func (suite *SaveFileTestSuite) TestSaveFile_Success() {
 	filePath := filepath.Join(suite.tempDir, "output_%s.csv")
 	dateString := "2025-01-15"
 
-	err := saveFile(customers, filePath, dateString)
+	err := saveFile(context.Background(), storage.NewLocal(""), writeSummaryCSV(customers, dateString), filePath, dateString)
 
 	suite.NoError(err)
 
@@ -620,7 +1151,7 @@ func (suite *SaveFileTestSuite) TestSaveFile_SortedByPoints() {
 	filePath := filepath.Join(suite.tempDir, "output_%s.csv")
 	dateString := "2025-01-15"
 
-	err := saveFile(customers, filePath, dateString)
+	err := saveFile(context.Background(), storage.NewLocal(""), writeSummaryCSV(customers, dateString), filePath, dateString)
 
 	suite.NoError(err)
 
@@ -656,7 +1187,7 @@ func (suite *SaveFileTestSuite) TestSaveFile_SortedByLastPurchaseDate() {
 	filePath := filepath.Join(suite.tempDir, "output_%s.csv")
 	dateString := "2025-01-15"
 
-	err := saveFile(customers, filePath, dateString)
+	err := saveFile(context.Background(), storage.NewLocal(""), writeSummaryCSV(customers, dateString), filePath, dateString)
 
 	suite.NoError(err)
 
@@ -684,7 +1215,7 @@ func (suite *SaveFileTestSuite) TestSaveFile_InvalidFilePath() {
 	filePath := "/invalid/path/output_%s.csv"
 	dateString := "2025-01-15"
 
-	err := saveFile(customers, filePath, dateString)
+	err := saveFile(context.Background(), storage.NewLocal(""), writeSummaryCSV(customers, dateString), filePath, dateString)
 
 	suite.Error(err)
 	suite.IsType(&apperr.AppError{}, err)
@@ -696,7 +1227,7 @@ func (suite *SaveFileTestSuite) TestSaveFile_EmptyCustomers() {
 	filePath := filepath.Join(suite.tempDir, "output_%s.csv")
 	dateString := "2025-01-15"
 
-	err := saveFile(customers, filePath, dateString)
+	err := saveFile(context.Background(), storage.NewLocal(""), writeSummaryCSV(customers, dateString), filePath, dateString)
 
 	suite.NoError(err)
 
@@ -710,6 +1241,12 @@ func (suite *SaveFileTestSuite) TestSaveFile_EmptyCustomers() {
 	suite.Contains(contentStr, "customer_id,points,last_purchase_date")
 }
 
+func writeSummaryCSV(customers []entity.Customer, dateString string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		return report.Encode(report.FormatCSV, w, summaryRecords(customers, dateString))
+	}
+}
+
 type ExtendedValidateAndCalculatePointsTestSuite struct {
 	suite.Suite
 }
@@ -741,7 +1278,7 @@ func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalcula
 
 	customers := entity.Customers{}
 
-	points, applied := validateAndCalculatePoints(rule, record, customers)
+	points, applied := validateAndCalculatePoints(rule, record, customers, entity.Purchaser{})
 
 	suite.False(applied)
 	suite.Equal(int64(0), points)
@@ -771,7 +1308,7 @@ func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalcula
 
 	customers := entity.Customers{}
 
-	points, applied := validateAndCalculatePoints(rule, record, customers)
+	points, applied := validateAndCalculatePoints(rule, record, customers, entity.Purchaser{})
 
 	suite.False(applied)
 	suite.Equal(int64(0), points)
@@ -801,7 +1338,7 @@ func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalcula
 
 	customers := entity.Customers{}
 
-	points, applied := validateAndCalculatePoints(rule, record, customers)
+	points, applied := validateAndCalculatePoints(rule, record, customers, entity.Purchaser{})
 
 	suite.False(applied)
 	suite.Equal(int64(0), points)
@@ -831,7 +1368,7 @@ func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalcula
 
 	customers := entity.Customers{}
 
-	points, applied := validateAndCalculatePoints(rule, record, customers)
+	points, applied := validateAndCalculatePoints(rule, record, customers, entity.Purchaser{})
 
 	suite.True(applied)
 	suite.Equal(int64(25), points)
@@ -859,7 +1396,7 @@ func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalcula
 
 	customers := entity.Customers{}
 
-	points, applied := validateAndCalculatePoints(rule, record, customers)
+	points, applied := validateAndCalculatePoints(rule, record, customers, entity.Purchaser{})
 
 	suite.False(applied)
 	suite.Equal(int64(0), points)
@@ -888,7 +1425,7 @@ func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalcula
 
 	customers := entity.Customers{}
 
-	points, applied := validateAndCalculatePoints(rule, record, customers)
+	points, applied := validateAndCalculatePoints(rule, record, customers, entity.Purchaser{})
 
 	suite.True(applied)
 	suite.Equal(int64(10), points)
@@ -917,7 +1454,7 @@ func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalcula
 
 	customers := entity.Customers{}
 
-	points, applied := validateAndCalculatePoints(rule, record, customers)
+	points, applied := validateAndCalculatePoints(rule, record, customers, entity.Purchaser{})
 
 	suite.True(applied)
 	suite.Equal(int64(10), points)
@@ -946,7 +1483,7 @@ func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalcula
 
 	customers := entity.Customers{}
 
-	points, applied := validateAndCalculatePoints(rule, record, customers)
+	points, applied := validateAndCalculatePoints(rule, record, customers, entity.Purchaser{})
 
 	suite.True(applied)
 	suite.Equal(int64(10), points)
@@ -979,7 +1516,7 @@ func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalcula
 		},
 	}
 
-	points, applied := validateAndCalculatePoints(rule, record, customers)
+	points, applied := validateAndCalculatePoints(rule, record, customers, entity.Purchaser{})
 
 	suite.True(applied)
 	suite.Equal(int64(10), points)
@@ -1019,7 +1556,7 @@ func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalcula
 		},
 	}
 
-	points, applied := validateAndCalculatePoints(rule, record, customers)
+	points, applied := validateAndCalculatePoints(rule, record, customers, entity.Purchaser{})
 
 	suite.False(applied)
 	suite.Equal(int64(0), points)
@@ -1047,12 +1584,45 @@ func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalcula
 
 	customers := entity.Customers{}
 
-	points, applied := validateAndCalculatePoints(rule, record, customers)
+	points, applied := validateAndCalculatePoints(rule, record, customers, entity.Purchaser{})
 
 	suite.True(applied)
 	suite.Equal(int64(0), points)
 }
 
+func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalculatePoints_ProductLists() {
+	record := PurchaseRecord{
+		CustomerID:      "U000001",
+		ProductID:       "P001",
+		BranchID:        "BR001",
+		CategoryID:      "CT001",
+		PurchasedAmount: decimal.NewFromFloat(100.0),
+		PurchaseDate:    time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	testCases := map[string]struct {
+		conditions entity.Conditions
+		applied    bool
+	}{
+		"included product":   {conditions: entity.Conditions{ProductIDs: []string{"P001", "P002"}}, applied: true},
+		"other product":      {conditions: entity.Conditions{ProductIDs: []string{"P002"}}, applied: false},
+		"excluded product":   {conditions: entity.Conditions{ExcludeProductIDs: []string{"P001"}}, applied: false},
+		"excluded category":  {conditions: entity.Conditions{ExcludeCategoryIDs: []string{"CT001"}}, applied: false},
+		"exclusion wins":     {conditions: entity.Conditions{CategoryID: []string{"CT001"}, ExcludeProductIDs: []string{"P001"}}, applied: false},
+		"unrelated excludes": {conditions: entity.Conditions{ExcludeProductIDs: []string{"P009"}, ExcludeCategoryIDs: []string{"CT009"}}, applied: true},
+	}
+
+	for name, tc := range testCases {
+		suite.Run(name, func() {
+			rule := entity.Rule{RuleType: entity.FixedPointRule, Conditions: tc.conditions, Reward: entity.Reward{Value: 3}}
+
+			_, applied := validateAndCalculatePoints(rule, record, entity.Customers{}, entity.Purchaser{})
+
+			suite.Equal(tc.applied, applied)
+		})
+	}
+}
+
 type ExtendedPurchaseRecordsTestSuite struct {
 	suite.Suite
 }
@@ -1118,6 +1688,57 @@ func (suite *ExtendedPurchaseRecordsTestSuite) TestMapRecordsToBranchCategories_
 	suite.Contains(result["BR001"], "CT002")
 }
 
+func (suite *ExtendedPurchaseRecordsTestSuite) TestMapRecordsToBranchProducts() {
+	records := PurchaseRecords{
+		{BranchID: "BR001", ProductID: "P001"},
+		{BranchID: "BR001", ProductID: "P001"},
+		{BranchID: "BR002", ProductID: "P002"},
+	}
+
+	result := records.mapRecordsToBranchProducts()
+
+	suite.Equal(map[string][]string{"BR001": {"P001"}, "BR002": {"P002"}}, result)
+}
+
+func (suite *ExtendedPurchaseRecordsTestSuite) TestMapRecordsToBranchGroups() {
+	groups := entity.BranchGroups{
+		{Name: "NORTH", BranchIDs: []string{"BR001", "BR002"}},
+		{Name: "FLAGSHIP", BranchIDs: []string{"BR001"}},
+	}
+	records := PurchaseRecords{
+		{BranchID: "BR001"},
+		{BranchID: "BR002"},
+		{BranchID: "BR009"},
+	}
+
+	result := records.mapRecordsToBranchGroups(groups)
+
+	suite.Equal(map[string][]string{"BR001": {"NORTH", "FLAGSHIP"}, "BR002": {"NORTH"}}, result)
+}
+
+func (suite *ExtendedPurchaseRecordsTestSuite) TestBaskets() {
+	jan15 := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	records := PurchaseRecords{
+		{CustomerID: "U000001", BranchID: "BR001", ProductID: "P1", PurchaseDate: jan15.AddDate(0, 0, 1)},
+		{CustomerID: "U000001", BranchID: "BR001", ProductID: "P2", PurchaseDate: jan15},
+		{CustomerID: "U000002", BranchID: "BR001", ProductID: "P3", PurchaseDate: jan15},
+		{CustomerID: "U000001", BranchID: "BR001", ProductID: "P4", PurchaseDate: jan15},
+		{CustomerID: "U000001", BranchID: "BR001", ProductID: "P5", PurchaseDate: jan15, ReceiptID: "R9"},
+	}
+
+	result := records.baskets()
+
+	products := make([][]string, 0, len(result))
+	for _, basket := range result {
+		ids := make([]string, 0, len(basket))
+		for _, record := range basket {
+			ids = append(ids, record.ProductID)
+		}
+		products = append(products, ids)
+	}
+	suite.Equal([][]string{{"P2", "P4"}, {"P3"}, {"P5"}, {"P1"}}, products)
+}
+
 func (suite *ExtendedPurchaseRecordsTestSuite) TestGetUniqueCustomerIDs_EmptyRecords() {
 	records := PurchaseRecords{}
 
@@ -1221,3 +1842,267 @@ func (suite *ExtendedPurchaseRecordsTestSuite) TestGetUniqueRecords_AllUnique()
 
 	suite.Len(result, 3)
 }
+
+func (suite *ExtendedPurchaseRecordsTestSuite) TestGetUniqueRecords_DifferentReceipts() {
+	baseTime := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	line := func(receiptID string) *PurchaseRecord {
+		return &PurchaseRecord{
+			CustomerID:      "U000001",
+			ProductID:       "P001",
+			BranchID:        "BR001",
+			PurchasedAmount: decimal.NewFromFloat(100.0),
+			PurchaseDate:    baseTime,
+			ReceiptID:       receiptID,
+		}
+	}
+	records := PurchaseRecords{line("R1"), line("R2"), line("R1")}
+
+	result := records.getUniqueRecords()
+
+	suite.Equal(PurchaseRecords{line("R1"), line("R2")}, result)
+}
+
+func (suite *ExtendedValidateAndCalculatePointsTestSuite) TestValidateAndCalculatePoints_RuleNotInForce() {
+	effectiveFrom := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	rule := entity.Rule{
+		RuleType:      entity.FixedPointRule,
+		Reward:        entity.Reward{Value: 10},
+		EffectiveFrom: &effectiveFrom,
+	}
+
+	record := PurchaseRecord{
+		CustomerID:      "U000001",
+		PurchasedAmount: decimal.NewFromFloat(100.0),
+		PurchaseDate:    time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	points, applied := validateAndCalculatePoints(rule, record, entity.Customers{}, entity.Purchaser{})
+
+	suite.False(applied)
+	suite.Equal(int64(0), points)
+}
+
+func (suite *CalculateBatchPointsTestSuite) TestCalculateBatchPoints_MovementsReferenceRuleVersion() {
+	rules := []entity.Rule{
+		{ID: "RULE001", Version: 3, RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 10}},
+		{ID: "RULE002", Version: 1, RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 4}},
+	}
+	records := PurchaseRecords{
+		{
+			CustomerID:      "U000001",
+			ProductID:       "P001",
+			CategoryID:      "CT001",
+			PurchasedAmount: decimal.NewFromFloat(100.0),
+			PurchaseDate:    time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	result := calculateBatchPoints(rules, nil, records, entity.Customers{})
+	suite.Len(result, 1)
+
+	movements := result[0].Records
+	suite.Len(movements, 2)
+	suite.Equal("RULE001", movements[0].RuleID)
+	suite.Equal(int64(3), movements[0].RuleVersion)
+	suite.Equal(int64(10), movements[0].Points)
+	suite.Equal("CT001", movements[0].CategoryID)
+	suite.Equal("RULE002", movements[1].RuleID)
+	suite.Equal(int64(4), movements[1].Points)
+}
+
+func testTiers() entity.Tiers {
+	return entity.Tiers{
+		{Name: "SILVER", Rank: 1, Basis: entity.TierBasisPoints, Threshold: decimal.Zero, Multiplier: decimal.NewFromInt(1)},
+		{Name: "GOLD", Rank: 2, Basis: entity.TierBasisPoints, Threshold: decimal.NewFromInt(100), Multiplier: decimal.RequireFromString("1.5")},
+		{Name: "PLATINUM", Rank: 3, Basis: entity.TierBasisSpend, Threshold: decimal.NewFromInt(10000), Multiplier: decimal.NewFromInt(2)},
+	}
+}
+
+func (suite *CalculateBatchPointsTestSuite) TestCalculateBatchPoints_TierMultiplier() {
+	rules := []entity.Rule{
+		{ID: "RULE001", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 10}},
+	}
+	jan15 := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	customers := entity.Customers{
+		{
+			CustomerID: "U000001",
+			// Earned 95 a month ago and 200 just over a year ago, outside the window.
+			PointsByDate: map[string]int64{"2024-12-15": 95, "2024-01-14": 200},
+		},
+	}
+	records := PurchaseRecords{
+		// Listed out of order; the 16th earns at GOLD once the 15th counts.
+		{CustomerID: "U000001", ProductID: "P2", PurchasedAmount: decimal.NewFromInt(50), PurchaseDate: jan15.AddDate(0, 0, 1)},
+		{CustomerID: "U000001", ProductID: "P1", PurchasedAmount: decimal.NewFromInt(50), PurchaseDate: jan15},
+	}
+
+	result := calculateBatchPoints(rules, testTiers(), records, customers)
+
+	suite.Require().Len(result, 1)
+	suite.Equal(int64(25), result[0].PointsToAdd)
+	suite.Equal(map[string]int64{"2025-01-15": 10, "2025-01-16": 15}, result[0].PointsByDate)
+	suite.Equal("SILVER", result[0].Records[0].Tier)
+	suite.Equal("GOLD", result[0].Records[1].Tier)
+}
+
+func (suite *CalculateBatchPointsTestSuite) TestCalculateBatchPoints_CustomerConditions() {
+	rules := []entity.Rule{
+		{ID: "BIRTHDAY", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 5}, Conditions: entity.Conditions{BirthdayMonth: true}},
+		{ID: "WELCOME", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 50}, Conditions: entity.Conditions{FirstPurchase: true}},
+		{ID: "VIP", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 7}, Conditions: entity.Conditions{Segments: []string{"VIP"}}},
+	}
+	jan15 := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	customers := entity.Customers{
+		{
+			CustomerID: "U000001",
+			Attributes: entity.CustomerAttributes{BirthMonth: 1, Segment: "VIP"},
+		},
+		{
+			CustomerID: "U000002",
+			Attributes: entity.CustomerAttributes{BirthMonth: 2},
+			Records:    []entity.Record{{ProductID: "P0", Amount: decimal.NewFromInt(10), PurchaseDate: jan15.AddDate(0, 0, -30)}},
+		},
+	}
+	records := PurchaseRecords{
+		{CustomerID: "U000001", ProductID: "P1", PurchasedAmount: decimal.NewFromInt(50), PurchaseDate: jan15},
+		{CustomerID: "U000001", ProductID: "P2", PurchasedAmount: decimal.NewFromInt(50), PurchaseDate: jan15},
+		{CustomerID: "U000002", ProductID: "P3", PurchasedAmount: decimal.NewFromInt(50), PurchaseDate: jan15},
+	}
+
+	result := calculateBatchPoints(rules, nil, records, customers)
+
+	byCustomer := make(map[string]entity.UpdateCustomer)
+	for _, update := range result {
+		byCustomer[update.CustomerID] = update
+	}
+
+	// Birthday and segment on both lines, the welcome bonus on the first only.
+	suite.Equal(int64(5+7+50+5+7), byCustomer["U000001"].PointsToAdd)
+	suite.NotContains(byCustomer, "U000002")
+}
+
+func (suite *CalculateBatchPointsTestSuite) TestCalculateBatchPoints_BranchScopes() {
+	groups := entity.BranchGroups{{Name: "NORTH", BranchIDs: []string{"BR0002"}}}
+	rules := groups.Resolve([]entity.Rule{
+		{ID: "GLOBAL", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 1}},
+		{ID: "LIST", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 10}, Conditions: entity.Conditions{BranchIDs: []string{"BR0001", "BR0003"}}},
+		{ID: "GROUP", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 100}, Conditions: entity.Conditions{BranchGroups: []string{"NORTH"}}},
+		{ID: "MISSING_GROUP", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 1000}, Conditions: entity.Conditions{BranchGroups: []string{"SOUTH"}}},
+	})
+	jan15 := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	records := PurchaseRecords{
+		{CustomerID: "U000001", ProductID: "P1", BranchID: "BR0001", PurchasedAmount: decimal.NewFromInt(50), PurchaseDate: jan15},
+		{CustomerID: "U000002", ProductID: "P1", BranchID: "BR0002", PurchasedAmount: decimal.NewFromInt(50), PurchaseDate: jan15},
+		{CustomerID: "U000003", ProductID: "P1", BranchID: "BR0009", PurchasedAmount: decimal.NewFromInt(50), PurchaseDate: jan15},
+	}
+
+	result := calculateBatchPoints(rules, nil, records, nil)
+
+	points := make(map[string]int64)
+	for _, update := range result {
+		points[update.CustomerID] = update.PointsToAdd
+	}
+	suite.Equal(map[string]int64{"U000001": 11, "U000002": 101, "U000003": 1}, points)
+}
+
+func (suite *CalculateBatchPointsTestSuite) TestCalculateBatchPoints_BasketRules() {
+	jan15 := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	rules := []entity.Rule{
+		{ID: "LINE", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 1}},
+		{ID: "SPEND", RuleType: entity.BasketSpendRule, Reward: entity.Reward{Value: 50},
+			Conditions: entity.Conditions{MinAmount: decimal.NewFromInt(1000), ExcludeCategoryIDs: []string{"CT9"}}},
+		{ID: "CATEGORIES", RuleType: entity.BasketCategoriesRule, Reward: entity.Reward{Value: 20},
+			Conditions: entity.Conditions{MinCategories: 3}},
+		{ID: "BUNDLE", RuleType: entity.BasketBundleRule, Reward: entity.Reward{Value: 30},
+			Conditions: entity.Conditions{RequiredProductIDs: []string{"P1", "P2"}}},
+	}
+	records := PurchaseRecords{
+		// U000001 buys P1, P2 and P3 across three categories at BR0001: 900 THB
+		// towards SPEND, as CT9 is excluded.
+		{CustomerID: "U000001", ProductID: "P1", CategoryID: "CT1", BranchID: "BR0001", PurchasedAmount: decimal.NewFromInt(600), PurchaseDate: jan15},
+		{CustomerID: "U000001", ProductID: "P2", CategoryID: "CT2", BranchID: "BR0001", PurchasedAmount: decimal.NewFromInt(300), PurchaseDate: jan15},
+		{CustomerID: "U000001", ProductID: "P3", CategoryID: "CT9", BranchID: "BR0001", PurchasedAmount: decimal.NewFromInt(500), PurchaseDate: jan15},
+		// The same day at another branch is a separate basket.
+		{CustomerID: "U000001", ProductID: "P4", CategoryID: "CT1", BranchID: "BR0002", PurchasedAmount: decimal.NewFromInt(1200), PurchaseDate: jan15},
+	}
+
+	result := calculateBatchPoints(rules, nil, records, nil)
+
+	suite.Require().Len(result, 1)
+	suite.Equal(int64(4+20+30+50), result[0].PointsToAdd)
+
+	awards := make(map[string]string)
+	for _, record := range result[0].Records {
+		if record.RuleID != "LINE" {
+			awards[record.RuleID] = record.ProductID
+		}
+	}
+	suite.Equal(map[string]string{"CATEGORIES": "P1", "BUNDLE": "P1", "SPEND": "P4"}, awards)
+}
+
+func (suite *CalculateBatchPointsTestSuite) TestCalculateBatchPoints_IdenticalLinesOnDifferentReceipts() {
+	jan15 := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	rules := []entity.Rule{
+		{ID: "LINE", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 1}},
+		{ID: "BUNDLE", RuleType: entity.BasketBundleRule, Reward: entity.Reward{Value: 30},
+			Conditions: entity.Conditions{RequiredProductIDs: []string{"P1", "P2"}}},
+	}
+	line := func(productID, receiptID string) *PurchaseRecord {
+		return &PurchaseRecord{CustomerID: "U000001", ProductID: productID, BranchID: "BR0001",
+			PurchasedAmount: decimal.NewFromInt(100), PurchaseDate: jan15, ReceiptID: receiptID}
+	}
+	customers := entity.Customers{
+		{CustomerID: "U000001", Records: []entity.Record{
+			{ProductID: "P1", BranchID: "BR0001", Amount: decimal.NewFromInt(100), PurchaseDate: jan15, ReceiptID: "R1"},
+		}},
+	}
+	// R1 was stored by an earlier upload; R2 buys the same lines the same day.
+	records := PurchaseRecords{line("P1", "R1"), line("P1", "R2"), line("P2", "R2")}
+
+	result := calculateBatchPoints(rules, nil, records, customers)
+
+	suite.Require().Len(result, 1)
+	suite.Equal(int64(2+30), result[0].PointsToAdd)
+}
+
+func (suite *CalculateBatchPointsTestSuite) TestCalculateBatchPoints_CustomRules() {
+	jan15 := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	rules := []entity.Rule{
+		{ID: "CUSTOM", RuleType: entity.CustomRule, Reward: entity.Reward{
+			Expression: `amount >= 300 && category in ["CT1001","CT1002"] ? floor(amount/50)*2 : 0`,
+		}},
+		{ID: "WEEKDAY", RuleType: entity.FixedPointRule, Reward: entity.Reward{Value: 5},
+			Conditions: entity.Conditions{Expression: `weekday == 3 && branch != "BR0002"`}},
+		{ID: "BROKEN", RuleType: entity.CustomRule, Reward: entity.Reward{Expression: `amount +`}},
+	}
+	records := PurchaseRecords{
+		{CustomerID: "U000001", CategoryID: "CT1001", BranchID: "BR0001", PurchasedAmount: decimal.NewFromInt(420), PurchaseDate: jan15},
+		{CustomerID: "U000001", CategoryID: "CT1003", BranchID: "BR0001", PurchasedAmount: decimal.NewFromInt(500), PurchaseDate: jan15},
+		{CustomerID: "U000002", CategoryID: "CT1002", BranchID: "BR0002", PurchasedAmount: decimal.NewFromInt(100), PurchaseDate: jan15},
+	}
+
+	result := calculateBatchPoints(rules, nil, records, nil)
+
+	points := make(map[string]int64)
+	for _, customer := range result {
+		points[customer.CustomerID] = customer.PointsToAdd
+	}
+	// 2025-01-15 is a Wednesday: U000001 earns 16 + 5 + 5, U000002 nothing.
+	suite.Equal(map[string]int64{"U000001": 26}, points)
+}
+
+func (suite *CalculateBatchPointsTestSuite) TestCalculateBatchPoints_CustomRuleOutOfRange() {
+	jan15 := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	rule, err := entity.Rule{ID: "HUGE", RuleType: entity.CustomRule, Reward: entity.Reward{
+		Expression: `amount * 100000000000000000000`,
+	}}.Compile()
+	suite.Require().NoError(err)
+	record := PurchaseRecord{CustomerID: "U000001", CategoryID: "CT1001", BranchID: "BR0001", PurchasedAmount: decimal.NewFromInt(420), PurchaseDate: jan15}
+
+	points, applied, err := rule.Reward.Evaluate(record.toPurchase(), entity.Purchaser{})
+	suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+	suite.False(applied)
+	suite.Zero(points)
+
+	suite.Nil(calculateBatchPoints([]entity.Rule{rule}, nil, PurchaseRecords{&record}, nil))
+}
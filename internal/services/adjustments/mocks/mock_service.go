@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAdjustmentService is a mock of AdjustmentService interface.
+type MockAdjustmentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdjustmentServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAdjustmentServiceMockRecorder is the mock recorder for MockAdjustmentService.
+type MockAdjustmentServiceMockRecorder struct {
+	mock *MockAdjustmentService
+}
+
+// NewMockAdjustmentService creates a new mock instance.
+func NewMockAdjustmentService(ctrl *gomock.Controller) *MockAdjustmentService {
+	mock := &MockAdjustmentService{ctrl: ctrl}
+	mock.recorder = &MockAdjustmentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdjustmentService) EXPECT() *MockAdjustmentServiceMockRecorder {
+	return m.recorder
+}
+
+// ApproveAdjustment mocks base method.
+func (m *MockAdjustmentService) ApproveAdjustment(ctx context.Context, id, approvedBy string) (*entity.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveAdjustment", ctx, id, approvedBy)
+	ret0, _ := ret[0].(*entity.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveAdjustment indicates an expected call of ApproveAdjustment.
+func (mr *MockAdjustmentServiceMockRecorder) ApproveAdjustment(ctx, id, approvedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveAdjustment", reflect.TypeOf((*MockAdjustmentService)(nil).ApproveAdjustment), ctx, id, approvedBy)
+}
+
+// ListAdjustments mocks base method.
+func (m *MockAdjustmentService) ListAdjustments(ctx context.Context, filter entity.AdjustmentFilter) ([]entity.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAdjustments", ctx, filter)
+	ret0, _ := ret[0].([]entity.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAdjustments indicates an expected call of ListAdjustments.
+func (mr *MockAdjustmentServiceMockRecorder) ListAdjustments(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAdjustments", reflect.TypeOf((*MockAdjustmentService)(nil).ListAdjustments), ctx, filter)
+}
+
+// RejectAdjustment mocks base method.
+func (m *MockAdjustmentService) RejectAdjustment(ctx context.Context, id, rejectedBy string) (*entity.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectAdjustment", ctx, id, rejectedBy)
+	ret0, _ := ret[0].(*entity.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectAdjustment indicates an expected call of RejectAdjustment.
+func (mr *MockAdjustmentServiceMockRecorder) RejectAdjustment(ctx, id, rejectedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectAdjustment", reflect.TypeOf((*MockAdjustmentService)(nil).RejectAdjustment), ctx, id, rejectedBy)
+}
+
+// RequestAdjustment mocks base method.
+func (m *MockAdjustmentService) RequestAdjustment(ctx context.Context, adjustment entity.Adjustment) (*entity.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestAdjustment", ctx, adjustment)
+	ret0, _ := ret[0].(*entity.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestAdjustment indicates an expected call of RequestAdjustment.
+func (mr *MockAdjustmentServiceMockRecorder) RequestAdjustment(ctx, adjustment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestAdjustment", reflect.TypeOf((*MockAdjustmentService)(nil).RequestAdjustment), ctx, adjustment)
+}
@@ -0,0 +1,196 @@
+package adjustments
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+)
+
+type adjustmentService struct {
+	adjustmentRepo repository.AdjustmentRepository
+	customerRepo   repository.CustomerRepository
+	cfg            *config.Config
+}
+
+//go:generate mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
+type AdjustmentService interface {
+	RequestAdjustment(ctx context.Context, adjustment entity.Adjustment) (*entity.Adjustment, error)
+	ApproveAdjustment(ctx context.Context, id, approvedBy string) (*entity.Adjustment, error)
+	RejectAdjustment(ctx context.Context, id, rejectedBy string) (*entity.Adjustment, error)
+	ListAdjustments(ctx context.Context, filter entity.AdjustmentFilter) ([]entity.Adjustment, error)
+}
+
+func NewAdjustmentService(adjustmentRepo repository.AdjustmentRepository, customerRepo repository.CustomerRepository, cfg *config.Config) AdjustmentService {
+	return &adjustmentService{
+		adjustmentRepo: adjustmentRepo,
+		customerRepo:   customerRepo,
+		cfg:            cfg,
+	}
+}
+
+// RequestAdjustment records a credit or debit for an existing customer. One of at
+// most AdjustmentApprovalThreshold points either way is applied straight away;
+// a larger one stays pending until someone other than the requester approves it.
+func (s adjustmentService) RequestAdjustment(ctx context.Context, adjustment entity.Adjustment) (*entity.Adjustment, error) {
+	if err := validateAdjustment(adjustment); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkBalance(ctx, adjustment); err != nil {
+		return nil, err
+	}
+
+	adjustment.Status = entity.AdjustmentPending
+	adjustment.RequestedAt = time.Now().UTC()
+	adjustment.DecidedBy = ""
+	adjustment.DecidedAt = nil
+
+	created, err := s.adjustmentRepo.CreateAdjustment(ctx, adjustment)
+	if err != nil {
+		return nil, err
+	}
+
+	if abs(created.Points) > s.cfg.AdjustmentApprovalThreshold {
+		return created, nil
+	}
+
+	return s.apply(ctx, *created, created.RequestedBy)
+}
+
+func (s adjustmentService) ApproveAdjustment(ctx context.Context, id, approvedBy string) (*entity.Adjustment, error) {
+	if approvedBy == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("decided_by is required")
+	}
+
+	adjustment, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if adjustment.RequestedBy == approvedBy {
+		return nil, apperr.ErrInvalidArgument.WithMessage("an adjustment must be approved by someone other than its requester")
+	}
+
+	if err = s.checkBalance(ctx, *adjustment); err != nil {
+		return nil, err
+	}
+
+	return s.apply(ctx, *adjustment, approvedBy)
+}
+
+func (s adjustmentService) RejectAdjustment(ctx context.Context, id, rejectedBy string) (*entity.Adjustment, error) {
+	if rejectedBy == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("decided_by is required")
+	}
+
+	if _, err := s.pending(ctx, id); err != nil {
+		return nil, err
+	}
+
+	decidedAt := time.Now().UTC()
+	return s.adjustmentRepo.UpdateAdjustmentStatus(ctx, id, entity.AdjustmentPending, entity.AdjustmentRejected, rejectedBy, &decidedAt)
+}
+
+func (s adjustmentService) ListAdjustments(ctx context.Context, filter entity.AdjustmentFilter) ([]entity.Adjustment, error) {
+	switch filter.Status {
+	case "", entity.AdjustmentPending, entity.AdjustmentApproved, entity.AdjustmentRejected:
+	default:
+		return nil, apperr.ErrInvalidArgument.WithMessage(fmt.Sprintf("unknown status %s", filter.Status))
+	}
+
+	return s.adjustmentRepo.ListAdjustments(ctx, filter)
+}
+
+// apply claims the pending adjustment as approved, so it cannot be applied twice,
+// then records it in the customer's history on the day it is approved. The claim
+// is given back when the customer cannot be updated.
+func (s adjustmentService) apply(ctx context.Context, adjustment entity.Adjustment, approvedBy string) (*entity.Adjustment, error) {
+	decidedAt := time.Now().UTC()
+	approved, err := s.adjustmentRepo.UpdateAdjustmentStatus(ctx, adjustment.ID, entity.AdjustmentPending, entity.AdjustmentApproved, approvedBy, &decidedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	movement := approved.Movement(decidedAt.Truncate(24 * time.Hour))
+	err = s.customerRepo.UpdateBulkCustomers(ctx, []entity.UpdateCustomer{{
+		CustomerID:   approved.CustomerID,
+		PointsToAdd:  movement.Points,
+		Records:      []entity.Record{movement},
+		PointsByDate: map[string]int64{movement.PurchaseDate.Format(time.DateOnly): movement.Points},
+	}})
+	if err != nil {
+		if _, revertErr := s.adjustmentRepo.UpdateAdjustmentStatus(ctx, adjustment.ID, entity.AdjustmentApproved, entity.AdjustmentPending, "", nil); revertErr != nil {
+			return nil, apperr.ErrInternal.Wrap(fmt.Errorf("%w; adjustment %s left approved: %w", err, adjustment.ID, revertErr))
+		}
+		return nil, err
+	}
+
+	return approved, nil
+}
+
+func (s adjustmentService) pending(ctx context.Context, id string) (*entity.Adjustment, error) {
+	adjustment, err := s.adjustmentRepo.GetAdjustment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if adjustment.Status != entity.AdjustmentPending {
+		return nil, apperr.ErrConflict.WithMessage(fmt.Sprintf("adjustment is already %s", adjustment.Status))
+	}
+
+	return adjustment, nil
+}
+
+// checkBalance makes sure the customer exists and a debit does not take their
+// balance below zero.
+func (s adjustmentService) checkBalance(ctx context.Context, adjustment entity.Adjustment) error {
+	customers, err := s.customerRepo.GetCustomers(ctx, []string{adjustment.CustomerID})
+	if err != nil {
+		return err
+	}
+
+	customer, found := entity.Customers(customers).GetCustomerByID(adjustment.CustomerID)
+	if !found {
+		return apperr.ErrNotFound.WithMessage("customer not found")
+	}
+	if customer.MergedInto != "" {
+		return apperr.ErrConflict.WithMessage(fmt.Sprintf("customer was merged into %s", customer.MergedInto))
+	}
+
+	if customer.Points+adjustment.Points < 0 {
+		return apperr.ErrInvalidArgument.WithMessage(fmt.Sprintf("debit of %d points exceeds the balance of %d", -adjustment.Points, customer.Points))
+	}
+
+	return nil
+}
+
+func validateAdjustment(adjustment entity.Adjustment) error {
+	if adjustment.CustomerID == "" {
+		return apperr.ErrInvalidArgument.WithMessage("customer_id is required")
+	}
+	if adjustment.Points == 0 {
+		return apperr.ErrInvalidArgument.WithMessage("points must not be zero")
+	}
+	if !slices.Contains(entity.AdjustmentReasons, adjustment.ReasonCode) {
+		return apperr.ErrInvalidArgument.WithMessage(fmt.Sprintf("reason_code must be one of %v", entity.AdjustmentReasons))
+	}
+	if adjustment.Reference == "" {
+		return apperr.ErrInvalidArgument.WithMessage("reference is required")
+	}
+	if adjustment.RequestedBy == "" {
+		return apperr.ErrInvalidArgument.WithMessage("requested_by is required")
+	}
+
+	return nil
+}
+
+func abs(points int64) int64 {
+	if points < 0 {
+		return -points
+	}
+	return points
+}
@@ -0,0 +1,242 @@
+package adjustments
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_adjustment"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_customer"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type AdjustmentServiceTestSuite struct {
+	suite.Suite
+	mockCtrl           *gomock.Controller
+	mockAdjustmentRepo *mocks_adjustment.MockAdjustmentRepository
+	mockCustRepo       *mocks_customer.MockCustomerRepository
+	service            AdjustmentService
+}
+
+func TestAdjustmentServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AdjustmentServiceTestSuite))
+}
+
+func (suite *AdjustmentServiceTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockAdjustmentRepo = mocks_adjustment.NewMockAdjustmentRepository(suite.mockCtrl)
+	suite.mockCustRepo = mocks_customer.NewMockCustomerRepository(suite.mockCtrl)
+	suite.service = NewAdjustmentService(suite.mockAdjustmentRepo, suite.mockCustRepo, &config.Config{AdjustmentApprovalThreshold: 1000})
+}
+
+func (suite *AdjustmentServiceTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func newAdjustment(points int64) entity.Adjustment {
+	return entity.Adjustment{
+		CustomerID:  "U000001",
+		Points:      points,
+		ReasonCode:  entity.AdjustmentReasonGoodwill,
+		Reference:   "TICKET-42",
+		RequestedBy: "alice",
+	}
+}
+
+func (suite *AdjustmentServiceTestSuite) expectBalance(points int64) {
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(gomock.Any(), []string{"U000001"}).
+		Return([]entity.Customer{{CustomerID: "U000001", Points: points}}, nil)
+}
+
+// approve makes the repository move adjustment from PENDING to APPROVED.
+func (suite *AdjustmentServiceTestSuite) approve(adjustment entity.Adjustment, by string) {
+	suite.mockAdjustmentRepo.EXPECT().
+		UpdateAdjustmentStatus(gomock.Any(), adjustment.ID, entity.AdjustmentPending, entity.AdjustmentApproved, by, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _, to entity.AdjustmentStatus, decidedBy string, decidedAt *time.Time) (*entity.Adjustment, error) {
+			adjustment.Status = to
+			adjustment.DecidedBy = decidedBy
+			adjustment.DecidedAt = decidedAt
+			return &adjustment, nil
+		})
+}
+
+func (suite *AdjustmentServiceTestSuite) TestRequestAdjustment_AppliedUnderThreshold() {
+	ctx := context.Background()
+	suite.expectBalance(100)
+
+	created := newAdjustment(-100)
+	created.ID = "A1"
+	created.Status = entity.AdjustmentPending
+	suite.mockAdjustmentRepo.EXPECT().CreateAdjustment(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, adjustment entity.Adjustment) (*entity.Adjustment, error) {
+			suite.Equal(entity.AdjustmentPending, adjustment.Status)
+			suite.False(adjustment.RequestedAt.IsZero())
+			return &created, nil
+		})
+	suite.approve(created, "alice")
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	suite.mockCustRepo.EXPECT().UpdateBulkCustomers(ctx, []entity.UpdateCustomer{{
+		CustomerID:  "U000001",
+		PointsToAdd: -100,
+		Records: []entity.Record{{
+			PurchaseDate: today,
+			Points:       -100,
+			Type:         entity.AdjustmentTransaction,
+			AdjustmentID: "A1",
+			ReasonCode:   entity.AdjustmentReasonGoodwill,
+		}},
+		PointsByDate: map[string]int64{today.Format(time.DateOnly): -100},
+	}}).Return(nil)
+
+	adjustment, err := suite.service.RequestAdjustment(ctx, newAdjustment(-100))
+
+	suite.NoError(err)
+	suite.Equal(entity.AdjustmentApproved, adjustment.Status)
+	suite.Equal("alice", adjustment.DecidedBy)
+}
+
+func (suite *AdjustmentServiceTestSuite) TestRequestAdjustment_PendingOverThreshold() {
+	ctx := context.Background()
+	suite.expectBalance(0)
+
+	suite.mockAdjustmentRepo.EXPECT().CreateAdjustment(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, adjustment entity.Adjustment) (*entity.Adjustment, error) {
+			adjustment.ID = "A1"
+			return &adjustment, nil
+		})
+
+	adjustment, err := suite.service.RequestAdjustment(ctx, newAdjustment(1001))
+
+	suite.NoError(err)
+	suite.Equal(entity.AdjustmentPending, adjustment.Status)
+}
+
+func (suite *AdjustmentServiceTestSuite) TestRequestAdjustment_Invalid() {
+	testCases := map[string]struct {
+		adjustment func(*entity.Adjustment)
+		want       string
+	}{
+		"zero points":       {adjustment: func(a *entity.Adjustment) { a.Points = 0 }, want: "points must not be zero"},
+		"unknown reason":    {adjustment: func(a *entity.Adjustment) { a.ReasonCode = "BORED" }, want: "reason_code must be one of"},
+		"missing reference": {adjustment: func(a *entity.Adjustment) { a.Reference = "" }, want: "reference is required"},
+		"missing requester": {adjustment: func(a *entity.Adjustment) { a.RequestedBy = "" }, want: "requested_by is required"},
+	}
+
+	for name, tc := range testCases {
+		suite.Run(name, func() {
+			adjustment := newAdjustment(10)
+			tc.adjustment(&adjustment)
+
+			_, err := suite.service.RequestAdjustment(context.Background(), adjustment)
+
+			suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+			suite.ErrorContains(err, tc.want)
+		})
+	}
+}
+
+func (suite *AdjustmentServiceTestSuite) TestRequestAdjustment_DebitOverBalance() {
+	suite.expectBalance(50)
+
+	_, err := suite.service.RequestAdjustment(context.Background(), newAdjustment(-51))
+
+	suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+	suite.ErrorContains(err, "exceeds the balance of 50")
+}
+
+func (suite *AdjustmentServiceTestSuite) TestRequestAdjustment_CustomerNotFound() {
+	suite.mockCustRepo.EXPECT().GetCustomers(gomock.Any(), []string{"U000001"}).Return(nil, nil)
+
+	_, err := suite.service.RequestAdjustment(context.Background(), newAdjustment(10))
+
+	suite.Equal(apperr.ErrNotFound.Code, apperr.GetCode(err))
+}
+
+func (suite *AdjustmentServiceTestSuite) TestApproveAdjustment() {
+	ctx := context.Background()
+	pending := newAdjustment(5000)
+	pending.ID = "A1"
+	pending.Status = entity.AdjustmentPending
+
+	suite.mockAdjustmentRepo.EXPECT().GetAdjustment(ctx, "A1").Return(&pending, nil)
+	suite.expectBalance(0)
+	suite.approve(pending, "bob")
+	suite.mockCustRepo.EXPECT().UpdateBulkCustomers(ctx, gomock.Len(1)).Return(nil)
+
+	adjustment, err := suite.service.ApproveAdjustment(ctx, "A1", "bob")
+
+	suite.NoError(err)
+	suite.Equal(entity.AdjustmentApproved, adjustment.Status)
+	suite.Equal("bob", adjustment.DecidedBy)
+}
+
+func (suite *AdjustmentServiceTestSuite) TestApproveAdjustment_BySameRequester() {
+	pending := newAdjustment(5000)
+	pending.ID = "A1"
+	pending.Status = entity.AdjustmentPending
+	suite.mockAdjustmentRepo.EXPECT().GetAdjustment(gomock.Any(), "A1").Return(&pending, nil)
+
+	_, err := suite.service.ApproveAdjustment(context.Background(), "A1", "alice")
+
+	suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+}
+
+func (suite *AdjustmentServiceTestSuite) TestApproveAdjustment_AlreadyDecided() {
+	rejected := newAdjustment(5000)
+	rejected.ID = "A1"
+	rejected.Status = entity.AdjustmentRejected
+	suite.mockAdjustmentRepo.EXPECT().GetAdjustment(gomock.Any(), "A1").Return(&rejected, nil)
+
+	_, err := suite.service.ApproveAdjustment(context.Background(), "A1", "bob")
+
+	suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+}
+
+func (suite *AdjustmentServiceTestSuite) TestApproveAdjustment_RevertsWhenNotApplied() {
+	ctx := context.Background()
+	pending := newAdjustment(5000)
+	pending.ID = "A1"
+	pending.Status = entity.AdjustmentPending
+
+	suite.mockAdjustmentRepo.EXPECT().GetAdjustment(ctx, "A1").Return(&pending, nil)
+	suite.expectBalance(0)
+	suite.approve(pending, "bob")
+	suite.mockCustRepo.EXPECT().UpdateBulkCustomers(ctx, gomock.Any()).Return(apperr.ErrInternal.Wrap(errors.New("write failed")))
+	suite.mockAdjustmentRepo.EXPECT().
+		UpdateAdjustmentStatus(ctx, "A1", entity.AdjustmentApproved, entity.AdjustmentPending, "", nil).
+		Return(&pending, nil)
+
+	_, err := suite.service.ApproveAdjustment(ctx, "A1", "bob")
+
+	suite.Equal(apperr.ErrInternal.Code, apperr.GetCode(err))
+}
+
+func (suite *AdjustmentServiceTestSuite) TestRejectAdjustment() {
+	ctx := context.Background()
+	pending := newAdjustment(5000)
+	pending.ID = "A1"
+	pending.Status = entity.AdjustmentPending
+
+	suite.mockAdjustmentRepo.EXPECT().GetAdjustment(ctx, "A1").Return(&pending, nil)
+	suite.mockAdjustmentRepo.EXPECT().
+		UpdateAdjustmentStatus(ctx, "A1", entity.AdjustmentPending, entity.AdjustmentRejected, "alice", gomock.Any()).
+		Return(&entity.Adjustment{ID: "A1", Status: entity.AdjustmentRejected}, nil)
+
+	adjustment, err := suite.service.RejectAdjustment(ctx, "A1", "alice")
+
+	suite.NoError(err)
+	suite.Equal(entity.AdjustmentRejected, adjustment.Status)
+}
+
+func (suite *AdjustmentServiceTestSuite) TestListAdjustments_UnknownStatus() {
+	_, err := suite.service.ListAdjustments(context.Background(), entity.AdjustmentFilter{Status: "DONE"})
+
+	suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+}
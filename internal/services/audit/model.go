@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+)
+
+// AuditRecord is an exported audit entry. Files and details are JSON, and
+// occurred_at keeps the precision the hash covers, so the chain can be checked
+// from an export alone.
+type AuditRecord struct {
+	Sequence   int64  `csv:"sequence"`
+	OccurredAt string `csv:"occurred_at"`
+	Actor      string `csv:"actor"`
+	Source     string `csv:"source"`
+	ClientIP   string `csv:"client_ip"`
+	Action     string `csv:"action"`
+	Target     string `csv:"target"`
+	Status     int64  `csv:"status"`
+	Files      string `csv:"files"`
+	Details    string `csv:"details"`
+	PrevHash   string `csv:"prev_hash"`
+	Hash       string `csv:"hash"`
+}
+
+func newAuditRecord(entry entity.AuditEntry) *AuditRecord {
+	record := &AuditRecord{
+		Sequence:   entry.Sequence,
+		OccurredAt: entry.OccurredAt.UTC().Format(time.RFC3339Nano),
+		Actor:      entry.Actor,
+		Source:     entry.Source,
+		ClientIP:   entry.ClientIP,
+		Action:     entry.Action,
+		Target:     entry.Target,
+		Status:     int64(entry.Status),
+		PrevHash:   entry.PrevHash,
+		Hash:       entry.Hash,
+	}
+
+	if len(entry.Files) > 0 {
+		files := make([]hashedFile, 0, len(entry.Files))
+		for _, file := range entry.Files {
+			files = append(files, hashedFile{Name: file.Name, SHA256: file.SHA256, Size: file.Size})
+		}
+		data, _ := json.Marshal(files)
+		record.Files = string(data)
+	}
+	if len(entry.Details) > 0 {
+		data, _ := json.Marshal(entry.Details)
+		record.Details = string(data)
+	}
+
+	return record
+}
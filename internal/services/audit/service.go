@@ -0,0 +1,242 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/user"
+	"strconv"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+)
+
+// Sources of audit entries.
+const (
+	SourceAPI       = "api"
+	SourceInbox     = "inbox"
+	SourcePointctl  = "pointctl"
+	SourceRecompute = "recompute"
+)
+
+// Actions recorded outside the API, whose entries name the route instead.
+const (
+	ActionUpload    = "upload"
+	ActionRecompute = "recompute"
+	ActionSwap      = "swap"
+)
+
+// maxAppendAttempts bounds how often Record retries when another writer took the
+// next place in the chain first.
+const maxAppendAttempts = 5
+
+type auditService struct {
+	auditRepo repository.AuditRepository
+}
+
+//go:generate mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
+type AuditService interface {
+	// Record appends the entry to the chain and returns it with its sequence and
+	// hashes.
+	Record(ctx context.Context, entry entity.AuditEntry) (*entity.AuditEntry, error)
+	ListEntries(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error)
+	ExportEntries(ctx context.Context, filter entity.AuditFilter, format report.Format, w io.Writer) error
+	// Verify walks the whole chain and reports the first entry that does not match
+	// its hash or does not follow the entry before it.
+	Verify(ctx context.Context) (*entity.AuditVerification, error)
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &auditService{auditRepo: auditRepo}
+}
+
+func (s auditService) Record(ctx context.Context, entry entity.AuditEntry) (*entity.AuditEntry, error) {
+	if entry.Actor == "" || entry.Source == "" || entry.Action == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("actor, source and action are required")
+	}
+
+	// The database keeps milliseconds, and the hash must survive the round trip.
+	entry.OccurredAt = time.Now().UTC().Truncate(time.Millisecond)
+
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		last, err := s.auditRepo.LastAuditEntry(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Sequence, entry.PrevHash = 1, ""
+		if last != nil {
+			entry.Sequence, entry.PrevHash = last.Sequence+1, last.Hash
+		}
+		entry.Hash = HashEntry(entry)
+
+		err = s.auditRepo.CreateAuditEntry(ctx, entry)
+		if err == nil {
+			return &entry, nil
+		}
+		if apperr.GetCode(err) != apperr.ErrConflict.Code {
+			return nil, err
+		}
+	}
+
+	return nil, apperr.ErrConflict.WithMessage("audit log is busy, try again")
+}
+
+func (s auditService) ListEntries(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error) {
+	entries := make([]entity.AuditEntry, 0)
+	err := s.auditRepo.StreamAuditEntries(ctx, filter, func(entry entity.AuditEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// ExportEntries encodes the matching entries in the given format, as they arrive
+// from the database.
+func (s auditService) ExportEntries(ctx context.Context, filter entity.AuditFilter, format report.Format, w io.Writer) error {
+	writer, err := report.NewWriter(format, w, AuditRecord{})
+	if err != nil {
+		return apperr.ErrInternal.Wrap(err)
+	}
+
+	err = s.auditRepo.StreamAuditEntries(ctx, filter, func(entry entity.AuditEntry) error {
+		if err := writer.Write(newAuditRecord(entry)); err != nil {
+			return apperr.ErrInternal.Wrap(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
+		return apperr.ErrInternal.Wrap(err)
+	}
+
+	return nil
+}
+
+func (s auditService) Verify(ctx context.Context) (*entity.AuditVerification, error) {
+	result := &entity.AuditVerification{Valid: true}
+
+	var prevHash string
+	err := s.auditRepo.StreamAuditEntries(ctx, entity.AuditFilter{}, func(entry entity.AuditEntry) error {
+		result.Entries++
+		if !result.Valid {
+			return nil
+		}
+
+		var reason string
+		switch {
+		case entry.Sequence != result.Entries:
+			reason = fmt.Sprintf("expected entry %d, found %d", result.Entries, entry.Sequence)
+		case entry.PrevHash != prevHash:
+			reason = "previous hash does not match the entry before it"
+		case entry.Hash != HashEntry(entry):
+			reason = "hash does not match the entry's content"
+		}
+		if reason != "" {
+			result.Valid, result.FirstBroken, result.Reason = false, entry.Sequence, reason
+		}
+
+		prevHash = entry.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.LastHash = prevHash
+	return result, nil
+}
+
+// hashedEntry is what an entry's hash covers: every field but the hash itself, in
+// a fixed order. Empty fields are left out, so an entry hashes the same whether
+// the database gives them back empty or missing.
+type hashedEntry struct {
+	Sequence   int64             `json:"sequence"`
+	OccurredAt string            `json:"occurred_at"`
+	Actor      string            `json:"actor"`
+	Source     string            `json:"source"`
+	ClientIP   string            `json:"client_ip,omitempty"`
+	Action     string            `json:"action"`
+	Target     string            `json:"target,omitempty"`
+	Status     int               `json:"status,omitempty"`
+	Files      []hashedFile      `json:"files,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	PrevHash   string            `json:"prev_hash"`
+}
+
+type hashedFile struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// HashEntry returns the hex SHA-256 of the entry's content and PrevHash. Map keys
+// are encoded in sorted order, so the hash does not depend on map iteration.
+func HashEntry(entry entity.AuditEntry) string {
+	hashed := hashedEntry{
+		Sequence:   entry.Sequence,
+		OccurredAt: entry.OccurredAt.UTC().Format(time.RFC3339Nano),
+		Actor:      entry.Actor,
+		Source:     entry.Source,
+		ClientIP:   entry.ClientIP,
+		Action:     entry.Action,
+		Target:     entry.Target,
+		Status:     entry.Status,
+		Details:    entry.Details,
+		PrevHash:   entry.PrevHash,
+	}
+	for _, file := range entry.Files {
+		hashed.Files = append(hashed.Files, hashedFile{Name: file.Name, SHA256: file.SHA256, Size: file.Size})
+	}
+
+	// Strings, numbers and a map of strings always encode.
+	data, _ := json.Marshal(hashed)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ProcessDetails are the totals of an upload as they are recorded.
+func ProcessDetails(result *entity.ProcessResult) map[string]string {
+	return map[string]string{
+		"records":        strconv.Itoa(result.Records),
+		"customers":      strconv.Itoa(result.Customers),
+		"points_awarded": strconv.FormatInt(result.PointsAwarded, 10),
+	}
+}
+
+// LocalActor names the operating system user running a command-line tool.
+func LocalActor() string {
+	current, err := user.Current()
+	if err != nil || current.Username == "" {
+		return "unknown"
+	}
+	return current.Username
+}
+
+// HashFile returns the SHA-256 and size of an uploaded file and rewinds it, so it
+// can still be processed.
+func HashFile(name string, r io.ReadSeeker) (entity.AuditFile, error) {
+	hash := sha256.New()
+	size, err := io.Copy(hash, r)
+	if err != nil {
+		return entity.AuditFile{}, apperr.ErrInternal.Wrap(err)
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return entity.AuditFile{}, apperr.ErrInternal.Wrap(err)
+	}
+
+	return entity.AuditFile{Name: name, SHA256: hex.EncodeToString(hash.Sum(nil)), Size: size}, nil
+}
@@ -0,0 +1,235 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_audit"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/report"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type AuditServiceTestSuite struct {
+	suite.Suite
+	mockCtrl      *gomock.Controller
+	mockAuditRepo *mocks_audit.MockAuditRepository
+	service       AuditService
+}
+
+func TestAuditServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditServiceTestSuite))
+}
+
+func (suite *AuditServiceTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockAuditRepo = mocks_audit.NewMockAuditRepository(suite.mockCtrl)
+	suite.service = NewAuditService(suite.mockAuditRepo)
+}
+
+func (suite *AuditServiceTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func newEntry(action string) entity.AuditEntry {
+	return entity.AuditEntry{
+		Actor:   "alice",
+		Source:  SourceAPI,
+		Action:  action,
+		Status:  201,
+		Details: map[string]string{"rule_id": "R1"},
+	}
+}
+
+// chain builds n linked entries the way Record would.
+func chain(n int) []entity.AuditEntry {
+	entries := make([]entity.AuditEntry, 0, n)
+	prevHash := ""
+	for i := 1; i <= n; i++ {
+		entry := newEntry("POST /api/v1/rules")
+		entry.Sequence = int64(i)
+		entry.OccurredAt = time.Date(2025, 1, 15, 10, 0, i, 123e6, time.UTC)
+		entry.PrevHash = prevHash
+		entry.Hash = HashEntry(entry)
+		prevHash = entry.Hash
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (suite *AuditServiceTestSuite) stream(entries []entity.AuditEntry) {
+	suite.mockAuditRepo.EXPECT().
+		StreamAuditEntries(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ entity.AuditFilter, fn func(entity.AuditEntry) error) error {
+			for _, entry := range entries {
+				if err := fn(entry); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
+
+func (suite *AuditServiceTestSuite) TestRecord_First() {
+	ctx := context.Background()
+
+	suite.mockAuditRepo.EXPECT().LastAuditEntry(ctx).Return(nil, nil)
+	suite.mockAuditRepo.EXPECT().CreateAuditEntry(ctx, gomock.Any()).Return(nil)
+
+	entry, err := suite.service.Record(ctx, newEntry("POST /api/v1/rules"))
+
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), entry.Sequence)
+	suite.Empty(entry.PrevHash)
+	suite.Equal(HashEntry(*entry), entry.Hash)
+	suite.Equal(entry.OccurredAt, entry.OccurredAt.Truncate(time.Millisecond))
+}
+
+func (suite *AuditServiceTestSuite) TestRecord_ChainsToLast() {
+	ctx := context.Background()
+	last := chain(3)[2]
+
+	suite.mockAuditRepo.EXPECT().LastAuditEntry(ctx).Return(&last, nil)
+	suite.mockAuditRepo.EXPECT().
+		CreateAuditEntry(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, entry entity.AuditEntry) error {
+			suite.Equal(int64(4), entry.Sequence)
+			suite.Equal(last.Hash, entry.PrevHash)
+			return nil
+		})
+
+	_, err := suite.service.Record(ctx, newEntry("PUT /api/v1/rules/:id"))
+
+	suite.NoError(err)
+}
+
+func (suite *AuditServiceTestSuite) TestRecord_RetriesWhenOvertaken() {
+	ctx := context.Background()
+	entries := chain(2)
+
+	gomock.InOrder(
+		suite.mockAuditRepo.EXPECT().LastAuditEntry(ctx).Return(&entries[0], nil),
+		suite.mockAuditRepo.EXPECT().CreateAuditEntry(ctx, gomock.Any()).Return(apperr.ErrConflict.WithMessage("audit entry 2 already exists")),
+		suite.mockAuditRepo.EXPECT().LastAuditEntry(ctx).Return(&entries[1], nil),
+		suite.mockAuditRepo.EXPECT().CreateAuditEntry(ctx, gomock.Any()).Return(nil),
+	)
+
+	entry, err := suite.service.Record(ctx, newEntry("POST /api/v1/rules"))
+
+	suite.Require().NoError(err)
+	suite.Equal(int64(3), entry.Sequence)
+	suite.Equal(entries[1].Hash, entry.PrevHash)
+}
+
+func (suite *AuditServiceTestSuite) TestRecord_Invalid() {
+	entry := newEntry("POST /api/v1/rules")
+	entry.Actor = ""
+
+	_, err := suite.service.Record(context.Background(), entry)
+
+	suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+}
+
+func (suite *AuditServiceTestSuite) TestVerify_Valid() {
+	entries := chain(3)
+	suite.stream(entries)
+
+	result, err := suite.service.Verify(context.Background())
+
+	suite.NoError(err)
+	suite.Equal(&entity.AuditVerification{Entries: 3, Valid: true, LastHash: entries[2].Hash}, result)
+}
+
+func (suite *AuditServiceTestSuite) TestVerify_Tampered() {
+	testCases := map[string]struct {
+		tamper      func([]entity.AuditEntry) []entity.AuditEntry
+		firstBroken int64
+		reason      string
+	}{
+		"changed detail": {
+			tamper: func(entries []entity.AuditEntry) []entity.AuditEntry {
+				entries[1].Details = map[string]string{"rule_id": "R2"}
+				return entries
+			},
+			firstBroken: 2,
+			reason:      "hash does not match",
+		},
+		"rehashed entry": {
+			tamper: func(entries []entity.AuditEntry) []entity.AuditEntry {
+				entries[1].Actor = "mallory"
+				entries[1].Hash = HashEntry(entries[1])
+				return entries
+			},
+			firstBroken: 3,
+			reason:      "previous hash does not match",
+		},
+		"removed entry": {
+			tamper: func(entries []entity.AuditEntry) []entity.AuditEntry {
+				return append(entries[:1], entries[2:]...)
+			},
+			firstBroken: 3,
+			reason:      "expected entry 2, found 3",
+		},
+	}
+
+	for name, tc := range testCases {
+		suite.Run(name, func() {
+			suite.SetupTest()
+			suite.stream(tc.tamper(chain(3)))
+
+			result, err := suite.service.Verify(context.Background())
+
+			suite.NoError(err)
+			suite.False(result.Valid)
+			suite.Equal(tc.firstBroken, result.FirstBroken)
+			suite.Contains(result.Reason, tc.reason)
+		})
+	}
+}
+
+func (suite *AuditServiceTestSuite) TestHashEntry_EmptyFieldsMatchMissing() {
+	entry := chain(1)[0]
+	withEmpty := entry
+	withEmpty.Files = []entity.AuditFile{}
+	withEmpty.Details = nil
+	entry.Details = map[string]string{}
+
+	suite.Equal(HashEntry(entry), HashEntry(withEmpty))
+}
+
+func (suite *AuditServiceTestSuite) TestExportEntries_CSV() {
+	entries := chain(1)
+	entries[0].Files = []entity.AuditFile{{Name: "2025-01-15.csv", SHA256: "abc", Size: 12}}
+	suite.stream(entries)
+
+	var buf bytes.Buffer
+	err := suite.service.ExportEntries(context.Background(), entity.AuditFilter{}, report.FormatCSV, &buf)
+
+	suite.NoError(err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	suite.Require().Len(lines, 2)
+	suite.Equal("sequence,occurred_at,actor,source,client_ip,action,target,status,files,details,prev_hash,hash", lines[0])
+	suite.Contains(lines[1], "1,2025-01-15T10:00:01.123Z,alice,api,,POST /api/v1/rules,,201,")
+	suite.Contains(lines[1], `"[{""name"":""2025-01-15.csv"",""sha256"":""abc"",""size"":12}]"`)
+	suite.True(strings.HasSuffix(lines[1], ","+entries[0].Hash))
+}
+
+func (suite *AuditServiceTestSuite) TestHashFile() {
+	file := strings.NewReader("abc")
+
+	digest, err := HashFile("2025-01-15.csv", file)
+
+	suite.NoError(err)
+	suite.Equal(entity.AuditFile{
+		Name:   "2025-01-15.csv",
+		SHA256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		Size:   3,
+	}, digest)
+	suite.Equal(int64(3), file.Size())
+	suite.Equal(3, file.Len())
+}
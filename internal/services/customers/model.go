@@ -0,0 +1,74 @@
+package customers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+)
+
+// AttributeRecord is one row of a customer attribute import. Empty columns clear
+// the attribute.
+type AttributeRecord struct {
+	CustomerID string `csv:"customer_id"`
+	SignupDate string `csv:"signup_date"`
+	BirthMonth string `csv:"birth_month"`
+	Segment    string `csv:"segment"`
+}
+
+type AttributeRecords []*AttributeRecord
+
+// toUpdates validates the rows and returns one update per customer. When a
+// customer appears more than once the last row wins.
+func (records AttributeRecords) toUpdates() ([]entity.UpdateCustomerAttributes, error) {
+	index := make(map[string]int, len(records))
+	result := make([]entity.UpdateCustomerAttributes, 0, len(records))
+
+	for i, record := range records {
+		// Row 1 is the header.
+		update, err := record.toUpdate()
+		if err != nil {
+			return nil, apperr.ErrInvalidArgument.WithMessage(fmt.Sprintf("row %d: %s", i+2, err))
+		}
+
+		if j, ok := index[update.CustomerID]; ok {
+			result[j] = update
+			continue
+		}
+		index[update.CustomerID] = len(result)
+		result = append(result, update)
+	}
+
+	return result, nil
+}
+
+func (record AttributeRecord) toUpdate() (entity.UpdateCustomerAttributes, error) {
+	update := entity.UpdateCustomerAttributes{
+		CustomerID: strings.TrimSpace(record.CustomerID),
+		Attributes: entity.CustomerAttributes{Segment: strings.TrimSpace(record.Segment)},
+	}
+	if update.CustomerID == "" {
+		return update, fmt.Errorf("customer_id is required")
+	}
+
+	if value := strings.TrimSpace(record.SignupDate); value != "" {
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return update, fmt.Errorf("signup_date must be in YYYY-MM-DD format")
+		}
+		update.Attributes.SignupDate = &date
+	}
+
+	if value := strings.TrimSpace(record.BirthMonth); value != "" {
+		month, err := strconv.Atoi(value)
+		if err != nil || month < 1 || month > 12 {
+			return update, fmt.Errorf("birth_month must be between 1 and 12")
+		}
+		update.Attributes.BirthMonth = month
+	}
+
+	return update, nil
+}
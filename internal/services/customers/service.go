@@ -0,0 +1,87 @@
+package customers
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/csv"
+)
+
+type customerService struct {
+	customerRepo repository.CustomerRepository
+	tierRepo     repository.TierRepository
+}
+
+//go:generate mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
+type CustomerService interface {
+	GetCustomer(ctx context.Context, customerID string, asOf time.Time) (*entity.CustomerProfile, error)
+	GetTiers(ctx context.Context) (entity.Tiers, error)
+	ImportAttributes(ctx context.Context, reader io.ReadSeeker) (int, error)
+}
+
+func NewCustomerService(customerRepo repository.CustomerRepository, tierRepo repository.TierRepository) CustomerService {
+	return &customerService{
+		customerRepo: customerRepo,
+		tierRepo:     tierRepo,
+	}
+}
+
+// GetCustomer returns the customer with the tier they hold at the end of asOf and
+// every promotion and demotion up to then.
+func (s customerService) GetCustomer(ctx context.Context, customerID string, asOf time.Time) (*entity.CustomerProfile, error) {
+	if customerID == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("customer_id is required")
+	}
+
+	customers, err := s.customerRepo.GetCustomers(ctx, []string{customerID})
+	if err != nil {
+		return nil, err
+	}
+
+	customer, found := entity.Customers(customers).GetCustomerByID(customerID)
+	if !found {
+		return nil, apperr.ErrNotFound.WithMessage("customer not found")
+	}
+
+	tiers, err := s.tierRepo.GetTiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.CustomerProfile{
+		Customer: customer,
+		Tier:     tiers.Standing(customer, asOf),
+	}, nil
+}
+
+func (s customerService) GetTiers(ctx context.Context) (entity.Tiers, error) {
+	return s.tierRepo.GetTiers(ctx)
+}
+
+// ImportAttributes reads customer attributes from a CSV with the columns of
+// AttributeRecord and stores them, replacing what each customer had. Nothing is
+// stored when any row is invalid. It returns the number of customers updated.
+func (s customerService) ImportAttributes(ctx context.Context, reader io.ReadSeeker) (int, error) {
+	var records AttributeRecords
+	if err := csv.UnmarshalWithHeaderValidation(reader, &records); err != nil {
+		return 0, apperr.ErrInvalidArgument.Wrap(err)
+	}
+
+	updates, err := records.toUpdates()
+	if err != nil {
+		return 0, err
+	}
+	if len(updates) == 0 {
+		return 0, apperr.ErrInvalidArgument.WithMessage("no customers to import")
+	}
+
+	if err = s.customerRepo.UpdateCustomerAttributes(ctx, updates); err != nil {
+		return 0, err
+	}
+
+	return len(updates), nil
+}
@@ -0,0 +1,108 @@
+package customers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_customer"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_tier"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type CustomerServiceTestSuite struct {
+	suite.Suite
+	mockCtrl     *gomock.Controller
+	mockCustRepo *mocks_customer.MockCustomerRepository
+	mockTierRepo *mocks_tier.MockTierRepository
+	service      CustomerService
+}
+
+func TestCustomerServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(CustomerServiceTestSuite))
+}
+
+func (suite *CustomerServiceTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockCustRepo = mocks_customer.NewMockCustomerRepository(suite.mockCtrl)
+	suite.mockTierRepo = mocks_tier.NewMockTierRepository(suite.mockCtrl)
+	suite.service = NewCustomerService(suite.mockCustRepo, suite.mockTierRepo)
+}
+
+func (suite *CustomerServiceTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *CustomerServiceTestSuite) TestGetCustomer_TierHistory() {
+	ctx := context.Background()
+	tiers := entity.Tiers{
+		{Name: "SILVER", Rank: 1, Basis: entity.TierBasisPoints, Threshold: decimal.NewFromInt(1), Multiplier: decimal.NewFromInt(1)},
+		{Name: "GOLD", Rank: 2, Basis: entity.TierBasisPoints, Threshold: decimal.NewFromInt(100), Multiplier: decimal.RequireFromString("1.25")},
+	}
+	customer := entity.Customer{
+		CustomerID:   "U000001",
+		Points:       130,
+		PointsByDate: map[string]int64{"2024-01-10": 20, "2024-03-01": 90, "2025-02-01": 20},
+	}
+
+	suite.mockCustRepo.EXPECT().GetCustomers(ctx, []string{"U000001"}).Return([]entity.Customer{customer}, nil)
+	suite.mockTierRepo.EXPECT().GetTiers(ctx).Return(tiers, nil)
+
+	profile, err := suite.service.GetCustomer(ctx, "U000001", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	suite.NoError(err)
+	suite.Equal("SILVER", profile.Tier.Tier)
+	suite.Equal(int64(20), profile.Tier.QualifyingPoints)
+	suite.Equal([]entity.TierChange{
+		{Date: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), To: "SILVER", Promotion: true},
+		{Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), From: "SILVER", To: "GOLD", Promotion: true},
+		{Date: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), From: "GOLD", To: "SILVER"},
+		{Date: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), From: "SILVER", To: "GOLD", Promotion: true},
+		{Date: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), From: "GOLD", To: "SILVER"},
+	}, profile.Tier.History)
+	suite.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), *profile.Tier.Since)
+}
+
+func (suite *CustomerServiceTestSuite) TestGetCustomer_NotFound() {
+	ctx := context.Background()
+	suite.mockCustRepo.EXPECT().GetCustomers(ctx, []string{"U404"}).Return(nil, nil)
+
+	_, err := suite.service.GetCustomer(ctx, "U404", time.Now())
+
+	suite.Equal(apperr.ErrNotFound.Code, apperr.GetCode(err))
+}
+
+func (suite *CustomerServiceTestSuite) TestImportAttributes() {
+	ctx := context.Background()
+	csvContent := "customer_id,signup_date,birth_month,segment\n" +
+		"U000001,2024-05-20,7,VIP\n" +
+		"U000002,,,\n" +
+		"U000001,2024-05-20,8,VIP\n"
+	signup := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+
+	suite.mockCustRepo.EXPECT().UpdateCustomerAttributes(ctx, []entity.UpdateCustomerAttributes{
+		{CustomerID: "U000001", Attributes: entity.CustomerAttributes{SignupDate: &signup, BirthMonth: 8, Segment: "VIP"}},
+		{CustomerID: "U000002"},
+	}).Return(nil)
+
+	count, err := suite.service.ImportAttributes(ctx, strings.NewReader(csvContent))
+
+	suite.NoError(err)
+	suite.Equal(2, count)
+}
+
+func (suite *CustomerServiceTestSuite) TestImportAttributes_InvalidRow() {
+	csvContent := "customer_id,signup_date,birth_month,segment\n" +
+		"U000001,2024-05-20,7,VIP\n" +
+		"U000002,,13,\n"
+
+	_, err := suite.service.ImportAttributes(context.Background(), strings.NewReader(csvContent))
+
+	suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+	suite.Contains(err.Error(), "row 3: birth_month must be between 1 and 12")
+}
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHouseholdService is a mock of HouseholdService interface.
+type MockHouseholdService struct {
+	ctrl     *gomock.Controller
+	recorder *MockHouseholdServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockHouseholdServiceMockRecorder is the mock recorder for MockHouseholdService.
+type MockHouseholdServiceMockRecorder struct {
+	mock *MockHouseholdService
+}
+
+// NewMockHouseholdService creates a new mock instance.
+func NewMockHouseholdService(ctrl *gomock.Controller) *MockHouseholdService {
+	mock := &MockHouseholdService{ctrl: ctrl}
+	mock.recorder = &MockHouseholdServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHouseholdService) EXPECT() *MockHouseholdServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateHousehold mocks base method.
+func (m *MockHouseholdService) CreateHousehold(ctx context.Context, household entity.Household) (*entity.Household, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHousehold", ctx, household)
+	ret0, _ := ret[0].(*entity.Household)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHousehold indicates an expected call of CreateHousehold.
+func (mr *MockHouseholdServiceMockRecorder) CreateHousehold(ctx, household any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHousehold", reflect.TypeOf((*MockHouseholdService)(nil).CreateHousehold), ctx, household)
+}
+
+// GetHousehold mocks base method.
+func (m *MockHouseholdService) GetHousehold(ctx context.Context, id string) (*entity.Household, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHousehold", ctx, id)
+	ret0, _ := ret[0].(*entity.Household)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHousehold indicates an expected call of GetHousehold.
+func (mr *MockHouseholdServiceMockRecorder) GetHousehold(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHousehold", reflect.TypeOf((*MockHouseholdService)(nil).GetHousehold), ctx, id)
+}
+
+// JoinHousehold mocks base method.
+func (m *MockHouseholdService) JoinHousehold(ctx context.Context, householdID, customerID string) (*entity.Household, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinHousehold", ctx, householdID, customerID)
+	ret0, _ := ret[0].(*entity.Household)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JoinHousehold indicates an expected call of JoinHousehold.
+func (mr *MockHouseholdServiceMockRecorder) JoinHousehold(ctx, householdID, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinHousehold", reflect.TypeOf((*MockHouseholdService)(nil).JoinHousehold), ctx, householdID, customerID)
+}
+
+// LeaveHousehold mocks base method.
+func (m *MockHouseholdService) LeaveHousehold(ctx context.Context, householdID, customerID string) (*entity.Household, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaveHousehold", ctx, householdID, customerID)
+	ret0, _ := ret[0].(*entity.Household)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LeaveHousehold indicates an expected call of LeaveHousehold.
+func (mr *MockHouseholdServiceMockRecorder) LeaveHousehold(ctx, householdID, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaveHousehold", reflect.TypeOf((*MockHouseholdService)(nil).LeaveHousehold), ctx, householdID, customerID)
+}
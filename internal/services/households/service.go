@@ -0,0 +1,136 @@
+package households
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+)
+
+type householdService struct {
+	householdRepo repository.HouseholdRepository
+	customerRepo  repository.CustomerRepository
+	cfg           *config.Config
+}
+
+//go:generate mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
+type HouseholdService interface {
+	CreateHousehold(ctx context.Context, household entity.Household) (*entity.Household, error)
+	GetHousehold(ctx context.Context, id string) (*entity.Household, error)
+	JoinHousehold(ctx context.Context, householdID, customerID string) (*entity.Household, error)
+	LeaveHousehold(ctx context.Context, householdID, customerID string) (*entity.Household, error)
+}
+
+func NewHouseholdService(householdRepo repository.HouseholdRepository, customerRepo repository.CustomerRepository, cfg *config.Config) HouseholdService {
+	return &householdService{
+		householdRepo: householdRepo,
+		customerRepo:  customerRepo,
+		cfg:           cfg,
+	}
+}
+
+// CreateHousehold creates an empty household. Its ID becomes the customer ID of
+// the pooled balance, so it must not be taken by a customer.
+func (s householdService) CreateHousehold(ctx context.Context, household entity.Household) (*entity.Household, error) {
+	if household.ID == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("id is required")
+	}
+
+	_, found, err := s.getCustomer(ctx, household.ID)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return nil, apperr.ErrConflict.WithMessage(fmt.Sprintf("%s is already a customer ID", household.ID))
+	}
+
+	household.Members = nil
+	household.CreatedAt = time.Now().UTC()
+
+	return s.householdRepo.CreateHousehold(ctx, household)
+}
+
+// GetHousehold returns the household with its pooled balance.
+func (s householdService) GetHousehold(ctx context.Context, id string) (*entity.Household, error) {
+	household, err := s.householdRepo.GetHousehold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	account, _, err := s.getCustomer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	household.Points = account.Points
+
+	return household, nil
+}
+
+// JoinHousehold adds a customer who is in no household. The household's member
+// list is updated first, so HouseholdMaxMembers holds under concurrent joins, and
+// is given back when the customer cannot be marked as a member.
+func (s householdService) JoinHousehold(ctx context.Context, householdID, customerID string) (*entity.Household, error) {
+	if _, err := s.householdRepo.GetHousehold(ctx, householdID); err != nil {
+		return nil, err
+	}
+
+	customer, found, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, apperr.ErrNotFound.WithMessage("customer not found")
+	}
+	if customer.MergedInto != "" {
+		return nil, apperr.ErrConflict.WithMessage(fmt.Sprintf("customer was merged into %s", customer.MergedInto))
+	}
+	if customer.HouseholdID != "" {
+		return nil, apperr.ErrConflict.WithMessage(fmt.Sprintf("customer is already in household %s", customer.HouseholdID))
+	}
+	if _, err = s.householdRepo.GetHousehold(ctx, customerID); err == nil {
+		return nil, apperr.ErrInvalidArgument.WithMessage("a household cannot join a household")
+	} else if apperr.GetCode(err) != apperr.ErrNotFound.Code {
+		return nil, err
+	}
+
+	if err = s.householdRepo.AddMember(ctx, householdID, customerID, s.cfg.HouseholdMaxMembers); err != nil {
+		return nil, err
+	}
+
+	if err = s.customerRepo.SetHousehold(ctx, customerID, "", householdID); err != nil {
+		if removeErr := s.householdRepo.RemoveMember(ctx, householdID, customerID); removeErr != nil {
+			return nil, apperr.ErrInternal.Wrap(fmt.Errorf("%w; %s left in household %s: %w", err, customerID, householdID, removeErr))
+		}
+		return nil, err
+	}
+
+	return s.GetHousehold(ctx, householdID)
+}
+
+// LeaveHousehold takes a member out of the household. What they pooled stays in
+// the household's balance.
+func (s householdService) LeaveHousehold(ctx context.Context, householdID, customerID string) (*entity.Household, error) {
+	if err := s.customerRepo.SetHousehold(ctx, customerID, householdID, ""); err != nil {
+		return nil, err
+	}
+
+	if err := s.householdRepo.RemoveMember(ctx, householdID, customerID); err != nil {
+		return nil, err
+	}
+
+	return s.GetHousehold(ctx, householdID)
+}
+
+func (s householdService) getCustomer(ctx context.Context, customerID string) (entity.Customer, bool, error) {
+	customers, err := s.customerRepo.GetCustomers(ctx, []string{customerID})
+	if err != nil {
+		return entity.Customer{}, false, err
+	}
+
+	customer, found := entity.Customers(customers).GetCustomerByID(customerID)
+	return customer, found, nil
+}
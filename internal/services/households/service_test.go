@@ -0,0 +1,135 @@
+package households
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_customer"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_household"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type HouseholdServiceTestSuite struct {
+	suite.Suite
+	mockCtrl          *gomock.Controller
+	mockHouseholdRepo *mocks_household.MockHouseholdRepository
+	mockCustRepo      *mocks_customer.MockCustomerRepository
+	service           HouseholdService
+}
+
+func TestHouseholdServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(HouseholdServiceTestSuite))
+}
+
+func (suite *HouseholdServiceTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockHouseholdRepo = mocks_household.NewMockHouseholdRepository(suite.mockCtrl)
+	suite.mockCustRepo = mocks_customer.NewMockCustomerRepository(suite.mockCtrl)
+	suite.service = NewHouseholdService(suite.mockHouseholdRepo, suite.mockCustRepo, &config.Config{HouseholdMaxMembers: 4})
+}
+
+func (suite *HouseholdServiceTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *HouseholdServiceTestSuite) TestCreateHousehold() {
+	ctx := context.Background()
+	suite.mockCustRepo.EXPECT().GetCustomers(ctx, []string{"HH0001"}).Return(nil, nil)
+	suite.mockHouseholdRepo.EXPECT().
+		CreateHousehold(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, household entity.Household) (*entity.Household, error) {
+			suite.False(household.CreatedAt.IsZero())
+			return &household, nil
+		})
+
+	household, err := suite.service.CreateHousehold(ctx, entity.Household{ID: "HH0001", Name: "Smith"})
+
+	suite.NoError(err)
+	suite.Equal("Smith", household.Name)
+}
+
+func (suite *HouseholdServiceTestSuite) TestCreateHousehold_CustomerID() {
+	suite.mockCustRepo.EXPECT().GetCustomers(gomock.Any(), []string{"U000001"}).Return([]entity.Customer{{CustomerID: "U000001"}}, nil)
+
+	_, err := suite.service.CreateHousehold(context.Background(), entity.Household{ID: "U000001"})
+
+	suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+}
+
+func (suite *HouseholdServiceTestSuite) TestGetHousehold_PooledBalance() {
+	ctx := context.Background()
+	suite.mockHouseholdRepo.EXPECT().GetHousehold(ctx, "HH0001").Return(&entity.Household{ID: "HH0001", Members: []string{"U000001"}}, nil)
+	suite.mockCustRepo.EXPECT().GetCustomers(ctx, []string{"HH0001"}).Return([]entity.Customer{{CustomerID: "HH0001", Points: 250}}, nil)
+
+	household, err := suite.service.GetHousehold(ctx, "HH0001")
+
+	suite.NoError(err)
+	suite.Equal(int64(250), household.Points)
+}
+
+func (suite *HouseholdServiceTestSuite) TestJoinHousehold() {
+	ctx := context.Background()
+	household := &entity.Household{ID: "HH0001"}
+	suite.mockHouseholdRepo.EXPECT().GetHousehold(ctx, "HH0001").Return(household, nil).Times(2)
+	suite.mockCustRepo.EXPECT().GetCustomers(ctx, []string{"U000001"}).Return([]entity.Customer{{CustomerID: "U000001"}}, nil)
+	suite.mockHouseholdRepo.EXPECT().GetHousehold(ctx, "U000001").Return(nil, apperr.ErrNotFound)
+	suite.mockHouseholdRepo.EXPECT().AddMember(ctx, "HH0001", "U000001", 4).Return(nil)
+	suite.mockCustRepo.EXPECT().SetHousehold(ctx, "U000001", "", "HH0001").Return(nil)
+	suite.mockCustRepo.EXPECT().GetCustomers(ctx, []string{"HH0001"}).Return(nil, nil)
+
+	_, err := suite.service.JoinHousehold(ctx, "HH0001", "U000001")
+
+	suite.NoError(err)
+}
+
+func (suite *HouseholdServiceTestSuite) TestJoinHousehold_AlreadyInHousehold() {
+	ctx := context.Background()
+	suite.mockHouseholdRepo.EXPECT().GetHousehold(ctx, "HH0002").Return(&entity.Household{ID: "HH0002"}, nil)
+	suite.mockCustRepo.EXPECT().GetCustomers(ctx, []string{"U000001"}).Return([]entity.Customer{{CustomerID: "U000001", HouseholdID: "HH0001"}}, nil)
+
+	_, err := suite.service.JoinHousehold(ctx, "HH0002", "U000001")
+
+	suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+	suite.ErrorContains(err, "already in household HH0001")
+}
+
+func (suite *HouseholdServiceTestSuite) TestJoinHousehold_GivesBackPlaceOnFailure() {
+	ctx := context.Background()
+	suite.mockHouseholdRepo.EXPECT().GetHousehold(ctx, "HH0001").Return(&entity.Household{ID: "HH0001"}, nil)
+	suite.mockCustRepo.EXPECT().GetCustomers(ctx, []string{"U000001"}).Return([]entity.Customer{{CustomerID: "U000001"}}, nil)
+	suite.mockHouseholdRepo.EXPECT().GetHousehold(ctx, "U000001").Return(nil, apperr.ErrNotFound)
+	suite.mockHouseholdRepo.EXPECT().AddMember(ctx, "HH0001", "U000001", 4).Return(nil)
+	suite.mockCustRepo.EXPECT().SetHousehold(ctx, "U000001", "", "HH0001").Return(apperr.ErrConflict.WithMessage("customer is not in the expected household"))
+	suite.mockHouseholdRepo.EXPECT().RemoveMember(ctx, "HH0001", "U000001").Return(nil)
+
+	_, err := suite.service.JoinHousehold(ctx, "HH0001", "U000001")
+
+	suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+}
+
+func (suite *HouseholdServiceTestSuite) TestJoinHousehold_Full() {
+	ctx := context.Background()
+	suite.mockHouseholdRepo.EXPECT().GetHousehold(ctx, "HH0001").Return(&entity.Household{ID: "HH0001"}, nil)
+	suite.mockCustRepo.EXPECT().GetCustomers(ctx, []string{"U000001"}).Return([]entity.Customer{{CustomerID: "U000001"}}, nil)
+	suite.mockHouseholdRepo.EXPECT().GetHousehold(ctx, "U000001").Return(nil, apperr.ErrNotFound)
+	suite.mockHouseholdRepo.EXPECT().AddMember(ctx, "HH0001", "U000001", 4).Return(apperr.ErrConflict.WithMessage("household is full"))
+
+	_, err := suite.service.JoinHousehold(ctx, "HH0001", "U000001")
+
+	suite.ErrorContains(err, "household is full")
+}
+
+func (suite *HouseholdServiceTestSuite) TestLeaveHousehold_NotMember() {
+	suite.mockCustRepo.EXPECT().
+		SetHousehold(gomock.Any(), "U000001", "HH0001", "").
+		Return(apperr.ErrConflict.Wrap(errors.New("no match")))
+
+	_, err := suite.service.LeaveHousehold(context.Background(), "HH0001", "U000001")
+
+	suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+}
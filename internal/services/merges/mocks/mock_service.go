@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMergeService is a mock of MergeService interface.
+type MockMergeService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMergeServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockMergeServiceMockRecorder is the mock recorder for MockMergeService.
+type MockMergeServiceMockRecorder struct {
+	mock *MockMergeService
+}
+
+// NewMockMergeService creates a new mock instance.
+func NewMockMergeService(ctrl *gomock.Controller) *MockMergeService {
+	mock := &MockMergeService{ctrl: ctrl}
+	mock.recorder = &MockMergeServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMergeService) EXPECT() *MockMergeServiceMockRecorder {
+	return m.recorder
+}
+
+// ListMerges mocks base method.
+func (m *MockMergeService) ListMerges(ctx context.Context, customerID string) ([]entity.Merge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerges", ctx, customerID)
+	ret0, _ := ret[0].([]entity.Merge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerges indicates an expected call of ListMerges.
+func (mr *MockMergeServiceMockRecorder) ListMerges(ctx, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerges", reflect.TypeOf((*MockMergeService)(nil).ListMerges), ctx, customerID)
+}
+
+// MergeCustomers mocks base method.
+func (m *MockMergeService) MergeCustomers(ctx context.Context, merge entity.Merge) (*entity.Merge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeCustomers", ctx, merge)
+	ret0, _ := ret[0].(*entity.Merge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeCustomers indicates an expected call of MergeCustomers.
+func (mr *MockMergeServiceMockRecorder) MergeCustomers(ctx, merge any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeCustomers", reflect.TypeOf((*MockMergeService)(nil).MergeCustomers), ctx, merge)
+}
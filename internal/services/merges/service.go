@@ -0,0 +1,180 @@
+package merges
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+)
+
+type mergeService struct {
+	mergeRepo    repository.MergeRepository
+	customerRepo repository.CustomerRepository
+}
+
+//go:generate mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
+type MergeService interface {
+	MergeCustomers(ctx context.Context, merge entity.Merge) (*entity.Merge, error)
+	ListMerges(ctx context.Context, customerID string) ([]entity.Merge, error)
+}
+
+func NewMergeService(mergeRepo repository.MergeRepository, customerRepo repository.CustomerRepository) MergeService {
+	return &mergeService{
+		mergeRepo:    mergeRepo,
+		customerRepo: customerRepo,
+	}
+}
+
+// MergeCustomers moves the source customer's points and history to the target,
+// dropping what the target already holds, and leaves the source ID as an alias
+// of the target so later uploads under it credit the target.
+func (s mergeService) MergeCustomers(ctx context.Context, merge entity.Merge) (*entity.Merge, error) {
+	if err := validateMerge(merge); err != nil {
+		return nil, err
+	}
+
+	customers, err := s.customerRepo.GetCustomers(ctx, []string{merge.SourceCustomerID, merge.TargetCustomerID})
+	if err != nil {
+		return nil, err
+	}
+
+	source, found := entity.Customers(customers).GetCustomerByID(merge.SourceCustomerID)
+	if !found {
+		return nil, apperr.ErrNotFound.WithMessage("customer to merge not found")
+	}
+	target, found := entity.Customers(customers).GetCustomerByID(merge.TargetCustomerID)
+	if !found {
+		return nil, apperr.ErrNotFound.WithMessage("customer to merge into not found")
+	}
+	for _, customer := range []entity.Customer{source, target} {
+		if customer.MergedInto != "" {
+			return nil, apperr.ErrConflict.WithMessage(fmt.Sprintf("customer %s was already merged into %s", customer.CustomerID, customer.MergedInto))
+		}
+	}
+	if source.HouseholdID != "" {
+		return nil, apperr.ErrConflict.WithMessage(fmt.Sprintf("customer %s must leave household %s first", source.CustomerID, source.HouseholdID))
+	}
+
+	merged, duplicates := mergeCustomers(target, source)
+	if err = s.customerRepo.MergeCustomers(ctx, source, merged); err != nil {
+		return nil, err
+	}
+
+	merge.MergedAt = time.Now().UTC()
+	merge.Records = len(source.Records) - len(duplicates)
+	merge.Duplicates = len(duplicates)
+	for _, duplicate := range duplicates {
+		merge.DuplicatePoints += duplicate.Points
+	}
+	merge.Points = source.Points - merge.DuplicatePoints
+
+	result, err := s.mergeRepo.CreateMerge(ctx, merge)
+	if err != nil {
+		return nil, apperr.ErrInternal.Wrap(fmt.Errorf("customer %s was merged into %s but the merge was not recorded: %w", source.CustomerID, target.CustomerID, err))
+	}
+
+	return result, nil
+}
+
+func (s mergeService) ListMerges(ctx context.Context, customerID string) ([]entity.Merge, error) {
+	return s.mergeRepo.ListMerges(ctx, customerID)
+}
+
+func validateMerge(merge entity.Merge) error {
+	if merge.SourceCustomerID == "" || merge.TargetCustomerID == "" {
+		return apperr.ErrInvalidArgument.WithMessage("source_customer_id and target_customer_id are required")
+	}
+	if merge.SourceCustomerID == merge.TargetCustomerID {
+		return apperr.ErrInvalidArgument.WithMessage("cannot merge a customer into itself")
+	}
+	if merge.RequestedBy == "" {
+		return apperr.ErrInvalidArgument.WithMessage("requested_by is required")
+	}
+
+	return nil
+}
+
+type duplicateKey struct {
+	Type         entity.TransactionType
+	PurchaseDate time.Time
+	BranchID     string
+	ProductID    string
+	Amount       string
+}
+
+func newDuplicateKey(record entity.Record) duplicateKey {
+	return duplicateKey{
+		Type:         record.Type,
+		PurchaseDate: record.PurchaseDate,
+		BranchID:     record.BranchID,
+		ProductID:    record.ProductID,
+		Amount:       record.Amount.String(),
+	}
+}
+
+// mergeCustomers returns target with the points, history and aliases of source
+// added, and the source movements left out because target already holds a
+// purchase or return of the same line, the way an upload would have refused it.
+// Adjustments, transfers and pool movements are never duplicates.
+func mergeCustomers(target, source entity.Customer) (merged entity.Customer, duplicates []entity.Record) {
+	held := make(map[duplicateKey]struct{})
+	for _, record := range target.Records {
+		if record.IsPurchase() || record.IsReturn() {
+			held[newDuplicateKey(record)] = struct{}{}
+		}
+	}
+
+	merged = target
+	merged.Records = slices.Clone(target.Records)
+	merged.PointsByDate = make(map[string]int64, len(target.PointsByDate)+len(source.PointsByDate))
+	for date, points := range target.PointsByDate {
+		merged.PointsByDate[date] = points
+	}
+	for date, points := range source.PointsByDate {
+		merged.PointsByDate[date] += points
+	}
+	merged.Points += source.Points
+
+	for _, record := range source.Records {
+		if _, ok := held[newDuplicateKey(record)]; ok && (record.IsPurchase() || record.IsReturn()) {
+			duplicates = append(duplicates, record)
+			merged.Points -= record.Points
+			merged.PointsByDate[record.PurchaseDate.Format(time.DateOnly)] -= record.Points
+			continue
+		}
+		merged.Records = append(merged.Records, record)
+	}
+	sort.SliceStable(merged.Records, func(i, j int) bool {
+		return merged.Records[i].PurchaseDate.Before(merged.Records[j].PurchaseDate)
+	})
+
+	if source.LastPurchaseDate.After(merged.LastPurchaseDate) {
+		merged.LastPurchaseDate = source.LastPurchaseDate
+	}
+	merged.Attributes = mergeAttributes(target.Attributes, source.Attributes)
+	merged.Aliases = append(slices.Clone(target.Aliases), source.CustomerID)
+	merged.Aliases = append(merged.Aliases, source.Aliases...)
+
+	return merged, duplicates
+}
+
+// mergeAttributes keeps the target's attributes, filling in what it lacks from the
+// source. The earlier signup date wins, since the person has been a member since.
+func mergeAttributes(target, source entity.CustomerAttributes) entity.CustomerAttributes {
+	result := target
+	if source.SignupDate != nil && (result.SignupDate == nil || source.SignupDate.Before(*result.SignupDate)) {
+		result.SignupDate = source.SignupDate
+	}
+	if result.BirthMonth == 0 {
+		result.BirthMonth = source.BirthMonth
+	}
+	if result.Segment == "" {
+		result.Segment = source.Segment
+	}
+	return result
+}
@@ -0,0 +1,176 @@
+package merges
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_customer"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_merge"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type MergeServiceTestSuite struct {
+	suite.Suite
+	mockCtrl      *gomock.Controller
+	mockMergeRepo *mocks_merge.MockMergeRepository
+	mockCustRepo  *mocks_customer.MockCustomerRepository
+	service       MergeService
+}
+
+func TestMergeServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(MergeServiceTestSuite))
+}
+
+func (suite *MergeServiceTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockMergeRepo = mocks_merge.NewMockMergeRepository(suite.mockCtrl)
+	suite.mockCustRepo = mocks_customer.NewMockCustomerRepository(suite.mockCtrl)
+	suite.service = NewMergeService(suite.mockMergeRepo, suite.mockCustRepo)
+}
+
+func (suite *MergeServiceTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func newMerge() entity.Merge {
+	return entity.Merge{SourceCustomerID: "U000002", TargetCustomerID: "U000001", Reason: "same person", RequestedBy: "alice"}
+}
+
+func purchase(productID string, day int, points int64) entity.Record {
+	return entity.Record{
+		ProductID:    productID,
+		BranchID:     "BR1",
+		Amount:       decimal.NewFromInt(100),
+		PurchaseDate: time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC),
+		RuleID:       "FIXED",
+		Points:       points,
+	}
+}
+
+func (suite *MergeServiceTestSuite) TestMergeCustomers() {
+	ctx := context.Background()
+	signup := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	target := entity.Customer{
+		CustomerID:       "U000001",
+		Points:           10,
+		LastPurchaseDate: purchase("P1", 10, 0).PurchaseDate,
+		Records:          []entity.Record{purchase("P1", 10, 10)},
+		PointsByDate:     map[string]int64{"2025-01-10": 10},
+		Attributes:       entity.CustomerAttributes{Segment: "VIP"},
+	}
+	source := entity.Customer{
+		CustomerID:       "U000002",
+		Points:           35,
+		LastPurchaseDate: purchase("P2", 12, 0).PurchaseDate,
+		Records: []entity.Record{
+			purchase("P1", 10, 10),
+			purchase("P2", 12, 20),
+			{PurchaseDate: purchase("P2", 12, 0).PurchaseDate, Points: 5, Type: entity.AdjustmentTransaction, AdjustmentID: "A1"},
+		},
+		PointsByDate: map[string]int64{"2025-01-10": 10, "2025-01-12": 25},
+		Attributes:   entity.CustomerAttributes{SignupDate: &signup, BirthMonth: 4},
+		Aliases:      []string{"U000003"},
+	}
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(ctx, []string{"U000002", "U000001"}).
+		Return([]entity.Customer{target, source}, nil)
+	suite.mockCustRepo.EXPECT().
+		MergeCustomers(ctx, source, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ entity.Customer, merged entity.Customer) error {
+			suite.Equal("U000001", merged.CustomerID)
+			suite.Equal(int64(35), merged.Points)
+			suite.Equal(map[string]int64{"2025-01-10": 10, "2025-01-12": 25}, merged.PointsByDate)
+			suite.Len(merged.Records, 3)
+			suite.Equal(source.LastPurchaseDate, merged.LastPurchaseDate)
+			suite.Equal([]string{"U000002", "U000003"}, merged.Aliases)
+			suite.Equal(entity.CustomerAttributes{SignupDate: &signup, BirthMonth: 4, Segment: "VIP"}, merged.Attributes)
+			return nil
+		})
+	suite.mockMergeRepo.EXPECT().
+		CreateMerge(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, merge entity.Merge) (*entity.Merge, error) {
+			merge.ID = "M1"
+			return &merge, nil
+		})
+
+	merge, err := suite.service.MergeCustomers(ctx, newMerge())
+
+	suite.NoError(err)
+	suite.Equal("M1", merge.ID)
+	suite.Equal(int64(25), merge.Points)
+	suite.Equal(2, merge.Records)
+	suite.Equal(1, merge.Duplicates)
+	suite.Equal(int64(10), merge.DuplicatePoints)
+	suite.False(merge.MergedAt.IsZero())
+}
+
+func (suite *MergeServiceTestSuite) TestMergeCustomers_Invalid() {
+	testCases := map[string]struct {
+		merge entity.Merge
+		want  string
+	}{
+		"missing target":    {merge: entity.Merge{SourceCustomerID: "U000002", RequestedBy: "alice"}, want: "target_customer_id are required"},
+		"same customer":     {merge: entity.Merge{SourceCustomerID: "U000001", TargetCustomerID: "U000001", RequestedBy: "alice"}, want: "into itself"},
+		"missing requester": {merge: entity.Merge{SourceCustomerID: "U000002", TargetCustomerID: "U000001"}, want: "requested_by is required"},
+	}
+
+	for name, tc := range testCases {
+		suite.Run(name, func() {
+			_, err := suite.service.MergeCustomers(context.Background(), tc.merge)
+
+			suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+			suite.ErrorContains(err, tc.want)
+		})
+	}
+}
+
+func (suite *MergeServiceTestSuite) TestMergeCustomers_AlreadyMerged() {
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(gomock.Any(), gomock.Any()).
+		Return([]entity.Customer{{CustomerID: "U000001"}, {CustomerID: "U000002", MergedInto: "U000005"}}, nil)
+
+	_, err := suite.service.MergeCustomers(context.Background(), newMerge())
+
+	suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+	suite.ErrorContains(err, "already merged into U000005")
+}
+
+func (suite *MergeServiceTestSuite) TestMergeCustomers_SourceInHousehold() {
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(gomock.Any(), gomock.Any()).
+		Return([]entity.Customer{{CustomerID: "U000001"}, {CustomerID: "U000002", HouseholdID: "HH0001"}}, nil)
+
+	_, err := suite.service.MergeCustomers(context.Background(), newMerge())
+
+	suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+	suite.ErrorContains(err, "leave household HH0001")
+}
+
+func (suite *MergeServiceTestSuite) TestMergeCustomers_TargetNotFound() {
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(gomock.Any(), gomock.Any()).
+		Return([]entity.Customer{{CustomerID: "U000002"}}, nil)
+
+	_, err := suite.service.MergeCustomers(context.Background(), newMerge())
+
+	suite.Equal(apperr.ErrNotFound.Code, apperr.GetCode(err))
+}
+
+func (suite *MergeServiceTestSuite) TestMergeCustomers_NotRecorded() {
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(gomock.Any(), gomock.Any()).
+		Return([]entity.Customer{{CustomerID: "U000001"}, {CustomerID: "U000002"}}, nil)
+	suite.mockCustRepo.EXPECT().MergeCustomers(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.mockMergeRepo.EXPECT().CreateMerge(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection lost"))
+
+	_, err := suite.service.MergeCustomers(context.Background(), newMerge())
+
+	suite.Equal(apperr.ErrInternal.Code, apperr.GetCode(err))
+	suite.ErrorContains(err, "merge was not recorded")
+}
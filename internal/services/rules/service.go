@@ -0,0 +1,227 @@
+package rules
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+)
+
+type ruleService struct {
+	ruleRepo repository.RuleRepository
+}
+
+//go:generate mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
+type RuleService interface {
+	GetRules(ctx context.Context, asOf *time.Time) ([]entity.Rule, error)
+	GetRuleVersions(ctx context.Context, id string) ([]entity.Rule, error)
+	CreateRule(ctx context.Context, rule entity.Rule, createdBy string) (*entity.Rule, error)
+	UpdateRule(ctx context.Context, id string, rule entity.Rule, createdBy string) (*entity.Rule, error)
+	GetBranchGroups(ctx context.Context) (entity.BranchGroups, error)
+	PutBranchGroup(ctx context.Context, group entity.BranchGroup) (*entity.BranchGroup, error)
+}
+
+func NewRuleService(ruleRepo repository.RuleRepository) RuleService {
+	return &ruleService{
+		ruleRepo: ruleRepo,
+	}
+}
+
+// GetRules returns the current rules, or the versions that were current at the end
+// of the asOf day when asOf is set.
+func (s ruleService) GetRules(ctx context.Context, asOf *time.Time) ([]entity.Rule, error) {
+	if asOf == nil {
+		return s.ruleRepo.GetRules(ctx)
+	}
+
+	return s.ruleRepo.GetRulesAsOf(ctx, asOf.AddDate(0, 0, 1))
+}
+
+func (s ruleService) GetRuleVersions(ctx context.Context, id string) ([]entity.Rule, error) {
+	versions, err := s.ruleRepo.GetRuleVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, apperr.ErrNotFound.WithMessage("rule not found")
+	}
+
+	return versions, nil
+}
+
+func (s ruleService) CreateRule(ctx context.Context, rule entity.Rule, createdBy string) (*entity.Rule, error) {
+	if err := validateRule(rule, createdBy); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateBranchGroups(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	rule.ID = ""
+	rule.CreatedBy = createdBy
+	rule.CreatedAt = time.Now().UTC()
+
+	return s.ruleRepo.CreateRule(ctx, rule)
+}
+
+// UpdateRule never edits a rule in place; it stores the change as the next version.
+func (s ruleService) UpdateRule(ctx context.Context, id string, rule entity.Rule, createdBy string) (*entity.Rule, error) {
+	if err := validateRule(rule, createdBy); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateBranchGroups(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	current, err := s.ruleRepo.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.ID = current.ID
+	rule.CreatedBy = createdBy
+	rule.CreatedAt = time.Now().UTC()
+
+	return s.ruleRepo.UpdateRule(ctx, rule, current.Version)
+}
+
+func (s ruleService) GetBranchGroups(ctx context.Context) (entity.BranchGroups, error) {
+	return s.ruleRepo.GetBranchGroups(ctx)
+}
+
+// PutBranchGroup creates the group or replaces its branches. Rules naming the
+// group follow the change from the next upload.
+func (s ruleService) PutBranchGroup(ctx context.Context, group entity.BranchGroup) (*entity.BranchGroup, error) {
+	if group.Name == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("branch group name is required")
+	}
+
+	if len(group.BranchIDs) == 0 || slices.Contains(group.BranchIDs, "") {
+		return nil, apperr.ErrInvalidArgument.WithMessage("branch_ids must list at least one branch and no empty IDs")
+	}
+
+	if err := s.ruleRepo.PutBranchGroup(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// validateBranchGroups rejects a rule naming a group that does not exist, which
+// would otherwise silently match no branch.
+func (s ruleService) validateBranchGroups(ctx context.Context, rule entity.Rule) error {
+	if len(rule.Conditions.BranchGroups) == 0 {
+		return nil
+	}
+
+	groups, err := s.ruleRepo.GetBranchGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range rule.Conditions.BranchGroups {
+		if !slices.ContainsFunc(groups, func(group entity.BranchGroup) bool { return group.Name == name }) {
+			return apperr.ErrInvalidArgument.WithMessage("unknown branch group: " + name)
+		}
+	}
+
+	return nil
+}
+
+func validateRule(rule entity.Rule, createdBy string) error {
+	if createdBy == "" {
+		return apperr.ErrInvalidArgument.WithMessage("created_by is required")
+	}
+
+	if rule.Name == "" {
+		return apperr.ErrInvalidArgument.WithMessage("rule name is required")
+	}
+
+	switch rule.RuleType {
+	case entity.FixedPointRule, entity.PercentageRule:
+	case entity.RatioRule:
+		if rule.Reward.RatioUnit == nil || *rule.Reward.RatioUnit <= 0 {
+			return apperr.ErrInvalidArgument.WithMessage("ratio_unit must be greater than zero for RATIO rules")
+		}
+	case entity.BasketSpendRule:
+		if !rule.Conditions.MinAmount.IsPositive() {
+			return apperr.ErrInvalidArgument.WithMessage("min_amount must be greater than zero for BASKET_SPEND rules")
+		}
+	case entity.BasketCategoriesRule:
+		if rule.Conditions.MinCategories <= 0 {
+			return apperr.ErrInvalidArgument.WithMessage("min_categories must be greater than zero for BASKET_CATEGORIES rules")
+		}
+	case entity.BasketBundleRule:
+		if len(rule.Conditions.RequiredProductIDs) == 0 {
+			return apperr.ErrInvalidArgument.WithMessage("required_product_ids must not be empty for BASKET_BUNDLE rules")
+		}
+	case entity.VisitCountRule:
+		if rule.Conditions.VisitCount <= 0 {
+			return apperr.ErrInvalidArgument.WithMessage("visit_count must be greater than zero for VISIT_COUNT rules")
+		}
+	case entity.StreakRule:
+		if rule.Conditions.StreakDays < 2 {
+			return apperr.ErrInvalidArgument.WithMessage("streak_days must be at least 2 for STREAK rules")
+		}
+	case entity.NewBranchRule:
+	case entity.CustomRule:
+		if rule.Reward.Expression == "" {
+			return apperr.ErrInvalidArgument.WithMessage("reward expression is required for CUSTOM rules")
+		}
+	default:
+		return apperr.ErrInvalidArgument.WithMessage("unknown rule type: " + string(rule.RuleType))
+	}
+
+	if rule.Status != entity.RuleStatusActive && rule.Status != entity.RuleStatusInactive {
+		return apperr.ErrInvalidArgument.WithMessage("status must be ACTIVE or INACTIVE")
+	}
+
+	if rule.Reward.Value < 0 {
+		return apperr.ErrInvalidArgument.WithMessage("reward value must not be negative")
+	}
+
+	if rule.Conditions.MinAmount.IsNegative() {
+		return apperr.ErrInvalidArgument.WithMessage("min_amount must not be negative")
+	}
+
+	if rule.EffectiveFrom != nil && rule.EffectiveTo != nil && !rule.EffectiveTo.After(*rule.EffectiveFrom) {
+		return apperr.ErrInvalidArgument.WithMessage("effective_to must be after effective_from")
+	}
+
+	conditions := rule.Conditions
+	if slices.Contains(conditions.BranchIDs, "") || slices.Contains(conditions.BranchGroups, "") {
+		return apperr.ErrInvalidArgument.WithMessage("branch_ids and branch_groups must not contain empty values")
+	}
+
+	for _, productID := range conditions.ProductIDs {
+		if slices.Contains(conditions.ExcludeProductIDs, productID) {
+			return apperr.ErrInvalidArgument.WithMessage("product " + productID + " is both included and excluded")
+		}
+	}
+
+	for _, categoryID := range conditions.CategoryID {
+		if slices.Contains(conditions.ExcludeCategoryIDs, categoryID) {
+			return apperr.ErrInvalidArgument.WithMessage("category " + categoryID + " is both included and excluded")
+		}
+	}
+
+	if conditions.SignupFrom != nil && conditions.SignupTo != nil && !conditions.SignupTo.After(*conditions.SignupFrom) {
+		return apperr.ErrInvalidArgument.WithMessage("signup_to must be after signup_from")
+	}
+
+	if rule.RuleType != entity.CustomRule && rule.Reward.Expression != "" {
+		return apperr.ErrInvalidArgument.WithMessage("reward expression is only used by CUSTOM rules")
+	}
+
+	if _, err := rule.Compile(); err != nil {
+		return apperr.ErrInvalidArgument.WithMessage(err.Error())
+	}
+
+	return nil
+}
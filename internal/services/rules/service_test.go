@@ -0,0 +1,231 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_rule"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type RuleServiceTestSuite struct {
+	suite.Suite
+	mockCtrl     *gomock.Controller
+	mockRuleRepo *mocks_rule.MockRuleRepository
+	service      RuleService
+}
+
+func TestRuleServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(RuleServiceTestSuite))
+}
+
+func (suite *RuleServiceTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRuleRepo = mocks_rule.NewMockRuleRepository(suite.mockCtrl)
+	suite.service = NewRuleService(suite.mockRuleRepo)
+}
+
+func (suite *RuleServiceTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func validRule() entity.Rule {
+	return entity.Rule{
+		Name:     "BEVERAGE (BR3456) - Bonus 2 points over 200 THB",
+		RuleType: entity.FixedPointRule,
+		Conditions: entity.Conditions{
+			MinAmount:  decimal.NewFromFloat(200.01),
+			BranchID:   "BR3456",
+			CategoryID: []string{"CT1001"},
+		},
+		Reward: entity.Reward{Value: 2},
+		Status: entity.RuleStatusActive,
+	}
+}
+
+func (suite *RuleServiceTestSuite) TestGetRules_Current() {
+	ctx := context.Background()
+	suite.mockRuleRepo.EXPECT().GetRules(ctx).Return([]entity.Rule{validRule()}, nil)
+
+	result, err := suite.service.GetRules(ctx, nil)
+
+	suite.NoError(err)
+	suite.Len(result, 1)
+}
+
+func (suite *RuleServiceTestSuite) TestGetRules_AsOfIncludesWholeDay() {
+	ctx := context.Background()
+	asOf := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	suite.mockRuleRepo.EXPECT().
+		GetRulesAsOf(ctx, time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)).
+		Return([]entity.Rule{validRule()}, nil)
+
+	result, err := suite.service.GetRules(ctx, &asOf)
+
+	suite.NoError(err)
+	suite.Len(result, 1)
+}
+
+func (suite *RuleServiceTestSuite) TestGetRuleVersions_NotFound() {
+	ctx := context.Background()
+	suite.mockRuleRepo.EXPECT().GetRuleVersions(ctx, "abc").Return(nil, nil)
+
+	_, err := suite.service.GetRuleVersions(ctx, "abc")
+
+	suite.Equal(apperr.ErrNotFound.Code, apperr.GetCode(err))
+}
+
+func (suite *RuleServiceTestSuite) TestCreateRule_SetsAuthor() {
+	ctx := context.Background()
+	suite.mockRuleRepo.EXPECT().
+		CreateRule(ctx, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, rule entity.Rule) (*entity.Rule, error) {
+			suite.Equal("alice", rule.CreatedBy)
+			suite.False(rule.CreatedAt.IsZero())
+			rule.ID = "rule-1"
+			rule.Version = 1
+			return &rule, nil
+		})
+
+	result, err := suite.service.CreateRule(ctx, validRule(), "alice")
+
+	suite.NoError(err)
+	suite.Equal(int64(1), result.Version)
+}
+
+func (suite *RuleServiceTestSuite) TestCreateRule_Invalid() {
+	ratioWithoutUnit := validRule()
+	ratioWithoutUnit.RuleType = entity.RatioRule
+
+	unknownType := validRule()
+	unknownType.RuleType = "BOGUS"
+
+	bundleWithoutProducts := validRule()
+	bundleWithoutProducts.RuleType = entity.BasketBundleRule
+
+	oneDayStreak := validRule()
+	oneDayStreak.RuleType = entity.StreakRule
+	oneDayStreak.Conditions.StreakDays = 1
+
+	customWithoutReward := validRule()
+	customWithoutReward.RuleType = entity.CustomRule
+
+	customWithBoolReward := validRule()
+	customWithBoolReward.RuleType = entity.CustomRule
+	customWithBoolReward.Reward.Expression = `amount > 100`
+
+	badCondition := validRule()
+	badCondition.Conditions.Expression = `amount >= "300"`
+
+	badStatus := validRule()
+	badStatus.Status = "PAUSED"
+
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	badWindow := validRule()
+	badWindow.EffectiveFrom = &from
+	badWindow.EffectiveTo = &to
+
+	overlappingProducts := validRule()
+	overlappingProducts.Conditions.ProductIDs = []string{"P1", "P2"}
+	overlappingProducts.Conditions.ExcludeProductIDs = []string{"P2"}
+
+	badSignup := validRule()
+	badSignup.Conditions.SignupFrom = &from
+	badSignup.Conditions.SignupTo = &to
+
+	testCases := map[string]struct {
+		rule      entity.Rule
+		createdBy string
+	}{
+		"missing author":     {rule: validRule(), createdBy: ""},
+		"ratio without unit": {rule: ratioWithoutUnit, createdBy: "alice"},
+		"unknown type":       {rule: unknownType, createdBy: "alice"},
+		"bad status":         {rule: badStatus, createdBy: "alice"},
+		"empty bundle":       {rule: bundleWithoutProducts, createdBy: "alice"},
+		"one day streak":     {rule: oneDayStreak, createdBy: "alice"},
+		"custom no reward":   {rule: customWithoutReward, createdBy: "alice"},
+		"custom bool reward": {rule: customWithBoolReward, createdBy: "alice"},
+		"bad condition":      {rule: badCondition, createdBy: "alice"},
+		"inverted window":    {rule: badWindow, createdBy: "alice"},
+		"inverted signup":    {rule: badSignup, createdBy: "alice"},
+		"product both ways":  {rule: overlappingProducts, createdBy: "alice"},
+	}
+
+	for name, tc := range testCases {
+		suite.Run(name, func() {
+			_, err := suite.service.CreateRule(context.Background(), tc.rule, tc.createdBy)
+			suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+		})
+	}
+}
+
+func (suite *RuleServiceTestSuite) TestUpdateRule_WritesNextVersion() {
+	ctx := context.Background()
+	current := validRule()
+	current.ID = "rule-1"
+	current.Version = 3
+
+	suite.mockRuleRepo.EXPECT().GetRule(ctx, "rule-1").Return(&current, nil)
+	suite.mockRuleRepo.EXPECT().
+		UpdateRule(ctx, gomock.Any(), int64(3)).
+		DoAndReturn(func(ctx context.Context, rule entity.Rule, previousVersion int64) (*entity.Rule, error) {
+			suite.Equal("rule-1", rule.ID)
+			suite.Equal("bob", rule.CreatedBy)
+			suite.Equal(int64(5), rule.Reward.Value)
+			rule.Version = previousVersion + 1
+			return &rule, nil
+		})
+
+	update := validRule()
+	update.Reward.Value = 5
+	result, err := suite.service.UpdateRule(ctx, "rule-1", update, "bob")
+
+	suite.NoError(err)
+	suite.Equal(int64(4), result.Version)
+}
+
+func (suite *RuleServiceTestSuite) TestUpdateRule_NotFound() {
+	ctx := context.Background()
+	suite.mockRuleRepo.EXPECT().GetRule(ctx, "missing").Return(nil, apperr.ErrNotFound)
+
+	_, err := suite.service.UpdateRule(ctx, "missing", validRule(), "bob")
+
+	suite.ErrorIs(err, apperr.ErrNotFound)
+}
+
+func (suite *RuleServiceTestSuite) TestCreateRule_UnknownBranchGroup() {
+	ctx := context.Background()
+	rule := validRule()
+	rule.Conditions.BranchID = ""
+	rule.Conditions.BranchGroups = []string{"NORTH", "SOUTH"}
+
+	suite.mockRuleRepo.EXPECT().GetBranchGroups(ctx).Return(entity.BranchGroups{{Name: "NORTH", BranchIDs: []string{"BR0001"}}}, nil)
+
+	_, err := suite.service.CreateRule(ctx, rule, "alice")
+
+	suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+	suite.Contains(err.Error(), "SOUTH")
+}
+
+func (suite *RuleServiceTestSuite) TestPutBranchGroup() {
+	ctx := context.Background()
+	group := entity.BranchGroup{Name: "NORTH", BranchIDs: []string{"BR0001", "BR0002"}}
+	suite.mockRuleRepo.EXPECT().PutBranchGroup(ctx, group).Return(nil)
+
+	result, err := suite.service.PutBranchGroup(ctx, group)
+
+	suite.NoError(err)
+	suite.Equal(group, *result)
+}
+
+func (suite *RuleServiceTestSuite) TestPutBranchGroup_NoBranches() {
+	_, err := suite.service.PutBranchGroup(context.Background(), entity.BranchGroup{Name: "NORTH"})
+
+	suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+}
@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTransferService is a mock of TransferService interface.
+type MockTransferService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockTransferServiceMockRecorder is the mock recorder for MockTransferService.
+type MockTransferServiceMockRecorder struct {
+	mock *MockTransferService
+}
+
+// NewMockTransferService creates a new mock instance.
+func NewMockTransferService(ctrl *gomock.Controller) *MockTransferService {
+	mock := &MockTransferService{ctrl: ctrl}
+	mock.recorder = &MockTransferServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransferService) EXPECT() *MockTransferServiceMockRecorder {
+	return m.recorder
+}
+
+// Transfer mocks base method.
+func (m *MockTransferService) Transfer(ctx context.Context, transfer entity.Transfer) (*entity.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, transfer)
+	ret0, _ := ret[0].(*entity.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockTransferServiceMockRecorder) Transfer(ctx, transfer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockTransferService)(nil).Transfer), ctx, transfer)
+}
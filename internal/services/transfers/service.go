@@ -0,0 +1,100 @@
+package transfers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+)
+
+type transferService struct {
+	customerRepo repository.CustomerRepository
+	cfg          *config.Config
+}
+
+//go:generate mockgen -source=service.go -destination=mocks/mock_service.go -package=mocks
+type TransferService interface {
+	Transfer(ctx context.Context, transfer entity.Transfer) (*entity.Transfer, error)
+}
+
+func NewTransferService(customerRepo repository.CustomerRepository, cfg *config.Config) TransferService {
+	return &transferService{
+		customerRepo: customerRepo,
+		cfg:          cfg,
+	}
+}
+
+// Transfer moves points between two existing customers today, within
+// TransferMaxPoints and the sender's TransferDailyLimit. The limits are checked
+// here for a clear error and again by the repository as it debits the sender, so
+// concurrent transfers cannot overdraw them.
+func (s transferService) Transfer(ctx context.Context, transfer entity.Transfer) (*entity.Transfer, error) {
+	if err := s.validateTransfer(transfer); err != nil {
+		return nil, err
+	}
+
+	customers, err := s.customerRepo.GetCustomers(ctx, []string{transfer.FromCustomerID, transfer.ToCustomerID})
+	if err != nil {
+		return nil, err
+	}
+
+	sender, found := entity.Customers(customers).GetCustomerByID(transfer.FromCustomerID)
+	if !found {
+		return nil, apperr.ErrNotFound.WithMessage("customer not found")
+	}
+	receiver, found := entity.Customers(customers).GetCustomerByID(transfer.ToCustomerID)
+	if !found {
+		return nil, apperr.ErrNotFound.WithMessage("customer to transfer to not found")
+	}
+	for _, customer := range []entity.Customer{sender, receiver} {
+		if customer.MergedInto != "" {
+			return nil, apperr.ErrConflict.WithMessage(fmt.Sprintf("customer %s was merged into %s", customer.CustomerID, customer.MergedInto))
+		}
+	}
+
+	transfer.Date = time.Now().UTC().Truncate(24 * time.Hour)
+
+	if sender.Points < transfer.Points {
+		return nil, apperr.ErrInvalidArgument.WithMessage(fmt.Sprintf("transfer of %d points exceeds the balance of %d", transfer.Points, sender.Points))
+	}
+	if sent := sentOn(sender, transfer.Date); s.cfg.TransferDailyLimit > 0 && sent+transfer.Points > s.cfg.TransferDailyLimit {
+		return nil, apperr.ErrInvalidArgument.WithMessage(fmt.Sprintf("transfer would exceed the daily limit of %d points; %d already transferred today", s.cfg.TransferDailyLimit, sent))
+	}
+
+	return s.customerRepo.TransferPoints(ctx, transfer, s.cfg.TransferDailyLimit)
+}
+
+func (s transferService) validateTransfer(transfer entity.Transfer) error {
+	if transfer.FromCustomerID == "" || transfer.ToCustomerID == "" {
+		return apperr.ErrInvalidArgument.WithMessage("customer_id and to_customer_id are required")
+	}
+	if transfer.FromCustomerID == transfer.ToCustomerID {
+		return apperr.ErrInvalidArgument.WithMessage("cannot transfer points to the same customer")
+	}
+	if transfer.Points <= 0 {
+		return apperr.ErrInvalidArgument.WithMessage("points must be positive")
+	}
+	if s.cfg.TransferMaxPoints > 0 && transfer.Points > s.cfg.TransferMaxPoints {
+		return apperr.ErrInvalidArgument.WithMessage(fmt.Sprintf("points must not exceed %d", s.cfg.TransferMaxPoints))
+	}
+	if transfer.RequestedBy == "" {
+		return apperr.ErrInvalidArgument.WithMessage("requested_by is required")
+	}
+
+	return nil
+}
+
+// sentOn returns the points the customer transferred out on date.
+func sentOn(customer entity.Customer, date time.Time) int64 {
+	var sent int64
+	for _, record := range customer.Records {
+		if record.Type == entity.TransferTransaction && record.Points < 0 && record.PurchaseDate.Equal(date) {
+			sent -= record.Points
+		}
+	}
+	return sent
+}
@@ -0,0 +1,132 @@
+package transfers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirawong/point-accumulate-interview/internal/domain/entity"
+	"github.com/sirawong/point-accumulate-interview/internal/domain/repository/mocks_customer"
+	apperr "github.com/sirawong/point-accumulate-interview/internal/errors"
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type TransferServiceTestSuite struct {
+	suite.Suite
+	mockCtrl     *gomock.Controller
+	mockCustRepo *mocks_customer.MockCustomerRepository
+	service      TransferService
+}
+
+func TestTransferServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TransferServiceTestSuite))
+}
+
+func (suite *TransferServiceTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockCustRepo = mocks_customer.NewMockCustomerRepository(suite.mockCtrl)
+	suite.service = NewTransferService(suite.mockCustRepo, &config.Config{TransferMaxPoints: 500, TransferDailyLimit: 800})
+}
+
+func (suite *TransferServiceTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func newTransfer(points int64) entity.Transfer {
+	return entity.Transfer{FromCustomerID: "U000001", ToCustomerID: "U000002", Points: points, RequestedBy: "alice"}
+}
+
+func (suite *TransferServiceTestSuite) expectCustomers(sender entity.Customer) {
+	sender.CustomerID = "U000001"
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(gomock.Any(), []string{"U000001", "U000002"}).
+		Return([]entity.Customer{sender, {CustomerID: "U000002"}}, nil)
+}
+
+func (suite *TransferServiceTestSuite) TestTransfer() {
+	ctx := context.Background()
+	suite.expectCustomers(entity.Customer{Points: 1000})
+	suite.mockCustRepo.EXPECT().
+		TransferPoints(ctx, gomock.Any(), int64(800)).
+		DoAndReturn(func(_ context.Context, transfer entity.Transfer, _ int64) (*entity.Transfer, error) {
+			suite.Equal(time.Now().UTC().Truncate(24*time.Hour), transfer.Date)
+			transfer.ID = "T1"
+			return &transfer, nil
+		})
+
+	transfer, err := suite.service.Transfer(ctx, newTransfer(300))
+
+	suite.NoError(err)
+	suite.Equal("T1", transfer.ID)
+}
+
+func (suite *TransferServiceTestSuite) TestTransfer_Invalid() {
+	testCases := map[string]struct {
+		transfer entity.Transfer
+		want     string
+	}{
+		"same customer":     {transfer: entity.Transfer{FromCustomerID: "U000001", ToCustomerID: "U000001", Points: 1, RequestedBy: "alice"}, want: "same customer"},
+		"missing receiver":  {transfer: entity.Transfer{FromCustomerID: "U000001", Points: 1, RequestedBy: "alice"}, want: "to_customer_id are required"},
+		"zero points":       {transfer: newTransfer(0), want: "points must be positive"},
+		"over the maximum":  {transfer: newTransfer(501), want: "must not exceed 500"},
+		"missing requester": {transfer: entity.Transfer{FromCustomerID: "U000001", ToCustomerID: "U000002", Points: 1}, want: "requested_by is required"},
+	}
+
+	for name, tc := range testCases {
+		suite.Run(name, func() {
+			_, err := suite.service.Transfer(context.Background(), tc.transfer)
+
+			suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+			suite.ErrorContains(err, tc.want)
+		})
+	}
+}
+
+func (suite *TransferServiceTestSuite) TestTransfer_OverBalance() {
+	suite.expectCustomers(entity.Customer{Points: 100})
+
+	_, err := suite.service.Transfer(context.Background(), newTransfer(101))
+
+	suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+	suite.ErrorContains(err, "exceeds the balance of 100")
+}
+
+func (suite *TransferServiceTestSuite) TestTransfer_OverDailyLimit() {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	suite.expectCustomers(entity.Customer{
+		Points: 2000,
+		Records: []entity.Record{
+			{Type: entity.TransferTransaction, PurchaseDate: today, Points: -500},
+			{Type: entity.TransferTransaction, PurchaseDate: today, Points: 400},
+			{Type: entity.TransferTransaction, PurchaseDate: today.AddDate(0, 0, -1), Points: -500},
+		},
+	})
+
+	_, err := suite.service.Transfer(context.Background(), newTransfer(301))
+
+	suite.Equal(apperr.ErrInvalidArgument.Code, apperr.GetCode(err))
+	suite.ErrorContains(err, "500 already transferred today")
+}
+
+func (suite *TransferServiceTestSuite) TestTransfer_ReceiverNotFound() {
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(gomock.Any(), gomock.Any()).
+		Return([]entity.Customer{{CustomerID: "U000001", Points: 1000}}, nil)
+
+	_, err := suite.service.Transfer(context.Background(), newTransfer(10))
+
+	suite.Equal(apperr.ErrNotFound.Code, apperr.GetCode(err))
+}
+
+func (suite *TransferServiceTestSuite) TestTransfer_ReceiverMerged() {
+	suite.mockCustRepo.EXPECT().
+		GetCustomers(gomock.Any(), gomock.Any()).
+		Return([]entity.Customer{{CustomerID: "U000001", Points: 1000}, {CustomerID: "U000002", MergedInto: "U000003"}}, nil)
+
+	_, err := suite.service.Transfer(context.Background(), newTransfer(10))
+
+	suite.Equal(apperr.ErrConflict.Code, apperr.GetCode(err))
+	suite.ErrorContains(err, "U000002 was merged into U000003")
+}
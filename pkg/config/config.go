@@ -3,9 +3,12 @@ package config
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
+
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
 )
 
 type Config struct {
@@ -14,7 +17,45 @@ type Config struct {
 	MongoURI    string `env:"MONGO_URI,required"`
 	MongoDBName string `env:"MONGO_DB_NAME,required"`
 
-	FilePath string `env:"FILE_PATH,required"`
+	FilePath  string `env:"FILE_PATH" envDefault:"./output_files/point-summary_%s.csv"`
+	InputPath string `env:"INPUT_PATH" envDefault:"./input_files/%s"`
+
+	StorageDriver string `env:"STORAGE_DRIVER" envDefault:"local"`
+	S3Endpoint    string `env:"S3_ENDPOINT"`
+	S3Region      string `env:"S3_REGION" envDefault:"us-east-1"`
+	S3Bucket      string `env:"S3_BUCKET"`
+	S3AccessKey   string `env:"S3_ACCESS_KEY"`
+	S3SecretKey   string `env:"S3_SECRET_KEY"`
+	S3UseSSL      bool   `env:"S3_USE_SSL" envDefault:"true"`
+
+	InboxDir          string        `env:"INBOX_DIR"`
+	InboxPollInterval time.Duration `env:"INBOX_POLL_INTERVAL" envDefault:"10s"`
+	InboxSettleTime   time.Duration `env:"INBOX_SETTLE_TIME" envDefault:"30s"`
+
+	// AdjustmentApprovalThreshold is the largest credit or debit, in points, that is
+	// applied without a second approver.
+	AdjustmentApprovalThreshold int64 `env:"ADJUSTMENT_APPROVAL_THRESHOLD" envDefault:"1000"`
+
+	// TransferMaxPoints caps a single transfer and TransferDailyLimit what a
+	// customer can transfer out in a day; zero means no limit.
+	TransferMaxPoints  int64 `env:"TRANSFER_MAX_POINTS" envDefault:"5000"`
+	TransferDailyLimit int64 `env:"TRANSFER_DAILY_LIMIT" envDefault:"10000"`
+
+	HouseholdMaxMembers int `env:"HOUSEHOLD_MAX_MEMBERS" envDefault:"6"`
+
+	// Requests authenticate with a key from AuthAPIKeysFile or a JWT signed with
+	// AuthJWTSecret (HS*) or a key of AuthJWKSFile (RS*). AuthDisabled lets every
+	// request through as an admin, for local development only.
+	AuthDisabled    bool   `env:"AUTH_DISABLED" envDefault:"false"`
+	AuthAPIKeysFile string `env:"AUTH_API_KEYS_FILE"`
+	AuthJWTSecret   string `env:"AUTH_JWT_SECRET"`
+	AuthJWKSFile    string `env:"AUTH_JWKS_FILE"`
+	AuthJWTIssuer   string `env:"AUTH_JWT_ISSUER"`
+	AuthJWTAudience string `env:"AUTH_JWT_AUDIENCE"`
+
+	// Tenants are the loyalty programs served besides the default one. A request
+	// names its program with the X-Tenant-ID header or through its API key or JWT.
+	Tenants []string `env:"TENANTS" envSeparator:","`
 }
 
 func LoadConfig() (*Config, error) {
@@ -28,5 +69,14 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("could not parse config: %w", err)
 	}
 
+	for _, id := range cfg.Tenants {
+		if id == tenant.Default {
+			return nil, fmt.Errorf("could not parse config: TENANTS has an empty entry")
+		}
+		if err = tenant.Validate(id); err != nil {
+			return nil, fmt.Errorf("could not parse config: %w", err)
+		}
+	}
+
 	return cfg, nil
 }
@@ -5,12 +5,21 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/gocarina/gocsv"
 )
 
-func getExpectedHeaders(v interface{}) []string {
-	var headers []string
+type expectedHeader struct {
+	name     string
+	optional bool
+}
+
+// getExpectedHeaders returns the columns of v in field order. Fields tagged
+// omitempty are optional and may be left out of a file.
+func getExpectedHeaders(v interface{}) []expectedHeader {
+	var headers []expectedHeader
 	t := reflect.TypeOf(v)
 
 	if t.Kind() == reflect.Ptr {
@@ -23,13 +32,32 @@ func getExpectedHeaders(v interface{}) []string {
 
 	for i := 0; i < t.NumField(); i++ {
 		tag := t.Field(i).Tag.Get("csv")
-		if tag != "" && tag != "-" {
-			headers = append(headers, tag)
+		if tag == "" || tag == "-" {
+			continue
 		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		headers = append(headers, expectedHeader{
+			name:     name,
+			optional: slices.Contains(strings.Split(options, ","), "omitempty"),
+		})
 	}
 	return headers
 }
 
+// wantedHeaders returns the expected columns a file with header must have, in
+// order: every required column and the optional ones the file has.
+func wantedHeaders(expected []expectedHeader, header []string) []string {
+	wanted := make([]string, 0, len(expected))
+	for _, column := range expected {
+		if column.optional && !slices.Contains(header, column.name) {
+			continue
+		}
+		wanted = append(wanted, column.name)
+	}
+	return wanted
+}
+
 func UnmarshalWithHeaderValidation(seeker io.ReadSeeker, out interface{}) error {
 	csvForHeaderCheck := csv.NewReader(seeker)
 	header, err := csvForHeaderCheck.Read()
@@ -41,7 +69,7 @@ func UnmarshalWithHeaderValidation(seeker io.ReadSeeker, out interface{}) error
 	if elemType.Kind() == reflect.Ptr {
 		elemType = elemType.Elem()
 	}
-	expectedHeaders := getExpectedHeaders(reflect.New(elemType).Interface())
+	expectedHeaders := wantedHeaders(getExpectedHeaders(reflect.New(elemType).Interface()), header)
 	if !reflect.DeepEqual(header, expectedHeaders) {
 		return fmt.Errorf("CSV header mismatch. Got: %v, Want: %v", header, expectedHeaders)
 	}
@@ -0,0 +1,24 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
+)
+
+// Collection is a collection that every loyalty program has its own copy of.
+type Collection struct {
+	db   *mongo.Database
+	name string
+}
+
+func NewCollection(db *mongo.Database, name string) Collection {
+	return Collection{db: db, name: name}
+}
+
+// For returns the copy of the collection that belongs to the program of ctx.
+func (c Collection) For(ctx context.Context) *mongo.Collection {
+	return c.db.Collection(tenant.CollectionName(ctx, c.name))
+}
@@ -0,0 +1,43 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/sirawong/point-accumulate-interview/pkg/tenant"
+)
+
+type CollectionTestSuite struct {
+	suite.Suite
+	db *mongo.Database
+}
+
+func TestCollectionTestSuite(t *testing.T) {
+	suite.Run(t, new(CollectionTestSuite))
+}
+
+func (suite *CollectionTestSuite) SetupTest() {
+	// Connect does not reach the server, so naming collections needs no database.
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { _ = client.Disconnect(context.Background()) })
+
+	suite.db = client.Database("pointdb")
+}
+
+func (suite *CollectionTestSuite) TestFor_IsolatesTenants() {
+	customers := NewCollection(suite.db, "customers")
+
+	brandA := customers.For(tenant.WithTenant(context.Background(), "brand_a"))
+	brandB := customers.For(tenant.WithTenant(context.Background(), "brand_b"))
+	shared := customers.For(context.Background())
+
+	suite.Equal("brand_a_customers", brandA.Name())
+	suite.Equal("brand_b_customers", brandB.Name())
+	suite.Equal("customers", shared.Name())
+	suite.Equal("pointdb", brandB.Database().Name())
+}
@@ -0,0 +1,116 @@
+// Package expr is a small, side-effect free expression language for rules.
+//
+// Expressions work on numbers, strings, booleans and lists of numbers or strings:
+//
+//	amount >= 300 && category in ["CT1001", "CT1002"] ? floor(amount / 50) * 2 : 0
+//
+// Operators, lowest precedence first, are ?:, ||, &&, == and !=, < <= > >= and
+// in, + and -, * / and %, and the unary ! and -. The functions are floor, ceil,
+// round, abs, min and max. Numbers are decimals, so money is never rounded by
+// floating point.
+//
+// Expressions are type checked against the declared variables when compiled.
+// There are no loops, assignments or calls out of the package, and the source
+// length and nesting depth are limited, so evaluating a compiled expression
+// always terminates quickly.
+package expr
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// MaxLength is the longest source Compile accepts.
+	MaxLength = 2000
+	// MaxDepth is how deeply expressions may nest.
+	MaxDepth = 32
+)
+
+type Type string
+
+const (
+	Bool       Type = "bool"
+	Number     Type = "number"
+	String     Type = "string"
+	NumberList Type = "list of numbers"
+	StringList Type = "list of strings"
+)
+
+// Env holds the variable values an expression is evaluated with: a
+// decimal.Decimal for a Number, a string for a String and a bool for a Bool.
+type Env map[string]any
+
+type Program struct {
+	source string
+	root   node
+}
+
+type node struct {
+	typ  Type
+	eval func(env Env) (any, error)
+}
+
+// Compile parses source and checks it against vars, the variables it may use
+// and their types.
+func Compile(source string, vars map[string]Type) (*Program, error) {
+	if len(source) > MaxLength {
+		return nil, fmt.Errorf("expression is longer than %d characters", MaxLength)
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, vars: vars}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, unexpected(tok)
+	}
+
+	return &Program{source: source, root: root}, nil
+}
+
+func (p *Program) String() string {
+	return p.source
+}
+
+// Type is the type of the expression's result.
+func (p *Program) Type() Type {
+	return p.root.typ
+}
+
+func (p *Program) Eval(env Env) (any, error) {
+	return p.root.eval(env)
+}
+
+// EvalBool evaluates an expression of type Bool.
+func (p *Program) EvalBool(env Env) (bool, error) {
+	if p.root.typ != Bool {
+		return false, fmt.Errorf("expression is a %s, not a %s", p.root.typ, Bool)
+	}
+
+	value, err := p.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+// EvalNumber evaluates an expression of type Number.
+func (p *Program) EvalNumber(env Env) (decimal.Decimal, error) {
+	if p.root.typ != Number {
+		return decimal.Zero, fmt.Errorf("expression is a %s, not a %s", p.root.typ, Number)
+	}
+
+	value, err := p.root.eval(env)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return value.(decimal.Decimal), nil
+}
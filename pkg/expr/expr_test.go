@@ -0,0 +1,118 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+var testVars = map[string]Type{
+	"amount":   Number,
+	"category": String,
+	"member":   Bool,
+}
+
+var testEnv = Env{
+	"amount":   decimal.RequireFromString("420.50"),
+	"category": "CT1001",
+	"member":   true,
+}
+
+type ExprTestSuite struct {
+	suite.Suite
+}
+
+func TestExprTestSuite(t *testing.T) {
+	suite.Run(t, new(ExprTestSuite))
+}
+
+func (suite *ExprTestSuite) TestEvalNumber() {
+	testCases := map[string]string{
+		`amount >= 300 && category in ["CT1001","CT1002"] ? floor(amount/50)*2 : 0`: "16",
+		`amount < 300 ? 1 : 2`:                       "2",
+		`1 + 2 * 3 - 4 / 2`:                          "5",
+		`(1 + 2) * 3 % 4`:                            "1",
+		`-amount + 0.5`:                              "-420",
+		`ceil(amount) + round(0.5)`:                  "422",
+		`min(amount, 100) + max(1, abs(-3))`:         "103",
+		`member ? 10 : category == 'CT1001' ? 5 : 0`: "10",
+	}
+
+	for source, want := range testCases {
+		suite.Run(source, func() {
+			program, err := Compile(source, testVars)
+			suite.Require().NoError(err)
+			suite.Equal(Number, program.Type())
+
+			got, err := program.EvalNumber(testEnv)
+			suite.Require().NoError(err)
+			suite.True(decimal.RequireFromString(want).Equal(got), "got %s", got)
+		})
+	}
+}
+
+func (suite *ExprTestSuite) TestEvalBool() {
+	testCases := map[string]bool{
+		`category in ["CT1002"]`:               false,
+		`!(category in ["CT1002"]) && member`:  true,
+		`amount in [420.5, 1]`:                 true,
+		`category != "CT1001" || amount > 400`: true,
+		`false && 1 / 0 > 0`:                   false,
+		`true || 1 / 0 > 0`:                    true,
+	}
+
+	for source, want := range testCases {
+		suite.Run(source, func() {
+			program, err := Compile(source, testVars)
+			suite.Require().NoError(err)
+
+			got, err := program.EvalBool(testEnv)
+			suite.Require().NoError(err)
+			suite.Equal(want, got)
+		})
+	}
+}
+
+func (suite *ExprTestSuite) TestCompile_Errors() {
+	testCases := map[string]string{
+		`amount >=`:          "unexpected end of expression",
+		`amount > "300"`:     "> needs numbers, got number and string at position 8",
+		`customer == "U1"`:   "unknown variable customer at position 1",
+		`member ? 1 : "x"`:   "both sides of ?: must have the same type",
+		`amount ? 1 : 0`:     "condition of ?: must be a bool",
+		`category in [1, 2]`: "cannot look for string in list of numbers",
+		`[1, "a"]`:           "list mixes number and string",
+		`floor(amount, 2)`:   "floor takes 1 arguments, got 2",
+		`exec("rm")`:         "unknown function exec",
+		`amount # 2`:         `unexpected character '#' at position 8`,
+		`"open`:              "unterminated string at position 1",
+		`(amount`:            "unexpected end of expression",
+		`amount 2`:           `unexpected "2" at position 8`,
+		strings.Repeat("(", 40) + "1" + strings.Repeat(")", 40): "nested more than 32 levels",
+		strings.Repeat("1+", MaxLength):                         "longer than 2000 characters",
+	}
+
+	for source, want := range testCases {
+		suite.Run(want, func() {
+			_, err := Compile(source, testVars)
+			suite.Require().Error(err)
+			suite.Contains(err.Error(), want)
+		})
+	}
+}
+
+func (suite *ExprTestSuite) TestEval_Errors() {
+	program, err := Compile(`amount / (amount - 420.5)`, testVars)
+	suite.Require().NoError(err)
+
+	_, err = program.EvalNumber(testEnv)
+	suite.EqualError(err, "division by zero")
+
+	_, err = program.EvalNumber(Env{"amount": "420.50"})
+	suite.EqualError(err, "variable amount is not set to a number")
+
+	_, err = program.EvalBool(testEnv)
+	suite.EqualError(err, "expression is a number, not a bool")
+}
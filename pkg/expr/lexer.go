@@ -0,0 +1,112 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "!", "<", ">", "+", "-", "*", "/", "%", "?", ":", "(", ")", "[", "]", ","}
+
+func tokenize(source string) ([]token, error) {
+	tokens := make([]token, 0)
+
+	for pos := 0; pos < len(source); {
+		c := source[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+
+		case isDigit(c):
+			start := pos
+			for pos < len(source) && isDigit(source[pos]) {
+				pos++
+			}
+			if pos+1 < len(source) && source[pos] == '.' && isDigit(source[pos+1]) {
+				pos++
+				for pos < len(source) && isDigit(source[pos]) {
+					pos++
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:pos], pos: start})
+
+		case isLetter(c):
+			start := pos
+			for pos < len(source) && (isLetter(source[pos]) || isDigit(source[pos])) {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[start:pos], pos: start})
+
+		case c == '"' || c == '\'':
+			text, end, err := readString(source, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: pos})
+			pos = end
+
+		default:
+			operator := ""
+			for _, op := range operators {
+				if strings.HasPrefix(source[pos:], op) {
+					operator = op
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, pos+1)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: operator, pos: pos})
+			pos += len(operator)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(source)}), nil
+}
+
+// readString reads the quoted string starting at start and returns its value and
+// the position after the closing quote. A backslash escapes the next character.
+func readString(source string, start int) (string, int, error) {
+	quote := source[start]
+
+	var b strings.Builder
+	for pos := start + 1; pos < len(source); pos++ {
+		switch source[pos] {
+		case quote:
+			return b.String(), pos + 1, nil
+		case '\\':
+			pos++
+			if pos == len(source) {
+				return "", 0, fmt.Errorf("unterminated string at position %d", start+1)
+			}
+			b.WriteByte(source[pos])
+		default:
+			b.WriteByte(source[pos])
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string at position %d", start+1)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
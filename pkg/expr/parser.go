@@ -0,0 +1,536 @@
+package expr
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/shopspring/decimal"
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+	vars   map[string]Type
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token when it is one of the operators or keywords.
+func (p *parser) accept(texts ...string) (token, bool) {
+	tok := p.peek()
+	if (tok.kind == tokenOperator || tok.kind == tokenIdent) && slices.Contains(texts, tok.text) {
+		return p.next(), true
+	}
+	return tok, false
+}
+
+func (p *parser) expect(text string) error {
+	if _, ok := p.accept(text); !ok {
+		return unexpected(p.peek())
+	}
+	return nil
+}
+
+func (p *parser) parseExpression() (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > MaxDepth {
+		return node{}, fmt.Errorf("expression is nested more than %d levels deep at position %d", MaxDepth, p.peek().pos+1)
+	}
+
+	return p.parseTernary()
+}
+
+func (p *parser) parseTernary() (node, error) {
+	condition, err := p.parseOr()
+	if err != nil {
+		return node{}, err
+	}
+
+	tok, ok := p.accept("?")
+	if !ok {
+		return condition, nil
+	}
+	if condition.typ != Bool {
+		return node{}, typeError(tok, "condition of ?: must be a bool, got %s", condition.typ)
+	}
+
+	then, err := p.parseExpression()
+	if err != nil {
+		return node{}, err
+	}
+	if err = p.expect(":"); err != nil {
+		return node{}, err
+	}
+	otherwise, err := p.parseExpression()
+	if err != nil {
+		return node{}, err
+	}
+	if then.typ != otherwise.typ {
+		return node{}, typeError(tok, "both sides of ?: must have the same type, got %s and %s", then.typ, otherwise.typ)
+	}
+
+	return node{typ: then.typ, eval: func(env Env) (any, error) {
+		value, err := condition.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if value.(bool) {
+			return then.eval(env)
+		}
+		return otherwise.eval(env)
+	}}, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseLogical("||", p.parseAnd, true)
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseLogical("&&", p.parseEquality, false)
+}
+
+// parseLogical parses a chain of || or && that stops evaluating once the result is
+// shortCircuit.
+func (p *parser) parseLogical(operator string, operand func() (node, error), shortCircuit bool) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return node{}, err
+	}
+
+	for {
+		tok, ok := p.accept(operator)
+		if !ok {
+			return left, nil
+		}
+
+		right, err := operand()
+		if err != nil {
+			return node{}, err
+		}
+		if left.typ != Bool || right.typ != Bool {
+			return node{}, typeError(tok, "%s needs bools, got %s and %s", operator, left.typ, right.typ)
+		}
+
+		l := left
+		left = node{typ: Bool, eval: func(env Env) (any, error) {
+			value, err := l.eval(env)
+			if err != nil || value.(bool) == shortCircuit {
+				return value, err
+			}
+			return right.eval(env)
+		}}
+	}
+}
+
+func (p *parser) parseEquality() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return node{}, err
+	}
+
+	for {
+		tok, ok := p.accept("==", "!=")
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseComparison()
+		if err != nil {
+			return node{}, err
+		}
+		if left.typ != right.typ || isList(left.typ) {
+			return node{}, typeError(tok, "cannot compare %s with %s", left.typ, right.typ)
+		}
+
+		l, negate := left, tok.text == "!="
+		left = node{typ: Bool, eval: func(env Env) (any, error) {
+			a, b, err := evalBoth(env, l, right)
+			if err != nil {
+				return nil, err
+			}
+			return equal(a, b) != negate, nil
+		}}
+	}
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return node{}, err
+	}
+
+	for {
+		tok, ok := p.accept("<", "<=", ">", ">=", "in")
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseAdditive()
+		if err != nil {
+			return node{}, err
+		}
+
+		l := left
+		if tok.text == "in" {
+			if right.typ != listOf(left.typ) {
+				return node{}, typeError(tok, "cannot look for %s in %s", left.typ, right.typ)
+			}
+			left = node{typ: Bool, eval: func(env Env) (any, error) {
+				value, list, err := evalBoth(env, l, right)
+				if err != nil {
+					return nil, err
+				}
+				return slices.ContainsFunc(list.([]any), func(item any) bool { return equal(value, item) }), nil
+			}}
+			continue
+		}
+
+		if left.typ != Number || right.typ != Number {
+			return node{}, typeError(tok, "%s needs numbers, got %s and %s", tok.text, left.typ, right.typ)
+		}
+		operator := tok.text
+		left = node{typ: Bool, eval: func(env Env) (any, error) {
+			a, b, err := evalBoth(env, l, right)
+			if err != nil {
+				return nil, err
+			}
+			cmp := a.(decimal.Decimal).Cmp(b.(decimal.Decimal))
+			switch operator {
+			case "<":
+				return cmp < 0, nil
+			case "<=":
+				return cmp <= 0, nil
+			case ">":
+				return cmp > 0, nil
+			default:
+				return cmp >= 0, nil
+			}
+		}}
+	}
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	return p.parseArithmetic([]string{"+", "-"}, p.parseMultiplicative)
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	return p.parseArithmetic([]string{"*", "/", "%"}, p.parseUnary)
+}
+
+func (p *parser) parseArithmetic(operators []string, operand func() (node, error)) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return node{}, err
+	}
+
+	for {
+		tok, ok := p.accept(operators...)
+		if !ok {
+			return left, nil
+		}
+
+		right, err := operand()
+		if err != nil {
+			return node{}, err
+		}
+		if left.typ != Number || right.typ != Number {
+			return node{}, typeError(tok, "%s needs numbers, got %s and %s", tok.text, left.typ, right.typ)
+		}
+
+		l, operator := left, tok.text
+		left = node{typ: Number, eval: func(env Env) (any, error) {
+			a, b, err := evalBoth(env, l, right)
+			if err != nil {
+				return nil, err
+			}
+			return arithmetic(operator, a.(decimal.Decimal), b.(decimal.Decimal))
+		}}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	tok, ok := p.accept("!", "-")
+	if !ok {
+		return p.parsePrimary()
+	}
+
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > MaxDepth {
+		return node{}, fmt.Errorf("expression is nested more than %d levels deep at position %d", MaxDepth, tok.pos+1)
+	}
+
+	operand, err := p.parseUnary()
+	if err != nil {
+		return node{}, err
+	}
+
+	if tok.text == "!" {
+		if operand.typ != Bool {
+			return node{}, typeError(tok, "! needs a bool, got %s", operand.typ)
+		}
+		return node{typ: Bool, eval: func(env Env) (any, error) {
+			value, err := operand.eval(env)
+			if err != nil {
+				return nil, err
+			}
+			return !value.(bool), nil
+		}}, nil
+	}
+
+	if operand.typ != Number {
+		return node{}, typeError(tok, "- needs a number, got %s", operand.typ)
+	}
+	return node{typ: Number, eval: func(env Env) (any, error) {
+		value, err := operand.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		return value.(decimal.Decimal).Neg(), nil
+	}}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		value, err := decimal.NewFromString(tok.text)
+		if err != nil {
+			return node{}, typeError(tok, "invalid number %s", tok.text)
+		}
+		return constant(Number, value), nil
+
+	case tokenString:
+		return constant(String, tok.text), nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return constant(Bool, true), nil
+		case "false":
+			return constant(Bool, false), nil
+		}
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(tok)
+		}
+		return p.variable(tok)
+
+	case tokenOperator:
+		switch tok.text {
+		case "(":
+			inner, err := p.parseExpression()
+			if err != nil {
+				return node{}, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			return p.parseList(tok)
+		}
+	}
+
+	return node{}, unexpected(tok)
+}
+
+func (p *parser) variable(tok token) (node, error) {
+	typ, ok := p.vars[tok.text]
+	if !ok {
+		return node{}, typeError(tok, "unknown variable %s", tok.text)
+	}
+
+	name := tok.text
+	return node{typ: typ, eval: func(env Env) (any, error) {
+		value, ok := env[name]
+		if !ok || !hasType(value, typ) {
+			return nil, fmt.Errorf("variable %s is not set to a %s", name, typ)
+		}
+		return value, nil
+	}}, nil
+}
+
+func (p *parser) parseList(open token) (node, error) {
+	items := make([]node, 0)
+	for {
+		item, err := p.parseExpression()
+		if err != nil {
+			return node{}, err
+		}
+		if item.typ != Number && item.typ != String {
+			return node{}, typeError(open, "lists hold numbers or strings, got %s", item.typ)
+		}
+		if len(items) > 0 && item.typ != items[0].typ {
+			return node{}, typeError(open, "list mixes %s and %s", items[0].typ, item.typ)
+		}
+		items = append(items, item)
+
+		if _, ok := p.accept(","); !ok {
+			break
+		}
+	}
+	if err := p.expect("]"); err != nil {
+		return node{}, err
+	}
+
+	return node{typ: listOf(items[0].typ), eval: func(env Env) (any, error) {
+		values := make([]any, 0, len(items))
+		for _, item := range items {
+			value, err := item.eval(env)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}}, nil
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return node{}, typeError(name, "unknown function %s", name.text)
+	}
+
+	args := make([]node, 0, fn.arity)
+	if _, ok = p.accept(")"); !ok {
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return node{}, err
+			}
+			args = append(args, arg)
+
+			if _, ok := p.accept(","); !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return node{}, err
+		}
+	}
+
+	if len(args) != fn.arity {
+		return node{}, typeError(name, "%s takes %d arguments, got %d", name.text, fn.arity, len(args))
+	}
+	for _, arg := range args {
+		if arg.typ != Number {
+			return node{}, typeError(name, "%s needs numbers, got %s", name.text, arg.typ)
+		}
+	}
+
+	return node{typ: Number, eval: func(env Env) (any, error) {
+		values := make([]decimal.Decimal, 0, len(args))
+		for _, arg := range args {
+			value, err := arg.eval(env)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value.(decimal.Decimal))
+		}
+		return fn.call(values), nil
+	}}, nil
+}
+
+type function struct {
+	arity int
+	call  func(args []decimal.Decimal) decimal.Decimal
+}
+
+var functions = map[string]function{
+	"floor": {arity: 1, call: func(args []decimal.Decimal) decimal.Decimal { return args[0].Floor() }},
+	"ceil":  {arity: 1, call: func(args []decimal.Decimal) decimal.Decimal { return args[0].Ceil() }},
+	"round": {arity: 1, call: func(args []decimal.Decimal) decimal.Decimal { return args[0].Round(0) }},
+	"abs":   {arity: 1, call: func(args []decimal.Decimal) decimal.Decimal { return args[0].Abs() }},
+	"min":   {arity: 2, call: func(args []decimal.Decimal) decimal.Decimal { return decimal.Min(args[0], args[1]) }},
+	"max":   {arity: 2, call: func(args []decimal.Decimal) decimal.Decimal { return decimal.Max(args[0], args[1]) }},
+}
+
+func constant(typ Type, value any) node {
+	return node{typ: typ, eval: func(Env) (any, error) { return value, nil }}
+}
+
+func evalBoth(env Env, left, right node) (any, any, error) {
+	a, err := left.eval(env)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := right.eval(env)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, b, nil
+}
+
+func arithmetic(operator string, a, b decimal.Decimal) (decimal.Decimal, error) {
+	switch operator {
+	case "+":
+		return a.Add(b), nil
+	case "-":
+		return a.Sub(b), nil
+	case "*":
+		return a.Mul(b), nil
+	}
+
+	if b.IsZero() {
+		return decimal.Zero, fmt.Errorf("division by zero")
+	}
+	if operator == "/" {
+		return a.Div(b), nil
+	}
+	return a.Mod(b), nil
+}
+
+func equal(a, b any) bool {
+	if value, ok := a.(decimal.Decimal); ok {
+		return value.Equal(b.(decimal.Decimal))
+	}
+	return a == b
+}
+
+func hasType(value any, typ Type) bool {
+	switch value.(type) {
+	case bool:
+		return typ == Bool
+	case decimal.Decimal:
+		return typ == Number
+	case string:
+		return typ == String
+	}
+	return false
+}
+
+func isList(typ Type) bool {
+	return typ == NumberList || typ == StringList
+}
+
+func listOf(typ Type) Type {
+	switch typ {
+	case Number:
+		return NumberList
+	case String:
+		return StringList
+	}
+	return ""
+}
+
+func unexpected(tok token) error {
+	if tok.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos+1)
+}
+
+func typeError(tok token, format string, args ...any) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), tok.pos+1)
+}
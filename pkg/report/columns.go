@@ -0,0 +1,76 @@
+package report
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+type Column struct {
+	Name  string
+	Type  reflect.Type
+	index int
+}
+
+func columnsOf(t reflect.Type) ([]Column, error) {
+	if t == nil {
+		return nil, fmt.Errorf("report rows must be structs")
+	}
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("report rows must be structs, got %s", t)
+	}
+
+	var columns []Column
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("csv")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		columns = append(columns, Column{Name: tag, Type: t.Field(i).Type, index: i})
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("%s has no csv columns", t)
+	}
+	return columns, nil
+}
+
+// values returns the column values of row in column order.
+func values(columns []Column, row any) ([]any, error) {
+	value := reflect.ValueOf(row)
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, fmt.Errorf("nil report row")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("report rows must be structs, got %T", row)
+	}
+
+	result := make([]any, len(columns))
+	for i, column := range columns {
+		result[i] = value.Field(column.index).Interface()
+	}
+	return result, nil
+}
+
+// formatValue renders a value the way it appears in text formats.
+func formatValue(v any) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case fmt.Stringer:
+		return value.String()
+	default:
+		return fmt.Sprint(value)
+	}
+}
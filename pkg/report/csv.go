@@ -0,0 +1,49 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string {
+	return "text/csv"
+}
+
+func (csvEncoder) NewWriter(w io.Writer, columns []Column) (RowWriter, error) {
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &csvWriter{writer: writer, columns: columns, record: make([]string, len(columns))}, nil
+}
+
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []Column
+	record  []string
+}
+
+func (c *csvWriter) Write(row any) error {
+	vals, err := values(c.columns, row)
+	if err != nil {
+		return err
+	}
+
+	for i, v := range vals {
+		c.record[i] = formatValue(v)
+	}
+	return c.writer.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string {
+	return "application/json"
+}
+
+// NewWriter writes a JSON array with one object per row, keys in column order.
+func (jsonEncoder) NewWriter(w io.Writer, columns []Column) (RowWriter, error) {
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(column.Name)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+
+	return &jsonWriter{writer: bufio.NewWriter(w), columns: columns, keys: keys}, nil
+}
+
+type jsonWriter struct {
+	writer  *bufio.Writer
+	columns []Column
+	keys    [][]byte
+	rows    int
+}
+
+func (j *jsonWriter) Write(row any) error {
+	vals, err := values(j.columns, row)
+	if err != nil {
+		return err
+	}
+
+	if j.rows == 0 {
+		j.writer.WriteString("[\n")
+	} else {
+		j.writer.WriteString(",\n")
+	}
+	j.rows++
+
+	j.writer.WriteByte('{')
+	for i, v := range vals {
+		if i > 0 {
+			j.writer.WriteByte(',')
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		j.writer.Write(j.keys[i])
+		j.writer.WriteByte(':')
+		j.writer.Write(data)
+	}
+	_, err = j.writer.WriteString("}")
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.rows == 0 {
+		j.writer.WriteString("[")
+	}
+	j.writer.WriteString("\n]\n")
+	return j.writer.Flush()
+}
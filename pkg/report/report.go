@@ -0,0 +1,146 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatJSON    Format = "json"
+	FormatXLSX    Format = "xlsx"
+	FormatParquet Format = "parquet"
+)
+
+// RowWriter writes rows of a single struct type. The columns and their order come
+// from the struct's csv tags, so every format carries the same columns as the CSV.
+type RowWriter interface {
+	Write(row any) error
+	// Close flushes buffered output. It does not close the underlying writer.
+	Close() error
+}
+
+type Encoder interface {
+	ContentType() string
+	NewWriter(w io.Writer, columns []Column) (RowWriter, error)
+}
+
+var encoders = map[Format]Encoder{
+	FormatCSV:     csvEncoder{},
+	FormatJSON:    jsonEncoder{},
+	FormatXLSX:    xlsxEncoder{},
+	FormatParquet: parquetEncoder{},
+}
+
+// Register adds or replaces the encoder used for format.
+func Register(format Format, encoder Encoder) {
+	encoders[format] = encoder
+}
+
+func ParseFormat(s string) (Format, error) {
+	format := Format(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := encoders[format]; !ok {
+		return "", fmt.Errorf("unsupported report format %q", s)
+	}
+	return format, nil
+}
+
+// FromAccept returns the first supported format in an Accept header, honouring
+// q-values. It returns false when nothing in the header is supported.
+func FromAccept(accept string) (Format, bool) {
+	type candidate struct {
+		format Format
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if _, err := fmt.Sscanf(value, "%g", &q); err != nil {
+				continue
+			}
+		}
+
+		for format, encoder := range encoders {
+			if encoder.ContentType() == mediaType && q > 0 {
+				candidates = append(candidates, candidate{format: format, q: q})
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].format, true
+}
+
+func (f Format) ContentType() string {
+	return encoders[f].ContentType()
+}
+
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// NewWriter returns a RowWriter for rows shaped like sample, which may be a
+// struct, a pointer to one, or a slice of either.
+func NewWriter(format Format, w io.Writer, sample any) (RowWriter, error) {
+	encoder, ok := encoders[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported report format %q", format)
+	}
+
+	columns, err := columnsOf(reflect.TypeOf(sample))
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.NewWriter(w, columns)
+}
+
+// Encode writes every element of rows, which must be a slice of structs or of
+// pointers to structs.
+func Encode(format Format, w io.Writer, rows any) error {
+	value := reflect.ValueOf(rows)
+	if value.Kind() != reflect.Slice {
+		return fmt.Errorf("rows must be a slice, got %T", rows)
+	}
+
+	writer, err := NewWriter(format, w, rows)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		if err = writer.Write(value.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// ReplaceExtension swaps the extension of a path or pattern for the format's own.
+func ReplaceExtension(path string, format Format) string {
+	ext := ""
+	if i := strings.LastIndex(path, "."); i > strings.LastIndex(path, "/") {
+		ext = path[i:]
+	}
+	return strings.TrimSuffix(path, ext) + format.Extension()
+}
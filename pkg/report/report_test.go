@@ -0,0 +1,165 @@
+package report
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	parquetreader "github.com/xitongsys/parquet-go/reader"
+	"github.com/xuri/excelize/v2"
+)
+
+type testRow struct {
+	CustomerID string          `csv:"customer_id"`
+	Points     int64           `csv:"points"`
+	Amount     decimal.Decimal `csv:"amount"`
+	Ratio      float64         `csv:"ratio"`
+	Active     bool            `csv:"active"`
+	Internal   string          `csv:"-"`
+}
+
+var testRows = []*testRow{
+	{CustomerID: "U000001", Points: 27, Amount: decimal.RequireFromString("100.50"), Ratio: 0.5, Active: true},
+	{CustomerID: "U000002", Points: 4, Amount: decimal.RequireFromString("40"), Ratio: 1.25},
+}
+
+// parquetTestRow is testRow as a parquet reader sees it.
+type parquetTestRow struct {
+	CustomerID string  `parquet:"name=customer_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Points     int64   `parquet:"name=points, type=INT64"`
+	Amount     string  `parquet:"name=amount, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Ratio      float64 `parquet:"name=ratio, type=DOUBLE"`
+	Active     bool    `parquet:"name=active, type=BOOLEAN"`
+}
+
+type ReportTestSuite struct {
+	suite.Suite
+}
+
+func TestReportTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportTestSuite))
+}
+
+func (suite *ReportTestSuite) TestEncode_CSV() {
+	var buf bytes.Buffer
+	suite.NoError(Encode(FormatCSV, &buf, testRows))
+
+	suite.Equal("customer_id,points,amount,ratio,active\n"+
+		"U000001,27,100.5,0.5,true\n"+
+		"U000002,4,40,1.25,false\n", buf.String())
+}
+
+func (suite *ReportTestSuite) TestEncode_CSVHeaderOnlyWhenEmpty() {
+	var buf bytes.Buffer
+	suite.NoError(Encode(FormatCSV, &buf, []testRow{}))
+
+	suite.Equal("customer_id,points,amount,ratio,active\n", buf.String())
+}
+
+func (suite *ReportTestSuite) TestEncode_JSON() {
+	var buf bytes.Buffer
+	suite.NoError(Encode(FormatJSON, &buf, testRows))
+
+	suite.JSONEq(`[
+		{"customer_id":"U000001","points":27,"amount":"100.5","ratio":0.5,"active":true},
+		{"customer_id":"U000002","points":4,"amount":"40","ratio":1.25,"active":false}
+	]`, buf.String())
+
+	buf.Reset()
+	suite.NoError(Encode(FormatJSON, &buf, []testRow{}))
+	suite.JSONEq(`[]`, buf.String())
+}
+
+func (suite *ReportTestSuite) TestEncode_XLSX() {
+	var buf bytes.Buffer
+	suite.NoError(Encode(FormatXLSX, &buf, testRows))
+
+	file, err := excelize.OpenReader(&buf)
+	suite.Require().NoError(err)
+	defer file.Close()
+
+	rows, err := file.GetRows(xlsxSheet)
+	suite.NoError(err)
+	suite.Equal([][]string{
+		{"customer_id", "points", "amount", "ratio", "active"},
+		{"U000001", "27", "100.5", "0.5", "TRUE"},
+		{"U000002", "4", "40", "1.25", "FALSE"},
+	}, rows)
+}
+
+func (suite *ReportTestSuite) TestEncode_Parquet() {
+	var buf bytes.Buffer
+	suite.NoError(Encode(FormatParquet, &buf, testRows))
+
+	file, err := buffer.NewBufferFile(buf.Bytes())
+	suite.Require().NoError(err)
+	reader, err := parquetreader.NewParquetReader(file, new(parquetTestRow), 1)
+	suite.Require().NoError(err)
+	defer reader.ReadStop()
+
+	var names []string
+	for i := 1; i < len(reader.SchemaHandler.Infos); i++ {
+		names = append(names, reader.SchemaHandler.GetExName(i))
+	}
+	suite.Equal([]string{"customer_id", "points", "amount", "ratio", "active"}, names)
+	suite.Equal(int64(2), reader.GetNumRows())
+
+	rows := make([]parquetTestRow, 2)
+	suite.Require().NoError(reader.Read(&rows))
+	suite.Equal([]parquetTestRow{
+		{CustomerID: "U000001", Points: 27, Amount: "100.5", Ratio: 0.5, Active: true},
+		{CustomerID: "U000002", Points: 4, Amount: "40", Ratio: 1.25},
+	}, rows)
+}
+
+func (suite *ReportTestSuite) TestEncode_ParquetEmpty() {
+	var buf bytes.Buffer
+	suite.NoError(Encode(FormatParquet, &buf, []testRow{}))
+
+	file, err := buffer.NewBufferFile(buf.Bytes())
+	suite.Require().NoError(err)
+	reader, err := parquetreader.NewParquetReader(file, new(parquetTestRow), 1)
+	suite.Require().NoError(err)
+	defer reader.ReadStop()
+
+	suite.Equal(int64(0), reader.GetNumRows())
+	suite.Len(reader.Footer.Schema, 6)
+}
+
+func (suite *ReportTestSuite) TestEncode_RejectsNonStructRows() {
+	suite.Error(Encode(FormatCSV, io.Discard, []string{"a"}))
+	suite.Error(Encode(FormatCSV, io.Discard, testRow{}))
+}
+
+func (suite *ReportTestSuite) TestParseFormat() {
+	format, err := ParseFormat(" XLSX ")
+	suite.NoError(err)
+	suite.Equal(FormatXLSX, format)
+
+	_, err = ParseFormat("pdf")
+	suite.Error(err)
+}
+
+func (suite *ReportTestSuite) TestFromAccept() {
+	format, ok := FromAccept("application/json")
+	suite.True(ok)
+	suite.Equal(FormatJSON, format)
+
+	format, ok = FromAccept("text/csv;q=0.5, application/vnd.apache.parquet")
+	suite.True(ok)
+	suite.Equal(FormatParquet, format)
+
+	_, ok = FromAccept("*/*")
+	suite.False(ok)
+
+	_, ok = FromAccept("")
+	suite.False(ok)
+}
+
+func (suite *ReportTestSuite) TestReplaceExtension() {
+	suite.Equal("out/point-summary_%s.xlsx", ReplaceExtension("out/point-summary_%s.csv", FormatXLSX))
+	suite.Equal("./out.d/report.json", ReplaceExtension("./out.d/report", FormatJSON))
+}
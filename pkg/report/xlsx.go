@@ -0,0 +1,83 @@
+package report
+
+import (
+	"io"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+const xlsxSheet = "Sheet1"
+
+type xlsxEncoder struct{}
+
+func (xlsxEncoder) ContentType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// NewWriter buffers the workbook in excelize's stream writer, which spills to a
+// temporary file for large sheets, and writes it to w on Close.
+func (xlsxEncoder) NewWriter(w io.Writer, columns []Column) (RowWriter, error) {
+	file := excelize.NewFile()
+
+	stream, err := file.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+
+	writer := &xlsxWriter{out: w, file: file, stream: stream, columns: columns}
+	if err = writer.setRow(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+type xlsxWriter struct {
+	out     io.Writer
+	file    *excelize.File
+	stream  *excelize.StreamWriter
+	columns []Column
+	row     int
+}
+
+func (x *xlsxWriter) Write(row any) error {
+	vals, err := values(x.columns, row)
+	if err != nil {
+		return err
+	}
+
+	for i, v := range vals {
+		// Keep amounts numeric so they can be summed in a spreadsheet.
+		if d, ok := v.(decimal.Decimal); ok {
+			vals[i] = d.InexactFloat64()
+		}
+	}
+	return x.setRow(vals)
+}
+
+func (x *xlsxWriter) setRow(vals []any) error {
+	x.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, vals)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.out)
+}
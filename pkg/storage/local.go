@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type local struct {
+	root string
+}
+
+// NewLocal stores objects as files under root. An empty root resolves keys
+// against the working directory, and absolute keys are used as they are.
+func NewLocal(root string) Storage {
+	return &local{root: root}
+}
+
+func (l local) Put(_ context.Context, key string, r io.Reader, _ int64) error {
+	fullPath := l.path(key)
+
+	dir := filepath.Dir(fullPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	// Write beside the target and rename, so a reader that fails part way leaves
+	// the previous object in place rather than a truncated one.
+	file, err := os.CreateTemp(dir, "."+filepath.Base(fullPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(file.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), fullPath)
+}
+
+func (l local) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(l.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotExist
+	}
+	return file, err
+}
+
+func (l local) Exists(_ context.Context, key string) (bool, error) {
+	_, err := os.Stat(l.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (l local) List(_ context.Context, prefix string) ([]string, error) {
+	fullPrefix := l.path(prefix)
+	if strings.HasSuffix(prefix, "/") {
+		fullPrefix += string(filepath.Separator)
+	}
+
+	dir := filepath.Dir(fullPrefix)
+	if strings.HasSuffix(fullPrefix, string(filepath.Separator)) {
+		dir = fullPrefix
+	}
+
+	var keys []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasPrefix(p, fullPrefix) {
+			return nil
+		}
+
+		keys = append(keys, l.key(p))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (l local) path(key string) string {
+	return filepath.Join(l.root, filepath.FromSlash(key))
+}
+
+func (l local) key(p string) string {
+	if l.root == "" {
+		return filepath.ToSlash(p)
+	}
+
+	rel, err := filepath.Rel(l.root, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(rel)
+}
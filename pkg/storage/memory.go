@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type memory struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+// NewMemory keeps objects in process memory. It is meant for tests and dry runs.
+func NewMemory() Storage {
+	return &memory{objects: make(map[string][]byte)}
+}
+
+func (m *memory) Put(_ context.Context, key string, r io.Reader, _ int64) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[objectKey(key)] = data
+	return nil
+}
+
+func (m *memory) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.objects[objectKey(key)]
+	if !ok {
+		return nil, ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memory) Exists(_ context.Context, key string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.objects[objectKey(key)]
+	return ok, nil
+}
+
+func (m *memory) List(_ context.Context, prefix string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	normalized := objectKey(prefix)
+	if strings.HasSuffix(prefix, "/") && normalized != "" {
+		normalized += "/"
+	}
+
+	var keys []string
+	for key := range m.objects {
+		if strings.HasPrefix(key, normalized) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
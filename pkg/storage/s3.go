@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// unknownSizePartSize bounds the buffer used for multipart uploads of unknown size.
+const unknownSizePartSize = 16 << 20
+
+type S3Options struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// Transport overrides the HTTP transport, mainly for tests.
+	Transport http.RoundTripper
+}
+
+type s3Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3 stores objects in an S3-compatible bucket such as AWS S3 or MinIO.
+func NewS3(opts S3Options) (Storage, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" {
+		return nil, errors.New("S3 storage requires an endpoint and a bucket")
+	}
+
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure:       opts.UseSSL,
+		Region:       opts.Region,
+		BucketLookup: minio.BucketLookupPath,
+		Transport:    opts.Transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create S3 client: %w", err)
+	}
+
+	return &s3Storage{client: client, bucket: opts.Bucket}, nil
+}
+
+func (s s3Storage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	opts := minio.PutObjectOptions{}
+	if size < 0 {
+		// Objects that fit in one part go up in a single request instead of a
+		// multipart upload.
+		var head bytes.Buffer
+		n, err := io.CopyN(&head, r, unknownSizePartSize+1)
+		switch {
+		case errors.Is(err, io.EOF):
+			r, size = &head, n
+		case err != nil:
+			return err
+		default:
+			r = io.MultiReader(&head, r)
+			opts.PartSize = unknownSizePartSize
+		}
+	}
+
+	_, err := s.client.PutObject(ctx, s.bucket, objectKey(key), r, size, opts)
+	return err
+}
+
+func (s s3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, objectKey(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = object.Stat(); err != nil {
+		object.Close()
+		if isNotFound(err) {
+			return nil, ErrNotExist
+		}
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func (s s3Storage) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.client.StatObject(ctx, s.bucket, objectKey(key), minio.StatObjectOptions{})
+	if isNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s s3Storage) List(ctx context.Context, prefix string) ([]string, error) {
+	normalized := objectKey(prefix)
+	if strings.HasSuffix(prefix, "/") && normalized != "" {
+		normalized += "/"
+	}
+
+	var keys []string
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: normalized, Recursive: true}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		keys = append(keys, object.Key)
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func isNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	code := minio.ToErrorResponse(err).Code
+	return code == "NoSuchKey" || code == "NotFound"
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/sirawong/point-accumulate-interview/pkg/config"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var ErrNotExist = errors.New("storage: object does not exist")
+
+// Storage keeps input and report artifacts. Keys are slash-separated; the local
+// driver maps them to paths, so existing FILE_PATH values keep working.
+type Storage interface {
+	// Put stores r under key. size may be -1 when unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Open returns ErrNotExist when key is missing.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	// List returns the keys that start with prefix, sorted.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+func New(cfg *config.Config) (Storage, error) {
+	switch cfg.StorageDriver {
+	case "", DriverLocal:
+		return NewLocal(""), nil
+	case DriverS3:
+		return NewS3(S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+// objectKey normalises key for object stores: "./a/b" and "/a/b" both become "a/b".
+func objectKey(key string) string {
+	return strings.TrimPrefix(path.Clean("/"+key), "/")
+}
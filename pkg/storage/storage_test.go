@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StorageTestSuite struct {
+	suite.Suite
+	ctx context.Context
+}
+
+func TestStorageTestSuite(t *testing.T) {
+	suite.Run(t, new(StorageTestSuite))
+}
+
+func (suite *StorageTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+}
+
+func (suite *StorageTestSuite) assertContract(store Storage) {
+	suite.NoError(store.Put(suite.ctx, "reports/a.csv", strings.NewReader("a"), 1))
+	suite.NoError(store.Put(suite.ctx, "./reports/b.csv", strings.NewReader("bb"), -1))
+	suite.NoError(store.Put(suite.ctx, "inputs/c.csv", strings.NewReader("c"), 1))
+
+	file, err := store.Open(suite.ctx, "reports/b.csv")
+	suite.Require().NoError(err)
+	data, err := io.ReadAll(file)
+	suite.NoError(err)
+	suite.NoError(file.Close())
+	suite.Equal("bb", string(data))
+
+	_, err = store.Open(suite.ctx, "reports/missing.csv")
+	suite.ErrorIs(err, ErrNotExist)
+
+	exists, err := store.Exists(suite.ctx, "reports/a.csv")
+	suite.NoError(err)
+	suite.True(exists)
+
+	exists, err = store.Exists(suite.ctx, "reports/missing.csv")
+	suite.NoError(err)
+	suite.False(exists)
+
+	keys, err := store.List(suite.ctx, "reports/")
+	suite.NoError(err)
+	suite.Equal([]string{"reports/a.csv", "reports/b.csv"}, keys)
+
+	keys, err = store.List(suite.ctx, "nothing/")
+	suite.NoError(err)
+	suite.Empty(keys)
+}
+
+func (suite *StorageTestSuite) TestLocal() {
+	suite.assertContract(NewLocal(suite.T().TempDir()))
+}
+
+func (suite *StorageTestSuite) TestMemory() {
+	suite.assertContract(NewMemory())
+}
+
+func (suite *StorageTestSuite) TestS3() {
+	server := httptest.NewTLSServer(newFakeS3("reports"))
+	defer server.Close()
+
+	store, err := NewS3(S3Options{
+		Endpoint:  strings.TrimPrefix(server.URL, "https://"),
+		Region:    "us-east-1",
+		Bucket:    "reports",
+		AccessKey: "access",
+		SecretKey: "secret",
+		UseSSL:    true,
+		Transport: server.Client().Transport,
+	})
+	suite.Require().NoError(err)
+
+	suite.assertContract(store)
+}
+
+func (suite *StorageTestSuite) TestNewS3_RequiresBucket() {
+	_, err := NewS3(S3Options{Endpoint: "localhost:9000"})
+	suite.Error(err)
+}
+
+// fakeS3 implements the handful of path-style S3 calls the driver makes.
+type fakeS3 struct {
+	bucket  string
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeS3(bucket string) *fakeS3 {
+	return &fakeS3{bucket: bucket, objects: make(map[string][]byte)}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPut && key != "":
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		f.objects[key] = data
+		w.Header().Set("ETag", fmt.Sprintf("%q", fmt.Sprint(len(data))))
+	case (r.Method == http.MethodGet || r.Method == http.MethodHead) && key != "":
+		data, ok := f.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", fmt.Sprintf("%q", fmt.Sprint(len(data))))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case r.Method == http.MethodGet:
+		f.list(w, r.URL.Query().Get("prefix"))
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, prefix string) {
+	type content struct {
+		Key          string
+		Size         int
+		LastModified string
+		ETag         string
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		IsTruncated bool
+		Contents    []content
+	}{Name: f.bucket, Prefix: prefix}
+
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			Size:         len(f.objects[key]),
+			LastModified: time.Now().UTC().Format(time.RFC3339),
+			ETag:         fmt.Sprintf("%q", fmt.Sprint(len(f.objects[key]))),
+		})
+	}
+	result.KeyCount = len(keys)
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
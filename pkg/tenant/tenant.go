@@ -0,0 +1,58 @@
+// Package tenant carries the loyalty program a request belongs to. Each program
+// other than the default one keeps its own collections, prefixed with its ID, and
+// its own directory of input and output files.
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// Default is the program of requests that name none. Its collections and files
+// keep the names they had before programs were added.
+const Default = ""
+
+type contextKey struct{}
+
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Validate checks that id can be used in collection names and file paths.
+func Validate(id string) error {
+	if id == Default || validID.MatchString(id) {
+		return nil
+	}
+	return fmt.Errorf("tenant %q must be 1-32 lowercase letters, digits, '-' or '_'", id)
+}
+
+// WithTenant returns ctx for the program id.
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the program of ctx, or Default when it names none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// CollectionName returns the name of the program's copy of collection.
+func CollectionName(ctx context.Context, collection string) string {
+	id := FromContext(ctx)
+	if id == Default {
+		return collection
+	}
+	return id + "_" + collection
+}
+
+// Path returns the program's copy of a file path or storage key, which lives in
+// a directory named after the program next to the default program's file.
+func Path(ctx context.Context, name string) string {
+	id := FromContext(ctx)
+	if id == Default {
+		return name
+	}
+	dir, file := path.Split(name)
+	return dir + id + "/" + file
+}
@@ -0,0 +1,46 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TenantTestSuite struct {
+	suite.Suite
+}
+
+func TestTenantTestSuite(t *testing.T) {
+	suite.Run(t, new(TenantTestSuite))
+}
+
+func (suite *TenantTestSuite) TestFromContext() {
+	suite.Equal(Default, FromContext(context.Background()))
+	suite.Equal("brand_b", FromContext(WithTenant(context.Background(), "brand_b")))
+}
+
+func (suite *TenantTestSuite) TestCollectionName() {
+	suite.Equal("customers", CollectionName(context.Background(), "customers"))
+	suite.Equal("brand_b_customers", CollectionName(WithTenant(context.Background(), "brand_b"), "customers"))
+}
+
+func (suite *TenantTestSuite) TestPath() {
+	ctx := WithTenant(context.Background(), "brand_b")
+
+	suite.Equal("./output_files/point-summary_%s.csv", Path(context.Background(), "./output_files/point-summary_%s.csv"))
+	suite.Equal("./output_files/brand_b/point-summary_%s.csv", Path(ctx, "./output_files/point-summary_%s.csv"))
+	suite.Equal("brand_b/point-summary_%s.csv", Path(ctx, "point-summary_%s.csv"))
+}
+
+func (suite *TenantTestSuite) TestValidate() {
+	suite.NoError(Validate(Default))
+	suite.NoError(Validate("brand_b"))
+	suite.NoError(Validate("brand-2"))
+
+	suite.Error(Validate("Brand"))
+	suite.Error(Validate("../brand"))
+	suite.Error(Validate("brand.b"))
+	suite.Error(Validate("_brand"))
+	suite.Error(Validate("a234567890123456789012345678901234"))
+}
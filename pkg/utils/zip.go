@@ -13,11 +13,15 @@ func AddFileToZip(zipWriter *zip.Writer, filePath, fileName string) error {
 	}
 	defer file.Close()
 
+	return AddReaderToZip(zipWriter, file, fileName)
+}
+
+func AddReaderToZip(zipWriter *zip.Writer, r io.Reader, fileName string) error {
 	writer, err := zipWriter.Create(fileName)
 	if err != nil {
 		return err
 	}
 
-	_, err = io.Copy(writer, file)
+	_, err = io.Copy(writer, r)
 	return err
 }
@@ -11,21 +11,83 @@ db.createUser({
     ]
 });
 
-db.createCollection('customers');
-
-db.customers.createIndex({"customer_id": 1}, {unique: true});
-
-db.createCollection('rules');
-db.rules.createIndex({
-        "status": 1,
-        "conditions.branch_id": 1,
-        "conditions.category_ids": 1,
-    },
-    {
-        name: "status_1_conditions.branch_id_1_conditions.category_ids_1"
+// Every loyalty program has its own collections. The default program's have the
+// plain names, and each program in TENANTS has a copy prefixed with its ID.
+function createProgram(prefix) {
+    const collection = (name) => {
+        db.createCollection(prefix + name);
+        return db.getCollection(prefix + name);
+    };
+
+    // Recompute gives customers_shadow the same indexes before swapping it in;
+    // keep indexesCustomer in internal/repository/mongodb/customers in step.
+    const customers = collection('customers');
+    customers.createIndex({"customer_id": 1}, {unique: true});
+    customers.createIndex({"records.purchase_date": 1});
+    customers.createIndex({"points_by_date.$**": 1});
+
+    const rules = collection('rules');
+    rules.createIndex({
+            "status": 1,
+            "conditions.branch_id": 1,
+            "conditions.category_ids": 1,
+        },
+        {
+            name: "status_1_conditions.branch_id_1_conditions.category_ids_1"
+        }
+    );
+    rules.createIndex({
+            "status": 1,
+            "conditions.branch_id": 1,
+            "conditions.product_ids": 1,
+        },
+        {
+            name: "status_1_conditions.branch_id_1_conditions.product_ids_1"
+        }
+    );
+    rules.createIndex({"status": 1, "conditions.branch_ids": 1});
+    rules.createIndex({"status": 1, "conditions.branch_groups": 1});
+
+    collection('branch_groups').createIndex({"name": 1}, {unique: true});
+
+    const tiers = collection('tiers');
+    tiers.createIndex({"name": 1}, {unique: true});
+
+    if (tiers.countDocuments() === 0) {
+        tiers.insertMany([
+            { name: "SILVER", rank: 1, basis: "POINTS", threshold: Decimal128("0"), multiplier: Decimal128("1") },
+            { name: "GOLD", rank: 2, basis: "POINTS", threshold: Decimal128("1000"), multiplier: Decimal128("1.25") },
+            { name: "PLATINUM", rank: 3, basis: "SPEND", threshold: Decimal128("100000"), multiplier: Decimal128("1.5") },
+        ]);
     }
-);
 
+    const ruleVersions = collection('rule_versions');
+    ruleVersions.createIndex({"rule_id": 1, "version": 1}, {unique: true});
+    ruleVersions.createIndex({"created_at": 1});
+
+    const adjustments = collection('adjustments');
+    adjustments.createIndex({"customer_id": 1, "requested_at": -1});
+    adjustments.createIndex({"status": 1, "requested_at": -1});
+
+    collection('households');
+
+    const merges = collection('merges');
+    merges.createIndex({"source_customer_id": 1});
+    merges.createIndex({"target_customer_id": 1});
+
+    const auditLog = collection('audit_log');
+    auditLog.createIndex({"occurred_at": 1});
+    auditLog.createIndex({"actor": 1, "_id": 1});
+    auditLog.createIndex({"action": 1, "_id": 1});
+}
+
+createProgram('');
+(process.env.TENANTS || '').split(',').map((id) => id.trim()).filter((id) => id !== '').forEach((id) => {
+    createProgram(id + '_');
+    print(`Created collections for tenant ${id}.`);
+});
+
+// The sample rules are for the default program only.
 if (db.rules.countDocuments() === 0) {
     print("Collection is empty. Inserting initial data...");
 
@@ -120,7 +182,16 @@ if (db.rules.countDocuments() === 0) {
         }
     ];
 
+    const createdAt = new Date();
+    rulesToInsert.forEach((rule) => {
+        rule._id = new ObjectId();
+        rule.version = NumberLong(1);
+        rule.created_by = "system";
+        rule.created_at = createdAt;
+    });
+
     db.rules.insertMany(rulesToInsert);
+    db.rule_versions.insertMany(rulesToInsert.map(({_id, ...rule}) => ({rule_id: _id, ...rule})));
     print(`Inserted ${rulesToInsert.length} initial rules.`);
 
 } else {
@@ -50,7 +50,10 @@ fi
 FILE_COUNT=$(echo "$CSV_FILES" | wc -l)
 print_info "Found $FILE_COUNT CSV files in '$FOLDER'"
 
-CURL_CMD="curl -X POST -o \"$OUTPUT_FILE\""
+CURL_CMD="curl -X POST -H \"Accept: application/zip\" -o \"$OUTPUT_FILE\""
+if [ -n "$API_KEY" ]; then
+    CURL_CMD="$CURL_CMD -H \"X-API-Key: $API_KEY\""
+fi
 
 while IFS= read -r file; do
     CURL_CMD="$CURL_CMD -F \"${FILE_KEY}=@${file};type=text/csv\""